@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/iriam/worklogr/internal/app"
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/spf13/cobra"
+)
+
+type annotateOptions struct {
+	hide      bool
+	unhide    bool
+	pin       bool
+	unpin     bool
+	note      string
+	clearNote bool
+	tags      []string
+	untags    []string
+	clear     bool
+}
+
+func newAnnotateCmd(rootOptions *rootOptions) *cobra.Command {
+	options := &annotateOptions{}
+	usecase := app.NewAnnotateUsecase()
+	cmd := &cobra.Command{
+		Use:   "annotate <イベントID>",
+		Short: "イベントのピン留め・非表示・メモ・タグを設定",
+		Long: `収集済みイベントに注釈を付けます。注釈はイベントとは別に保存され、再収集しても維持されます。
+
+  --hide      非表示にします。非表示のイベントは export / timesheet / summarize から外れます（--include-hidden で含められます）
+  --pin       ピン留めします。--pinned-only でピン留めしたイベントだけを出力でき、summarize では重要なイベントとして扱います
+  --note      メモを付けます。json-ai のエクスポートと summarize のMarkdownに含まれます
+  --tag       タグを付けます。export / query の --tag で絞り込めます
+
+フラグを指定しない場合は現在の注釈を表示します。`,
+		Example: `  worklogr annotate github_pr_created_octo/app_42 --pin --note "リリースのブロッカーを解消"
+  worklogr annotate slack_search_C123_1700000000.000100 --hide
+  worklogr annotate github_pr_created_octo/app_42 --tag release --untag wip`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			update, err := options.update(cmd)
+			if err != nil {
+				return err
+			}
+
+			result, err := usecase.Run(app.AnnotateRequest{
+				ConfigPath: rootOptions.configPath,
+				EventID:    args[0],
+				Update:     update,
+			})
+			if err != nil {
+				return err
+			}
+
+			if result.Changed {
+				fmt.Printf("イベント %s の注釈を更新しました\n", result.EventID)
+			} else {
+				fmt.Printf("イベント %s\n", result.EventID)
+			}
+			if result.Annotation == nil {
+				fmt.Println("注釈はありません")
+				return nil
+			}
+			printAnnotation(os.Stdout, result.Annotation)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&options.hide, "hide", false, "非表示にする（エクスポートから除外）")
+	cmd.Flags().BoolVar(&options.unhide, "unhide", false, "非表示を解除する")
+	cmd.Flags().BoolVar(&options.pin, "pin", false, "ピン留めする")
+	cmd.Flags().BoolVar(&options.unpin, "unpin", false, "ピン留めを解除する")
+	cmd.Flags().StringVar(&options.note, "note", "", "メモを設定する")
+	cmd.Flags().BoolVar(&options.clearNote, "clear-note", false, "メモを削除する")
+	cmd.Flags().StringSliceVar(&options.tags, "tag", []string{}, "タグを追加する（複数指定可）")
+	cmd.Flags().StringSliceVar(&options.untags, "untag", []string{}, "タグを削除する（複数指定可）")
+	cmd.Flags().BoolVar(&options.clear, "clear", false, "すべての注釈を削除する")
+	cmd.MarkFlagsMutuallyExclusive("hide", "unhide")
+	cmd.MarkFlagsMutuallyExclusive("pin", "unpin")
+	cmd.MarkFlagsMutuallyExclusive("note", "clear-note")
+
+	return cmd
+}
+
+func (o *annotateOptions) update(cmd *cobra.Command) (app.AnnotationUpdate, error) {
+	update := app.AnnotationUpdate{
+		AddTags:    o.tags,
+		RemoveTags: o.untags,
+		Clear:      o.clear,
+	}
+	switch {
+	case o.hide:
+		update.Hidden = boolPtr(true)
+	case o.unhide:
+		update.Hidden = boolPtr(false)
+	}
+	switch {
+	case o.pin:
+		update.Pinned = boolPtr(true)
+	case o.unpin:
+		update.Pinned = boolPtr(false)
+	}
+	if o.hide && o.pin {
+		return update, fmt.Errorf("--hide と --pin は同時に指定できません")
+	}
+	if cmd.Flags().Changed("note") {
+		note := o.note
+		update.Note = &note
+	} else if o.clearNote {
+		empty := ""
+		update.Note = &empty
+	}
+	return update, nil
+}
+
+// printAnnotation はイベントの注釈（ピン留め・非表示・メモ・タグ）を表示します
+func printAnnotation(out io.Writer, annotation *config.EventAnnotation) {
+	if annotation == nil || annotation.IsEmpty() {
+		return
+	}
+	var flags []string
+	if annotation.Pinned {
+		flags = append(flags, "ピン留め")
+	}
+	if annotation.Hidden {
+		flags = append(flags, "非表示")
+	}
+	if len(flags) > 0 {
+		fmt.Fprintf(out, "状態:     %s\n", strings.Join(flags, " / "))
+	}
+	if len(annotation.Tags) > 0 {
+		fmt.Fprintf(out, "タグ:     %s\n", strings.Join(annotation.Tags, ", "))
+	}
+	if annotation.Note != "" {
+		fmt.Fprintf(out, "メモ:     %s\n", annotation.Note)
+	}
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/iriam/worklogr/internal/app"
+	"github.com/spf13/cobra"
+)
+
+func newAuthCmd(rootOptions *rootOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "サービスの認証を管理",
+		Long: `Slack・GitHub・OktaのOAuthログインを行い、取得したトークンを暗号化したトークンストアに保存します。
+
+保存したトークンは、設定ファイルで access_token が未設定のサービスに自動的に使用されます。
+use_okta を有効にしたサービスは、Oktaのトークンを交換（RFC 8693）して取得したトークンを使用します。`,
+	}
+	cmd.AddCommand(newAuthLoginCmd(rootOptions))
+	cmd.AddCommand(newAuthStatusCmd(rootOptions))
+	cmd.AddCommand(newAuthRefreshCmd(rootOptions))
+	cmd.AddCommand(newAuthLogoutCmd(rootOptions))
+	return cmd
+}
+
+type authLoginOptions struct {
+	device    bool
+	noBrowser bool
+	port      int
+	scopes    []string
+	timeout   time.Duration
+}
+
+func newAuthLoginCmd(rootOptions *rootOptions) *cobra.Command {
+	options := &authLoginOptions{}
+	usecase := app.NewAuthLoginUsecase()
+
+	cmd := &cobra.Command{
+		Use:   "login <slack|github|okta>",
+		Short: "ブラウザでOAuthログインしてトークンを保存",
+		Long: `OAuthの認可コードフロー（PKCE、ローカルのリダイレクト受付サーバー）でログインします。
+OAuthアプリのリダイレクトURLには http://127.0.0.1:<port>/callback を登録してください（--port で固定できます）。
+
+GitHub・Oktaでは --device でデバイスフローも使用できます（ブラウザを開けない環境向け、client_secret不要）。
+
+Oktaは okta.domain と okta.client_id を使用し、okta.redirect_uri が設定されていればそのアドレスでリダイレクトを受け付けます。
+ログイン後、use_okta が有効なサービスのトークンをOktaのトークン交換で取得して保存します。`,
+		Example: `  worklogr auth login slack --port 8976
+  worklogr auth login github --device
+  worklogr auth login okta`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			service := strings.ToLower(args[0])
+			request := app.AuthLoginRequest{
+				ConfigPath: rootOptions.configPath,
+				Service:    service,
+				Device:     options.device,
+				Scopes:     options.scopes,
+				ListenAddr: fmt.Sprintf("127.0.0.1:%d", options.port),
+				Timeout:    options.timeout,
+				OpenURL: func(authURL string) error {
+					fmt.Println("以下のURLをブラウザで開いてログインしてください:")
+					fmt.Println(authURL)
+					if !options.noBrowser {
+						if err := openBrowser(authURL); err != nil {
+							fmt.Printf("ブラウザを起動できませんでした: %v\n", err)
+						}
+					}
+					fmt.Println("\nログインの完了を待っています...")
+					return nil
+				},
+				PromptDevice: func(verificationURI, userCode string) {
+					fmt.Printf("%s を開き、コード %s を入力してください\n", verificationURI, userCode)
+					fmt.Println("\n承認を待っています...")
+				},
+			}
+
+			result, err := usecase.Run(cmd.Context(), request)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("%s にログインしました（%s フロー）\n", result.Service, result.Flow)
+			if len(result.Scopes) > 0 {
+				fmt.Printf("スコープ: %s\n", strings.Join(result.Scopes, ", "))
+			}
+			if result.ExpiresAt != nil {
+				fmt.Printf("有効期限: %s\n", result.ExpiresAt.Format("2006-01-02 15:04:05"))
+			}
+			for _, exchange := range result.Exchanges {
+				name := serviceDisplayNames[exchange.Service]
+				if exchange.Error != nil {
+					fmt.Printf("%-15s: トークン交換に失敗しました - %v\n", name, exchange.Error)
+				} else {
+					fmt.Printf("%-15s: トークン交換で取得しました（有効期限: %s）\n", name, formatOptionalTime(exchange.ExpiresAt, "なし"))
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&options.device, "device", false, "デバイスフローを使用（GitHub・Okta）")
+	cmd.Flags().BoolVar(&options.noBrowser, "no-browser", false, "ブラウザを自動で起動しない")
+	cmd.Flags().IntVar(&options.port, "port", 0, "リダイレクトを受け付けるポート（0は空きポート）")
+	cmd.Flags().StringSliceVar(&options.scopes, "scopes", []string{}, "要求するスコープ（未指定時はサービスの既定値）")
+	cmd.Flags().DurationVar(&options.timeout, "timeout", 5*time.Minute, "ログイン完了までの待機時間")
+	return cmd
+}
+
+type authStatusOptions struct {
+	services  []string
+	skipCheck bool
+}
+
+func newAuthStatusCmd(rootOptions *rootOptions) *cobra.Command {
+	options := &authStatusOptions{}
+	usecase := app.NewAuthStatusUsecase()
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "サービスごとの認証状態を詳しく表示",
+		Long:  "各サービスのトークンの取得元・種類・スコープ・有効期限と、APIによる検証（ヘルスチェック）結果を表示します。\n検証時には付与スコープを確認し、有効な収集機能に不足しているスコープとその対処方法も表示します。",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := usecase.Run(cmd.Context(), app.AuthStatusRequest{
+				ConfigPath: rootOptions.configPath,
+				Services:   options.services,
+				SkipCheck:  options.skipCheck,
+			})
+			if err != nil {
+				return err
+			}
+
+			if result.TokenStoreError != nil {
+				fmt.Printf("警告: トークンストアを開けませんでした: %v\n\n", result.TokenStoreError)
+			}
+
+			for i, status := range result.Services {
+				if i > 0 {
+					fmt.Println()
+				}
+				fmt.Printf("%s\n", status.DisplayName)
+				fmt.Println(strings.Repeat("=", len(status.DisplayName)))
+				if !status.Enabled {
+					fmt.Println("  無効（設定ファイルで enabled: false）")
+					continue
+				}
+				fmt.Printf("  取得元:       %s\n", authSourceLabel(status.Source))
+				fmt.Printf("  トークン種別: %s\n", valueOrDash(status.TokenType))
+				fmt.Printf("  スコープ:     %s\n", valueOrDash(strings.Join(status.Scopes, ", ")))
+				fmt.Printf("  有効期限:     %s\n", formatOptionalTime(status.ExpiresAt, "なし"))
+				fmt.Printf("  最終更新:     %s\n", formatOptionalTime(status.LastRefresh, "-"))
+
+				if !status.Checked {
+					fmt.Println("  検証:         未実施（--no-check）")
+					continue
+				}
+				health := "OK"
+				if !status.Valid {
+					health = "NG"
+				}
+				fmt.Printf("  検証:         %s（%s）\n", health, status.LastChecked.Format("2006-01-02 15:04:05"))
+				if status.Error != "" {
+					fmt.Printf("  エラー:       %s\n", status.Error)
+				}
+				if status.GrantedScopes != nil {
+					fmt.Printf("  付与スコープ: %s\n", valueOrDash(strings.Join(status.GrantedScopes, ", ")))
+				}
+				for _, missing := range status.MissingScopes {
+					fmt.Printf("  不足スコープ: %s（%s）\n", strings.Join(missing.Scopes, ", "), missing.Feature)
+				}
+				for _, suggestion := range status.Suggestions {
+					fmt.Printf("  → %s\n", suggestion)
+				}
+				if status.ScopeHint != "" {
+					fmt.Printf("  → %s\n", status.ScopeHint)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&options.services, "services", []string{}, "対象サービス（例: slack,github,google_calendar）")
+	cmd.Flags().BoolVar(&options.skipCheck, "no-check", false, "APIによるトークン検証を行わない")
+	return cmd
+}
+
+type authRefreshOptions struct {
+	services []string
+	force    bool
+}
+
+func newAuthRefreshCmd(rootOptions *rootOptions) *cobra.Command {
+	options := &authRefreshOptions{}
+	usecase := app.NewAuthRefreshUsecase()
+
+	cmd := &cobra.Command{
+		Use:   "refresh",
+		Short: "期限切れのトークンを更新",
+		Long:  "リフレッシュトークンを使用して期限切れのアクセストークンを更新します。--force を指定すると有効期限内でも更新します。",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := usecase.Run(cmd.Context(), app.AuthRefreshRequest{
+				ConfigPath: rootOptions.configPath,
+				Services:   options.services,
+				Force:      options.force,
+			})
+			if err != nil {
+				return err
+			}
+
+			if result.TokenStoreError != nil {
+				fmt.Printf("警告: トークンストアを開けませんでした: %v\n", result.TokenStoreError)
+			}
+
+			failed := 0
+			for _, outcome := range result.Outcomes {
+				name := serviceDisplayNames[outcome.Service]
+				switch {
+				case outcome.Error != nil:
+					failed++
+					fmt.Printf("%-15s: 失敗 - %v\n", name, outcome.Error)
+				case outcome.Refreshed:
+					fmt.Printf("%-15s: 更新しました（有効期限: %s）\n", name, formatOptionalTime(outcome.ExpiresAt, "なし"))
+				default:
+					fmt.Printf("%-15s: スキップ - %s\n", name, outcome.Skipped)
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d 件のサービスでトークンの更新に失敗しました", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&options.services, "services", []string{}, "対象サービス（例: slack,github,google_calendar）")
+	cmd.Flags().BoolVar(&options.force, "force", false, "有効期限内でも更新する")
+	return cmd
+}
+
+func newAuthLogoutCmd(rootOptions *rootOptions) *cobra.Command {
+	usecase := app.NewAuthLogoutUsecase()
+
+	return &cobra.Command{
+		Use:   "logout <service>...",
+		Short: "保存済みのトークンを削除",
+		Long:  "トークンストアに保存したトークンを削除します。設定ファイルに直接書かれたトークンは削除されません。",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			services := make([]string, len(args))
+			for i, arg := range args {
+				services[i] = strings.ToLower(arg)
+			}
+
+			result, err := usecase.Run(app.AuthLogoutRequest{
+				ConfigPath: rootOptions.configPath,
+				Services:   services,
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, outcome := range result.Outcomes {
+				name := serviceDisplayNames[outcome.Service]
+				if outcome.Error != nil {
+					fmt.Printf("%-15s: 失敗 - %v\n", name, outcome.Error)
+				} else {
+					fmt.Printf("%-15s: トークンを削除しました\n", name)
+				}
+				for _, note := range outcome.Notes {
+					fmt.Printf("  注意: %s\n", note)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func authSourceLabel(source string) string {
+	switch source {
+	case app.AuthSourceConfig:
+		return "設定ファイル"
+	case app.AuthSourceTokenStore:
+		return "トークンストア（auth login）"
+	case app.AuthSourceGCloud:
+		return "gcloud認証（ADC）"
+	case app.AuthSourceOkta:
+		return "Okta（トークン交換）"
+	case app.AuthSourceCredentialsFile:
+		return "認証情報ファイル（credentials_file）"
+	default:
+		return "未設定"
+	}
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func formatOptionalTime(value *time.Time, fallback string) string {
+	if value == nil || value.IsZero() {
+		return fallback
+	}
+	return value.Format("2006-01-02 15:04:05")
+}
+
+// openBrowser はOSの既定ブラウザでURLを開きます
+func openBrowser(url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	default:
+		return exec.Command("xdg-open", url).Start()
+	}
+}
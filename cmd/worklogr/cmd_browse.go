@@ -0,0 +1,435 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iriam/worklogr/internal/app"
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/spf13/cobra"
+)
+
+type browseOptions struct {
+	startDate string
+	endDate   string
+	eventQueryOptions
+}
+
+func newBrowseCmd(rootOptions *rootOptions) *cobra.Command {
+	options := &browseOptions{}
+	usecase := app.NewBrowseUsecase()
+	cmd := &cobra.Command{
+		Use:   "browse",
+		Short: "収集したイベントを日ごとに閲覧",
+		Long: `収集済みイベントを日ごとのタイムラインで表示する対話型ブラウザです。
+
+イベントのmetadataや添付本文を確認し、ピン留め（+）・非表示（-）・メモ・タグを付けられます。
+非表示にしたイベントはすべてのエクスポートから外れ、--pinned-only を指定するとピン留めしたイベントだけを出力します。
+注釈はデータベースに保存され、再収集しても維持されます（worklogr annotate でも変更できます）。
+
+操作（h でいつでも表示できます）:
+` + browseHelp,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			startTime, endTime, err := parseAdjustedTimeRange(options.startDate, options.endDate, rootOptions.configPath)
+			if err != nil {
+				return fmt.Errorf("時間範囲が無効です: %w", err)
+			}
+
+			session, err := usecase.Open(app.BrowseRequest{
+				ConfigPath: rootOptions.configPath,
+				StartTime:  startTime,
+				EndTime:    endTime,
+				EventQuery: options.eventQuery(),
+			})
+			if err != nil {
+				return err
+			}
+			defer session.Close()
+
+			b := &browser{session: session, out: cmd.OutOrStdout(), open: openBrowser}
+			return b.run(cmd.InOrStdin())
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.startDate, "start", "s", "", "開始日時 (YYYY-MM-DD または YYYY-MM-DD HH:MM:SS)")
+	cmd.Flags().StringVarP(&options.endDate, "end", "e", "", "終了日時 (YYYY-MM-DD または YYYY-MM-DD HH:MM:SS)")
+	addEventQueryFlags(cmd, &options.eventQueryOptions, "表示")
+	cmd.MarkFlagRequired("start")
+	cmd.MarkFlagRequired("end")
+
+	return cmd
+}
+
+const browseHelp = `  n / p              次の日 / 前の日
+  d YYYY-MM-DD       指定した日へ移動
+  l                  現在の日を再表示
+  o <番号>           イベントの詳細（metadata・添付本文・関連イベント）を表示
+  + <番号...>        ピン留め（重要なイベント）
+  - <番号...>        非表示（レポートから除外）
+  = <番号...>        ピン留め・非表示を解除
+  note <番号> <メモ>  メモを設定（メモを省略すると削除）
+  tag <番号> <タグ,...>   タグを追加（-タグ で削除）
+  u <番号>           イベントのリンクをブラウザで開く
+  f [service=a,b] [type=x,y]   サービス・種別で絞り込み（引数なしで解除）
+  m                  ピン留め・非表示の件数を表示
+  reset              表示中の期間のピン留め・非表示を解除（確認あり。メモとタグは残ります）
+  h                  ヘルプ
+  q                  終了`
+
+// browseSession は browser が使う BrowseSession の操作です（テストで差し替え可能）
+type browseSession interface {
+	Days() []app.BrowseDay
+	Location() *time.Location
+	Annotate(event *config.Event, update app.AnnotationUpdate) error
+	AnnotationCounts() (pinned, hidden int)
+	ResetFlags() (int64, error)
+	SetFilter(services, types []string) error
+	Filter() (services, types []string)
+}
+
+// browser は1行ずつコマンドを読み取る対話型のイベントブラウザです
+type browser struct {
+	session browseSession
+	out     io.Writer
+	open    func(url string) error
+	day     int
+	// confirmingReset は reset の確認の返答を待っていることを表します
+	confirmingReset bool
+}
+
+func (b *browser) run(in io.Reader) error {
+	if len(b.session.Days()) == 0 {
+		fmt.Fprintln(b.out, "指定された期間にイベントが見つかりませんでした")
+		return nil
+	}
+
+	b.showDay()
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(b.out, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(b.out)
+			return scanner.Err()
+		}
+		if b.confirmingReset {
+			b.confirmingReset = false
+			b.confirmReset(strings.TrimSpace(scanner.Text()))
+			continue
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if quit := b.execute(fields[0], fields[1:]); quit {
+			return nil
+		}
+	}
+}
+
+func (b *browser) execute(command string, args []string) (quit bool) {
+	switch command {
+	case "q", "quit", "exit":
+		return true
+	case "h", "help", "?":
+		fmt.Fprintln(b.out, browseHelp)
+	case "n":
+		b.moveDay(b.day + 1)
+	case "p":
+		b.moveDay(b.day - 1)
+	case "l":
+		b.showDay()
+	case "d":
+		if len(args) != 1 {
+			fmt.Fprintln(b.out, "使い方: d YYYY-MM-DD")
+			return false
+		}
+		for i, day := range b.session.Days() {
+			if day.Date == args[0] {
+				b.moveDay(i)
+				return false
+			}
+		}
+		fmt.Fprintf(b.out, "%s のイベントはありません\n", args[0])
+	case "o":
+		if event := b.eventArg(args); event != nil {
+			b.showEvent(event)
+		}
+	case "+":
+		b.annotate(args, app.AnnotationUpdate{Pinned: boolPtr(true), Hidden: boolPtr(false)})
+	case "-":
+		b.annotate(args, app.AnnotationUpdate{Hidden: boolPtr(true), Pinned: boolPtr(false)})
+	case "=":
+		b.annotate(args, app.AnnotationUpdate{Hidden: boolPtr(false), Pinned: boolPtr(false)})
+	case "note":
+		if len(args) == 0 {
+			fmt.Fprintln(b.out, "使い方: note <番号> <メモ>")
+			return false
+		}
+		note := strings.Join(args[1:], " ")
+		b.annotate(args[:1], app.AnnotationUpdate{Note: &note})
+	case "tag":
+		if len(args) != 2 {
+			fmt.Fprintln(b.out, "使い方: tag <番号> <タグ,...>（-タグ で削除）")
+			return false
+		}
+		update := app.AnnotationUpdate{}
+		for _, tag := range strings.Split(args[1], ",") {
+			if removed, ok := strings.CutPrefix(tag, "-"); ok {
+				update.RemoveTags = append(update.RemoveTags, removed)
+			} else {
+				update.AddTags = append(update.AddTags, tag)
+			}
+		}
+		b.annotate(args[:1], update)
+	case "u":
+		if event := b.eventArg(args); event != nil {
+			b.openPermalink(event)
+		}
+	case "f":
+		b.setFilter(args)
+	case "m":
+		pinned, hidden := b.session.AnnotationCounts()
+		fmt.Fprintf(b.out, "ピン留め: %d 件 / 非表示: %d 件（表示中の期間）\n", pinned, hidden)
+	case "reset":
+		pinned, hidden := b.session.AnnotationCounts()
+		if pinned == 0 && hidden == 0 {
+			fmt.Fprintln(b.out, "表示中の期間にピン留め・非表示のイベントはありません")
+			return false
+		}
+		fmt.Fprintf(b.out, "表示中の期間のピン留め %d 件・非表示 %d 件を解除します。よろしいですか？ [y/N] ", pinned, hidden)
+		b.confirmingReset = true
+	default:
+		fmt.Fprintf(b.out, "不明なコマンドです: %s（h でヘルプ）\n", command)
+	}
+	return false
+}
+
+// confirmReset は reset の確認への返答が y のときだけ解除します
+func (b *browser) confirmReset(answer string) {
+	if answer != "y" && answer != "yes" {
+		fmt.Fprintln(b.out, "解除を取り消しました")
+		return
+	}
+	count, err := b.session.ResetFlags()
+	if err != nil {
+		fmt.Fprintf(b.out, "エラー: %v\n", err)
+		return
+	}
+	fmt.Fprintf(b.out, "%d 件のピン留め・非表示を解除しました\n", count)
+	b.showDay()
+}
+
+func (b *browser) moveDay(index int) {
+	days := b.session.Days()
+	if index < 0 || index >= len(days) {
+		fmt.Fprintln(b.out, "これ以上の日はありません")
+		return
+	}
+	b.day = index
+	b.showDay()
+}
+
+func (b *browser) currentDay() *app.BrowseDay {
+	days := b.session.Days()
+	if len(days) == 0 {
+		return nil
+	}
+	if b.day >= len(days) {
+		b.day = len(days) - 1
+	}
+	return &days[b.day]
+}
+
+func (b *browser) showDay() {
+	day := b.currentDay()
+	if day == nil {
+		fmt.Fprintln(b.out, "条件に一致するイベントがありません（f で絞り込みを解除できます）")
+		return
+	}
+
+	header := fmt.Sprintf("=== %s (%d/%d日目) %d 件", day.Date, b.day+1, len(b.session.Days()), len(day.Events))
+	if services, types := b.session.Filter(); len(services) > 0 || len(types) > 0 {
+		header += fmt.Sprintf("  [絞り込み: service=%s type=%s]", strings.Join(services, ","), strings.Join(types, ","))
+	}
+	fmt.Fprintln(b.out, header+" ===")
+
+	for i, event := range day.Events {
+		marker := " "
+		suffix := ""
+		if annotation := event.Annotation; annotation != nil {
+			switch {
+			case annotation.Hidden:
+				marker = "-"
+			case annotation.Pinned:
+				marker = "+"
+			}
+			if len(annotation.Tags) > 0 {
+				suffix += " #" + strings.Join(annotation.Tags, " #")
+			}
+			if annotation.Note != "" {
+				suffix += " ✎"
+			}
+		}
+		service := serviceDisplayNames[event.Service]
+		if service == "" {
+			service = event.Service
+		}
+		member := ""
+		if event.Member != "" {
+			member = " [" + event.Member + "]"
+		}
+		fmt.Fprintf(b.out, "%s %3d  %s  %-15s %-16s %s%s%s\n",
+			marker,
+			i+1,
+			event.Timestamp.In(b.session.Location()).Format("15:04"),
+			service,
+			event.Type,
+			truncateRunes(strings.ReplaceAll(event.Title, "\n", " "), 70),
+			member,
+			suffix)
+	}
+}
+
+// eventArg は現在の日の番号（1始まり）からイベントを返します
+func (b *browser) eventArg(args []string) *config.Event {
+	if len(args) != 1 {
+		fmt.Fprintln(b.out, "イベントの番号を1つ指定してください")
+		return nil
+	}
+	events := b.eventsArg(args)
+	if len(events) == 0 {
+		return nil
+	}
+	return events[0]
+}
+
+func (b *browser) eventsArg(args []string) []*config.Event {
+	day := b.currentDay()
+	if day == nil {
+		return nil
+	}
+	if len(args) == 0 {
+		fmt.Fprintln(b.out, "イベントの番号を指定してください")
+		return nil
+	}
+
+	var events []*config.Event
+	for _, arg := range args {
+		for _, part := range strings.Split(arg, ",") {
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 1 || index > len(day.Events) {
+				fmt.Fprintf(b.out, "番号が無効です: %s（1〜%d）\n", part, len(day.Events))
+				return nil
+			}
+			events = append(events, day.Events[index-1])
+		}
+	}
+	return events
+}
+
+func (b *browser) annotate(args []string, update app.AnnotationUpdate) {
+	events := b.eventsArg(args)
+	for _, event := range events {
+		if err := b.session.Annotate(event, update); err != nil {
+			fmt.Fprintf(b.out, "エラー: %v\n", err)
+			return
+		}
+	}
+	if len(events) > 0 {
+		b.showDay()
+	}
+}
+
+func (b *browser) setFilter(args []string) {
+	var services, types []string
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || value == "" {
+			fmt.Fprintf(b.out, "絞り込みの指定が無効です: %s（例: service=slack type=message）\n", arg)
+			return
+		}
+		values := strings.Split(value, ",")
+		switch key {
+		case "service", "services":
+			services = values
+		case "type", "types":
+			types = values
+		default:
+			fmt.Fprintf(b.out, "絞り込みに使えるのは service と type です: %s\n", key)
+			return
+		}
+	}
+
+	if err := b.session.SetFilter(services, types); err != nil {
+		fmt.Fprintf(b.out, "エラー: %v\n", err)
+		return
+	}
+	b.day = 0
+	b.showDay()
+}
+
+func (b *browser) showEvent(event *config.Event) {
+	fmt.Fprintf(b.out, "ID:       %s\n", event.ID)
+	fmt.Fprintf(b.out, "日時:     %s\n", event.Timestamp.In(b.session.Location()).Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(b.out, "サービス: %s / %s\n", event.Service, event.Type)
+	if event.Member != "" {
+		fmt.Fprintf(b.out, "メンバー: %s\n", event.Member)
+	}
+	fmt.Fprintf(b.out, "タイトル: %s\n", event.Title)
+	if permalink := app.EventPermalink(event); permalink != "" {
+		fmt.Fprintf(b.out, "リンク:   %s\n", permalink)
+	}
+	printAnnotation(b.out, event.Annotation)
+	if event.Content != "" {
+		fmt.Fprintf(b.out, "\n%s\n", event.Content)
+	}
+
+	if event.Metadata != "" {
+		var metadata interface{}
+		if err := json.Unmarshal([]byte(event.Metadata), &metadata); err == nil {
+			if formatted, err := json.MarshalIndent(metadata, "", "  "); err == nil {
+				fmt.Fprintf(b.out, "\n--- metadata ---\n%s\n", formatted)
+			}
+		} else {
+			fmt.Fprintf(b.out, "\n--- metadata ---\n%s\n", event.Metadata)
+		}
+	}
+
+	for _, attachment := range event.Attachments {
+		fmt.Fprintf(b.out, "\n--- 添付: %s (%s) ---\n", attachment.Title, attachment.MimeType)
+		if attachment.TextFull != "" {
+			fmt.Fprintln(b.out, attachment.TextFull)
+		}
+		if attachment.Truncated {
+			fmt.Fprintln(b.out, "（本文は上限で切り詰められています）")
+		}
+	}
+
+	if len(event.Related) > 0 {
+		fmt.Fprintln(b.out, "\n--- 関連イベント ---")
+		for _, related := range event.Related {
+			fmt.Fprintf(b.out, "%s %s: %s [%s %s]\n", related.Relation, related.Key, related.Title, related.Service, related.Type)
+		}
+	}
+}
+
+func (b *browser) openPermalink(event *config.Event) {
+	permalink := app.EventPermalink(event)
+	if permalink == "" {
+		fmt.Fprintln(b.out, "このイベントにはリンクがありません")
+		return
+	}
+	fmt.Fprintln(b.out, permalink)
+	if err := b.open(permalink); err != nil {
+		fmt.Fprintf(b.out, "ブラウザを開けませんでした: %v\n", err)
+	}
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
@@ -2,15 +2,19 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/iriam/worklogr/internal/app"
 	"github.com/spf13/cobra"
 )
 
 type collectOptions struct {
-	startDate string
-	endDate   string
-	services  []string
+	startDate      string
+	endDate        string
+	services       []string
+	skipScopeCheck bool
+	users          []string
+	teams          []string
 }
 
 func newCollectCmd(rootOptions *rootOptions) *cobra.Command {
@@ -22,7 +26,12 @@ func newCollectCmd(rootOptions *rootOptions) *cobra.Command {
 		Long: `設定されたサービスから指定期間のイベントを収集し、SQLiteに保存します。
 
 Google Calendarが有効な場合、イベントに添付されたGoogleドキュメント（Geminiメモ等）の本文テキストも取得できます（デフォルトON）。
-添付本文は event_attachments テーブルに保存され、動画などの添付は対象外です。`,
+添付本文は event_attachments テーブルに保存され、動画などの添付は対象外です。
+収集前に各サービスのトークンの付与スコープを検証し、不足している場合は必要な機能とスコープを表示して中断します。
+保存後、イベント間の参照（Issueキー、PR URL、コミットSHA等）を検出して event_links テーブルに保存します。
+
+設定ファイルに users を定義している場合は、メンバーごとの認証情報で順に収集し、イベントにメンバー名を記録します。
+--user / --team で対象メンバーを絞り込めます。1人の収集に失敗しても残りのメンバーの収集は続行します。`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			startTime, endTime, err := parseAdjustedTimeRange(options.startDate, options.endDate, rootOptions.configPath)
 			if err != nil {
@@ -33,12 +42,19 @@ Google Calendarが有効な場合、イベントに添付されたGoogleドキ
 				startTime.Format("2006-01-02 15:04:05"),
 				endTime.Format("2006-01-02 15:04:05"))
 
-			if _, err := usecase.Run(app.CollectRequest{
-				ConfigPath: rootOptions.configPath,
-				StartTime:  startTime,
-				EndTime:    endTime,
-				Services:   options.services,
-			}); err != nil {
+			result, err := usecase.Run(app.CollectRequest{
+				ConfigPath:     rootOptions.configPath,
+				StartTime:      startTime,
+				EndTime:        endTime,
+				Services:       options.services,
+				SkipScopeCheck: options.skipScopeCheck,
+				Users:          options.users,
+				Teams:          options.teams,
+			})
+			if result != nil {
+				printMemberCollectResults(result.Members)
+			}
+			if err != nil {
 				return err
 			}
 
@@ -50,8 +66,21 @@ Google Calendarが有効な場合、イベントに添付されたGoogleドキ
 	cmd.Flags().StringVarP(&options.startDate, "start", "s", "", "開始日時 (YYYY-MM-DD または YYYY-MM-DD HH:MM:SS)")
 	cmd.Flags().StringVarP(&options.endDate, "end", "e", "", "終了日時 (YYYY-MM-DD または YYYY-MM-DD HH:MM:SS)")
 	cmd.Flags().StringSliceVar(&options.services, "services", []string{}, "収集対象サービス（例: slack,github,google_calendar）")
+	cmd.Flags().BoolVar(&options.skipScopeCheck, "skip-scope-check", false, "収集前のトークンスコープ検証を省略")
+	cmd.Flags().StringSliceVar(&options.users, "user", []string{}, "収集対象メンバー（users[].name、複数指定可）")
+	cmd.Flags().StringSliceVar(&options.teams, "team", []string{}, "収集対象チーム（users[].teams、複数指定可）")
 	cmd.MarkFlagRequired("start")
 	cmd.MarkFlagRequired("end")
 
 	return cmd
 }
+
+func printMemberCollectResults(members []app.MemberCollectResult) {
+	for _, member := range members {
+		if member.Error != nil {
+			fmt.Printf("  ✗ %s: %v\n", member.DisplayName, member.Error)
+			continue
+		}
+		fmt.Printf("  ✓ %s: %s\n", member.DisplayName, strings.Join(member.TargetServices, ", "))
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/iriam/worklogr/internal/app"
+	"github.com/spf13/cobra"
+)
+
+func newConvertCmd(rootOptions *rootOptions) *cobra.Command {
+	usecase := app.NewConvertUsecase()
+	var format, outputPath string
+
+	cmd := &cobra.Command{
+		Use:   "convert <入力ファイル>",
+		Short: "エクスポートしたファイルを別の形式に変換",
+		Long: `export で出力した json / json-events / ndjson のファイルを、データベースを使わずに別の形式へ変換します。
+入力の形式は内容から判定します。json-ai は metadata 等を省いているため入力にできません。
+
+入力に - を指定すると標準入力から、-o - を指定すると標準出力へ書き出します。
+xlsx の日時セルは設定ファイルのタイムゾーンで出力します。`,
+		Example: `  worklogr convert march.json -f xlsx -o march.xlsx
+  worklogr convert march.ndjson -f csv-daily -o -
+  cat march.ndjson | worklogr convert - -f json-ai -o march_ai.json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := usecase.Run(app.ConvertRequest{
+				ConfigPath: rootOptions.configPath,
+				InputPath:  args[0],
+				Format:     format,
+				OutputPath: outputPath,
+			})
+			if err != nil {
+				return err
+			}
+
+			status := statusWriter(outputPath)
+			if result.EventCount == 0 {
+				fmt.Fprintf(status, "入力にイベントがなかったため、空の %s 形式を出力しました\n", format)
+				return nil
+			}
+			fmt.Fprintf(status, "%d 件のイベントを %s 形式に変換しました\n", result.EventCount, format)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "csv", "変換後の形式 ("+exportFormatList+")")
+	cmd.Flags().StringVarP(&outputPath, "output", "o", "", "出力ファイルパス（- で標準出力）")
+	return cmd
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/iriam/worklogr/internal/app"
+	"github.com/spf13/cobra"
+)
+
+func newDBCmd(rootOptions *rootOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "データベースのバックアップ・復元・統合・鍵の変更",
+		Long: `設定ファイルの database_path にあるデータベースを操作します。
+
+backup は SQLite のオンラインバックアップを使うため、収集の実行中でも安全に取得できます。
+rekey は encryption を有効にしたデータベースの暗号化の鍵を変更します。`,
+	}
+	cmd.AddCommand(newDBBackupCmd(rootOptions))
+	cmd.AddCommand(newDBRestoreCmd(rootOptions))
+	cmd.AddCommand(newDBMergeCmd(rootOptions))
+	cmd.AddCommand(newDBRekeyCmd(rootOptions))
+	return cmd
+}
+
+func newDBBackupCmd(rootOptions *rootOptions) *cobra.Command {
+	usecase := app.NewDBUsecase()
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:     "backup <ファイル>",
+		Short:   "データベースをファイルにバックアップ",
+		Example: `  worklogr db backup ~/backup/worklogr-$(date +%Y%m%d).db`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := usecase.Backup(app.DBBackupRequest{
+				ConfigPath: rootOptions.configPath,
+				OutputPath: args[0],
+				Force:      force,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s を %s にバックアップしました（%d バイト）\n", result.DatabasePath, result.OutputPath, result.Size)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "既存のファイルを上書き")
+	return cmd
+}
+
+func newDBRestoreCmd(rootOptions *rootOptions) *cobra.Command {
+	usecase := app.NewDBUsecase()
+
+	return &cobra.Command{
+		Use:   "restore <ファイル>",
+		Short: "バックアップからデータベースを復元",
+		Long: `バックアップファイルの内容で現在のデータベースを置き換えます。
+
+置き換える前の状態は <database_path>.before-restore-<日時> に退避されます。
+古いバージョンで作成したバックアップは、復元後に現在のスキーマへ移行されます。`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := usecase.Restore(app.DBRestoreRequest{
+				ConfigPath: rootOptions.configPath,
+				InputPath:  args[0],
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s を %s から復元しました\n", result.DatabasePath, args[0])
+			fmt.Printf("復元前のデータベース: %s\n", result.SafetyBackupPath)
+			return nil
+		},
+	}
+}
+
+func newDBMergeCmd(rootOptions *rootOptions) *cobra.Command {
+	usecase := app.NewDBUsecase()
+
+	return &cobra.Command{
+		Use:   "merge <other.db>",
+		Short: "別の worklogr データベースを統合",
+		Long: `別の端末などで収集した worklogr データベースのイベント・添付・注釈・リンクを取り込みます。
+
+同じIDのイベントは、後から保存された方（created_at が新しい方）を添付ごと採用します。
+注釈（ピン留め・メモ・タグ）は更新日時が新しい方を採用します。
+統合元のファイルは変更されません。`,
+		Example: `  worklogr db backup before-merge.db
+  worklogr db merge ~/laptop/worklogr.db`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := usecase.Merge(app.DBMergeRequest{
+				ConfigPath: rootOptions.configPath,
+				OtherPath:  args[0],
+			})
+			if err != nil {
+				return err
+			}
+			stats := result.Stats
+			fmt.Printf("%s を %s に統合しました\n", args[0], result.DatabasePath)
+			fmt.Printf("イベント: 追加 %d 件、更新 %d 件、既存を維持 %d 件\n", stats.EventsAdded, stats.EventsUpdated, stats.EventsKept)
+			fmt.Printf("添付 %d 件、注釈 %d 件、リンク %d 件を取り込みました\n", stats.AttachmentsMerged, stats.AnnotationsMerged, stats.LinksAdded)
+			return nil
+		},
+	}
+}
+
+func newDBRekeyCmd(rootOptions *rootOptions) *cobra.Command {
+	usecase := app.NewDBUsecase()
+	var decrypt bool
+
+	cmd := &cobra.Command{
+		Use:   "rekey",
+		Short: "暗号化の鍵を変更（新しい鍵は標準入力から読み込み）",
+		Long: `データベースを新しい鍵で暗号化し直します。現在の鍵は設定ファイルの encryption.key から読み込みます。
+
+encryption.key が secret:// 参照の場合は、再暗号化の後でそのシークレットを新しい鍵に更新します。
+それ以外の場合は、設定ファイルの encryption.key を新しい鍵に書き換えてください。
+--decrypt は暗号化を解除して平文に戻します（その後 encryption.enabled を false にしてください）。`,
+		Example: `  openssl rand -base64 32 | worklogr db rekey
+  worklogr db rekey --decrypt`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			request := app.DBRekeyRequest{ConfigPath: rootOptions.configPath, Decrypt: decrypt}
+			if !decrypt {
+				data, err := io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return fmt.Errorf("標準入力の読み込みに失敗しました: %w", err)
+				}
+				request.NewKey = strings.TrimRight(string(data), "\r\n")
+			}
+
+			result, err := usecase.Rekey(request)
+			if err != nil {
+				return err
+			}
+			switch {
+			case decrypt:
+				fmt.Printf("%s の暗号化を解除しました。設定ファイルの encryption.enabled を false にしてください\n", result.DatabasePath)
+			case result.SecretName != "":
+				fmt.Printf("%s を新しい鍵で暗号化し直し、シークレット %s を更新しました\n", result.DatabasePath, result.SecretName)
+			default:
+				fmt.Printf("%s を新しい鍵で暗号化し直しました。設定ファイルの encryption.key を新しい鍵に更新してください\n", result.DatabasePath)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&decrypt, "decrypt", false, "暗号化を解除して平文に戻す")
+	return cmd
+}
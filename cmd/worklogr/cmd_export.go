@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/iriam/worklogr/internal/app"
 	"github.com/spf13/cobra"
@@ -10,9 +12,13 @@ import (
 type exportOptions struct {
 	startDate  string
 	endDate    string
-	services   []string
 	outputPath string
 	format     string
+	redact     bool
+	maxTokens  int
+	maxBytes   int
+	chunkBy    string
+	eventQueryOptions
 }
 
 func newExportCmd(rootOptions *rootOptions) *cobra.Command {
@@ -23,14 +29,38 @@ func newExportCmd(rootOptions *rootOptions) *cobra.Command {
 		Short: "収集したイベントをエクスポート",
 		Long: `SQLiteからイベントを取得して、指定形式でエクスポートします。
 
-json-ai 形式では、イベントのmetadataに加えて、添付本文（Googleドキュメント等）がある場合は context.attachments に含めます。`,
+json-ai 形式では、イベントのmetadataに加えて、添付本文（Googleドキュメント等）がある場合は context.attachments に含めます。
+収集時に検出されたイベント間のリンク（Issueキー、PR URL、コミットSHA等）は context.related に含めます。
+
+redaction.enabled が true で stage が export / both の場合、またはオプション --redact を指定した場合は、
+メールアドレス・電話番号・APIキー/トークン・秘匿パラメータ付きURLなどをマスキングしてから出力します。
+
+json-ai 形式で --max-tokens / --max-bytes を指定すると、上限内に収まるよう出力を順序付きのチャンク（<出力名>_001.json, ...）に分割します。
+--chunk-by day / service で日付・サービスごとにまとめ、上限を超えるイベントは添付本文・関連・context・本文の順に削減します。
+削減内容は各チャンクの manifest と <出力名>_manifest.json に記録されます。
+
+users を定義して収集したデータベースでは、--user / --team で対象メンバーを絞り込めます（チームの週次レポート等）。
+json-ai 形式ではイベントに member、statistics に events_by_member が含まれ、--chunk-by member でメンバーごとに分割できます。
+
+ndjson 形式は1行に1イベント（添付本文・注釈を含む）を出力します。jq や DuckDB での処理、worklogr import での取り込みに使えます。
+
+json-events はイベントの配列だけ、csv-spreadsheet は日付・時刻・曜日・URL等の列を加えたCSV、csv-daily は日付×サービスごとの件数のCSVです。
+-o - を指定すると、ファイルの代わりに標準出力へ書き出します（進捗の表示は標準エラーに出ます）。
+
+xlsx 形式は Excel のブック（イベント・日別サマリー・サービス別統計・添付本文の4シート）を出力します。
+日時は設定のタイムゾーンの日付セルになり、タイトルとURLの列はイベントのURLへのリンクになります。
+
+--type / --exclude-type / --match / --repo / --channel / --where metadata.key=value でイベントを絞り込めます。
+絞り込みはSQLite上で行われ（metadataは json_extract で参照）、条件はすべて満たすものが対象です。`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			startTime, endTime, err := parseAdjustedTimeRange(options.startDate, options.endDate, rootOptions.configPath)
 			if err != nil {
 				return fmt.Errorf("時間範囲が無効です: %w", err)
 			}
 
-			fmt.Printf("%s から %s までのイベントをエクスポート中...\n",
+			// -o - のときは標準出力を出力データだけにするため、進捗は標準エラーに表示する
+			status := statusWriter(options.outputPath)
+			fmt.Fprintf(status, "%s から %s までのイベントをエクスポート中...\n",
 				startTime.Format("2006-01-02 15:04:05"),
 				endTime.Format("2006-01-02 15:04:05"))
 
@@ -38,31 +68,53 @@ json-ai 形式では、イベントのmetadataに加えて、添付本文（Goog
 				ConfigPath: rootOptions.configPath,
 				StartTime:  startTime,
 				EndTime:    endTime,
-				Services:   options.services,
+				EventQuery: options.eventQuery(),
 				Format:     options.format,
 				OutputPath: options.outputPath,
+				Redact:     options.redact,
+				MaxTokens:  options.maxTokens,
+				MaxBytes:   options.maxBytes,
+				ChunkBy:    options.chunkBy,
 			})
 			if err != nil {
 				return err
 			}
 
 			if result.MatchedEventCount == 0 {
-				fmt.Println("指定された期間にイベントが見つかりませんでした")
+				fmt.Fprintln(status, "指定された期間にイベントが見つかりませんでした")
 				return nil
 			}
 
-			fmt.Printf("エクスポート対象のイベントが %d 件見つかりました\n", result.MatchedEventCount)
+			fmt.Fprintf(status, "エクスポート対象のイベントが %d 件見つかりました\n", result.MatchedEventCount)
+			if total := result.RedactionStats.Total(); total > 0 {
+				fmt.Fprintf(status, "%d 件の秘匿情報をマスキングしました (%s)\n", total, result.RedactionStats)
+			}
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVarP(&options.startDate, "start", "s", "", "開始日時 (YYYY-MM-DD または YYYY-MM-DD HH:MM:SS)")
 	cmd.Flags().StringVarP(&options.endDate, "end", "e", "", "終了日時 (YYYY-MM-DD または YYYY-MM-DD HH:MM:SS)")
-	cmd.Flags().StringSliceVar(&options.services, "services", []string{}, "エクスポート対象サービス（例: slack,github,google_calendar）")
-	cmd.Flags().StringVarP(&options.outputPath, "output", "o", "", "出力ファイルパス")
-	cmd.Flags().StringVarP(&options.format, "format", "f", "json", "エクスポート形式 (json, json-ai, csv, csv-summary)")
+	addEventQueryFlags(cmd, &options.eventQueryOptions, "エクスポート")
+	cmd.Flags().StringVarP(&options.outputPath, "output", "o", "", "出力ファイルパス（- で標準出力）")
+	cmd.Flags().StringVarP(&options.format, "format", "f", "json", "エクスポート形式 ("+exportFormatList+")")
+	cmd.Flags().BoolVar(&options.redact, "redact", false, "設定に関わらず秘匿情報をマスキングして出力")
+	cmd.Flags().IntVar(&options.maxTokens, "max-tokens", 0, "json-ai: 1チャンクあたりの推定トークン上限")
+	cmd.Flags().IntVar(&options.maxBytes, "max-bytes", 0, "json-ai: 1チャンクあたりのバイト数上限")
+	cmd.Flags().StringVar(&options.chunkBy, "chunk-by", "", "json-ai: チャンクのまとめ方 (day, service, member)")
 	cmd.MarkFlagRequired("start")
 	cmd.MarkFlagRequired("end")
 
 	return cmd
 }
+
+// exportFormatList は export / convert の --format のヘルプに表示する形式の一覧です
+const exportFormatList = "json, json-ai, ndjson, json-events, csv, csv-summary, csv-spreadsheet, csv-daily, xlsx"
+
+// statusWriter は進捗メッセージの出力先です。出力データを標準出力に書くときは標準エラーにします
+func statusWriter(outputPath string) io.Writer {
+	if outputPath == "-" {
+		return os.Stderr
+	}
+	return os.Stdout
+}
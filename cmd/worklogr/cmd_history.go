@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iriam/worklogr/internal/app"
+	"github.com/spf13/cobra"
+)
+
+func newHistoryCmd(rootOptions *rootOptions) *cobra.Command {
+	usecase := app.NewHistoryUsecase()
+	cmd := &cobra.Command{
+		Use:   "history <イベントID>",
+		Short: "イベントの編集履歴を表示",
+		Long: `再収集でタイトル・本文・日時・metadata が変わったイベントの、以前の版を表示します。
+
+以前の版は再収集で上書きされる直前に保存され、その版を収集した日時（seen_at）と一緒に記録されます。
+収集元で削除されたイベント（再収集で見つからなくなったもの）は削除を検知した日時を表示します。`,
+		Example: `  worklogr history slack_search_C123_1700000000.000100
+  worklogr history google_calendar_abc123`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := usecase.Run(app.HistoryRequest{
+				ConfigPath: rootOptions.configPath,
+				EventID:    args[0],
+			})
+			if err != nil {
+				return err
+			}
+
+			event := result.Event
+			fmt.Printf("イベント %s\n", event.ID)
+			fmt.Printf("現在の版: %s  %s\n", event.Timestamp.Local().Format("2006-01-02 15:04"), historyText(event.Title))
+			if event.DeletedAt != nil {
+				fmt.Printf("収集元で削除されました（%s に検知）\n", event.DeletedAt.Local().Format("2006-01-02 15:04"))
+			}
+			if len(result.Versions) == 0 {
+				fmt.Println("以前の版はありません")
+				return nil
+			}
+
+			fmt.Printf("\n以前の版: %d 件（古い順）\n", len(result.Versions))
+			for _, version := range result.Versions {
+				fmt.Printf("- %s 収集 → %s 更新\n", historyTime(version.SeenAt), historyTime(version.ReplacedAt))
+				fmt.Printf("  %s  %s\n", version.Timestamp.Local().Format("2006-01-02 15:04"), historyText(version.Title))
+				if version.Content != "" && version.Content != version.Title {
+					fmt.Printf("  %s\n", historyText(version.Content))
+				}
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+func historyTime(t time.Time) string {
+	if t.IsZero() {
+		return "不明"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func historyText(value string) string {
+	return truncateRunes(strings.ReplaceAll(value, "\n", " "), 80)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/iriam/worklogr/internal/app"
+	"github.com/spf13/cobra"
+)
+
+func newImportCmd(rootOptions *rootOptions) *cobra.Command {
+	usecase := app.NewImportUsecase()
+
+	return &cobra.Command{
+		Use:   "import [ファイル]",
+		Short: "NDJSONのイベントをデータベースに取り込み",
+		Long: `NDJSON（JSON Lines、1行に1イベント）を読み込んでデータベースに保存します。
+export --format ndjson の出力をそのまま取り込めるほか、他のツールで作成したイベントも取り込めます。
+
+各行は export の events と同じ形式で、id / service / type / timestamp が必須です。
+attachments（添付本文）と annotation（ピン留め・メモ・タグ）も一緒に保存されます。
+同じIDのイベントは上書きされるため、同じファイルを何度取り込んでも重複しません。
+
+ファイルを省略するか - を指定すると標準入力から読み込みます。`,
+		Example: `  worklogr export -s 2026-03-01 -e 2026-03-31 -f ndjson -o march.ndjson
+  worklogr import march.ndjson
+  jq -c '.events[]' worklogr_events.json | worklogr import -`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			request := app.ImportRequest{ConfigPath: rootOptions.configPath, Input: cmd.InOrStdin()}
+			if len(args) == 1 {
+				request.InputPath = args[0]
+			}
+
+			result, err := usecase.Run(request)
+			if err != nil {
+				return err
+			}
+
+			if result.ImportedEventCount == 0 {
+				fmt.Println("取り込むイベントがありませんでした")
+				return nil
+			}
+			fmt.Printf("%d 件のイベントを取り込みました（新規 %d 件、更新 %d 件）\n",
+				result.ImportedEventCount,
+				result.NewEventCount,
+				result.ImportedEventCount-result.NewEventCount)
+			if result.AnnotationCount > 0 {
+				fmt.Printf("%d 件の注釈を取り込みました\n", result.AnnotationCount)
+			}
+			if total := result.RedactionStats.Total(); total > 0 {
+				fmt.Printf("保存前に %d 件の秘匿情報をマスキングしました (%s)\n", total, result.RedactionStats)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/iriam/worklogr/internal/app"
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func newPruneCmd(rootOptions *rootOptions) *cobra.Command {
+	usecase := app.NewPruneUsecase()
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "保存期間を過ぎたデータを削除",
+		Long: `設定ファイルの retention に従って、保存期間を過ぎたイベントと添付本文を削除します。
+
+イベントを削除すると、その添付・リンク・注釈も一緒に削除されます（ピン留めしたイベントも対象です）。
+削除後に VACUUM を実行してデータベースファイルを縮小します。
+--dry-run では削除対象の件数だけを表示し、データベースは変更しません。`,
+		Example: `  worklogr prune --dry-run
+  worklogr db backup before-prune.db && worklogr prune`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := usecase.Run(app.PruneRequest{
+				ConfigPath: rootOptions.configPath,
+				DryRun:     dryRun,
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Println("保存期間:")
+			for _, rule := range result.Rules {
+				fmt.Printf("  %s: %s\n", retentionScope(rule), retentionPeriods(rule))
+			}
+
+			stats := result.Stats
+			verb := "削除しました"
+			if dryRun {
+				verb = "削除します（dry-run）"
+			}
+			fmt.Printf("イベント %d 件を%s\n", stats.EventsDeleted, verb)
+			services := make([]string, 0, len(stats.EventsByService))
+			for service := range stats.EventsByService {
+				services = append(services, service)
+			}
+			sort.Strings(services)
+			for _, service := range services {
+				fmt.Printf("  %s: %d 件\n", service, stats.EventsByService[service])
+			}
+			fmt.Printf("添付 %d 件、リンク %d 件、注釈 %d 件を%s\n", stats.AttachmentsDeleted, stats.LinksDeleted, stats.AnnotationsDeleted, verb)
+			fmt.Printf("添付本文 %d 件を%s\n", stats.AttachmentTextsCleared, verb)
+			if !dryRun {
+				fmt.Printf("データベースサイズ: %d → %d バイト\n", result.SizeBefore, result.SizeAfter)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "削除対象の件数だけを表示")
+	return cmd
+}
+
+func retentionScope(rule config.RetentionRule) string {
+	switch {
+	case rule.Service == "":
+		return "既定"
+	case rule.Type == "":
+		return rule.Service
+	default:
+		return rule.Service + "/" + rule.Type
+	}
+}
+
+func retentionPeriods(rule config.RetentionRule) string {
+	days := func(n int) string {
+		if n <= 0 {
+			return "無期限"
+		}
+		return fmt.Sprintf("%d 日", n)
+	}
+	return fmt.Sprintf("イベント %s、添付本文 %s", days(rule.EventDays), days(rule.AttachmentTextDays))
+}
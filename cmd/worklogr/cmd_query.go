@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/iriam/worklogr/internal/app"
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/spf13/cobra"
+)
+
+type queryOptions struct {
+	startDate string
+	endDate   string
+	format    string
+	limit     int
+	eventQueryOptions
+}
+
+func newQueryCmd(rootOptions *rootOptions) *cobra.Command {
+	options := &queryOptions{}
+	usecase := app.NewQueryUsecase()
+	cmd := &cobra.Command{
+		Use:   "query",
+		Short: "条件に一致するイベントを表示",
+		Long: `SQLiteから条件に一致するイベントを検索して表示します。
+
+絞り込み条件は export と共通です（--type / --exclude-type / --match / --repo / --channel / --where metadata.key=value）。
+--where は metadata のJSONを json_extract で参照し、ネストしたキーは metadata.pull_request.state=open のようにドットで区切ります。
+真偽値は true / false、数値は文字列として比較します。`,
+		Example: `  worklogr query -s 2026-03-01 -e 2026-03-07 --type pull_request --repo octo/app
+  worklogr query -s 2026-03-01 -e 2026-03-07 --channel dev --match "障害"
+  worklogr query -s 2026-03-01 -e 2026-03-07 --where metadata.state=merged -f json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			startTime, endTime, err := parseAdjustedTimeRange(options.startDate, options.endDate, rootOptions.configPath)
+			if err != nil {
+				return fmt.Errorf("時間範囲が無効です: %w", err)
+			}
+
+			format := strings.ToLower(options.format)
+			if format != "table" && format != "json" {
+				return fmt.Errorf("サポートされていない形式です: %s。対応形式: table, json", options.format)
+			}
+
+			result, err := usecase.Run(app.QueryRequest{
+				ConfigPath: rootOptions.configPath,
+				StartTime:  startTime,
+				EndTime:    endTime,
+				EventQuery: options.eventQuery(),
+				Limit:      options.limit,
+			})
+			if err != nil {
+				return err
+			}
+
+			if format == "json" {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				events := result.Events
+				if events == nil {
+					events = []*config.Event{}
+				}
+				return encoder.Encode(events)
+			}
+
+			if result.MatchedEventCount == 0 {
+				fmt.Println("条件に一致するイベントが見つかりませんでした")
+				return nil
+			}
+			for _, event := range result.Events {
+				printQueryEvent(event, startTime.Location())
+			}
+			if len(result.Events) < result.MatchedEventCount {
+				fmt.Printf("\n%d 件中 %d 件を表示しました（--limit で変更できます）\n", result.MatchedEventCount, len(result.Events))
+			} else {
+				fmt.Printf("\n%d 件のイベントが一致しました\n", result.MatchedEventCount)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.startDate, "start", "s", "", "開始日時 (YYYY-MM-DD または YYYY-MM-DD HH:MM:SS)")
+	cmd.Flags().StringVarP(&options.endDate, "end", "e", "", "終了日時 (YYYY-MM-DD または YYYY-MM-DD HH:MM:SS)")
+	addEventQueryFlags(cmd, &options.eventQueryOptions, "検索")
+	cmd.Flags().StringVarP(&options.format, "format", "f", "table", "表示形式 (table, json)")
+	cmd.Flags().IntVar(&options.limit, "limit", 100, "表示する件数の上限（0で無制限）")
+	cmd.MarkFlagRequired("start")
+	cmd.MarkFlagRequired("end")
+
+	return cmd
+}
+
+func printQueryEvent(event *config.Event, loc *time.Location) {
+	service := serviceDisplayNames[event.Service]
+	if service == "" {
+		service = event.Service
+	}
+	member := ""
+	if event.Member != "" {
+		member = " [" + event.Member + "]"
+	}
+	fmt.Printf("%s  %-15s %-16s %s%s\n",
+		event.Timestamp.In(loc).Format("2006-01-02 15:04"),
+		service,
+		event.Type,
+		truncateRunes(strings.ReplaceAll(event.Title, "\n", " "), 80),
+		member)
+}
+
+func truncateRunes(value string, max int) string {
+	if utf8.RuneCountInString(value) <= max {
+		return value
+	}
+	runes := []rune(value)
+	return string(runes[:max-1]) + "…"
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/iriam/worklogr/internal/app"
+	"github.com/iriam/worklogr/internal/exporter"
+	"github.com/spf13/cobra"
+)
+
+func newSchemaCmd() *cobra.Command {
+	usecase := app.NewSchemaUsecase()
+	cmd := &cobra.Command{
+		Use:   "schema [json|json-ai|ndjson]",
+		Short: "出力形式の JSON Schema を表示",
+		Long: fmt.Sprintf(`export の json / json-ai / ndjson 形式の JSON Schema（draft 2020-12）を表示します。
+形式を省略すると json を表示します。
+
+スキーマのバージョン（現在 %s）は json の metadata.version と json-ai の summary.schema_version に記録されます。
+項目の削除・名前や型の変更ではメジャー、任意項目の追加ではマイナーを上げます。`, exporter.SchemaVersion),
+		Example: `  worklogr schema json-ai > worklogr-ai.schema.json
+  worklogr schema validate report.json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format := "json"
+			if len(args) == 1 {
+				format = args[0]
+			}
+			schema, err := usecase.Show(format)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(schema))
+			return nil
+		},
+	}
+	cmd.AddCommand(newSchemaValidateCmd(usecase))
+	return cmd
+}
+
+func newSchemaValidateCmd(usecase *app.SchemaUsecase) *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "validate <ファイル>",
+		Short: "export の出力ファイルをスキーマで検証",
+		Long: `export の出力ファイルがスキーマに適合するかを検証します。
+.ndjson / .jsonl は1行ずつイベントとして、それ以外は json または json-ai（summary の有無で判定）として検証します。
+メジャーバージョンの異なるスキーマで書き出されたファイルはエラーになります。`,
+		Example: `  worklogr schema validate report.json
+  worklogr schema validate events.txt --format ndjson`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := usecase.Validate(app.SchemaValidateRequest{Path: args[0], Format: format})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s は %s 形式のスキーマ %s に適合しています\n", result.Path, result.Format, result.SchemaVersion)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "", "形式 (json, json-ai, ndjson)。省略時は拡張子と内容から判定")
+	return cmd
+}
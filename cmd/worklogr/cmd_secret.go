@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/iriam/worklogr/internal/app"
+	"github.com/spf13/cobra"
+)
+
+func newSecretCmd(rootOptions *rootOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secret",
+		Short: "シークレットを管理",
+		Long: `認証情報をシークレットストアに保存・管理します。
+
+設定ファイルでは secret://<名前> と書くことで、ストアに保存した値を参照できます。
+ストアの種類は設定ファイルの secrets.backend で選択します（file / env / command）。`,
+	}
+	cmd.AddCommand(newSecretSetCmd(rootOptions))
+	cmd.AddCommand(newSecretListCmd(rootOptions))
+	cmd.AddCommand(newSecretDeleteCmd(rootOptions))
+	cmd.AddCommand(newSecretMigrateCmd(rootOptions))
+	return cmd
+}
+
+func newSecretSetCmd(rootOptions *rootOptions) *cobra.Command {
+	usecase := app.NewSecretUsecase()
+
+	return &cobra.Command{
+		Use:   "set <name>",
+		Short: "シークレットを保存（値は標準入力から読み込み）",
+		Example: `  printf '%s' "$SLACK_TOKEN" | worklogr secret set slack
+  worklogr secret set llm_api_key < key.txt`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("標準入力の読み込みに失敗しました: %w", err)
+			}
+			value := strings.TrimRight(string(data), "\r\n")
+
+			if err := usecase.Set(app.SecretSetRequest{
+				ConfigPath: rootOptions.configPath,
+				Name:       args[0],
+				Value:      value,
+			}); err != nil {
+				return err
+			}
+			fmt.Printf("シークレット %s を保存しました（参照: secret://%s）\n", args[0], args[0])
+			return nil
+		},
+	}
+}
+
+func newSecretListCmd(rootOptions *rootOptions) *cobra.Command {
+	usecase := app.NewSecretUsecase()
+
+	return &cobra.Command{
+		Use:   "list",
+		Short: "保存済みのシークレット名を表示",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names, err := usecase.List(rootOptions.configPath)
+			if err != nil {
+				return err
+			}
+			if len(names) == 0 {
+				fmt.Println("保存済みのシークレットはありません")
+				return nil
+			}
+			for _, name := range names {
+				fmt.Println(name)
+			}
+			return nil
+		},
+	}
+}
+
+func newSecretDeleteCmd(rootOptions *rootOptions) *cobra.Command {
+	usecase := app.NewSecretUsecase()
+
+	return &cobra.Command{
+		Use:   "delete <name>",
+		Short: "シークレットを削除",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := usecase.Delete(rootOptions.configPath, args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("シークレット %s を削除しました\n", args[0])
+			return nil
+		},
+	}
+}
+
+type secretMigrateOptions struct {
+	dryRun bool
+}
+
+func newSecretMigrateCmd(rootOptions *rootOptions) *cobra.Command {
+	options := &secretMigrateOptions{}
+	usecase := app.NewSecretUsecase()
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "設定ファイルの平文の認証情報をシークレットストアへ移行",
+		Long: `設定ファイルに平文で書かれたトークン・クライアントシークレット・APIキーをシークレットストアへ保存し、
+設定ファイルの値を secret://<名前> 参照に書き換えます。`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := usecase.Migrate(app.SecretMigrateRequest{
+				ConfigPath: rootOptions.configPath,
+				DryRun:     options.dryRun,
+			})
+			if err != nil {
+				return err
+			}
+			if len(result.Migrated) == 0 {
+				fmt.Println("移行対象の平文の認証情報はありません")
+				return nil
+			}
+
+			for _, migration := range result.Migrated {
+				fmt.Printf("  %-30s -> %s\n", migration.Path, migration.Ref)
+			}
+			if options.dryRun {
+				fmt.Printf("%d件を移行します（--dry-run のため変更していません）\n", len(result.Migrated))
+				return nil
+			}
+			fmt.Printf("%d件をシークレットストアへ移行し、%s を更新しました\n", len(result.Migrated), result.ConfigPath)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&options.dryRun, "dry-run", false, "移行対象を表示するだけで変更しない")
+	return cmd
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/iriam/worklogr/internal/app"
+	"github.com/spf13/cobra"
+)
+
+type summarizeOptions struct {
+	startDate      string
+	endDate        string
+	services       []string
+	outputPath     string
+	promptTemplate string
+	maxTokens      int
+	redact         bool
+}
+
+func newSummarizeCmd(rootOptions *rootOptions) *cobra.Command {
+	options := &summarizeOptions{}
+	usecase := app.NewSummarizeUsecase()
+	cmd := &cobra.Command{
+		Use:   "summarize",
+		Short: "LLMで作業サマリーをMarkdown出力",
+		Long: `収集したイベントを json-ai 形式でメモリ上に構築し、設定した LLM（llm セクション）に送信して作業サマリーを Markdown で出力します。
+
+対応プロバイダー: openai（OpenAI互換API）, gemini, ollama（ローカル）
+イベントが llm.max_input_tokens（または --max-tokens）を超える場合は分割して要約し、最後に1つの報告にまとめます。
+--prompt-template には Go の text/template 形式のファイルを指定できます（{{.Start}}, {{.End}}, {{.Part}}, {{.Parts}}, {{.Data}}）。`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			startTime, endTime, err := parseAdjustedTimeRange(options.startDate, options.endDate, rootOptions.configPath)
+			if err != nil {
+				return fmt.Errorf("時間範囲が無効です: %w", err)
+			}
+
+			fmt.Printf("%s から %s までのイベントを要約中...\n",
+				startTime.Format("2006-01-02 15:04:05"),
+				endTime.Format("2006-01-02 15:04:05"))
+
+			result, err := usecase.Run(cmd.Context(), app.SummarizeRequest{
+				ConfigPath:     rootOptions.configPath,
+				StartTime:      startTime,
+				EndTime:        endTime,
+				Services:       options.services,
+				PromptTemplate: options.promptTemplate,
+				OutputPath:     options.outputPath,
+				MaxInputTokens: options.maxTokens,
+				Redact:         options.redact,
+			})
+			if err != nil {
+				return err
+			}
+
+			if result.MatchedEventCount == 0 {
+				fmt.Println("指定された期間にイベントが見つかりませんでした")
+				return nil
+			}
+
+			if total := result.RedactionStats.Total(); total > 0 {
+				fmt.Printf("%d 件の秘匿情報をマスキングしました (%s)\n", total, result.RedactionStats)
+			}
+			fmt.Printf("%d 件のイベントを %d 分割で要約しました（リクエスト %d 回）\n", result.MatchedEventCount, result.Chunks, result.Requests)
+			fmt.Printf("サマリーを出力しました: %s\n", result.OutputPath)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.startDate, "start", "s", "", "開始日時 (YYYY-MM-DD または YYYY-MM-DD HH:MM:SS)")
+	cmd.Flags().StringVarP(&options.endDate, "end", "e", "", "終了日時 (YYYY-MM-DD または YYYY-MM-DD HH:MM:SS)")
+	cmd.Flags().StringSliceVar(&options.services, "services", []string{}, "要約対象サービス（例: slack,github,google_calendar）")
+	cmd.Flags().StringVarP(&options.outputPath, "output", "o", "", "出力ファイルパス（Markdown）")
+	cmd.Flags().StringVar(&options.promptTemplate, "prompt-template", "", "プロンプトテンプレートファイル（text/template 形式）")
+	cmd.Flags().IntVar(&options.maxTokens, "max-tokens", 0, "1リクエストあたりの推定トークン上限（未指定時は llm.max_input_tokens）")
+	cmd.Flags().BoolVar(&options.redact, "redact", false, "設定に関わらず秘匿情報をマスキングして送信")
+	cmd.MarkFlagRequired("start")
+	cmd.MarkFlagRequired("end")
+
+	return cmd
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/iriam/worklogr/internal/app"
+	"github.com/spf13/cobra"
+)
+
+type timesheetOptions struct {
+	startDate  string
+	endDate    string
+	services   []string
+	outputPath string
+	format     string
+	gap        time.Duration
+}
+
+func newTimesheetCmd(rootOptions *rootOptions) *cobra.Command {
+	options := &timesheetOptions{}
+	usecase := app.NewTimesheetUsecase()
+	cmd := &cobra.Command{
+		Use:   "timesheet",
+		Short: "日別・プロジェクト別の推定作業時間を出力",
+		Long: `収集済みイベントから作業セッションを推定し、日別・プロジェクト別の作業時間をエクスポートします。
+
+- GitHubイベントはリポジトリ単位、Slackイベントはチャンネル単位でセッションにまとめます
+- イベント間隔が --gap を超えると別セッションとして扱います
+- 参加した会議は duration_minutes を確定した時間枠として計上し、重なる他セッションの時間からは差し引きます
+- 時間は15分単位に丸めます`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			startTime, endTime, err := parseAdjustedTimeRange(options.startDate, options.endDate, rootOptions.configPath)
+			if err != nil {
+				return fmt.Errorf("時間範囲が無効です: %w", err)
+			}
+
+			fmt.Printf("%s から %s までの作業時間を集計中...\n",
+				startTime.Format("2006-01-02 15:04:05"),
+				endTime.Format("2006-01-02 15:04:05"))
+
+			result, err := usecase.Run(app.TimesheetRequest{
+				ConfigPath: rootOptions.configPath,
+				StartTime:  startTime,
+				EndTime:    endTime,
+				Services:   options.services,
+				Format:     options.format,
+				OutputPath: options.outputPath,
+				MaxGap:     options.gap,
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(result.Entries) == 0 {
+				fmt.Println("指定された期間に集計対象のイベントが見つかりませんでした")
+				return nil
+			}
+
+			fmt.Printf("推定作業時間の合計: %.2f 時間\n", result.TotalHours)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.startDate, "start", "s", "", "開始日時 (YYYY-MM-DD または YYYY-MM-DD HH:MM:SS)")
+	cmd.Flags().StringVarP(&options.endDate, "end", "e", "", "終了日時 (YYYY-MM-DD または YYYY-MM-DD HH:MM:SS)")
+	cmd.Flags().StringSliceVar(&options.services, "services", []string{}, "集計対象サービス（例: slack,github,google_calendar）")
+	cmd.Flags().StringVarP(&options.outputPath, "output", "o", "", "出力ファイルパス")
+	cmd.Flags().StringVarP(&options.format, "format", "f", "csv", "出力形式 (csv, json)")
+	cmd.Flags().DurationVar(&options.gap, "gap", 45*time.Minute, "同一セッションとみなすイベント間隔の上限")
+	cmd.MarkFlagRequired("start")
+	cmd.MarkFlagRequired("end")
+
+	return cmd
+}
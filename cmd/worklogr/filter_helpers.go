@@ -0,0 +1,60 @@
+package main
+
+import (
+	"github.com/iriam/worklogr/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// eventQueryOptions は export / query 共通の絞り込みフラグです
+type eventQueryOptions struct {
+	services       []string
+	users          []string
+	teams          []string
+	types          []string
+	excludeTypes   []string
+	match          string
+	repos          []string
+	channels       []string
+	where          []string
+	includeHidden  bool
+	pinnedOnly     bool
+	includeDeleted bool
+	deletedOnly    bool
+	tags           []string
+}
+
+func addEventQueryFlags(cmd *cobra.Command, options *eventQueryOptions, verb string) {
+	cmd.Flags().StringSliceVar(&options.services, "services", []string{}, verb+"対象サービス（例: slack,github,google_calendar）")
+	cmd.Flags().StringSliceVar(&options.users, "user", []string{}, verb+"対象メンバー（users[].name、複数指定可）")
+	cmd.Flags().StringSliceVar(&options.teams, "team", []string{}, verb+"対象チーム（users[].teams、複数指定可）")
+	cmd.Flags().StringSliceVar(&options.types, "type", []string{}, "イベント種別で絞り込み（例: pull_request,commit）")
+	cmd.Flags().StringSliceVar(&options.excludeTypes, "exclude-type", []string{}, "除外するイベント種別")
+	cmd.Flags().StringVar(&options.match, "match", "", "タイトル・本文の部分一致（大文字小文字を区別しない）")
+	cmd.Flags().StringSliceVar(&options.repos, "repo", []string{}, "GitHubリポジトリで絞り込み（owner/name）")
+	cmd.Flags().StringSliceVar(&options.channels, "channel", []string{}, "Slackチャンネル名またはIDで絞り込み")
+	cmd.Flags().StringArrayVar(&options.where, "where", []string{}, "metadataの値で絞り込み（metadata.key=value、複数指定はAND）")
+	cmd.Flags().BoolVar(&options.includeHidden, "include-hidden", false, "非表示にしたイベントも対象にする")
+	cmd.Flags().BoolVar(&options.pinnedOnly, "pinned-only", false, "ピン留めしたイベントだけを対象にする")
+	cmd.Flags().BoolVar(&options.includeDeleted, "include-deleted", false, "収集元で削除されたイベントも対象にする")
+	cmd.Flags().BoolVar(&options.deletedOnly, "deleted-only", false, "収集元で削除されたイベントだけを対象にする")
+	cmd.Flags().StringSliceVar(&options.tags, "tag", []string{}, "annotate で付けたタグで絞り込み（いずれかを持つイベント）")
+}
+
+func (o *eventQueryOptions) eventQuery() app.EventQuery {
+	return app.EventQuery{
+		Services:       o.services,
+		Users:          o.users,
+		Teams:          o.teams,
+		Types:          o.types,
+		ExcludeTypes:   o.excludeTypes,
+		Match:          o.match,
+		Repos:          o.repos,
+		Channels:       o.channels,
+		Where:          o.where,
+		IncludeHidden:  o.includeHidden,
+		PinnedOnly:     o.pinnedOnly,
+		IncludeDeleted: o.includeDeleted,
+		DeletedOnly:    o.deletedOnly,
+		Tags:           o.tags,
+	}
+}
@@ -1,10 +1,15 @@
 package main
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/iriam/worklogr/internal/app"
+	"github.com/iriam/worklogr/internal/config"
 )
 
 func writeCommandTestConfig(t *testing.T, timezone string) string {
@@ -121,7 +126,7 @@ func TestCommandBuildersKeepFlagStateIndependent(t *testing.T) {
 func TestNewRootCmdWiresExpectedSubcommands(t *testing.T) {
 	cmd := newRootCmd()
 
-	for _, subcommand := range []string{"gcloud", "collect", "export", "status", "config"} {
+	for _, subcommand := range []string{"gcloud", "collect", "export", "import", "convert", "schema", "query", "browse", "annotate", "history", "timesheet", "summarize", "status", "db", "prune", "config", "secret", "auth"} {
 		if _, _, err := cmd.Find([]string{subcommand}); err != nil {
 			t.Fatalf("expected root command to include %q: %v", subcommand, err)
 		}
@@ -131,3 +136,117 @@ func TestNewRootCmdWiresExpectedSubcommands(t *testing.T) {
 		t.Fatalf("expected root command to have persistent config flag")
 	}
 }
+
+type fakeBrowseSession struct {
+	days     []app.BrowseDay
+	services []string
+	types    []string
+	resets   int
+}
+
+func (s *fakeBrowseSession) Days() []app.BrowseDay    { return s.days }
+func (s *fakeBrowseSession) Location() *time.Location { return time.UTC }
+func (s *fakeBrowseSession) Annotate(event *config.Event, update app.AnnotationUpdate) error {
+	annotation := update.Apply(event.Annotation)
+	if annotation.IsEmpty() {
+		annotation = nil
+	}
+	event.Annotation = annotation
+	return nil
+}
+func (s *fakeBrowseSession) AnnotationCounts() (pinned, hidden int) {
+	for _, day := range s.days {
+		for _, event := range day.Events {
+			if event.Annotation != nil && event.Annotation.Pinned {
+				pinned++
+			}
+			if event.Annotation != nil && event.Annotation.Hidden {
+				hidden++
+			}
+		}
+	}
+	return pinned, hidden
+}
+func (s *fakeBrowseSession) ResetFlags() (int64, error) {
+	s.resets++
+	return 0, nil
+}
+func (s *fakeBrowseSession) SetFilter(services, types []string) error {
+	s.services, s.types = services, types
+	return nil
+}
+func (s *fakeBrowseSession) Filter() ([]string, []string) { return s.services, s.types }
+
+func TestBrowserResetAsksForConfirmation(t *testing.T) {
+	ts := time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)
+	session := &fakeBrowseSession{
+		days: []app.BrowseDay{
+			{Date: "2026-03-02", Events: []*config.Event{
+				{ID: "a", Service: "slack", Type: "message", Title: "standup", Timestamp: ts, Annotation: &config.EventAnnotation{Pinned: true}},
+			}},
+		},
+	}
+	var out bytes.Buffer
+	b := &browser{session: session, out: &out, open: func(string) error { return nil }}
+
+	input := strings.Join([]string{"reset", "n", "reset", "y", "q"}, "\n")
+	if err := b.run(strings.NewReader(input)); err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+	if session.resets != 1 {
+		t.Fatalf("expected reset to run only after confirming, ran %d times", session.resets)
+	}
+	output := out.String()
+	for _, want := range []string{"ピン留め 1 件・非表示 0 件を解除します", "解除を取り消しました", "件のピン留め・非表示を解除しました"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestBrowserRunAnnotatesOpensAndFiltersEvents(t *testing.T) {
+	ts := time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)
+	session := &fakeBrowseSession{
+		days: []app.BrowseDay{
+			{Date: "2026-03-02", Events: []*config.Event{
+				{ID: "a", Service: "slack", Type: "message", Title: "standup", Timestamp: ts, Metadata: `{"permalink":"https://example.slack.com/p1"}`},
+				{ID: "b", Service: "github", Type: "commit", Title: "fix bug", Timestamp: ts.Add(time.Hour),
+					Attachments: []config.EventAttachment{{Title: "notes", TextFull: "meeting notes body"}}},
+			}},
+			{Date: "2026-03-03", Events: []*config.Event{
+				{ID: "c", Service: "slack", Type: "message", Title: "retro", Timestamp: ts.Add(24 * time.Hour)},
+			}},
+		},
+	}
+	var opened []string
+	var out bytes.Buffer
+	b := &browser{session: session, out: &out, open: func(url string) error {
+		opened = append(opened, url)
+		return nil
+	}}
+
+	input := strings.Join([]string{"+ 1", "- 2", "note 1 朝会の議事録", "tag 1 team,-none", "o 1", "o 2", "u 1", "n", "f service=slack type=message", "bogus", "q"}, "\n")
+	if err := b.run(strings.NewReader(input)); err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+
+	first, second := session.days[0].Events[0].Annotation, session.days[0].Events[1].Annotation
+	if first == nil || !first.Pinned || first.Note != "朝会の議事録" || strings.Join(first.Tags, ",") != "team" {
+		t.Fatalf("unexpected annotation for first event: %+v", first)
+	}
+	if second == nil || !second.Hidden || second.Pinned {
+		t.Fatalf("unexpected annotation for second event: %+v", second)
+	}
+	if len(opened) != 1 || opened[0] != "https://example.slack.com/p1" {
+		t.Fatalf("expected permalink to be opened, got %v", opened)
+	}
+	if strings.Join(session.services, ",") != "slack" || strings.Join(session.types, ",") != "message" {
+		t.Fatalf("unexpected filter: services=%v types=%v", session.services, session.types)
+	}
+	output := out.String()
+	for _, want := range []string{"=== 2026-03-02 (1/2日目)", "+   1  09:30", "メモ:     朝会の議事録", "meeting notes body", "=== 2026-03-03", "不明なコマンドです: bogus"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
@@ -65,8 +65,21 @@ Google Calendarはgcloud認証（ADC）を使用し、イベントに添付さ
 	cmd.AddCommand(newGCloudCmd())
 	cmd.AddCommand(newCollectCmd(options))
 	cmd.AddCommand(newExportCmd(options))
+	cmd.AddCommand(newImportCmd(options))
+	cmd.AddCommand(newConvertCmd(options))
+	cmd.AddCommand(newSchemaCmd())
+	cmd.AddCommand(newQueryCmd(options))
+	cmd.AddCommand(newBrowseCmd(options))
+	cmd.AddCommand(newAnnotateCmd(options))
+	cmd.AddCommand(newHistoryCmd(options))
+	cmd.AddCommand(newTimesheetCmd(options))
+	cmd.AddCommand(newSummarizeCmd(options))
 	cmd.AddCommand(newStatusCmd(options))
+	cmd.AddCommand(newDBCmd(options))
+	cmd.AddCommand(newPruneCmd(options))
 	cmd.AddCommand(newConfigCmd(options))
+	cmd.AddCommand(newSecretCmd(options))
+	cmd.AddCommand(newAuthCmd(options))
 
 	return cmd
 }
@@ -6,4 +6,5 @@ var serviceDisplayNames = map[string]string{
 	"slack":           "Slack",
 	"github":          "GitHub",
 	"google_calendar": "Google Calendar",
+	"okta":            "Okta",
 }
@@ -9,6 +9,7 @@ require (
 	github.com/mattn/go-sqlite3 v1.14.19
 	github.com/slack-go/slack v0.17.3
 	github.com/spf13/cobra v1.10.1
+	golang.org/x/crypto v0.16.0
 	golang.org/x/oauth2 v0.15.0
 	google.golang.org/api v0.149.0
 	gopkg.in/yaml.v3 v3.0.1
@@ -28,7 +29,6 @@ require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.9 // indirect
 	go.opencensus.io v0.24.0 // indirect
-	golang.org/x/crypto v0.16.0 // indirect
 	golang.org/x/net v0.19.0 // indirect
 	golang.org/x/sys v0.15.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
@@ -0,0 +1,68 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+)
+
+func TestBuildSessionsGroupsByTopicAndGap(t *testing.T) {
+	base := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	events := []*config.Event{
+		{ID: "c1", Service: "github", Type: "commit", Timestamp: base, Metadata: `{"repository":"acme/api"}`},
+		{ID: "c2", Service: "github", Type: "commit", Timestamp: base.Add(30 * time.Minute), Metadata: `{"repository":"acme/api"}`},
+		{ID: "c3", Service: "github", Type: "commit", Timestamp: base.Add(3 * time.Hour), Metadata: `{"repository":"acme/api"}`},
+		{ID: "m1", Service: "slack", Type: "message", Timestamp: base.Add(10 * time.Minute), Metadata: `{"channel_name":"dev"}`},
+	}
+
+	sessions := BuildSessions(events, SessionOptions{MaxGap: 45 * time.Minute, LeadIn: 15 * time.Minute, Location: time.UTC})
+	if len(sessions) != 3 {
+		t.Fatalf("expected 3 sessions, got %d: %+v", len(sessions), sessions)
+	}
+
+	first := sessions[0]
+	if first.Topic.Label() != "acme/api" || first.EventCount != 2 {
+		t.Fatalf("unexpected first session: %+v", first)
+	}
+	if first.Duration() != 45*time.Minute {
+		t.Fatalf("expected 45m including lead-in, got %v", first.Duration())
+	}
+	if sessions[1].Topic.Label() != "#dev" {
+		t.Fatalf("expected slack channel session second, got %+v", sessions[1])
+	}
+}
+
+func TestBuildTimesheetSubtractsMeetingOverlap(t *testing.T) {
+	base := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	events := []*config.Event{
+		{ID: "c1", Service: "github", Type: "commit", Timestamp: base, Metadata: `{"repository":"acme/api"}`},
+		{ID: "c2", Service: "github", Type: "commit", Timestamp: base.Add(2 * time.Hour), Metadata: `{"repository":"acme/api"}`},
+		{ID: "c3", Service: "github", Type: "commit", Timestamp: base.Add(40 * time.Minute), Metadata: `{"repository":"acme/api"}`},
+		{ID: "c4", Service: "github", Type: "commit", Timestamp: base.Add(80 * time.Minute), Metadata: `{"repository":"acme/api"}`},
+		{ID: "mtg", Service: "google_calendar", Type: "event_attended", Content: "Sync", Timestamp: base.Add(time.Hour), Metadata: `{"duration_minutes":30}`},
+		{ID: "created", Service: "google_calendar", Type: "event_created", Content: "Other", Timestamp: base},
+	}
+
+	options := SessionOptions{MaxGap: 45 * time.Minute, LeadIn: 0, DefaultMeeting: 30 * time.Minute, Location: time.UTC}
+	entries := BuildTimesheet(BuildSessions(events, options), time.UTC)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", entries)
+	}
+
+	byProject := map[string]TimesheetEntry{}
+	for _, entry := range entries {
+		byProject[entry.Project] = entry
+	}
+
+	// 9:00-11:00 の作業から会議の30分を差し引く
+	if got := byProject["acme/api"].Hours; got != 1.5 {
+		t.Fatalf("expected 1.5h for acme/api, got %v", got)
+	}
+	if got := byProject["meeting: Sync"].Hours; got != 0.5 {
+		t.Fatalf("expected 0.5h for meeting, got %v", got)
+	}
+	if byProject["acme/api"].Date != "2026-03-10" {
+		t.Fatalf("unexpected date: %+v", byProject["acme/api"])
+	}
+}
@@ -0,0 +1,171 @@
+// Package analysis derives higher-level views (work sessions, time allocation) from stored events.
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/eventmeta"
+)
+
+// SessionOptions はセッション推定のパラメータです
+type SessionOptions struct {
+	// MaxGap は同一トピック内で同じセッションとみなすイベント間隔の上限です
+	MaxGap time.Duration
+	// LeadIn は最初のイベント（コミット・レビュー等）より前に作業していたとみなす時間です
+	LeadIn time.Duration
+	// DefaultMeeting は duration_minutes が無い会議に割り当てる時間です
+	DefaultMeeting time.Duration
+	// Location は日付の区切りに使用するタイムゾーンです
+	Location *time.Location
+}
+
+// DefaultSessionOptions はデフォルトのセッション推定パラメータを返します
+func DefaultSessionOptions() SessionOptions {
+	return SessionOptions{
+		MaxGap:         45 * time.Minute,
+		LeadIn:         15 * time.Minute,
+		DefaultMeeting: 30 * time.Minute,
+		Location:       time.Local,
+	}
+}
+
+// Topic はセッションの集計単位（リポジトリ、チャンネル、会議）です
+type Topic struct {
+	Service string `json:"service"`
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+}
+
+// Label はトピックの表示名を返します
+func (t Topic) Label() string {
+	switch t.Kind {
+	case "channel":
+		return "#" + t.Name
+	case "meeting":
+		return "meeting: " + t.Name
+	default:
+		return t.Name
+	}
+}
+
+// Session は同一トピックで連続したイベントのまとまりです
+type Session struct {
+	Topic      Topic     `json:"topic"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	EventCount int       `json:"event_count"`
+	EventIDs   []string  `json:"event_ids"`
+	// Fixed は会議のように時間枠が確定しているセッションかどうかです
+	Fixed bool `json:"fixed"`
+}
+
+// Duration はセッションの長さを返します
+func (s *Session) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// BuildSessions はイベントをトピックごとのセッションにクラスタリングします
+func BuildSessions(events []*config.Event, options SessionOptions) []*Session {
+	if options.Location == nil {
+		options.Location = time.Local
+	}
+
+	var sessions []*Session
+	byTopic := make(map[Topic][]*config.Event)
+	var topics []Topic
+
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+		common := eventmeta.CommonOf(event)
+
+		if event.Service == "google_calendar" {
+			// 作成/更新は作業時間ではないため、参加した会議のみを時間枠として扱う
+			if event.Type != "event_attended" || isAllDay(event) {
+				continue
+			}
+			duration := time.Duration(common.DurationMinutes) * time.Minute
+			if duration <= 0 {
+				duration = options.DefaultMeeting
+			}
+			sessions = append(sessions, &Session{
+				Topic:      Topic{Service: event.Service, Kind: "meeting", Name: event.Content},
+				Start:      event.Timestamp,
+				End:        event.Timestamp.Add(duration),
+				EventCount: 1,
+				EventIDs:   []string{event.ID},
+				Fixed:      true,
+			})
+			continue
+		}
+
+		topic := topicForEvent(event, common)
+		if _, exists := byTopic[topic]; !exists {
+			topics = append(topics, topic)
+		}
+		byTopic[topic] = append(byTopic[topic], event)
+	}
+
+	for _, topic := range topics {
+		topicEvents := byTopic[topic]
+		sort.SliceStable(topicEvents, func(i, j int) bool {
+			return topicEvents[i].Timestamp.Before(topicEvents[j].Timestamp)
+		})
+
+		var current *Session
+		var last time.Time
+		for _, event := range topicEvents {
+			if current == nil || event.Timestamp.Sub(last) > options.MaxGap || !sameDay(last, event.Timestamp, options.Location) {
+				current = &Session{
+					Topic: topic,
+					Start: event.Timestamp.Add(-options.LeadIn),
+					End:   event.Timestamp,
+				}
+				sessions = append(sessions, current)
+			}
+			current.End = event.Timestamp
+			current.EventCount++
+			current.EventIDs = append(current.EventIDs, event.ID)
+			last = event.Timestamp
+		}
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].Start.Before(sessions[j].Start)
+	})
+
+	return sessions
+}
+
+// topicForEvent はイベントのトピック（リポジトリ/チャンネル）を決定します
+func topicForEvent(event *config.Event, common eventmeta.Common) Topic {
+	switch event.Service {
+	case "github":
+		if common.Repository != "" {
+			return Topic{Service: event.Service, Kind: "repository", Name: common.Repository}
+		}
+	case "slack":
+		if common.Channel != "" {
+			return Topic{Service: event.Service, Kind: "channel", Name: common.Channel}
+		}
+		if common.ChannelID != "" {
+			return Topic{Service: event.Service, Kind: "channel", Name: common.ChannelID}
+		}
+	}
+	return Topic{Service: event.Service, Kind: "other", Name: fmt.Sprintf("%s (other)", event.Service)}
+}
+
+func sameDay(a, b time.Time, loc *time.Location) bool {
+	return a.In(loc).Format("2006-01-02") == b.In(loc).Format("2006-01-02")
+}
+
+// isAllDay は終日の予定かどうかを返します
+func isAllDay(event *config.Event) bool {
+	metadata, _ := eventmeta.Decode(event)
+	calendarEvent, ok := metadata.(*eventmeta.CalendarEvent)
+	return ok && calendarEvent.AllDay
+}
@@ -0,0 +1,127 @@
+package analysis
+
+import (
+	"sort"
+	"time"
+)
+
+// TimesheetEntry は1日・1プロジェクトあたりの推定作業時間です
+type TimesheetEntry struct {
+	Date     string  `json:"date"`
+	Project  string  `json:"project"`
+	Service  string  `json:"service"`
+	Hours    float64 `json:"hours"`
+	Sessions int     `json:"sessions"`
+	Events   int     `json:"events"`
+}
+
+type interval struct {
+	start time.Time
+	end   time.Time
+}
+
+// BuildTimesheet はセッションから日別・プロジェクト別の推定時間を集計します。
+// 会議は確定した時間枠として扱い、他のセッションと重なる時間は会議側に計上します。
+func BuildTimesheet(sessions []*Session, loc *time.Location) []TimesheetEntry {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	var meetings []interval
+	for _, session := range sessions {
+		if session.Fixed {
+			meetings = append(meetings, interval{start: session.Start, end: session.End})
+		}
+	}
+
+	type entryKey struct {
+		date    string
+		project string
+		service string
+	}
+	totals := make(map[entryKey]*TimesheetEntry)
+
+	for _, session := range sessions {
+		duration := session.Duration()
+		if !session.Fixed {
+			duration -= overlapWith(session.Start, session.End, meetings)
+		}
+		if duration <= 0 {
+			continue
+		}
+
+		key := entryKey{
+			date:    session.Start.In(loc).Format("2006-01-02"),
+			project: session.Topic.Label(),
+			service: session.Topic.Service,
+		}
+		entry := totals[key]
+		if entry == nil {
+			entry = &TimesheetEntry{Date: key.date, Project: key.project, Service: key.service}
+			totals[key] = entry
+		}
+		entry.Hours += duration.Hours()
+		entry.Sessions++
+		entry.Events += session.EventCount
+	}
+
+	entries := make([]TimesheetEntry, 0, len(totals))
+	for _, entry := range totals {
+		entry.Hours = roundHours(entry.Hours)
+		entries = append(entries, *entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Date != entries[j].Date {
+			return entries[i].Date < entries[j].Date
+		}
+		if entries[i].Hours != entries[j].Hours {
+			return entries[i].Hours > entries[j].Hours
+		}
+		return entries[i].Project < entries[j].Project
+	})
+
+	return entries
+}
+
+// overlapWith は区間と会議区間群の重なり時間の合計を返します（会議同士の重なりは1回のみ計上）
+func overlapWith(start, end time.Time, meetings []interval) time.Duration {
+	var clipped []interval
+	for _, meeting := range meetings {
+		s, e := meeting.start, meeting.end
+		if s.Before(start) {
+			s = start
+		}
+		if e.After(end) {
+			e = end
+		}
+		if e.After(s) {
+			clipped = append(clipped, interval{start: s, end: e})
+		}
+	}
+	if len(clipped) == 0 {
+		return 0
+	}
+
+	sort.Slice(clipped, func(i, j int) bool { return clipped[i].start.Before(clipped[j].start) })
+
+	var total time.Duration
+	current := clipped[0]
+	for _, next := range clipped[1:] {
+		if next.start.After(current.end) {
+			total += current.end.Sub(current.start)
+			current = next
+			continue
+		}
+		if next.end.After(current.end) {
+			current.end = next.end
+		}
+	}
+	total += current.end.Sub(current.start)
+	return total
+}
+
+// roundHours は時間を0.25時間（15分）単位に丸めます
+func roundHours(hours float64) float64 {
+	return float64(int(hours*4+0.5)) / 4
+}
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/database"
+)
+
+// AnnotationUpdate はイベントの注釈の変更内容です（nil の項目は変更しません）
+type AnnotationUpdate struct {
+	Hidden *bool
+	Pinned *bool
+	Note   *string
+	// AddTags / RemoveTags はタグの追加・削除です
+	AddTags    []string
+	RemoveTags []string
+	// Clear はすべての注釈を削除します（他の変更より先に適用します）
+	Clear bool
+}
+
+// IsEmpty は変更内容が無い（表示のみ）かどうかを返します
+func (u AnnotationUpdate) IsEmpty() bool {
+	return u.Hidden == nil && u.Pinned == nil && u.Note == nil && len(u.AddTags) == 0 && len(u.RemoveTags) == 0 && !u.Clear
+}
+
+// Apply は注釈に変更を適用した結果を返します
+func (u AnnotationUpdate) Apply(current *config.EventAnnotation) *config.EventAnnotation {
+	annotation := &config.EventAnnotation{}
+	if current != nil && !u.Clear {
+		*annotation = *current
+		annotation.Tags = append([]string(nil), current.Tags...)
+	}
+	if u.Hidden != nil {
+		annotation.Hidden = *u.Hidden
+	}
+	if u.Pinned != nil {
+		annotation.Pinned = *u.Pinned
+	}
+	if u.Note != nil {
+		annotation.Note = strings.TrimSpace(*u.Note)
+	}
+	for _, tag := range u.AddTags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !containsString(annotation.Tags, tag) {
+			annotation.Tags = append(annotation.Tags, tag)
+		}
+	}
+	if len(u.RemoveTags) > 0 {
+		var kept []string
+		for _, tag := range annotation.Tags {
+			if !containsString(u.RemoveTags, tag) {
+				kept = append(kept, tag)
+			}
+		}
+		annotation.Tags = kept
+	}
+	return annotation
+}
+
+type AnnotateRequest struct {
+	ConfigPath string
+	EventID    string
+	Update     AnnotationUpdate
+}
+
+type AnnotateResult struct {
+	EventID string
+	// Annotation は変更後の注釈です（注釈が無い場合は nil）
+	Annotation *config.EventAnnotation
+	Changed    bool
+}
+
+type AnnotateUsecase struct {
+	runtime *appRuntime
+}
+
+func NewAnnotateUsecase() *AnnotateUsecase {
+	return &AnnotateUsecase{runtime: newAppRuntime()}
+}
+
+func (u *AnnotateUsecase) Run(request AnnotateRequest) (*AnnotateResult, error) {
+	eventID := strings.TrimSpace(request.EventID)
+	if eventID == "" {
+		return nil, fmt.Errorf("イベントIDを指定してください")
+	}
+
+	return withDatabase(u.runtime, request.ConfigPath, func(cfg *config.Config, db *database.DatabaseManager) (*AnnotateResult, error) {
+		exists, err := db.EventExists(eventID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("イベント '%s' は見つかりません（IDは `worklogr query -f json` や browse の o で確認できます）", eventID)
+		}
+
+		result := &AnnotateResult{EventID: eventID}
+		if request.Update.IsEmpty() {
+			annotation, err := db.GetAnnotation(eventID)
+			if err != nil {
+				return nil, fmt.Errorf("注釈の取得に失敗しました: %w", err)
+			}
+			result.Annotation = annotation
+			return result, nil
+		}
+
+		annotation, err := updateAnnotation(db, eventID, request.Update)
+		if err != nil {
+			return nil, err
+		}
+		result.Annotation = annotation
+		result.Changed = true
+		return result, nil
+	})
+}
+
+// updateAnnotation は保存済みの注釈に変更を適用して保存し、変更後の注釈を返します（空なら nil）
+func updateAnnotation(db *database.DatabaseManager, eventID string, update AnnotationUpdate) (*config.EventAnnotation, error) {
+	current, err := db.GetAnnotation(eventID)
+	if err != nil {
+		return nil, fmt.Errorf("注釈の取得に失敗しました: %w", err)
+	}
+	annotation := update.Apply(current)
+	if err := db.SaveAnnotation(eventID, annotation); err != nil {
+		return nil, fmt.Errorf("注釈の保存に失敗しました: %w", err)
+	}
+	if annotation.IsEmpty() {
+		return nil, nil
+	}
+	return annotation, nil
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
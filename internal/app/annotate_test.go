@@ -0,0 +1,81 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/database"
+)
+
+func TestAnnotationUpdateApplyMergesTagsAndClears(t *testing.T) {
+	pinned, note := true, "  重要  "
+	current := &config.EventAnnotation{Hidden: true, Tags: []string{"a", "b"}}
+
+	got := AnnotationUpdate{Pinned: &pinned, Note: &note, AddTags: []string{"b", "c", " "}, RemoveTags: []string{"a"}}.Apply(current)
+	if !got.Hidden || !got.Pinned || got.Note != "重要" || strings.Join(got.Tags, ",") != "b,c" {
+		t.Fatalf("unexpected annotation: %+v", got)
+	}
+	if strings.Join(current.Tags, ",") != "a,b" {
+		t.Fatalf("expected current annotation to be left untouched, got %+v", current)
+	}
+
+	cleared := AnnotationUpdate{Clear: true, AddTags: []string{"new"}}.Apply(got)
+	if cleared.Pinned || cleared.Hidden || cleared.Note != "" || strings.Join(cleared.Tags, ",") != "new" {
+		t.Fatalf("expected clear to drop existing values, got %+v", cleared)
+	}
+}
+
+func TestAnnotateUsecaseRunSavesShowsAndRejectsUnknownEvents(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "annotate.db")
+	db, err := database.NewDatabaseManager(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	if err := db.InsertEvents([]*config.Event{
+		{ID: "pr-1", Service: "github", Type: "pull_request", Title: "PR", Timestamp: time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)},
+	}); err != nil {
+		t.Fatalf("failed to seed test database: %v", err)
+	}
+	db.Close()
+
+	usecase := &AnnotateUsecase{
+		runtime: &appRuntime{
+			loadConfig: func(path string) (*config.Config, error) {
+				return &config.Config{DatabasePath: dbPath}, nil
+			},
+			openDatabase: database.NewDatabaseManager,
+		},
+	}
+
+	pinned, note := true, "リリース対応"
+	result, err := usecase.Run(AnnotateRequest{EventID: "pr-1", Update: AnnotationUpdate{Pinned: &pinned, Note: &note, AddTags: []string{"release"}}})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if !result.Changed || result.Annotation == nil || !result.Annotation.Pinned {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	result, err = usecase.Run(AnnotateRequest{EventID: "pr-1"})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if result.Changed || result.Annotation == nil || result.Annotation.Note != "リリース対応" || strings.Join(result.Annotation.Tags, ",") != "release" {
+		t.Fatalf("expected stored annotation to be shown, got %+v", result.Annotation)
+	}
+
+	result, err = usecase.Run(AnnotateRequest{EventID: "pr-1", Update: AnnotationUpdate{Clear: true}})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if result.Annotation != nil {
+		t.Fatalf("expected cleared annotation to be nil, got %+v", result.Annotation)
+	}
+
+	if _, err := usecase.Run(AnnotateRequest{EventID: "missing", Update: AnnotationUpdate{Pinned: &pinned}}); err == nil {
+		t.Fatalf("expected unknown event to be rejected")
+	}
+}
@@ -0,0 +1,153 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/iriam/worklogr/internal/auth"
+	"github.com/iriam/worklogr/internal/collector"
+	"github.com/iriam/worklogr/internal/config"
+)
+
+const (
+	AuthFlowLoopback = "loopback"
+	AuthFlowDevice   = "device"
+)
+
+type AuthLoginRequest struct {
+	ConfigPath string
+	Service    string
+	// Device はデバイスフローを使用します（GitHub・Okta）
+	Device     bool
+	Scopes     []string
+	ListenAddr string
+	Timeout    time.Duration
+	// OpenURL は認可URLをユーザーに提示します
+	OpenURL func(authURL string) error
+	// PromptDevice はデバイスフローの確認URLとユーザーコードを提示します
+	PromptDevice func(verificationURI, userCode string)
+}
+
+type AuthLoginResult struct {
+	Service   string
+	Flow      string
+	Scopes    []string
+	ExpiresAt *time.Time
+	// Exchanges はOktaログイン後に use_okta のサービスで行ったトークン交換の結果です
+	Exchanges []OktaExchangeOutcome
+}
+
+type AuthLoginUsecase struct {
+	runtime        *appRuntime
+	openTokenStore func(*config.Config) (*auth.TokenStore, error)
+	providerFor    func(string) (auth.OAuthProvider, error)
+	loopbackLogin  func(context.Context, auth.OAuthProvider, auth.LoginOptions) (*auth.LoginResult, error)
+	deviceLogin    func(context.Context, auth.OAuthProvider, auth.LoginOptions) (*auth.LoginResult, error)
+	newOktaManager func(auth.OktaSettings, *auth.TokenStore) oktaExchanger
+}
+
+func NewAuthLoginUsecase() *AuthLoginUsecase {
+	return &AuthLoginUsecase{
+		runtime:        newAppRuntime(),
+		openTokenStore: openTokenStore,
+		providerFor:    auth.OAuthProviderFor,
+		loopbackLogin:  auth.LoopbackLogin,
+		deviceLogin:    auth.DeviceLogin,
+		newOktaManager: func(settings auth.OktaSettings, store *auth.TokenStore) oktaExchanger {
+			return auth.NewOktaAuthManager(settings, store)
+		},
+	}
+}
+
+func (u *AuthLoginUsecase) Run(ctx context.Context, request AuthLoginRequest) (*AuthLoginResult, error) {
+	// Oktaのエンドポイントは設定ファイルの okta.domain から決まる
+	var provider auth.OAuthProvider
+	if request.Service != oktaServiceName {
+		var err error
+		provider, err = u.providerFor(request.Service)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cfg, err := u.runtime.loadAppConfig(request.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	options := auth.LoginOptions{
+		Scopes:       request.Scopes,
+		ListenAddr:   request.ListenAddr,
+		OpenURL:      request.OpenURL,
+		PromptDevice: request.PromptDevice,
+	}
+	switch request.Service {
+	case "slack":
+		options.ClientID, options.ClientSecret = cfg.Slack.ClientID, cfg.Slack.ClientSecret
+	case "github":
+		options.ClientID, options.ClientSecret = cfg.GitHub.ClientID, cfg.GitHub.ClientSecret
+	case oktaServiceName:
+		settings := collector.OktaSettings(cfg)
+		if err := settings.Validate(); err != nil {
+			return nil, err
+		}
+		provider = settings.Provider()
+		options.ClientID, options.ClientSecret = settings.ClientID, settings.ClientSecret
+		// Oktaアプリに登録したリダイレクトURIと一致させる
+		listenAddr, callbackPath, err := settings.LoopbackAddress()
+		if err != nil {
+			return nil, err
+		}
+		if listenAddr != "" {
+			options.ListenAddr, options.CallbackPath = listenAddr, callbackPath
+		}
+	}
+
+	// トークンを取得してから保存に失敗しないよう、先にストアを開く
+	store, err := u.openTokenStore(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("トークンストアを開けませんでした: %w", err)
+	}
+
+	if request.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, request.Timeout)
+		defer cancel()
+	}
+
+	flow := AuthFlowLoopback
+	login := u.loopbackLogin
+	if request.Device {
+		flow = AuthFlowDevice
+		login = u.deviceLogin
+	}
+	token, err := login(ctx, provider, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := token.Store(store, request.Service); err != nil {
+		return nil, fmt.Errorf("トークンの保存に失敗しました: %w", err)
+	}
+
+	result := &AuthLoginResult{
+		Service:   request.Service,
+		Flow:      flow,
+		Scopes:    token.Scopes,
+		ExpiresAt: token.ExpiresAt,
+	}
+	if request.Service == oktaServiceName {
+		exchanger := u.newOktaManager(collector.OktaSettings(cfg), store)
+		for _, service := range oktaExchangeServices(cfg) {
+			outcome := OktaExchangeOutcome{Service: service}
+			if exchanged, err := exchangeOktaToken(ctx, cfg, exchanger, store, service); err != nil {
+				outcome.Error = err
+			} else {
+				outcome.ExpiresAt = exchanged.ExpiresAt
+			}
+			result.Exchanges = append(result.Exchanges, outcome)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,434 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/iriam/worklogr/internal/auth"
+	"github.com/iriam/worklogr/internal/collector"
+	"github.com/iriam/worklogr/internal/config"
+)
+
+// トークンの取得元
+const (
+	AuthSourceConfig     = "config"
+	AuthSourceTokenStore = "token_store"
+	AuthSourceGCloud     = "gcloud"
+	AuthSourceOkta       = "okta"
+	// AuthSourceCredentialsFile はサービスアカウント鍵・外部アカウントの認証情報ファイルです
+	AuthSourceCredentialsFile = "credentials_file"
+	AuthSourceNone            = "none"
+)
+
+type authCollector interface {
+	ValidateAllAuthentications(ctx context.Context) map[string]*auth.AuthValidationResult
+	GetAuthManager(serviceName string) (auth.AuthManager, bool)
+	UpdateAuthConfig(serviceName string, authConfig *auth.AuthConfig) error
+}
+
+// authServices は認証系コマンドの共通処理です
+type authServices struct {
+	runtime        *appRuntime
+	openTokenStore func(*config.Config) (*auth.TokenStore, error)
+	newCollector   func(*config.Config, *auth.TokenStore) authCollector
+}
+
+func newAuthServices() authServices {
+	return authServices{
+		runtime:        newAppRuntime(),
+		openTokenStore: openTokenStore,
+		newCollector: func(cfg *config.Config, store *auth.TokenStore) authCollector {
+			return collector.NewEventCollectorWithAuth(cfg, nil, store)
+		},
+	}
+}
+
+// authContext は認証系コマンドで使用する設定・トークンストア・コレクターです
+type authContext struct {
+	config    *config.Config
+	store     *auth.TokenStore
+	collector authCollector
+	// storeErr はトークンストアを開けなかった理由です（ストアなしで続行します）
+	storeErr error
+}
+
+// load は設定とトークンストアを読み込み、認証マネージャー付きのコレクターを作成します
+func (s authServices) load(configPath string) (*authContext, error) {
+	cfg, err := s.runtime.loadAppConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := &authContext{config: cfg}
+	if path, err := cfg.TokenStore.EffectivePath(); err == nil {
+		if _, err := os.Stat(path); err == nil {
+			loaded.store, loaded.storeErr = s.openTokenStore(cfg)
+		}
+	}
+	loaded.collector = s.newCollector(cfg, loaded.store)
+	return loaded, nil
+}
+
+// authManagerName はサービス名をコレクター内の認証マネージャー名に変換します
+func authManagerName(service string) string {
+	if service == "google_calendar" {
+		return "calendar"
+	}
+	return service
+}
+
+func serviceConfigFor(cfg *config.Config, service string) *config.ServiceConfig {
+	switch service {
+	case "slack":
+		return &cfg.Slack
+	case "github":
+		return &cfg.GitHub
+	case "google_calendar":
+		return &cfg.GoogleCal
+	default:
+		return nil
+	}
+}
+
+// authServiceDefinitions は認証系コマンドの対象サービスです（収集サービスに加えてOkta）
+var authServiceDefinitions = append(append([]serviceDefinition{}, appServiceDefinitions...), serviceDefinition{Name: oktaServiceName, DisplayName: "Okta"})
+
+// serviceEnabled はサービス（Oktaを含む）が設定で有効かどうかを返します
+func serviceEnabled(cfg *config.Config, service string) bool {
+	if service == oktaServiceName {
+		return cfg.Okta.Enabled
+	}
+	return serviceConfigFor(cfg, service).Enabled
+}
+
+// selectAuthServices は対象サービスを返します。未指定の場合、Oktaは有効なときだけ含めます。
+func selectAuthServices(cfg *config.Config, services []string) ([]serviceDefinition, error) {
+	if len(services) == 0 {
+		if cfg.Okta.Enabled {
+			return authServiceDefinitions, nil
+		}
+		return appServiceDefinitions, nil
+	}
+	selected := make([]serviceDefinition, 0, len(services))
+	for _, name := range services {
+		found := false
+		for _, definition := range authServiceDefinitions {
+			if definition.Name == name {
+				selected = append(selected, definition)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("未知のサービスです: %s", name)
+		}
+	}
+	return selected, nil
+}
+
+type AuthStatusRequest struct {
+	ConfigPath string
+	Services   []string
+	// SkipCheck はAPIによるトークン検証（ヘルスチェック）を行いません
+	SkipCheck bool
+}
+
+// AuthServiceStatus はサービスごとの認証状態です
+type AuthServiceStatus struct {
+	Service     string
+	DisplayName string
+	Enabled     bool
+	Source      string
+	TokenType   string
+	Scopes      []string
+	ExpiresAt   *time.Time
+	LastRefresh *time.Time
+	Checked     bool
+	Valid       bool
+	LastChecked time.Time
+	Error       string
+	Suggestions []string
+	// GrantedScopes はAPIが返した付与スコープです（取得できない場合は nil）
+	GrantedScopes []string
+	// MissingScopes は有効な収集機能に対して不足しているスコープです
+	MissingScopes []auth.MissingScope
+	ScopeHint     string
+}
+
+type AuthStatusResult struct {
+	Services        []AuthServiceStatus
+	TokenStoreError error
+}
+
+type AuthStatusUsecase struct {
+	authServices
+}
+
+func NewAuthStatusUsecase() *AuthStatusUsecase {
+	return &AuthStatusUsecase{authServices: newAuthServices()}
+}
+
+func (u *AuthStatusUsecase) Run(ctx context.Context, request AuthStatusRequest) (*AuthStatusResult, error) {
+	loaded, err := u.load(request.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg, store, eventCollector := loaded.config, loaded.store, loaded.collector
+	definitions, err := selectAuthServices(cfg, request.Services)
+	if err != nil {
+		return nil, err
+	}
+
+	// 検証時に設定ファイルのトークンがストアへ保存されるため、取得元は検証前に判定する
+	sources := make(map[string]string, len(definitions))
+	for _, definition := range definitions {
+		sources[definition.Name] = tokenSource(cfg, definition.Name, store)
+	}
+
+	var validations map[string]*auth.AuthValidationResult
+	if !request.SkipCheck {
+		validations = eventCollector.ValidateAllAuthentications(ctx)
+	}
+
+	result := &AuthStatusResult{TokenStoreError: loaded.storeErr}
+	for _, definition := range definitions {
+		status := AuthServiceStatus{
+			Service:     definition.Name,
+			DisplayName: definition.DisplayName,
+			Enabled:     serviceEnabled(cfg, definition.Name),
+			Source:      sources[definition.Name],
+		}
+
+		manager, exists := eventCollector.GetAuthManager(authManagerName(definition.Name))
+		if !exists {
+			result.Services = append(result.Services, status)
+			continue
+		}
+
+		if validation, ok := validations[authManagerName(definition.Name)]; ok {
+			status.Checked = true
+			status.Valid = validation.Status.IsValid
+			status.LastChecked = validation.Status.LastChecked
+			status.Error = validation.Status.ErrorMessage
+			status.Suggestions = validation.Suggestions
+			if validation.Status.GrantedScopes != nil {
+				report := auth.ScopeReport{
+					Service: definition.Name,
+					Checked: true,
+					Granted: validation.Status.GrantedScopes,
+					Missing: auth.CheckScopes(definition.Name, validation.Status.GrantedScopes, collector.ScopeRequirements(cfg, []string{definition.Name})),
+				}
+				status.GrantedScopes = report.Granted
+				status.MissingScopes = report.Missing
+				status.ScopeHint = report.Hint()
+			}
+		}
+
+		// 検証後に取得することで、gcloud認証で得たトークンの有効期限も反映する
+		info := manager.GetTokenInfo()
+		status.TokenType = info.TokenType
+		status.Scopes = info.Scopes
+		status.ExpiresAt = info.ExpiresAt
+		status.LastRefresh = info.LastRefresh
+
+		result.Services = append(result.Services, status)
+	}
+	return result, nil
+}
+
+// tokenSource はサービスのトークンの取得元を判定します
+func tokenSource(cfg *config.Config, service string, store *auth.TokenStore) string {
+	if service == oktaServiceName {
+		if store != nil {
+			if _, err := store.GetToken(oktaServiceName); err == nil {
+				return AuthSourceTokenStore
+			}
+		}
+		return AuthSourceNone
+	}
+	serviceConfig := serviceConfigFor(cfg, service)
+	if serviceConfig.AccessToken == "" {
+		if service == "google_calendar" {
+			if cfg.GoogleCalendarOptions.CredentialsFile != "" {
+				return AuthSourceCredentialsFile
+			}
+			return AuthSourceGCloud
+		}
+		return AuthSourceNone
+	}
+	if store != nil {
+		if stored, err := store.GetToken(authManagerName(service)); err == nil && stored.AccessToken == serviceConfig.AccessToken {
+			if serviceConfig.UseOkta {
+				return AuthSourceOkta
+			}
+			return AuthSourceTokenStore
+		}
+	}
+	return AuthSourceConfig
+}
+
+type AuthRefreshRequest struct {
+	ConfigPath string
+	Services   []string
+	// Force は有効期限に関わらずリフレッシュします
+	Force bool
+}
+
+// AuthRefreshOutcome はサービスごとのリフレッシュ結果です
+type AuthRefreshOutcome struct {
+	Service   string
+	Refreshed bool
+	Skipped   string
+	Error     error
+	ExpiresAt *time.Time
+}
+
+type AuthRefreshResult struct {
+	Outcomes        []AuthRefreshOutcome
+	TokenStoreError error
+}
+
+type AuthRefreshUsecase struct {
+	authServices
+}
+
+func NewAuthRefreshUsecase() *AuthRefreshUsecase {
+	return &AuthRefreshUsecase{authServices: newAuthServices()}
+}
+
+func (u *AuthRefreshUsecase) Run(ctx context.Context, request AuthRefreshRequest) (*AuthRefreshResult, error) {
+	loaded, err := u.load(request.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg, eventCollector := loaded.config, loaded.collector
+	definitions, err := selectAuthServices(cfg, request.Services)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AuthRefreshResult{TokenStoreError: loaded.storeErr}
+	for _, definition := range definitions {
+		outcome := AuthRefreshOutcome{Service: definition.Name}
+		manager, exists := eventCollector.GetAuthManager(authManagerName(definition.Name))
+		switch {
+		case !exists:
+			outcome.Skipped = "サービスが無効です"
+		case usesOkta(cfg, definition.Name) && (request.Force || manager.IsTokenExpired() || serviceConfigFor(cfg, definition.Name).AccessToken == ""):
+			// use_okta のサービスはOktaのトークンを交換し直す
+			outcome.ExpiresAt, outcome.Error = u.reexchange(ctx, loaded, definition.Name)
+			outcome.Refreshed = outcome.Error == nil
+		case !request.Force && !manager.IsTokenExpired():
+			outcome.Skipped = "有効期限内です（--force で強制更新）"
+		default:
+			if err := manager.RefreshToken(ctx); err != nil {
+				outcome.Error = err
+				break
+			}
+			outcome.Refreshed = true
+			outcome.ExpiresAt = manager.GetTokenInfo().ExpiresAt
+			if configurable, ok := manager.(interface{ GetConfig() *auth.AuthConfig }); ok {
+				if err := eventCollector.UpdateAuthConfig(authManagerName(definition.Name), configurable.GetConfig()); err != nil {
+					outcome.Error = err
+				}
+			}
+		}
+		result.Outcomes = append(result.Outcomes, outcome)
+	}
+	return result, nil
+}
+
+// reexchange はOktaのトークンを交換し直し、コレクターの認証設定に反映します
+func (u *AuthRefreshUsecase) reexchange(ctx context.Context, loaded *authContext, service string) (*time.Time, error) {
+	oktaManager, exists := loaded.collector.GetAuthManager(oktaServiceName)
+	exchanger, ok := oktaManager.(oktaExchanger)
+	if !exists || !ok {
+		return nil, fmt.Errorf("%s は use_okta が有効ですが、Oktaが無効です（okta.enabled を true にして auth login okta を実行してください）", service)
+	}
+	token, err := exchangeOktaToken(ctx, loaded.config, exchanger, loaded.store, service)
+	if err != nil {
+		return nil, err
+	}
+	authConfig := &auth.AuthConfig{
+		AccessToken:    token.AccessToken,
+		RefreshToken:   token.RefreshToken,
+		TokenExpiresAt: token.ExpiresAt,
+	}
+	if err := loaded.collector.UpdateAuthConfig(authManagerName(service), authConfig); err != nil {
+		return nil, err
+	}
+	return token.ExpiresAt, nil
+}
+
+type AuthLogoutRequest struct {
+	ConfigPath string
+	Services   []string
+}
+
+// AuthLogoutOutcome はサービスごとのログアウト結果です
+type AuthLogoutOutcome struct {
+	Service string
+	Cleared bool
+	Error   error
+	Notes   []string
+}
+
+type AuthLogoutResult struct {
+	Outcomes        []AuthLogoutOutcome
+	TokenStoreError error
+}
+
+type AuthLogoutUsecase struct {
+	authServices
+}
+
+func NewAuthLogoutUsecase() *AuthLogoutUsecase {
+	return &AuthLogoutUsecase{authServices: newAuthServices()}
+}
+
+func (u *AuthLogoutUsecase) Run(request AuthLogoutRequest) (*AuthLogoutResult, error) {
+	if len(request.Services) == 0 {
+		return nil, fmt.Errorf("ログアウトするサービスを指定してください")
+	}
+	loaded, err := u.load(request.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	if loaded.storeErr != nil {
+		return nil, fmt.Errorf("トークンストアを開けませんでした: %w", loaded.storeErr)
+	}
+	cfg, store, eventCollector := loaded.config, loaded.store, loaded.collector
+	definitions, err := selectAuthServices(cfg, request.Services)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AuthLogoutResult{}
+	for _, definition := range definitions {
+		outcome := AuthLogoutOutcome{Service: definition.Name}
+		source := tokenSource(cfg, definition.Name, store)
+		name := authManagerName(definition.Name)
+
+		manager, exists := eventCollector.GetAuthManager(name)
+		if clearer, ok := manager.(interface{ ClearToken() error }); exists && ok {
+			outcome.Error = clearer.ClearToken()
+		} else if store != nil {
+			// 無効化されたサービスでもストアのトークンは削除する
+			outcome.Error = store.DeleteToken(name)
+		}
+		outcome.Cleared = outcome.Error == nil
+
+		switch source {
+		case AuthSourceConfig:
+			outcome.Notes = append(outcome.Notes, fmt.Sprintf("設定ファイルの %s.access_token は削除されません。手動で削除してください", definition.Name))
+		case AuthSourceGCloud:
+			outcome.Notes = append(outcome.Notes, "gcloud認証を取り消すには `gcloud auth application-default revoke` を実行してください")
+		case AuthSourceCredentialsFile:
+			outcome.Notes = append(outcome.Notes, "google_calendar_options.credentials_file の認証情報ファイルは削除されません")
+		}
+		result.Outcomes = append(result.Outcomes, outcome)
+	}
+	return result, nil
+}
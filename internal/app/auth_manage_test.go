@@ -0,0 +1,175 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/iriam/worklogr/internal/auth"
+	"github.com/iriam/worklogr/internal/config"
+)
+
+type fakeAuthManager struct {
+	status    *auth.AuthStatus
+	info      *auth.TokenInfo
+	expired   bool
+	refreshed bool
+	cleared   bool
+	refreshFn func() error
+}
+
+func (m *fakeAuthManager) ValidateToken(ctx context.Context) (*auth.AuthStatus, error) {
+	return m.status, nil
+}
+func (m *fakeAuthManager) RefreshToken(ctx context.Context) error {
+	m.refreshed = true
+	if m.refreshFn != nil {
+		return m.refreshFn()
+	}
+	return nil
+}
+func (m *fakeAuthManager) GetAuthStatus() *auth.AuthStatus { return m.status }
+func (m *fakeAuthManager) IsTokenExpired() bool            { return m.expired }
+func (m *fakeAuthManager) GetTokenInfo() *auth.TokenInfo   { return m.info }
+func (m *fakeAuthManager) ClearToken() error {
+	m.cleared = true
+	return nil
+}
+
+type fakeAuthCollector struct {
+	managers map[string]*fakeAuthManager
+	updated  []string
+}
+
+func (c *fakeAuthCollector) ValidateAllAuthentications(ctx context.Context) map[string]*auth.AuthValidationResult {
+	results := map[string]*auth.AuthValidationResult{}
+	for name, manager := range c.managers {
+		results[name] = &auth.AuthValidationResult{ServiceName: name, Status: manager.status, Suggestions: []string{"check " + name}}
+	}
+	return results
+}
+
+func (c *fakeAuthCollector) GetAuthManager(name string) (auth.AuthManager, bool) {
+	manager, ok := c.managers[name]
+	if !ok {
+		return nil, false
+	}
+	return manager, true
+}
+
+func (c *fakeAuthCollector) UpdateAuthConfig(name string, authConfig *auth.AuthConfig) error {
+	c.updated = append(c.updated, name)
+	return nil
+}
+
+func newFakeAuthServices(cfg *config.Config, eventCollector *fakeAuthCollector) authServices {
+	return authServices{
+		runtime: &appRuntime{
+			loadConfig: func(string) (*config.Config, error) { return cfg, nil },
+		},
+		openTokenStore: func(*config.Config) (*auth.TokenStore, error) { return nil, fmt.Errorf("unused") },
+		newCollector: func(*config.Config, *auth.TokenStore) authCollector {
+			return eventCollector
+		},
+	}
+}
+
+func TestAuthStatusUsecaseReportsTokenDetailsAndHealth(t *testing.T) {
+	expiresAt := time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)
+	cfg := &config.Config{
+		Slack:      config.ServiceConfig{Enabled: true, AccessToken: "xoxp"},
+		GoogleCal:  config.ServiceConfig{Enabled: true},
+		TokenStore: config.TokenStoreConfig{Path: filepath.Join(t.TempDir(), "missing.json")},
+	}
+	eventCollector := &fakeAuthCollector{managers: map[string]*fakeAuthManager{
+		"slack": {
+			status: &auth.AuthStatus{IsValid: false, ErrorMessage: "invalid_auth"},
+			info:   &auth.TokenInfo{TokenType: "access", Scopes: []string{"search:read"}},
+		},
+		"calendar": {
+			status: &auth.AuthStatus{IsValid: true},
+			info:   &auth.TokenInfo{TokenType: "gcloud", ExpiresAt: &expiresAt},
+		},
+	}}
+	usecase := &AuthStatusUsecase{authServices: newFakeAuthServices(cfg, eventCollector)}
+
+	result, err := usecase.Run(context.Background(), AuthStatusRequest{})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if len(result.Services) != 3 {
+		t.Fatalf("expected 3 services, got %d", len(result.Services))
+	}
+
+	slack := result.Services[0]
+	if slack.Source != AuthSourceConfig || !slack.Checked || slack.Valid || slack.Error != "invalid_auth" {
+		t.Fatalf("unexpected slack status: %+v", slack)
+	}
+	if len(slack.Scopes) != 1 || slack.Suggestions[0] != "check slack" {
+		t.Fatalf("unexpected slack details: %+v", slack)
+	}
+	if github := result.Services[1]; github.Enabled || github.Checked {
+		t.Fatalf("disabled github should not be checked: %+v", github)
+	}
+	calendar := result.Services[2]
+	if calendar.Source != AuthSourceGCloud || !calendar.Valid || calendar.ExpiresAt == nil || !calendar.ExpiresAt.Equal(expiresAt) {
+		t.Fatalf("unexpected calendar status: %+v", calendar)
+	}
+}
+
+func TestAuthRefreshUsecaseRefreshesExpiredOrForced(t *testing.T) {
+	cfg := &config.Config{TokenStore: config.TokenStoreConfig{Path: filepath.Join(t.TempDir(), "missing.json")}}
+	eventCollector := &fakeAuthCollector{managers: map[string]*fakeAuthManager{
+		"slack":    {info: &auth.TokenInfo{}, refreshFn: func() error { return fmt.Errorf("再認証が必要です") }, expired: true},
+		"github":   {info: &auth.TokenInfo{}},
+		"calendar": {info: &auth.TokenInfo{}, expired: true},
+	}}
+	usecase := &AuthRefreshUsecase{authServices: newFakeAuthServices(cfg, eventCollector)}
+
+	result, err := usecase.Run(context.Background(), AuthRefreshRequest{})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if result.Outcomes[0].Error == nil || result.Outcomes[1].Skipped == "" || !result.Outcomes[2].Refreshed {
+		t.Fatalf("unexpected outcomes: %+v", result.Outcomes)
+	}
+	if eventCollector.managers["github"].refreshed {
+		t.Fatalf("non-expired github token should not be refreshed without --force")
+	}
+
+	if _, err := usecase.Run(context.Background(), AuthRefreshRequest{Services: []string{"github"}, Force: true}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if !eventCollector.managers["github"].refreshed {
+		t.Fatalf("expected forced refresh of github")
+	}
+}
+
+func TestAuthLogoutUsecaseClearsTokens(t *testing.T) {
+	cfg := &config.Config{
+		GitHub:     config.ServiceConfig{Enabled: true, AccessToken: "ghp-config"},
+		TokenStore: config.TokenStoreConfig{Path: filepath.Join(t.TempDir(), "missing.json")},
+	}
+	eventCollector := &fakeAuthCollector{managers: map[string]*fakeAuthManager{"github": {}}}
+	usecase := &AuthLogoutUsecase{authServices: newFakeAuthServices(cfg, eventCollector)}
+
+	result, err := usecase.Run(AuthLogoutRequest{Services: []string{"github"}})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if !eventCollector.managers["github"].cleared || !result.Outcomes[0].Cleared {
+		t.Fatalf("expected github token to be cleared: %+v", result.Outcomes)
+	}
+	if len(result.Outcomes[0].Notes) != 1 {
+		t.Fatalf("expected a note about the plaintext config token: %+v", result.Outcomes[0])
+	}
+
+	if _, err := usecase.Run(AuthLogoutRequest{}); err == nil {
+		t.Fatalf("expected error when no service is given")
+	}
+	if _, err := usecase.Run(AuthLogoutRequest{Services: []string{"jira"}}); err == nil {
+		t.Fatalf("expected error for unknown service")
+	}
+}
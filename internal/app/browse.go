@@ -0,0 +1,187 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/database"
+	"github.com/iriam/worklogr/internal/eventmeta"
+	"github.com/iriam/worklogr/internal/redact"
+)
+
+type BrowseRequest struct {
+	ConfigPath string
+	StartTime  time.Time
+	EndTime    time.Time
+	EventQuery
+}
+
+// BrowseDay は1日分のイベントです（設定のタイムゾーンで日付を区切ります）
+type BrowseDay struct {
+	Date   string
+	Events []*config.Event
+}
+
+// BrowseSession は browse の実行中に開いたままにするデータベースと表示中のイベントです
+type BrowseSession struct {
+	cfg      *config.Config
+	db       *database.DatabaseManager
+	request  BrowseRequest
+	location *time.Location
+	days     []BrowseDay
+}
+
+type BrowseUsecase struct {
+	runtime *appRuntime
+}
+
+func NewBrowseUsecase() *BrowseUsecase {
+	return &BrowseUsecase{runtime: newAppRuntime()}
+}
+
+// Open はデータベースを開いてイベントを読み込みます。終了時は Close を呼んでください
+func (u *BrowseUsecase) Open(request BrowseRequest) (*BrowseSession, error) {
+	cfg, err := u.runtime.loadAppConfig(request.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := u.runtime.openAppDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	location := time.Local
+	if timezoneManager, err := cfg.GetTimezoneManager(); err == nil {
+		location = timezoneManager.GetLocation()
+	}
+
+	// 非表示を解除できるよう、非表示にしたイベントも表示する
+	request.IncludeHidden = true
+	request.PinnedOnly = false
+
+	session := &BrowseSession{cfg: cfg, db: db, request: request, location: location}
+	if err := session.Reload(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return session, nil
+}
+
+func (s *BrowseSession) Close() error {
+	return s.db.Close()
+}
+
+// Reload は現在の絞り込み条件でイベントと注釈を読み込み直します
+func (s *BrowseSession) Reload() error {
+	filter, err := buildEventFilter(s.cfg, s.request.EventQuery)
+	if err != nil {
+		return err
+	}
+
+	events, err := s.db.QueryEvents(s.request.StartTime, s.request.EndTime, filter)
+	if err != nil {
+		return fmt.Errorf("イベントの取得に失敗しました: %w", err)
+	}
+
+	if s.cfg.Redaction.AppliesAtExport() {
+		redactor, err := redact.New(s.cfg.Redaction)
+		if err != nil {
+			return fmt.Errorf("redaction設定が無効です: %w", err)
+		}
+		redactor.RedactEvents(events)
+	}
+
+	s.days = groupEventsByDay(events, s.location)
+	return nil
+}
+
+// SetFilter はサービスと種別の絞り込みを変更して読み込み直します（空なら絞り込みなし）
+func (s *BrowseSession) SetFilter(services, types []string) error {
+	previous := s.request.EventQuery
+	s.request.Services = services
+	s.request.Types = types
+	if err := s.Reload(); err != nil {
+		s.request.EventQuery = previous
+		return err
+	}
+	return nil
+}
+
+// Filter は現在のサービスと種別の絞り込みを返します
+func (s *BrowseSession) Filter() (services, types []string) {
+	return s.request.Services, s.request.Types
+}
+
+func (s *BrowseSession) Days() []BrowseDay {
+	return s.days
+}
+
+func (s *BrowseSession) Location() *time.Location {
+	return s.location
+}
+
+// Annotate はイベントの注釈を変更し、表示中のイベントにも反映します
+func (s *BrowseSession) Annotate(event *config.Event, update AnnotationUpdate) error {
+	annotation, err := updateAnnotation(s.db, event.ID, update)
+	if err != nil {
+		return err
+	}
+	event.Annotation = annotation
+	return nil
+}
+
+// AnnotationCounts は表示中のイベントのうちピン留め・非表示にしたものの件数を返します
+func (s *BrowseSession) AnnotationCounts() (pinned, hidden int) {
+	for _, day := range s.days {
+		for _, event := range day.Events {
+			if event.Annotation == nil {
+				continue
+			}
+			if event.Annotation.Pinned {
+				pinned++
+			}
+			if event.Annotation.Hidden {
+				hidden++
+			}
+		}
+	}
+	return pinned, hidden
+}
+
+// ResetFlags は表示中のイベント（期間と絞り込みに一致するもの）のピン留め・非表示を解除します。
+// 対象は AnnotationCounts と同じで、メモとタグは残します
+func (s *BrowseSession) ResetFlags() (int64, error) {
+	var eventIDs []string
+	for _, day := range s.days {
+		for _, event := range day.Events {
+			eventIDs = append(eventIDs, event.ID)
+		}
+	}
+	count, err := s.db.ClearPinnedAndHidden(eventIDs)
+	if err != nil {
+		return 0, fmt.Errorf("ピン留め・非表示の解除に失敗しました: %w", err)
+	}
+	if err := s.Reload(); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func groupEventsByDay(events []*config.Event, location *time.Location) []BrowseDay {
+	var days []BrowseDay
+	for _, event := range events {
+		date := event.Timestamp.In(location).Format("2006-01-02")
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, BrowseDay{Date: date})
+		}
+		days[len(days)-1].Events = append(days[len(days)-1].Events, event)
+	}
+	return days
+}
+
+// EventPermalink はイベントのWeb上のリンク（Slackのpermalink、GitHubのURL、カレンダーのリンク）を返します
+func EventPermalink(event *config.Event) string {
+	return eventmeta.CommonOf(event).URL
+}
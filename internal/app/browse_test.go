@@ -0,0 +1,123 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/database"
+	"github.com/iriam/worklogr/internal/exporter"
+)
+
+func TestBrowseSessionGroupsDaysAndAnnotationsAffectExport(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "browse.db")
+	db, err := database.NewDatabaseManager(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	day1 := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	if err := db.InsertEvents([]*config.Event{
+		{ID: "a", Service: "slack", Type: "message", Title: "a", Timestamp: day1, Metadata: `{"permalink":"https://example.slack.com/archives/C1/p1"}`},
+		{ID: "b", Service: "github", Type: "commit", Title: "b", Timestamp: day1.Add(time.Hour), Metadata: `{"url":"https://github.com/octo/app/commit/1"}`},
+		{ID: "c", Service: "slack", Type: "message", Title: "c", Timestamp: day2},
+	}); err != nil {
+		t.Fatalf("failed to seed test database: %v", err)
+	}
+	db.Close()
+
+	runtime := &appRuntime{
+		loadConfig: func(path string) (*config.Config, error) {
+			return &config.Config{DatabasePath: dbPath, Timezone: "UTC"}, nil
+		},
+		openDatabase: database.NewDatabaseManager,
+	}
+
+	session, err := (&BrowseUsecase{runtime: runtime}).Open(BrowseRequest{
+		StartTime: day1.Add(-time.Hour),
+		EndTime:   day2.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+
+	days := session.Days()
+	if len(days) != 2 || days[0].Date != "2026-03-02" || len(days[0].Events) != 2 || len(days[1].Events) != 1 {
+		t.Fatalf("unexpected days: %+v", days)
+	}
+	if got := EventPermalink(days[0].Events[1]); got != "https://github.com/octo/app/commit/1" {
+		t.Fatalf("unexpected permalink: %q", got)
+	}
+
+	pin, hide := true, true
+	if err := session.Annotate(days[0].Events[0], AnnotationUpdate{Pinned: &pin}); err != nil {
+		t.Fatalf("Annotate returned error: %v", err)
+	}
+	if err := session.Annotate(days[0].Events[1], AnnotationUpdate{Hidden: &hide}); err != nil {
+		t.Fatalf("Annotate returned error: %v", err)
+	}
+	if pinned, hidden := session.AnnotationCounts(); pinned != 1 || hidden != 1 {
+		t.Fatalf("unexpected annotation counts: pinned=%d hidden=%d", pinned, hidden)
+	}
+	if err := session.SetFilter([]string{"slack"}, nil); err != nil {
+		t.Fatalf("SetFilter returned error: %v", err)
+	}
+	reloaded := session.Days()[0].Events
+	if len(reloaded) != 1 || reloaded[0].Annotation == nil || !reloaded[0].Annotation.Pinned {
+		t.Fatalf("expected filtered reload to keep annotations, got %+v", reloaded)
+	}
+	session.Close()
+
+	var exported []string
+	exportUsecase := &ExportUsecase{
+		runtime: runtime,
+		exportEvents: func(source exporter.EventSource, target exportTarget) error {
+			events := readAllEvents(t, source)
+			exported = nil
+			for _, event := range events {
+				exported = append(exported, event.ID)
+			}
+			return nil
+		},
+	}
+	request := ExportRequest{StartTime: day1.Add(-time.Hour), EndTime: day2.Add(time.Hour), Format: "json"}
+	if _, err := exportUsecase.Run(request); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if len(exported) != 2 || exported[0] != "a" || exported[1] != "c" {
+		t.Fatalf("expected hidden event to be skipped, got %v", exported)
+	}
+
+	request.PinnedOnly = true
+	if _, err := exportUsecase.Run(request); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if len(exported) != 1 || exported[0] != "a" {
+		t.Fatalf("expected only pinned event, got %v", exported)
+	}
+
+	// reset は表示中（期間と絞り込みに一致する）イベントだけを解除する
+	session, err = (&BrowseUsecase{runtime: runtime}).Open(BrowseRequest{
+		StartTime:  day1.Add(-time.Hour),
+		EndTime:    day2.Add(time.Hour),
+		EventQuery: EventQuery{Services: []string{"slack"}},
+	})
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	count, err := session.ResetFlags()
+	if err != nil {
+		t.Fatalf("ResetFlags returned error: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected only the displayed pinned event to be reset, got %d", count)
+	}
+	if err := session.SetFilter(nil, nil); err != nil {
+		t.Fatalf("SetFilter returned error: %v", err)
+	}
+	if hidden := session.Days()[0].Events[1].Annotation; hidden == nil || !hidden.Hidden {
+		t.Fatalf("expected the hidden github event outside the filter to stay hidden, got %+v", hidden)
+	}
+	session.Close()
+}
@@ -1,10 +1,12 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/iriam/worklogr/internal/auth"
 	"github.com/iriam/worklogr/internal/collector"
 	"github.com/iriam/worklogr/internal/config"
 	"github.com/iriam/worklogr/internal/database"
@@ -15,17 +17,37 @@ type CollectRequest struct {
 	StartTime  time.Time
 	EndTime    time.Time
 	Services   []string
+	// SkipScopeCheck は収集前のトークンスコープ検証を行いません
+	SkipScopeCheck bool
+	// Users / Teams は users 設定時に収集するメンバーを絞り込みます（未指定なら全員）
+	Users []string
+	Teams []string
 }
 
 type CollectResult struct {
 	TargetServices []string
 	CollectedRange TimeRange
+	// ScopeReports は収集前に行ったスコープ検証の結果です
+	ScopeReports []auth.ScopeReport
+	// Members は users 設定時のメンバーごとの収集結果です
+	Members []MemberCollectResult
+}
+
+// MemberCollectResult はチームメンバー1人分の収集結果です
+type MemberCollectResult struct {
+	Name           string
+	DisplayName    string
+	TargetServices []string
+	ScopeReports   []auth.ScopeReport
+	Error          error
 }
 
 type collectCoordinator interface {
 	InitializeServicesFor([]string) error
 	ValidateTimeRange(time.Time, time.Time) error
 	CollectAndStore(time.Time, time.Time, []string) error
+	VerifyScopes(context.Context, []string) []auth.ScopeReport
+	SetMember(string)
 }
 
 type CollectUsecase struct {
@@ -49,29 +71,105 @@ func (u *CollectUsecase) Run(request CollectRequest) (*CollectResult, error) {
 			return nil, err
 		}
 
-		eventCollector := u.newCollector(cfg, db)
-		if err := eventCollector.InitializeServicesFor(targetServices); err != nil {
-			return nil, fmt.Errorf("サービスの初期化に失敗しました: %w", err)
+		result := &CollectResult{
+			TargetServices: targetServices,
+			CollectedRange: TimeRange{
+				StartTime: request.StartTime,
+				EndTime:   request.EndTime,
+			},
 		}
 
-		if err := eventCollector.ValidateTimeRange(request.StartTime, request.EndTime); err != nil {
-			return nil, fmt.Errorf("時間範囲が無効です: %w", err)
+		if !cfg.HasUsers() {
+			if len(request.Users) > 0 || len(request.Teams) > 0 {
+				return nil, fmt.Errorf("--user / --team を使うには設定ファイルに users を定義してください")
+			}
+			scopeReports, err := u.collect(cfg, db, request, "", targetServices)
+			if err != nil {
+				return nil, err
+			}
+			result.ScopeReports = scopeReports
+			return result, nil
 		}
 
-		if err := eventCollector.CollectAndStore(request.StartTime, request.EndTime, targetServices); err != nil {
-			return nil, fmt.Errorf("イベント収集に失敗しました: %w", err)
+		members, err := cfg.SelectUsers(request.Users, request.Teams)
+		if err != nil {
+			return nil, err
 		}
 
-		return &CollectResult{
-			TargetServices: targetServices,
-			CollectedRange: TimeRange{
-				StartTime: request.StartTime,
-				EndTime:   request.EndTime,
-			},
-		}, nil
+		// 1人の失敗で他のメンバーの収集を止めない
+		var failed []string
+		for _, name := range members {
+			user, _ := cfg.FindUser(name)
+			member := MemberCollectResult{Name: user.Name, DisplayName: user.Label()}
+
+			userCfg, err := cfg.ForUser(name)
+			if err == nil {
+				member.TargetServices, err = memberCollectServices(userCfg, targetServices)
+			}
+			if err == nil {
+				member.ScopeReports, err = u.collect(userCfg, db, request, user.Name, member.TargetServices)
+			}
+			if err != nil {
+				member.Error = err
+				failed = append(failed, user.Label())
+				appLogger.Warnf("%s の収集に失敗しました: %v", user.Label(), err)
+			}
+			result.Members = append(result.Members, member)
+		}
+
+		if len(members) > 0 && len(failed) == len(members) {
+			return result, fmt.Errorf("すべてのメンバーの収集に失敗しました: %s", strings.Join(failed, ", "))
+		}
+		return result, nil
 	})
 }
 
+// collect は1つの設定（単一ユーザーまたはメンバー1人分）でイベントを収集・保存します
+func (u *CollectUsecase) collect(cfg *config.Config, db *database.DatabaseManager, request CollectRequest, member string, targetServices []string) ([]auth.ScopeReport, error) {
+	eventCollector := u.newCollector(cfg, db)
+	eventCollector.SetMember(member)
+	if err := eventCollector.InitializeServicesFor(targetServices); err != nil {
+		return nil, fmt.Errorf("サービスの初期化に失敗しました: %w", err)
+	}
+
+	if err := eventCollector.ValidateTimeRange(request.StartTime, request.EndTime); err != nil {
+		return nil, fmt.Errorf("時間範囲が無効です: %w", err)
+	}
+
+	var scopeReports []auth.ScopeReport
+	if !request.SkipScopeCheck {
+		scopeReports = eventCollector.VerifyScopes(context.Background(), targetServices)
+		if err := missingScopesError(scopeReports); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := eventCollector.CollectAndStore(request.StartTime, request.EndTime, targetServices); err != nil {
+		return nil, fmt.Errorf("イベント収集に失敗しました: %w", err)
+	}
+
+	return scopeReports, nil
+}
+
+// memberCollectServices は対象サービスのうち、メンバーの認証情報が設定されているものを返します
+func memberCollectServices(userCfg *config.Config, targetServices []string) ([]string, error) {
+	enabled := map[string]bool{
+		"slack":           userCfg.Slack.Enabled,
+		"github":          userCfg.GitHub.Enabled,
+		"google_calendar": userCfg.GoogleCal.Enabled,
+	}
+	var services []string
+	for _, service := range targetServices {
+		if enabled[service] {
+			services = append(services, service)
+		}
+	}
+	if len(services) == 0 {
+		return nil, fmt.Errorf("収集できるサービスがありません（users の access_token / identity を確認してください）")
+	}
+	return services, nil
+}
+
 func resolveCollectServices(cfg *config.Config, requested []string) ([]string, error) {
 	knownServices := make(map[string]bool, len(appServiceDefinitions))
 	for _, service := range appServiceDefinitions {
@@ -120,3 +218,25 @@ func resolveCollectServices(cfg *config.Config, requested []string) ([]string, e
 
 	return targets, nil
 }
+
+// missingScopesError は不足スコープがあるサービスについて、機能ごとの不足スコープと対処方法をまとめたエラーを返します。
+// スコープを確認できなかったサービス（検証エラー・付与スコープ不明）は収集を妨げません。
+func missingScopesError(reports []auth.ScopeReport) error {
+	var lines []string
+	for _, report := range reports {
+		if report.OK() {
+			continue
+		}
+		name := serviceDisplayName(report.Service)
+		for _, missing := range report.Missing {
+			lines = append(lines, fmt.Sprintf("  %s: %s には %s スコープが必要です", name, missing.Feature, strings.Join(missing.Scopes, ", ")))
+		}
+		if hint := report.Hint(); hint != "" {
+			lines = append(lines, "    → "+hint)
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return fmt.Errorf("トークンのスコープが不足しています（--skip-scope-check で検証を省略できます）:\n%s", strings.Join(lines, "\n"))
+}
@@ -1,12 +1,15 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/iriam/worklogr/internal/auth"
 	"github.com/iriam/worklogr/internal/config"
 	"github.com/iriam/worklogr/internal/database"
 )
@@ -15,11 +18,15 @@ type stubCollectCoordinator struct {
 	initializedWith []string
 	collectedWith   []string
 	validateCalls   int
+	scopeReports    []auth.ScopeReport
+	scopeChecks     int
+	member          string
+	initErr         error
 }
 
 func (s *stubCollectCoordinator) InitializeServicesFor(serviceNames []string) error {
 	s.initializedWith = append([]string(nil), serviceNames...)
-	return nil
+	return s.initErr
 }
 
 func (s *stubCollectCoordinator) ValidateTimeRange(startTime, endTime time.Time) error {
@@ -32,6 +39,15 @@ func (s *stubCollectCoordinator) CollectAndStore(startTime, endTime time.Time, s
 	return nil
 }
 
+func (s *stubCollectCoordinator) VerifyScopes(ctx context.Context, serviceNames []string) []auth.ScopeReport {
+	s.scopeChecks++
+	return s.scopeReports
+}
+
+func (s *stubCollectCoordinator) SetMember(name string) {
+	s.member = name
+}
+
 func TestResolveCollectServicesReturnsEnabledServicesInOrder(t *testing.T) {
 	cfg := &config.Config{
 		Slack:     config.ServiceConfig{Enabled: true},
@@ -153,3 +169,137 @@ func TestCollectUsecaseRunReturnsConfigLoadError(t *testing.T) {
 		t.Fatalf("expected Run to return config load error")
 	}
 }
+
+func TestCollectUsecaseRunStopsOnMissingScopes(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "collect.db")
+	db, err := database.NewDatabaseManager(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+
+	coordinator := &stubCollectCoordinator{
+		scopeReports: []auth.ScopeReport{
+			{Service: "slack", Checked: true, Granted: []string{"users:read"}, Missing: []auth.MissingScope{{Feature: "メッセージ検索", Scopes: []string{"search:read"}}}},
+			{Service: "github", Checked: false},
+		},
+	}
+	usecase := &CollectUsecase{
+		runtime: &appRuntime{
+			loadConfig: func(path string) (*config.Config, error) {
+				return &config.Config{
+					DatabasePath: dbPath,
+					Slack:        config.ServiceConfig{Enabled: true},
+					GitHub:       config.ServiceConfig{Enabled: true},
+				}, nil
+			},
+			openDatabase: func(path string) (*database.DatabaseManager, error) {
+				return db, nil
+			},
+		},
+		newCollector: func(cfg *config.Config, db *database.DatabaseManager) collectCoordinator {
+			return coordinator
+		},
+	}
+
+	_, err = usecase.Run(CollectRequest{
+		StartTime: time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2026, 3, 10, 18, 0, 0, 0, time.UTC),
+	})
+	if err == nil {
+		t.Fatalf("expected missing scopes to stop collection")
+	}
+	for _, want := range []string{"Slack", "メッセージ検索", "search:read", "auth login slack"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected error to mention %q, got %v", want, err)
+		}
+	}
+	if coordinator.collectedWith != nil {
+		t.Fatalf("expected CollectAndStore not to be called, got %v", coordinator.collectedWith)
+	}
+
+	coordinator.scopeChecks = 0
+	if _, err := usecase.Run(CollectRequest{
+		StartTime:      time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC),
+		EndTime:        time.Date(2026, 3, 10, 18, 0, 0, 0, time.UTC),
+		SkipScopeCheck: true,
+	}); err != nil {
+		t.Fatalf("Run with SkipScopeCheck returned error: %v", err)
+	}
+	if coordinator.scopeChecks != 0 {
+		t.Fatalf("expected scope check to be skipped, got %d calls", coordinator.scopeChecks)
+	}
+}
+
+func TestCollectUsecaseRunCollectsEachSelectedUser(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "collect.db")
+	db, err := database.NewDatabaseManager(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+
+	var coordinators []*stubCollectCoordinator
+	var tokens []string
+	usecase := &CollectUsecase{
+		runtime: &appRuntime{
+			loadConfig: func(path string) (*config.Config, error) {
+				return &config.Config{
+					DatabasePath: dbPath,
+					Slack:        config.ServiceConfig{Enabled: true, AccessToken: "lead-token"},
+					GitHub:       config.ServiceConfig{Enabled: true},
+					Users: []config.UserConfig{
+						{Name: "alice", Teams: []string{"backend"}, Slack: config.UserServiceConfig{AccessToken: "xoxp-alice"}, GitHub: config.UserServiceConfig{AccessToken: "ghp-alice"}},
+						{Name: "bob", Teams: []string{"backend"}, Slack: config.UserServiceConfig{AccessToken: "xoxp-bob"}},
+						{Name: "carol", Teams: []string{"design"}, Slack: config.UserServiceConfig{AccessToken: "xoxp-carol"}},
+					},
+				}, nil
+			},
+			openDatabase: func(path string) (*database.DatabaseManager, error) {
+				return db, nil
+			},
+		},
+		newCollector: func(cfg *config.Config, db *database.DatabaseManager) collectCoordinator {
+			coordinator := &stubCollectCoordinator{}
+			if cfg.Slack.AccessToken == "xoxp-bob" {
+				coordinator.initErr = fmt.Errorf("invalid_auth")
+			}
+			coordinators = append(coordinators, coordinator)
+			tokens = append(tokens, cfg.Slack.AccessToken)
+			return coordinator
+		},
+	}
+
+	result, err := usecase.Run(CollectRequest{
+		StartTime: time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2026, 3, 10, 18, 0, 0, 0, time.UTC),
+		Teams:     []string{"backend"},
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(tokens, []string{"xoxp-alice", "xoxp-bob"}) {
+		t.Fatalf("expected collection with each member's token, got %v", tokens)
+	}
+	if len(result.Members) != 2 || result.Members[0].Name != "alice" || result.Members[1].Name != "bob" {
+		t.Fatalf("unexpected member results: %+v", result.Members)
+	}
+	if coordinators[0].member != "alice" {
+		t.Fatalf("expected events to be tagged with alice, got %q", coordinators[0].member)
+	}
+	if !reflect.DeepEqual(coordinators[0].collectedWith, []string{"slack", "github"}) {
+		t.Fatalf("expected alice to collect slack and github, got %v", coordinators[0].collectedWith)
+	}
+	if result.Members[0].Error != nil {
+		t.Fatalf("expected alice to succeed, got %v", result.Members[0].Error)
+	}
+	if result.Members[1].Error == nil {
+		t.Fatalf("expected bob's failure to be reported")
+	}
+	if !reflect.DeepEqual(result.Members[1].TargetServices, []string{"slack"}) {
+		t.Fatalf("expected bob to be limited to services with credentials, got %v", result.Members[1].TargetServices)
+	}
+
+	if _, err := usecase.Run(CollectRequest{Users: []string{"dave"}}); err == nil {
+		t.Fatalf("expected unknown user to return error")
+	}
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/exporter"
+)
+
+type ConvertRequest struct {
+	ConfigPath string
+	// InputPath は json / json-events / ndjson の出力ファイルです。"-" で標準入力から読み込みます
+	InputPath  string
+	Format     string
+	OutputPath string
+}
+
+type ConvertResult struct {
+	EventCount int
+}
+
+// ConvertUsecase は export の出力ファイルを、データベースを使わずに別の形式へ変換します。
+// 設定ファイルはタイムゾーン（xlsx の日時セル）にだけ使い、読み込めない場合はローカルのタイムゾーンで出力します。
+type ConvertUsecase struct {
+	loadConfig   func(string) (*config.Config, error)
+	openInput    func(string) (io.ReadCloser, error)
+	exportEvents func(exporter.EventSource, exportTarget) error
+}
+
+func NewConvertUsecase() *ConvertUsecase {
+	return &ConvertUsecase{
+		loadConfig:   config.LoadConfigUnresolved,
+		openInput:    openConvertInput,
+		exportEvents: exportEvents,
+	}
+}
+
+func openConvertInput(path string) (io.ReadCloser, error) {
+	if path == stdioPath {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+func (u *ConvertUsecase) Run(request ConvertRequest) (*ConvertResult, error) {
+	target := exportTarget{Format: request.Format, OutputPath: request.OutputPath}
+	if err := target.validate(); err != nil {
+		return nil, err
+	}
+
+	input, err := u.openInput(request.InputPath)
+	if err != nil {
+		return nil, fmt.Errorf("入力ファイルを開けませんでした: %w", err)
+	}
+	events, err := exporter.ReadEvents(input)
+	input.Close()
+	if err != nil {
+		return nil, fmt.Errorf("入力をイベントとして読み込めませんでした: %w", err)
+	}
+
+	// イベントがなくても、ヘッダーだけの CSV や空配列など空の出力を書き出します
+	if cfg, err := u.loadConfig(request.ConfigPath); err == nil {
+		if timezoneManager, err := cfg.GetTimezoneManager(); err == nil {
+			target.Location = timezoneManager.GetLocation()
+			target.Chunk.Location = target.Location
+		}
+	}
+
+	if err := u.exportEvents(exporter.NewSliceSource(events), target); err != nil {
+		return nil, err
+	}
+	return &ConvertResult{EventCount: len(events)}, nil
+}
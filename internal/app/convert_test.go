@@ -0,0 +1,168 @@
+package app
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/exporter"
+)
+
+func convertTestEvents() []*config.Event {
+	base := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	return []*config.Event{
+		{ID: "slack-1", Service: "slack", Type: "message", Title: "朝会", Timestamp: base, Metadata: `{"schema_version":1}`},
+		{ID: "github-1", Service: "github", Type: "pull_request_created", Title: "PR", Timestamp: base.Add(time.Hour), Metadata: `{"schema_version":1}`},
+		{ID: "slack-2", Service: "slack", Type: "message", Title: "翌日", Timestamp: base.AddDate(0, 0, 1)},
+	}
+}
+
+func TestConvertUsecaseRunReadsEveryEventFormat(t *testing.T) {
+	dir := t.TempDir()
+	jsonExporter := exporter.NewJSONExporter()
+	inputs := map[string]func(*bytes.Buffer) error{
+		"export.json": func(b *bytes.Buffer) error {
+			_, err := jsonExporter.WriteJSON(b, exporter.NewSliceSource(convertTestEvents()))
+			return err
+		},
+		"events.json": func(b *bytes.Buffer) error {
+			_, err := jsonExporter.WriteEventsOnly(b, exporter.NewSliceSource(convertTestEvents()))
+			return err
+		},
+		"events.ndjson": func(b *bytes.Buffer) error {
+			_, err := jsonExporter.WriteNDJSON(b, exporter.NewSliceSource(convertTestEvents()))
+			return err
+		},
+	}
+
+	for name, write := range inputs {
+		var buffer bytes.Buffer
+		if err := write(&buffer); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		inputPath := filepath.Join(dir, name)
+		if err := os.WriteFile(inputPath, buffer.Bytes(), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+
+		var exported []*config.Event
+		var exportedTarget exportTarget
+		usecase := &ConvertUsecase{
+			loadConfig: func(string) (*config.Config, error) { return &config.Config{Timezone: "Asia/Tokyo"}, nil },
+			openInput:  openConvertInput,
+			exportEvents: func(source exporter.EventSource, target exportTarget) error {
+				exported = readAllEvents(t, source)
+				exportedTarget = target
+				return nil
+			},
+		}
+		result, err := usecase.Run(ConvertRequest{InputPath: inputPath, Format: "xlsx", OutputPath: "out.xlsx"})
+		if err != nil {
+			t.Fatalf("Run(%s) returned error: %v", name, err)
+		}
+		if result.EventCount != 3 || len(exported) != 3 || exported[1].ID != "github-1" {
+			t.Fatalf("unexpected events converted from %s: %+v", name, exported)
+		}
+		if exportedTarget.Location == nil || exportedTarget.Location.String() != "Asia/Tokyo" {
+			t.Fatalf("expected the configured timezone for %s, got %v", name, exportedTarget.Location)
+		}
+	}
+}
+
+func TestConvertUsecaseRunRejectsJSONAIAndUnknownFormats(t *testing.T) {
+	var buffer bytes.Buffer
+	if _, err := exporter.NewJSONExporter().WriteForAI(&buffer, exporter.NewSliceSource(convertTestEvents())); err != nil {
+		t.Fatalf("failed to write json-ai: %v", err)
+	}
+	inputPath := filepath.Join(t.TempDir(), "ai.json")
+	if err := os.WriteFile(inputPath, buffer.Bytes(), 0644); err != nil {
+		t.Fatalf("failed to write json-ai: %v", err)
+	}
+
+	usecase := &ConvertUsecase{
+		loadConfig: func(string) (*config.Config, error) { return nil, errors.New("no config") },
+		openInput:  openConvertInput,
+		exportEvents: func(exporter.EventSource, exportTarget) error {
+			t.Fatalf("exportEvents should not be called")
+			return nil
+		},
+	}
+	if _, err := usecase.Run(ConvertRequest{InputPath: inputPath, Format: "csv"}); err == nil || !strings.Contains(err.Error(), "json-ai") {
+		t.Fatalf("expected json-ai input to be rejected, got %v", err)
+	}
+	if _, err := usecase.Run(ConvertRequest{InputPath: inputPath, Format: "pdf"}); err == nil || !strings.Contains(err.Error(), "csv-daily") {
+		t.Fatalf("expected unsupported format error listing formats, got %v", err)
+	}
+}
+
+func TestConvertUsecaseRunWritesEmptyOutputForEmptyInput(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "empty.ndjson")
+	if err := os.WriteFile(inputPath, nil, 0644); err != nil {
+		t.Fatalf("failed to write empty input: %v", err)
+	}
+
+	usecase := &ConvertUsecase{
+		loadConfig:   func(string) (*config.Config, error) { return nil, errors.New("no config") },
+		openInput:    openConvertInput,
+		exportEvents: exportEvents,
+	}
+	outputs := map[string]string{"csv": "Timestamp,", "json-events": "[]\n"}
+	for format, want := range outputs {
+		outputPath := filepath.Join(dir, "out-"+format)
+		result, err := usecase.Run(ConvertRequest{InputPath: inputPath, Format: format, OutputPath: outputPath})
+		if err != nil {
+			t.Fatalf("Run(%s) returned error: %v", format, err)
+		}
+		if result.EventCount != 0 {
+			t.Fatalf("expected no events, got %d", result.EventCount)
+		}
+		data, err := os.ReadFile(outputPath)
+		if err != nil {
+			t.Fatalf("expected %s output to be written: %v", format, err)
+		}
+		if !strings.HasPrefix(string(data), want) {
+			t.Fatalf("unexpected empty %s output: %q", format, data)
+		}
+	}
+}
+
+func TestWriteEventsWritesEveryFormatToWriter(t *testing.T) {
+	for _, format := range exportFormats {
+		var buffer bytes.Buffer
+		target := exportTarget{Format: format, OutputPath: stdioPath, Location: time.UTC}
+		if err := writeEvents(&buffer, exporter.NewSliceSource(convertTestEvents()), target); err != nil {
+			t.Fatalf("writeEvents(%s) returned error: %v", format, err)
+		}
+		if buffer.Len() == 0 {
+			t.Fatalf("writeEvents(%s) wrote nothing", format)
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := writeEvents(&buffer, exporter.NewSliceSource(convertTestEvents()), exportTarget{Format: "csv-daily"}); err != nil {
+		t.Fatalf("writeEvents(csv-daily) returned error: %v", err)
+	}
+	records, err := csv.NewReader(&buffer).ReadAll()
+	if err != nil {
+		t.Fatalf("csv-daily output is not CSV: %v", err)
+	}
+	var rows []string
+	for _, record := range records[1:] {
+		rows = append(rows, record[0]+" "+record[1]+" "+record[2])
+	}
+	if strings.Join(rows, ",") != "2026-03-04 github 1,2026-03-04 slack 1,2026-03-05 slack 1" {
+		t.Fatalf("unexpected csv-daily rows: %v", rows)
+	}
+
+	chunked := exportTarget{Format: "json-ai", OutputPath: stdioPath, Chunk: exporter.ChunkOptions{MaxTokens: 1000}}
+	if err := chunked.validate(); err == nil {
+		t.Fatalf("expected chunked json-ai to stdout to be rejected")
+	}
+}
@@ -0,0 +1,195 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/database"
+	"github.com/iriam/worklogr/internal/secrets"
+)
+
+type DBBackupRequest struct {
+	ConfigPath string
+	OutputPath string
+	// Force は既存のファイルを上書きします
+	Force bool
+}
+
+type DBBackupResult struct {
+	DatabasePath string
+	OutputPath   string
+	Size         int64
+}
+
+type DBRestoreRequest struct {
+	ConfigPath string
+	InputPath  string
+}
+
+type DBRestoreResult struct {
+	DatabasePath string
+	// SafetyBackupPath は復元前のデータベースを退避したファイルです
+	SafetyBackupPath string
+}
+
+type DBMergeRequest struct {
+	ConfigPath string
+	OtherPath  string
+}
+
+type DBMergeResult struct {
+	DatabasePath string
+	Stats        *database.MergeStats
+}
+
+type DBRekeyRequest struct {
+	ConfigPath string
+	// NewKey は新しい暗号化の鍵です（Decrypt の場合は不要）
+	NewKey string
+	// Decrypt は暗号化を解除して平文に戻します
+	Decrypt bool
+}
+
+type DBRekeyResult struct {
+	DatabasePath string
+	// SecretName は新しい鍵を保存したシークレット名です（空の場合は設定ファイルの更新が必要です）
+	SecretName string
+}
+
+// DBUsecase はデータベースファイルのバックアップ・復元・統合と、暗号化の鍵の変更を行います
+type DBUsecase struct {
+	runtime   *appRuntime
+	now       func() time.Time
+	openStore func(secrets.Options) (secrets.Store, error)
+}
+
+func NewDBUsecase() *DBUsecase {
+	return &DBUsecase{runtime: newAppRuntime(), now: time.Now, openStore: secrets.Open}
+}
+
+// Backup は SQLite のオンラインバックアップでデータベースを複製します。
+// 収集の実行中でも一貫した状態のコピーが作られます。
+func (u *DBUsecase) Backup(request DBBackupRequest) (*DBBackupResult, error) {
+	if request.OutputPath == "" {
+		return nil, fmt.Errorf("バックアップ先のファイルを指定してください")
+	}
+	if _, err := os.Stat(request.OutputPath); err == nil && !request.Force {
+		return nil, fmt.Errorf("%s は既に存在します（上書きするには --force を指定してください）", request.OutputPath)
+	}
+
+	return withDatabase(u.runtime, request.ConfigPath, func(cfg *config.Config, db *database.DatabaseManager) (*DBBackupResult, error) {
+		if samePath(cfg.DatabasePath, request.OutputPath) {
+			return nil, fmt.Errorf("バックアップ先が現在のデータベースと同じです: %s", request.OutputPath)
+		}
+		if err := db.Backup(request.OutputPath); err != nil {
+			return nil, fmt.Errorf("バックアップに失敗しました: %w", err)
+		}
+
+		result := &DBBackupResult{DatabasePath: cfg.DatabasePath, OutputPath: request.OutputPath}
+		if info, err := os.Stat(request.OutputPath); err == nil {
+			result.Size = info.Size()
+		}
+		return result, nil
+	})
+}
+
+// Restore はバックアップの内容でデータベースを置き換えます。
+// 置き換える前に現在のデータベースを同じディレクトリへ退避します。
+func (u *DBUsecase) Restore(request DBRestoreRequest) (*DBRestoreResult, error) {
+	if request.InputPath == "" {
+		return nil, fmt.Errorf("復元するバックアップファイルを指定してください")
+	}
+
+	return withDatabase(u.runtime, request.ConfigPath, func(cfg *config.Config, db *database.DatabaseManager) (*DBRestoreResult, error) {
+		if samePath(cfg.DatabasePath, request.InputPath) {
+			return nil, fmt.Errorf("復元元が現在のデータベースと同じです: %s", request.InputPath)
+		}
+
+		safetyPath := fmt.Sprintf("%s.before-restore-%s", cfg.DatabasePath, u.now().Format("20060102_150405"))
+		if err := db.Backup(safetyPath); err != nil {
+			return nil, fmt.Errorf("復元前のデータベースの退避に失敗しました: %w", err)
+		}
+		if err := db.Restore(request.InputPath); err != nil {
+			return nil, fmt.Errorf("復元に失敗しました（元のデータベースは %s に退避済みです）: %w", safetyPath, err)
+		}
+		return &DBRestoreResult{DatabasePath: cfg.DatabasePath, SafetyBackupPath: safetyPath}, nil
+	})
+}
+
+// Merge は別の worklogr データベースのイベント・添付・注釈を取り込みます。
+// 同じIDのイベントは、後から保存された方（created_at が新しい方）を採用します。
+func (u *DBUsecase) Merge(request DBMergeRequest) (*DBMergeResult, error) {
+	if request.OtherPath == "" {
+		return nil, fmt.Errorf("統合するデータベースファイルを指定してください")
+	}
+
+	return withDatabase(u.runtime, request.ConfigPath, func(cfg *config.Config, db *database.DatabaseManager) (*DBMergeResult, error) {
+		if samePath(cfg.DatabasePath, request.OtherPath) {
+			return nil, fmt.Errorf("統合元が現在のデータベースと同じです: %s", request.OtherPath)
+		}
+		stats, err := db.Merge(request.OtherPath)
+		if err != nil {
+			return nil, fmt.Errorf("データベースの統合に失敗しました: %w", err)
+		}
+		return &DBMergeResult{DatabasePath: cfg.DatabasePath, Stats: stats}, nil
+	})
+}
+
+// Rekey はデータベースを新しい鍵で暗号化し直します。
+// encryption.key が secret:// 参照の場合は、再暗号化の後でそのシークレットを新しい鍵に更新します。
+func (u *DBUsecase) Rekey(request DBRekeyRequest) (*DBRekeyResult, error) {
+	if !request.Decrypt && request.NewKey == "" {
+		return nil, fmt.Errorf("新しい鍵が空です")
+	}
+
+	return withDatabase(u.runtime, request.ConfigPath, func(cfg *config.Config, db *database.DatabaseManager) (*DBRekeyResult, error) {
+		result := &DBRekeyResult{DatabasePath: cfg.DatabasePath}
+		if request.Decrypt {
+			if !db.Encrypted() {
+				return nil, fmt.Errorf("データベースは暗号化されていません")
+			}
+			if err := db.Rekey(""); err != nil {
+				return nil, fmt.Errorf("暗号化の解除に失敗しました: %w", err)
+			}
+			return result, nil
+		}
+
+		if !cfg.Encryption.IsEnabled() {
+			return nil, fmt.Errorf("暗号化が有効ではありません（設定ファイルの encryption.enabled を true にしてください）")
+		}
+		if err := db.Rekey(request.NewKey); err != nil {
+			return nil, fmt.Errorf("再暗号化に失敗しました: %w", err)
+		}
+
+		ref := cfg.SecretRef("encryption.key")
+		if ref == "" {
+			return result, nil
+		}
+		name := secrets.RefName(ref)
+		store, err := u.openStore(cfg.Secrets.StoreOptions())
+		if err == nil {
+			err = store.Set(name, request.NewKey)
+		}
+		if err != nil {
+			// データベースは新しい鍵で暗号化済みのため、鍵の保存だけをやり直してもらう
+			return nil, fmt.Errorf("データベースは新しい鍵で再暗号化しましたが、シークレット %s を更新できませんでした。新しい鍵を手動で保存してください: %w", name, err)
+		}
+		result.SecretName = name
+		return result, nil
+	})
+}
+
+// samePath は2つのパスが同じファイルを指すかどうかを返します
+func samePath(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	if errA == nil && errB == nil {
+		return os.SameFile(infoA, infoB)
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/database"
+	"github.com/iriam/worklogr/internal/secrets"
+)
+
+func TestDBUsecaseBackupRestoreAndMerge(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "worklogr.db")
+	timestamp := time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)
+
+	db, err := database.NewDatabaseManager(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	if err := db.InsertEvent(&config.Event{ID: "event-1", Service: "slack", Type: "message", Title: "投稿", Timestamp: timestamp}); err != nil {
+		t.Fatalf("failed to seed test database: %v", err)
+	}
+	db.Close()
+
+	usecase := &DBUsecase{
+		runtime: newImportTestRuntime(dbPath),
+		now:     func() time.Time { return time.Date(2026, 3, 11, 9, 0, 0, 0, time.UTC) },
+	}
+
+	backupPath := filepath.Join(dir, "backup.db")
+	backup, err := usecase.Backup(DBBackupRequest{OutputPath: backupPath})
+	if err != nil {
+		t.Fatalf("Backup returned error: %v", err)
+	}
+	if backup.Size == 0 {
+		t.Fatalf("expected backup file to be written, got %+v", backup)
+	}
+	if _, err := usecase.Backup(DBBackupRequest{OutputPath: backupPath}); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected existing backup to require --force, got %v", err)
+	}
+	if _, err := usecase.Backup(DBBackupRequest{OutputPath: dbPath, Force: true}); err == nil {
+		t.Fatalf("expected backup onto the database itself to fail")
+	}
+
+	// 別の端末で収集したデータベース
+	otherPath := filepath.Join(dir, "laptop.db")
+	other, err := database.NewDatabaseManager(otherPath)
+	if err != nil {
+		t.Fatalf("failed to create other database: %v", err)
+	}
+	if err := other.InsertEvent(&config.Event{ID: "event-2", Service: "github", Type: "pull_request", Title: "PR", Timestamp: timestamp.Add(time.Hour)}); err != nil {
+		t.Fatalf("failed to seed other database: %v", err)
+	}
+	other.Close()
+
+	merged, err := usecase.Merge(DBMergeRequest{OtherPath: otherPath})
+	if err != nil {
+		t.Fatalf("Merge returned error: %v", err)
+	}
+	if merged.Stats.EventsAdded != 1 {
+		t.Fatalf("expected 1 merged event, got %+v", merged.Stats)
+	}
+
+	restored, err := usecase.Restore(DBRestoreRequest{InputPath: backupPath})
+	if err != nil {
+		t.Fatalf("Restore returned error: %v", err)
+	}
+	if restored.SafetyBackupPath != dbPath+".before-restore-20260311_090000" {
+		t.Fatalf("unexpected safety backup path: %q", restored.SafetyBackupPath)
+	}
+	if _, err := os.Stat(restored.SafetyBackupPath); err != nil {
+		t.Fatalf("expected safety backup to exist: %v", err)
+	}
+
+	db, err = database.NewDatabaseManager(dbPath)
+	if err != nil {
+		t.Fatalf("failed to reopen database: %v", err)
+	}
+	defer db.Close()
+	ids, err := db.ExistingEventIDs([]string{"event-1", "event-2"})
+	if err != nil {
+		t.Fatalf("ExistingEventIDs returned error: %v", err)
+	}
+	if !ids["event-1"] || ids["event-2"] {
+		t.Fatalf("expected restore to bring back the backed-up state, got %v", ids)
+	}
+}
+
+func TestDBUsecaseRekeyUpdatesSecret(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "worklogr.db")
+	enabled := true
+	cfg := &config.Config{
+		DatabasePath: dbPath,
+		Encryption:   config.EncryptionConfig{Enabled: &enabled, Key: "old-key"},
+	}
+	cfg.SetSecretRef("encryption.key", "secret://database_key")
+	store := memorySecretStore{"database_key": "old-key"}
+
+	usecase := &DBUsecase{
+		runtime: &appRuntime{
+			loadConfig:   func(string) (*config.Config, error) { return cfg, nil },
+			openDatabase: database.NewDatabaseManager,
+		},
+		now:       time.Now,
+		openStore: func(secrets.Options) (secrets.Store, error) { return store, nil },
+	}
+
+	result, err := usecase.Rekey(DBRekeyRequest{NewKey: "new-key"})
+	if err != nil {
+		t.Fatalf("Rekey returned error: %v", err)
+	}
+	if result.SecretName != "database_key" || store["database_key"] != "new-key" {
+		t.Fatalf("expected secret to hold the new key, got %+v / %v", result, store)
+	}
+
+	// 古い鍵では開けず、新しい鍵で開ける
+	if _, err := usecase.Backup(DBBackupRequest{OutputPath: filepath.Join(t.TempDir(), "backup.db")}); err == nil {
+		t.Fatalf("expected old key to be rejected")
+	}
+	cfg.Encryption.Key = "new-key"
+	if _, err := usecase.Rekey(DBRekeyRequest{Decrypt: true}); err != nil {
+		t.Fatalf("decrypting Rekey returned error: %v", err)
+	}
+	if _, err := usecase.Rekey(DBRekeyRequest{}); err == nil {
+		t.Fatalf("expected empty key to be rejected")
+	}
+}
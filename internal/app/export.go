@@ -1,31 +1,52 @@
 package app
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/iriam/worklogr/internal/config"
 	"github.com/iriam/worklogr/internal/database"
 	"github.com/iriam/worklogr/internal/exporter"
+	"github.com/iriam/worklogr/internal/redact"
 )
 
 type ExportRequest struct {
 	ConfigPath string
 	StartTime  time.Time
 	EndTime    time.Time
-	Services   []string
+	EventQuery
 	Format     string
 	OutputPath string
+	// Redact はredaction設定のstageに関わらず出力時のマスキングを強制します
+	Redact bool
+	// MaxTokens / MaxBytes を指定すると json-ai 出力を上限内のチャンクに分割します
+	MaxTokens int
+	MaxBytes  int
+	// ChunkBy はチャンクのまとめ方です（day, service, member）
+	ChunkBy string
+}
+
+// exportTarget は出力形式と出力先、チャンク分割の設定です
+type exportTarget struct {
+	Format     string
+	OutputPath string
+	Chunk      exporter.ChunkOptions
+	// Location は xlsx の日時セルと日付の区切りに使うタイムゾーンです
+	Location *time.Location
 }
 
 type ExportResult struct {
 	MatchedEventCount int
+	RedactionStats    redact.Stats
 }
 
 type ExportUsecase struct {
 	runtime      *appRuntime
-	exportEvents func([]*config.Event, string, string) error
+	exportEvents func(exporter.EventSource, exportTarget) error
 }
 
 func NewExportUsecase() *ExportUsecase {
@@ -36,18 +57,58 @@ func NewExportUsecase() *ExportUsecase {
 }
 
 func (u *ExportUsecase) Run(request ExportRequest) (*ExportResult, error) {
+	target := exportTarget{
+		Format:     request.Format,
+		OutputPath: request.OutputPath,
+		Chunk: exporter.ChunkOptions{
+			MaxTokens: request.MaxTokens,
+			MaxBytes:  request.MaxBytes,
+			GroupBy:   request.ChunkBy,
+		},
+	}
+	if err := target.Chunk.Validate(); err != nil {
+		return nil, fmt.Errorf("チャンク分割の指定が無効です: %w", err)
+	}
+	if target.Chunk.Enabled() && strings.ToLower(request.Format) != "json-ai" {
+		return nil, fmt.Errorf("--max-tokens / --max-bytes は json-ai 形式でのみ指定できます")
+	}
+	if err := target.validate(); err != nil {
+		return nil, err
+	}
+
 	return withDatabase(u.runtime, request.ConfigPath, func(cfg *config.Config, db *database.DatabaseManager) (*ExportResult, error) {
-		events, err := db.GetEvents(request.StartTime, request.EndTime, request.Services)
+		filter, err := buildEventFilter(cfg, request.EventQuery)
+		if err != nil {
+			return nil, err
+		}
+
+		// 添付本文を含むイベントを一度に読み込まないよう、少しずつ読み出しながら書き出す
+		iterator, err := db.IterateEvents(request.StartTime, request.EndTime, filter)
 		if err != nil {
 			return nil, fmt.Errorf("イベントの取得に失敗しました: %w", err)
 		}
 
-		result := &ExportResult{MatchedEventCount: len(events)}
-		if len(events) == 0 {
+		result := &ExportResult{MatchedEventCount: iterator.Count()}
+		if iterator.Count() == 0 {
 			return result, nil
 		}
 
-		if err := u.exportEvents(events, request.Format, request.OutputPath); err != nil {
+		var source exporter.EventSource = iterator
+		if request.Redact || cfg.Redaction.AppliesAtExport() {
+			redactor, err := redact.New(cfg.Redaction)
+			if err != nil {
+				return nil, fmt.Errorf("redaction設定が無効です: %w", err)
+			}
+			result.RedactionStats = make(redact.Stats)
+			source = &redactingSource{EventSource: iterator, redactor: redactor, stats: result.RedactionStats}
+		}
+
+		if timezoneManager, err := cfg.GetTimezoneManager(); err == nil {
+			target.Chunk.Location = timezoneManager.GetLocation()
+			target.Location = target.Chunk.Location
+		}
+
+		if err := u.exportEvents(source, target); err != nil {
 			return nil, err
 		}
 
@@ -55,31 +116,165 @@ func (u *ExportUsecase) Run(request ExportRequest) (*ExportResult, error) {
 	})
 }
 
-func exportEvents(events []*config.Event, format, outputPath string) error {
+// redactingSource は読み出したイベントを書き出す前にマスキングします
+type redactingSource struct {
+	exporter.EventSource
+	redactor *redact.Redactor
+	stats    redact.Stats
+}
+
+func (s *redactingSource) Next() bool {
+	if !s.EventSource.Next() {
+		return false
+	}
+	s.redactor.RedactEvent(s.EventSource.Event(), s.stats)
+	return true
+}
+
+// exportFormats は export / convert で指定できる形式です
+var exportFormats = []string{"json", "json-ai", "ndjson", "json-events", "csv", "csv-summary", "csv-spreadsheet", "csv-daily", "xlsx"}
+
+// stdioPath を出力先に指定すると標準出力へ書き出し、convert の入力に指定すると標準入力から読み込みます
+const stdioPath = "-"
+
+// validate は形式と出力先の組み合わせを確認します
+func (target exportTarget) validate() error {
+	format := strings.ToLower(target.Format)
+	known := false
+	for _, candidate := range exportFormats {
+		if candidate == format {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return fmt.Errorf("サポートされていない形式です: %s。対応形式: %s", target.Format, strings.Join(exportFormats, ", "))
+	}
+	if target.OutputPath == stdioPath && target.Chunk.Enabled() {
+		return fmt.Errorf("チャンク分割した json-ai は複数のファイルに書き出すため、標準出力（-o -）には出力できません")
+	}
+	return nil
+}
+
+// exportEvents はイベントを1件ずつ書き出します。
+// 全体を見て出力するチャンク分割した json-ai と csv-summary だけは、読み込んでから書き出します。
+func exportEvents(source exporter.EventSource, target exportTarget) error {
+	if target.OutputPath == stdioPath {
+		return writeEvents(os.Stdout, source, target)
+	}
+
+	format := target.Format
+	outputPath := target.OutputPath
+
 	switch strings.ToLower(format) {
 	case "json":
 		jsonExporter := exporter.NewJSONExporter()
-		if err := jsonExporter.ExportToJSON(events, outputPath); err != nil {
+		if err := jsonExporter.StreamToJSON(source, outputPath); err != nil {
 			return fmt.Errorf("JSONエクスポートに失敗しました: %w", err)
 		}
+	case "ndjson":
+		jsonExporter := exporter.NewJSONExporter()
+		if err := jsonExporter.StreamToNDJSON(source, outputPath); err != nil {
+			return fmt.Errorf("NDJSONエクスポートに失敗しました: %w", err)
+		}
 	case "json-ai":
 		jsonExporter := exporter.NewJSONExporter()
-		if err := jsonExporter.ExportForAI(events, outputPath); err != nil {
+		if target.Chunk.Enabled() {
+			events, err := exporter.ReadAll(source)
+			if err != nil {
+				return fmt.Errorf("イベントの取得に失敗しました: %w", err)
+			}
+			if _, err := jsonExporter.ExportForAIChunked(events, outputPath, target.Chunk); err != nil {
+				return fmt.Errorf("AI用JSONのチャンク分割エクスポートに失敗しました: %w", err)
+			}
+			return nil
+		}
+		if err := jsonExporter.StreamForAI(source, outputPath); err != nil {
 			return fmt.Errorf("AI用JSONエクスポートに失敗しました: %w", err)
 		}
+	case "json-events":
+		jsonExporter := exporter.NewJSONExporter()
+		if err := jsonExporter.StreamEventsOnly(source, outputPath); err != nil {
+			return fmt.Errorf("イベント配列のJSONエクスポートに失敗しました: %w", err)
+		}
 	case "csv":
 		csvExporter := exporter.NewCSVExporter()
-		if err := csvExporter.ExportToCSV(events, outputPath); err != nil {
+		if err := csvExporter.StreamToCSV(source, outputPath); err != nil {
 			return fmt.Errorf("CSVエクスポートに失敗しました: %w", err)
 		}
 	case "csv-summary":
+		events, err := exporter.ReadAll(source)
+		if err != nil {
+			return fmt.Errorf("イベントの取得に失敗しました: %w", err)
+		}
 		csvExporter := exporter.NewCSVExporter()
 		if err := csvExporter.ExportToCSVWithSummary(events, outputPath); err != nil {
 			return fmt.Errorf("サマリー付きCSVエクスポートに失敗しました: %w", err)
 		}
+	case "csv-spreadsheet":
+		csvExporter := exporter.NewCSVExporter()
+		if err := csvExporter.StreamForSpreadsheet(source, outputPath); err != nil {
+			return fmt.Errorf("表計算向けCSVエクスポートに失敗しました: %w", err)
+		}
+	case "csv-daily":
+		csvExporter := exporter.NewCSVExporter()
+		if err := csvExporter.StreamDailySummary(source, outputPath); err != nil {
+			return fmt.Errorf("日別サマリーCSVエクスポートに失敗しました: %w", err)
+		}
+	case "xlsx":
+		xlsxExporter := exporter.NewXLSXExporter()
+		xlsxExporter.Location = target.Location
+		if err := xlsxExporter.StreamToXLSX(source, outputPath); err != nil {
+			return fmt.Errorf("Excelエクスポートに失敗しました: %w", err)
+		}
 	default:
-		return fmt.Errorf("サポートされていない形式です: %s。対応形式: json, json-ai, csv, csv-summary", format)
+		return fmt.Errorf("サポートされていない形式です: %s。対応形式: %s", format, strings.Join(exportFormats, ", "))
 	}
 
 	return nil
 }
+
+// writeEvents は指定の形式で w に書き出します（-o - のとき標準出力）。
+// 出力先のファイル名や件数の表示は行いません。
+func writeEvents(w io.Writer, source exporter.EventSource, target exportTarget) error {
+	buffered := bufio.NewWriter(w)
+	jsonExporter := exporter.NewJSONExporter()
+	csvExporter := exporter.NewCSVExporter()
+
+	var err error
+	switch format := strings.ToLower(target.Format); format {
+	case "json":
+		_, err = jsonExporter.WriteJSON(buffered, source)
+	case "ndjson":
+		_, err = jsonExporter.WriteNDJSON(buffered, source)
+	case "json-ai":
+		_, err = jsonExporter.WriteForAI(buffered, source)
+	case "csv":
+		_, err = csvExporter.WriteCSV(buffered, source)
+	case "csv-spreadsheet":
+		_, err = csvExporter.WriteSpreadsheet(buffered, source)
+	case "csv-daily":
+		_, err = csvExporter.WriteDailySummary(buffered, source)
+	case "xlsx":
+		xlsxExporter := exporter.NewXLSXExporter()
+		xlsxExporter.Location = target.Location
+		_, err = xlsxExporter.WriteXLSX(buffered, source)
+	case "json-events":
+		_, err = jsonExporter.WriteEventsOnly(buffered, source)
+	case "csv-summary":
+		events, readErr := exporter.ReadAll(source)
+		if readErr != nil {
+			return fmt.Errorf("イベントの取得に失敗しました: %w", readErr)
+		}
+		_, err = csvExporter.WriteCSVWithSummary(buffered, events)
+	default:
+		return fmt.Errorf("サポートされていない形式です: %s。対応形式: %s", target.Format, strings.Join(exportFormats, ", "))
+	}
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if err != nil {
+		return fmt.Errorf("標準出力への書き出しに失敗しました: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/database"
+)
+
+type HistoryRequest struct {
+	ConfigPath string
+	EventID    string
+}
+
+type HistoryResult struct {
+	// Event は現在保存されている版です（収集元で削除された場合は DeletedAt が設定されます）
+	Event *config.Event
+	// Versions は再収集で置き換えられた以前の版です（古い順）
+	Versions []database.EventVersion
+}
+
+// HistoryUsecase はイベントの編集履歴（再収集で置き換えられた以前の版）を表示します
+type HistoryUsecase struct {
+	runtime *appRuntime
+}
+
+func NewHistoryUsecase() *HistoryUsecase {
+	return &HistoryUsecase{runtime: newAppRuntime()}
+}
+
+func (u *HistoryUsecase) Run(request HistoryRequest) (*HistoryResult, error) {
+	eventID := strings.TrimSpace(request.EventID)
+	if eventID == "" {
+		return nil, fmt.Errorf("イベントIDを指定してください")
+	}
+
+	return withDatabase(u.runtime, request.ConfigPath, func(cfg *config.Config, db *database.DatabaseManager) (*HistoryResult, error) {
+		event, err := db.GetEvent(eventID)
+		if err != nil {
+			return nil, fmt.Errorf("イベントの取得に失敗しました: %w", err)
+		}
+		if event == nil {
+			return nil, fmt.Errorf("イベント '%s' は見つかりません（IDは `worklogr query -f json` や browse の o で確認できます）", eventID)
+		}
+
+		versions, err := db.EventVersions(eventID)
+		if err != nil {
+			return nil, fmt.Errorf("編集履歴の取得に失敗しました: %w", err)
+		}
+		return &HistoryResult{Event: event, Versions: versions}, nil
+	})
+}
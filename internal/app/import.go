@@ -0,0 +1,194 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/iriam/worklogr/internal/collector"
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/database"
+	"github.com/iriam/worklogr/internal/redact"
+)
+
+// importBatchSize は1トランザクションで保存するイベント数です
+const importBatchSize = 500
+
+type ImportRequest struct {
+	ConfigPath string
+	// InputPath は NDJSON ファイルのパスです（"-" または空で Input / 標準入力から読み込みます）
+	InputPath string
+	Input     io.Reader
+}
+
+type ImportResult struct {
+	// ImportedEventCount は読み込んで保存したイベント数、NewEventCount はそのうち新規のものです
+	ImportedEventCount int
+	NewEventCount      int
+	// AnnotationCount は一緒に取り込んだ注釈（ピン留め・メモ等）の件数です
+	AnnotationCount int
+	StartTime       time.Time
+	EndTime         time.Time
+	RedactionStats  redact.Stats
+}
+
+type ImportUsecase struct {
+	runtime *appRuntime
+}
+
+func NewImportUsecase() *ImportUsecase {
+	return &ImportUsecase{runtime: newAppRuntime()}
+}
+
+// Run は NDJSON（1行に1イベント）を読み込んでデータベースに保存します。
+// 同じIDのイベントは上書きされるため、同じファイルを何度取り込んでも結果は変わりません。
+func (u *ImportUsecase) Run(request ImportRequest) (*ImportResult, error) {
+	input := request.Input
+	if request.InputPath != "" && request.InputPath != "-" {
+		file, err := os.Open(request.InputPath)
+		if err != nil {
+			return nil, fmt.Errorf("入力ファイルを開けません: %w", err)
+		}
+		defer file.Close()
+		input = file
+	}
+	if input == nil {
+		input = os.Stdin
+	}
+
+	return withDatabase(u.runtime, request.ConfigPath, func(cfg *config.Config, db *database.DatabaseManager) (*ImportResult, error) {
+		importer := &eventImporter{db: db, result: &ImportResult{}}
+
+		// 収集時と同じく、保存前にマスキングする（stage: collect / both）
+		if cfg.Redaction.AppliesAtCollect() {
+			redactor, err := redact.New(cfg.Redaction)
+			if err != nil {
+				return nil, fmt.Errorf("redaction設定が無効です: %w", err)
+			}
+			importer.redactor = redactor
+			importer.result.RedactionStats = make(redact.Stats)
+		}
+
+		if err := importer.readAll(input); err != nil {
+			return nil, err
+		}
+
+		result := importer.result
+		if result.ImportedEventCount > 0 && cfg.Linking.IsEnabled() {
+			if err := collector.NewEventCollector(cfg, db).LinkEvents(result.StartTime, result.EndTime); err != nil {
+				// リンク検出の失敗で取り込み結果を無効にはしない
+				appLogger.Warnf("イベントのリンク検出に失敗しました: %v", err)
+			}
+		}
+		return result, nil
+	})
+}
+
+// eventImporter は読み込んだイベントをまとめて保存します
+type eventImporter struct {
+	db       *database.DatabaseManager
+	redactor *redact.Redactor
+	result   *ImportResult
+	batch    []*config.Event
+}
+
+func (i *eventImporter) readAll(input io.Reader) error {
+	reader := bufio.NewReader(input)
+	for line := 1; ; line++ {
+		data, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return fmt.Errorf("入力の読み込みに失敗しました: %w", readErr)
+		}
+
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			event, err := decodeImportedEvent(data)
+			if err != nil {
+				// それまでに保存した分は残るが、同じファイルを取り込み直しても重複しない
+				return fmt.Errorf("%d 行目: %w", line, err)
+			}
+			i.batch = append(i.batch, event)
+			if len(i.batch) >= importBatchSize {
+				if err := i.flush(); err != nil {
+					return err
+				}
+			}
+		}
+
+		if readErr == io.EOF {
+			return i.flush()
+		}
+	}
+}
+
+func decodeImportedEvent(data []byte) (*config.Event, error) {
+	var event config.Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("イベントのJSONとして解釈できません: %w", err)
+	}
+	switch {
+	case event.ID == "":
+		return nil, fmt.Errorf("id がありません")
+	case event.Service == "" || event.Type == "":
+		return nil, fmt.Errorf("イベント %s に service / type がありません", event.ID)
+	case event.Timestamp.IsZero():
+		return nil, fmt.Errorf("イベント %s に timestamp がありません", event.ID)
+	}
+	// 関連イベントは保存後のリンク検出で作り直す
+	event.Related = nil
+	return &event, nil
+}
+
+func (i *eventImporter) flush() error {
+	if len(i.batch) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(i.batch))
+	for _, event := range i.batch {
+		ids = append(ids, event.ID)
+	}
+	existing, err := i.db.ExistingEventIDs(ids)
+	if err != nil {
+		return fmt.Errorf("既存イベントの確認に失敗しました: %w", err)
+	}
+
+	if i.redactor != nil {
+		for _, event := range i.batch {
+			i.redactor.RedactEvent(event, i.result.RedactionStats)
+		}
+	}
+
+	if err := i.db.InsertEvents(i.batch); err != nil {
+		return fmt.Errorf("イベントの保存に失敗しました: %w", err)
+	}
+
+	seen := make(map[string]bool, len(i.batch))
+	for _, event := range i.batch {
+		if !existing[event.ID] && !seen[event.ID] {
+			i.result.NewEventCount++
+		}
+		seen[event.ID] = true
+
+		if !event.Annotation.IsEmpty() {
+			if err := i.db.SaveAnnotation(event.ID, event.Annotation); err != nil {
+				return fmt.Errorf("注釈の保存に失敗しました: %w", err)
+			}
+			i.result.AnnotationCount++
+		}
+
+		if i.result.StartTime.IsZero() || event.Timestamp.Before(i.result.StartTime) {
+			i.result.StartTime = event.Timestamp
+		}
+		if event.Timestamp.After(i.result.EndTime) {
+			i.result.EndTime = event.Timestamp
+		}
+	}
+	i.result.ImportedEventCount += len(i.batch)
+
+	i.batch = i.batch[:0]
+	return nil
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/database"
+)
+
+func newImportTestRuntime(dbPath string) *appRuntime {
+	return &appRuntime{
+		loadConfig: func(path string) (*config.Config, error) {
+			return &config.Config{DatabasePath: dbPath}, nil
+		},
+		openDatabase: database.NewDatabaseManager,
+	}
+}
+
+func TestImportUsecaseRunRoundTripsNDJSONExportIdempotently(t *testing.T) {
+	dir := t.TempDir()
+	sourcePath := filepath.Join(dir, "source.db")
+	db, err := database.NewDatabaseManager(sourcePath)
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	timestamp := time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)
+	if err := db.InsertEvents([]*config.Event{
+		{ID: "meeting-1", Service: "google_calendar", Type: "event_attended", Title: "定例", Timestamp: timestamp,
+			Attachments: []config.EventAttachment{{FileID: "doc-1", Title: "メモ", TextFull: "議事録の本文"}}},
+		{ID: "pr-1", Service: "github", Type: "pull_request", Title: "PR", Timestamp: timestamp.Add(time.Hour), Metadata: `{"repository":"octo/app"}`},
+	}); err != nil {
+		t.Fatalf("failed to seed test database: %v", err)
+	}
+	if err := db.SaveAnnotation("pr-1", &config.EventAnnotation{Pinned: true, Note: "重要"}); err != nil {
+		t.Fatalf("failed to annotate event: %v", err)
+	}
+	db.Close()
+
+	exportPath := filepath.Join(dir, "events.ndjson")
+	if _, err := (&ExportUsecase{runtime: newImportTestRuntime(sourcePath), exportEvents: exportEvents}).Run(ExportRequest{
+		StartTime:  timestamp.Add(-time.Hour),
+		EndTime:    timestamp.Add(2 * time.Hour),
+		Format:     "ndjson",
+		OutputPath: exportPath,
+	}); err != nil {
+		t.Fatalf("export returned error: %v", err)
+	}
+
+	targetPath := filepath.Join(dir, "target.db")
+	usecase := &ImportUsecase{runtime: newImportTestRuntime(targetPath)}
+	result, err := usecase.Run(ImportRequest{InputPath: exportPath})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if result.ImportedEventCount != 2 || result.NewEventCount != 2 || result.AnnotationCount != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	result, err = usecase.Run(ImportRequest{InputPath: exportPath})
+	if err != nil {
+		t.Fatalf("second Run returned error: %v", err)
+	}
+	if result.ImportedEventCount != 2 || result.NewEventCount != 0 {
+		t.Fatalf("expected re-import to update existing events only, got %+v", result)
+	}
+
+	target, err := database.NewDatabaseManager(targetPath)
+	if err != nil {
+		t.Fatalf("failed to open target database: %v", err)
+	}
+	defer target.Close()
+	events, err := target.GetEvents(timestamp.Add(-time.Hour), timestamp.Add(2*time.Hour), nil)
+	if err != nil {
+		t.Fatalf("GetEvents returned error: %v", err)
+	}
+	if len(events) != 2 || len(events[0].Attachments) != 1 || events[0].Attachments[0].TextFull != "議事録の本文" {
+		t.Fatalf("expected events with attachments, got %+v", events)
+	}
+	if events[1].Annotation == nil || !events[1].Annotation.Pinned || events[1].Annotation.Note != "重要" {
+		t.Fatalf("expected annotation to be imported, got %+v", events[1].Annotation)
+	}
+}
+
+func TestImportUsecaseRunReportsInvalidLine(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "import.db")
+	usecase := &ImportUsecase{runtime: newImportTestRuntime(dbPath)}
+
+	input := strings.Join([]string{
+		`{"id":"a","service":"slack","type":"message","title":"ok","timestamp":"2026-03-10T10:00:00Z"}`,
+		``,
+		`{"id":"b","service":"slack","title":"no type","timestamp":"2026-03-10T10:00:00Z"}`,
+	}, "\n")
+	_, err := usecase.Run(ImportRequest{Input: strings.NewReader(input)})
+	if err == nil || !strings.Contains(err.Error(), "3 行目") {
+		t.Fatalf("expected error for line 3, got %v", err)
+	}
+
+	if _, err := usecase.Run(ImportRequest{InputPath: filepath.Join(t.TempDir(), "missing.ndjson")}); err == nil {
+		t.Fatalf("expected missing file to return error")
+	}
+}
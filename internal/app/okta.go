@@ -0,0 +1,61 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/iriam/worklogr/internal/auth"
+	"github.com/iriam/worklogr/internal/config"
+)
+
+// oktaServiceName はOktaの認証系コマンド上のサービス名です
+const oktaServiceName = "okta"
+
+// oktaExchanger はOktaのトークンを下流サービスのトークンに交換します
+type oktaExchanger interface {
+	ExchangeToken(ctx context.Context, audience string, scopes []string) (*auth.LoginResult, error)
+}
+
+// OktaExchangeOutcome はサービスごとのトークン交換結果です
+type OktaExchangeOutcome struct {
+	Service   string
+	ExpiresAt *time.Time
+	Error     error
+}
+
+// usesOkta はサービスのトークンをOktaのトークン交換で取得するかどうかを返します
+func usesOkta(cfg *config.Config, service string) bool {
+	serviceConfig := serviceConfigFor(cfg, service)
+	return serviceConfig != nil && serviceConfig.UseOkta
+}
+
+// oktaExchangeServices は use_okta が有効なサービスを返します
+func oktaExchangeServices(cfg *config.Config) []string {
+	var services []string
+	for _, definition := range appServiceDefinitions {
+		if usesOkta(cfg, definition.Name) && serviceConfigFor(cfg, definition.Name).Enabled {
+			services = append(services, definition.Name)
+		}
+	}
+	return services
+}
+
+// exchangeOktaToken はOktaのトークンをサービスのトークンに交換し、トークンストアに保存します
+func exchangeOktaToken(ctx context.Context, cfg *config.Config, exchanger oktaExchanger, store *auth.TokenStore, service string) (*auth.LoginResult, error) {
+	serviceConfig := serviceConfigFor(cfg, service)
+	if serviceConfig.OktaAudience == "" {
+		return nil, fmt.Errorf("%s.okta_audience が設定されていません", service)
+	}
+	token, err := exchanger.ExchangeToken(ctx, serviceConfig.OktaAudience, serviceConfig.OktaScopes)
+	if err != nil {
+		return nil, err
+	}
+	if store == nil {
+		return nil, fmt.Errorf("交換したトークンを保存するトークンストアがありません")
+	}
+	if err := token.Store(store, authManagerName(service)); err != nil {
+		return nil, fmt.Errorf("トークンの保存に失敗しました: %w", err)
+	}
+	return token, nil
+}
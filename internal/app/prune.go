@@ -0,0 +1,95 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/database"
+)
+
+type PruneRequest struct {
+	ConfigPath string
+	// DryRun は削除対象を数えるだけで、データベースは変更しません
+	DryRun bool
+}
+
+type PruneResult struct {
+	DatabasePath string
+	// Rules は継承を反映した保存期間の設定です（具体的なものから順に、最後が全体の既定値）
+	Rules []config.RetentionRule
+	Stats *database.PruneStats
+	// SizeBefore / SizeAfter は VACUUM 前後のデータベースファイルのサイズです（dry-run では SizeAfter は 0）
+	SizeBefore int64
+	SizeAfter  int64
+}
+
+type PruneUsecase struct {
+	runtime *appRuntime
+	now     func() time.Time
+}
+
+func NewPruneUsecase() *PruneUsecase {
+	return &PruneUsecase{runtime: newAppRuntime(), now: time.Now}
+}
+
+// Run は設定ファイルの retention に従って古いイベントと添付本文を削除し、VACUUM します
+func (u *PruneUsecase) Run(request PruneRequest) (*PruneResult, error) {
+	return withDatabase(u.runtime, request.ConfigPath, func(cfg *config.Config, db *database.DatabaseManager) (*PruneResult, error) {
+		if err := cfg.Retention.Validate(); err != nil {
+			return nil, fmt.Errorf("retention設定が無効です: %w", err)
+		}
+		if !cfg.Retention.IsConfigured() {
+			return nil, fmt.Errorf("保存期間が設定されていません（設定ファイルの retention を指定してください）")
+		}
+
+		result := &PruneResult{DatabasePath: cfg.DatabasePath, Rules: cfg.Retention.Resolved()}
+		result.SizeBefore = fileSize(cfg.DatabasePath)
+
+		stats, err := db.Prune(retentionRules(result.Rules, u.now()), request.DryRun)
+		if err != nil {
+			return nil, fmt.Errorf("古いデータの削除に失敗しました: %w", err)
+		}
+		result.Stats = stats
+		if request.DryRun {
+			return result, nil
+		}
+
+		// 削除しただけではファイルは小さくならない
+		if err := db.Vacuum(); err != nil {
+			return nil, fmt.Errorf("データベースの最適化に失敗しました: %w", err)
+		}
+		result.SizeAfter = fileSize(cfg.DatabasePath)
+		return result, nil
+	})
+}
+
+// retentionRules は日数の設定を基準時刻からの削除期限に変換します
+func retentionRules(rules []config.RetentionRule, now time.Time) []database.RetentionRule {
+	cutoff := func(days int) time.Time {
+		if days <= 0 {
+			return time.Time{}
+		}
+		return now.AddDate(0, 0, -days)
+	}
+
+	converted := make([]database.RetentionRule, 0, len(rules))
+	for _, rule := range rules {
+		converted = append(converted, database.RetentionRule{
+			Service:              rule.Service,
+			Type:                 rule.Type,
+			EventsBefore:         cutoff(rule.EventDays),
+			AttachmentTextBefore: cutoff(rule.AttachmentTextDays),
+		})
+	}
+	return converted
+}
+
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/database"
+)
+
+func TestPruneUsecaseRunAppliesRetentionConfig(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "worklogr.db")
+	now := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	db, err := database.NewDatabaseManager(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	if err := db.InsertEvents([]*config.Event{
+		{ID: "slack-old", Service: "slack", Type: "message", Title: "古い投稿", Timestamp: now.AddDate(0, 0, -100)},
+		{ID: "slack-new", Service: "slack", Type: "message", Title: "新しい投稿", Timestamp: now.AddDate(0, 0, -10)},
+		{ID: "github-old", Service: "github", Type: "pull_request", Title: "PR", Timestamp: now.AddDate(0, 0, -100)},
+	}); err != nil {
+		t.Fatalf("failed to seed test database: %v", err)
+	}
+	db.Close()
+
+	cfg := &config.Config{
+		DatabasePath: dbPath,
+		Retention: config.RetentionOptions{
+			EventDays: 365,
+			Rules:     []config.RetentionRule{{Service: "slack", EventDays: 90}},
+		},
+	}
+	usecase := &PruneUsecase{
+		runtime: &appRuntime{
+			loadConfig:   func(string) (*config.Config, error) { return cfg, nil },
+			openDatabase: database.NewDatabaseManager,
+		},
+		now: func() time.Time { return now },
+	}
+
+	dryRun, err := usecase.Run(PruneRequest{DryRun: true})
+	if err != nil {
+		t.Fatalf("dry-run returned error: %v", err)
+	}
+	if dryRun.Stats.EventsDeleted != 1 || dryRun.SizeAfter != 0 {
+		t.Fatalf("unexpected dry-run result: %+v", dryRun)
+	}
+
+	result, err := usecase.Run(PruneRequest{})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if result.Stats.EventsByService["slack"] != 1 || result.SizeAfter == 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	again, err := usecase.Run(PruneRequest{})
+	if err != nil {
+		t.Fatalf("second Run returned error: %v", err)
+	}
+	if again.Stats.EventsDeleted != 0 {
+		t.Fatalf("expected nothing left to prune, got %+v", again.Stats)
+	}
+
+	cfg.Retention = config.RetentionOptions{}
+	if _, err := usecase.Run(PruneRequest{}); err == nil || !strings.Contains(err.Error(), "retention") {
+		t.Fatalf("expected missing retention to be reported, got %v", err)
+	}
+}
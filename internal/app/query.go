@@ -0,0 +1,142 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/database"
+	"github.com/iriam/worklogr/internal/redact"
+)
+
+// EventQuery はイベントの絞り込み条件です（export / query 共通）
+type EventQuery struct {
+	Services []string
+	// Users / Teams を指定すると、そのメンバーのイベントだけを対象にします（チームレポート用）
+	Users []string
+	Teams []string
+	// Types / ExcludeTypes はイベント種別で絞り込みます
+	Types        []string
+	ExcludeTypes []string
+	// Match はタイトル・本文の部分一致（大文字小文字を区別しない）です
+	Match string
+	// Repos は GitHub のリポジトリ（owner/name）、Channels は Slack のチャンネル名またはIDです
+	Repos    []string
+	Channels []string
+	// Where は metadata.key=value 形式の条件です（すべて満たすものを対象にします）
+	Where []string
+	// 非表示にしたイベントは通常対象外です。IncludeHidden で含め、
+	// PinnedOnly でピン留めしたイベントだけを対象にします
+	IncludeHidden bool
+	PinnedOnly    bool
+	// 収集元で削除された（再収集で見つからなくなった）イベントは通常対象外です。
+	// IncludeDeleted で含め、DeletedOnly で削除されたイベントだけを対象にします
+	IncludeDeleted bool
+	DeletedOnly    bool
+	// Tags は annotate で付けたタグのいずれかを持つイベントに絞り込みます
+	Tags []string
+}
+
+// buildEventFilter は絞り込み条件をデータベースのフィルタに変換します
+func buildEventFilter(cfg *config.Config, query EventQuery) (database.EventFilter, error) {
+	members, err := resolveExportMembers(cfg, query.Users, query.Teams)
+	if err != nil {
+		return database.EventFilter{}, err
+	}
+
+	filter := database.EventFilter{
+		Services:       query.Services,
+		Members:        members,
+		Repositories:   query.Repos,
+		Types:          query.Types,
+		ExcludeTypes:   query.ExcludeTypes,
+		Match:          strings.TrimSpace(query.Match),
+		IncludeHidden:  query.IncludeHidden,
+		PinnedOnly:     query.PinnedOnly,
+		IncludeDeleted: query.IncludeDeleted,
+		DeletedOnly:    query.DeletedOnly,
+		Tags:           query.Tags,
+	}
+	for _, channel := range query.Channels {
+		filter.Channels = append(filter.Channels, strings.TrimPrefix(strings.TrimSpace(channel), "#"))
+	}
+	for _, expr := range query.Where {
+		condition, err := database.ParseWhere(expr)
+		if err != nil {
+			return database.EventFilter{}, fmt.Errorf("--where の指定が無効です: %w", err)
+		}
+		filter.Metadata = append(filter.Metadata, condition)
+	}
+	return filter, nil
+}
+
+// resolveExportMembers は --user / --team をメンバー名の一覧に変換します。
+// どちらも未指定の場合は nil（メンバーで絞り込まない）を返します。
+func resolveExportMembers(cfg *config.Config, users, teams []string) ([]string, error) {
+	if len(users) == 0 && len(teams) == 0 {
+		return nil, nil
+	}
+	if !cfg.HasUsers() {
+		return nil, fmt.Errorf("--user / --team を使うには設定ファイルに users を定義してください")
+	}
+	return cfg.SelectUsers(users, teams)
+}
+
+type QueryRequest struct {
+	ConfigPath string
+	StartTime  time.Time
+	EndTime    time.Time
+	EventQuery
+	// Limit は返す件数の上限です（0 は無制限）
+	Limit int
+}
+
+type QueryResult struct {
+	Events []*config.Event
+	// MatchedEventCount は Limit を適用する前の一致件数です
+	MatchedEventCount int
+}
+
+type QueryUsecase struct {
+	runtime *appRuntime
+}
+
+func NewQueryUsecase() *QueryUsecase {
+	return &QueryUsecase{runtime: newAppRuntime()}
+}
+
+func (u *QueryUsecase) Run(request QueryRequest) (*QueryResult, error) {
+	if request.Limit < 0 {
+		return nil, fmt.Errorf("--limit には0以上の値を指定してください")
+	}
+
+	return withDatabase(u.runtime, request.ConfigPath, func(cfg *config.Config, db *database.DatabaseManager) (*QueryResult, error) {
+		filter, err := buildEventFilter(cfg, request.EventQuery)
+		if err != nil {
+			return nil, err
+		}
+
+		events, err := db.QueryEvents(request.StartTime, request.EndTime, filter)
+		if err != nil {
+			return nil, fmt.Errorf("イベントの取得に失敗しました: %w", err)
+		}
+
+		result := &QueryResult{MatchedEventCount: len(events)}
+		if request.Limit > 0 && len(events) > request.Limit {
+			events = events[:request.Limit]
+		}
+
+		// 画面表示もエクスポートと同じくマスキング設定に従う
+		if cfg.Redaction.AppliesAtExport() {
+			redactor, err := redact.New(cfg.Redaction)
+			if err != nil {
+				return nil, fmt.Errorf("redaction設定が無効です: %w", err)
+			}
+			redactor.RedactEvents(events)
+		}
+
+		result.Events = events
+		return result, nil
+	})
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/database"
+)
+
+func newQueryTestUsecase(t *testing.T, events ...*config.Event) *QueryUsecase {
+	t.Helper()
+
+	dbPath := filepath.Join(t.TempDir(), "query.db")
+	db, err := database.NewDatabaseManager(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	if err := db.InsertEvents(events); err != nil {
+		t.Fatalf("failed to seed test database: %v", err)
+	}
+	db.Close()
+
+	return &QueryUsecase{
+		runtime: &appRuntime{
+			loadConfig: func(path string) (*config.Config, error) {
+				return &config.Config{DatabasePath: dbPath}, nil
+			},
+			openDatabase: database.NewDatabaseManager,
+		},
+	}
+}
+
+func TestQueryUsecaseRunTranslatesRepoChannelAndWhereFilters(t *testing.T) {
+	timestamp := time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)
+	usecase := newQueryTestUsecase(t,
+		&config.Event{ID: "pr-1", Service: "github", Type: "pull_request_created", Title: "PR", Timestamp: timestamp, Metadata: `{"repository":"octo/app","state":"open"}`},
+		&config.Event{ID: "pr-2", Service: "github", Type: "pull_request_created", Title: "PR", Timestamp: timestamp.Add(time.Minute), Metadata: `{"repository":"octo/app","state":"merged"}`},
+		&config.Event{ID: "msg-1", Service: "slack", Type: "message", Title: "msg", Timestamp: timestamp.Add(2 * time.Minute), Metadata: `{"channel_name":"dev","channel_id":"C1"}`},
+	)
+
+	request := QueryRequest{
+		StartTime:  timestamp.Add(-time.Hour),
+		EndTime:    timestamp.Add(time.Hour),
+		EventQuery: EventQuery{Repos: []string{"octo/app"}, Where: []string{"metadata.state=merged"}},
+	}
+	result, err := usecase.Run(request)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if result.MatchedEventCount != 1 || result.Events[0].ID != "pr-2" {
+		t.Fatalf("expected only pr-2, got %+v", result.Events)
+	}
+
+	request.EventQuery = EventQuery{Channels: []string{"#dev"}}
+	result, err = usecase.Run(request)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if result.MatchedEventCount != 1 || result.Events[0].ID != "msg-1" {
+		t.Fatalf("expected only msg-1, got %+v", result.Events)
+	}
+
+	request.EventQuery = EventQuery{Where: []string{"state=merged"}}
+	if _, err := usecase.Run(request); err == nil {
+		t.Fatalf("expected invalid --where to return error")
+	}
+}
+
+func TestQueryUsecaseRunAppliesLimitAfterCounting(t *testing.T) {
+	timestamp := time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)
+	var events []*config.Event
+	for _, id := range []string{"a", "b", "c"} {
+		events = append(events, &config.Event{ID: id, Service: "slack", Type: "message", Title: id, Timestamp: timestamp})
+		timestamp = timestamp.Add(time.Minute)
+	}
+	usecase := newQueryTestUsecase(t, events...)
+
+	result, err := usecase.Run(QueryRequest{
+		StartTime: timestamp.Add(-time.Hour),
+		EndTime:   timestamp.Add(time.Hour),
+		Limit:     2,
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if result.MatchedEventCount != 3 || len(result.Events) != 2 || result.Events[0].ID != "a" {
+		t.Fatalf("unexpected limited result: count=%d events=%d", result.MatchedEventCount, len(result.Events))
+	}
+}
@@ -5,8 +5,11 @@ import (
 
 	"github.com/iriam/worklogr/internal/config"
 	"github.com/iriam/worklogr/internal/database"
+	"github.com/iriam/worklogr/internal/utils"
 )
 
+var appLogger = utils.NewLogger().WithService("app")
+
 type appRuntime struct {
 	loadConfig   func(string) (*config.Config, error)
 	openDatabase func(string) (*database.DatabaseManager, error)
@@ -14,7 +17,7 @@ type appRuntime struct {
 
 func newAppRuntime() *appRuntime {
 	return &appRuntime{
-		loadConfig:   config.LoadConfig,
+		loadConfig:   loadConfigWithStoredTokens,
 		openDatabase: database.NewDatabaseManager,
 	}
 }
@@ -28,6 +31,19 @@ func (r *appRuntime) loadAppConfig(configPath string) (*config.Config, error) {
 	return cfg, nil
 }
 
+// openAppDatabase はデータベースを開き、設定に応じて暗号化の鍵を適用します
+func (r *appRuntime) openAppDatabase(cfg *config.Config) (*database.DatabaseManager, error) {
+	db, err := r.openDatabase(cfg.DatabasePath)
+	if err != nil {
+		return nil, fmt.Errorf("データベースの初期化に失敗しました: %w", err)
+	}
+	if err := db.UseEncryption(cfg.Encryption.Passphrase()); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("データベースの暗号化設定を適用できません: %w", err)
+	}
+	return db, nil
+}
+
 func withDatabase[T any](runtime *appRuntime, configPath string, fn func(*config.Config, *database.DatabaseManager) (T, error)) (T, error) {
 	var zero T
 
@@ -36,9 +52,9 @@ func withDatabase[T any](runtime *appRuntime, configPath string, fn func(*config
 		return zero, err
 	}
 
-	db, err := runtime.openDatabase(cfg.DatabasePath)
+	db, err := runtime.openAppDatabase(cfg)
 	if err != nil {
-		return zero, fmt.Errorf("データベースの初期化に失敗しました: %w", err)
+		return zero, err
 	}
 	defer db.Close()
 
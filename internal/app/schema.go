@@ -0,0 +1,79 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/iriam/worklogr/internal/exporter"
+)
+
+type SchemaValidateRequest struct {
+	Path string
+	// Format は検証する形式です。空のときは拡張子（.ndjson / .jsonl は ndjson）と内容から判定します
+	Format string
+}
+
+type SchemaValidateResult struct {
+	Path          string
+	Format        string
+	SchemaVersion string
+}
+
+// SchemaUsecase は export 形式の JSON Schema の表示と、出力ファイルの検証を行います
+type SchemaUsecase struct {
+	jsonExporter *exporter.JSONExporter
+}
+
+func NewSchemaUsecase() *SchemaUsecase {
+	return &SchemaUsecase{jsonExporter: exporter.NewJSONExporter()}
+}
+
+// Show は指定した形式の JSON Schema を返します
+func (u *SchemaUsecase) Show(format string) ([]byte, error) {
+	schema, err := exporter.Schema(strings.ToLower(format))
+	if err != nil {
+		return nil, fmt.Errorf("%s 形式のスキーマはありません。対応形式: %s", format, strings.Join(exporter.SchemaFormats, ", "))
+	}
+	return schema, nil
+}
+
+// Validate は export の出力ファイルをスキーマで検証します
+func (u *SchemaUsecase) Validate(request SchemaValidateRequest) (*SchemaValidateResult, error) {
+	format := strings.ToLower(request.Format)
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(request.Path)) {
+		case ".ndjson", ".jsonl":
+			format = "ndjson"
+		default:
+			format = "json"
+		}
+	}
+
+	result := &SchemaValidateResult{Path: request.Path, Format: format, SchemaVersion: exporter.SchemaVersion}
+	switch format {
+	case "ndjson":
+		file, err := os.Open(request.Path)
+		if err != nil {
+			return nil, fmt.Errorf("ファイルを開けませんでした: %w", err)
+		}
+		defer file.Close()
+		if err := exporter.ValidateNDJSON(file); err != nil {
+			return nil, fmt.Errorf("%s はスキーマに適合しません: %w", request.Path, err)
+		}
+	case "json", "json-ai":
+		// json と json-ai は中身（summary の有無）で判定されます
+		data, err := os.ReadFile(request.Path)
+		if err != nil {
+			return nil, fmt.Errorf("ファイルを読み込めませんでした: %w", err)
+		}
+		result.Format = exporter.DetectJSONFormat(data)
+		if err := u.jsonExporter.ValidateJSON(request.Path); err != nil {
+			return nil, fmt.Errorf("%s はスキーマに適合しません: %w", request.Path, err)
+		}
+	default:
+		return nil, fmt.Errorf("%s 形式のスキーマはありません。対応形式: %s", request.Format, strings.Join(exporter.SchemaFormats, ", "))
+	}
+	return result, nil
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/exporter"
+)
+
+func TestSchemaUsecaseValidateDetectsFormat(t *testing.T) {
+	dir := t.TempDir()
+	events := []*config.Event{
+		{ID: "slack-1", Service: "slack", Type: "message", Title: "朝会", Timestamp: time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC), Metadata: `{"schema_version":1}`},
+	}
+	jsonExporter := exporter.NewJSONExporter()
+
+	write := func(name string, fn func(*os.File) error) string {
+		path := filepath.Join(dir, name)
+		file, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+		defer file.Close()
+		if err := fn(file); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	aiPath := write("report.json", func(file *os.File) error {
+		_, err := jsonExporter.WriteForAI(file, exporter.NewSliceSource(events))
+		return err
+	})
+	ndjsonPath := write("events.jsonl", func(file *os.File) error {
+		_, err := jsonExporter.WriteNDJSON(file, exporter.NewSliceSource(events))
+		return err
+	})
+
+	usecase := NewSchemaUsecase()
+	for path, want := range map[string]string{aiPath: "json-ai", ndjsonPath: "ndjson"} {
+		result, err := usecase.Validate(SchemaValidateRequest{Path: path})
+		if err != nil {
+			t.Fatalf("Validate(%s) returned error: %v", filepath.Base(path), err)
+		}
+		if result.Format != want || result.SchemaVersion != exporter.SchemaVersion {
+			t.Fatalf("unexpected result for %s: %+v", filepath.Base(path), result)
+		}
+	}
+
+	brokenPath := filepath.Join(dir, "broken.ndjson")
+	if err := os.WriteFile(brokenPath, []byte(`{"id":"slack-1","service":"slack"}`+"\n"), 0644); err != nil {
+		t.Fatalf("failed to write broken ndjson: %v", err)
+	}
+	if _, err := usecase.Validate(SchemaValidateRequest{Path: brokenPath}); err == nil || !strings.Contains(err.Error(), `line 1 /: missing required field "timestamp"`) {
+		t.Fatalf("expected missing field error, got %v", err)
+	}
+
+	if _, err := usecase.Show("csv"); err == nil {
+		t.Fatalf("expected error for a format without a schema")
+	}
+}
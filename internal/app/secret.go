@@ -0,0 +1,142 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/secrets"
+)
+
+type SecretSetRequest struct {
+	ConfigPath string
+	Name       string
+	Value      string
+}
+
+type SecretMigrateRequest struct {
+	ConfigPath string
+	DryRun     bool
+}
+
+// SecretMigration は設定ファイルからシークレットストアへ移した1項目です
+type SecretMigration struct {
+	Path string
+	Ref  string
+}
+
+type SecretMigrateResult struct {
+	ConfigPath string
+	Migrated   []SecretMigration
+}
+
+// SecretUsecase はシークレットストアの操作と設定ファイルからの移行を行います。
+// 設定は secret:// 参照を解決せずに読み込みます。
+type SecretUsecase struct {
+	resolveConfigPath func(string) (string, error)
+	loadConfig        func(string) (*config.Config, error)
+	saveConfig        func(*config.Config, string) error
+	openStore         func(secrets.Options) (secrets.Store, error)
+}
+
+func NewSecretUsecase() *SecretUsecase {
+	return &SecretUsecase{
+		resolveConfigPath: config.ResolveConfigPath,
+		loadConfig:        config.LoadConfigUnresolved,
+		saveConfig:        config.SaveConfig,
+		openStore:         secrets.Open,
+	}
+}
+
+func (u *SecretUsecase) store(configPath string) (*config.Config, secrets.Store, error) {
+	cfg, err := u.loadConfig(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("設定ファイルの読み込みに失敗しました: %w", err)
+	}
+	store, err := u.openStore(cfg.Secrets.StoreOptions())
+	if err != nil {
+		return nil, nil, fmt.Errorf("シークレットストアを開けませんでした: %w", err)
+	}
+	return cfg, store, nil
+}
+
+func (u *SecretUsecase) Set(request SecretSetRequest) error {
+	if request.Name == "" {
+		return fmt.Errorf("シークレット名を指定してください")
+	}
+	if request.Value == "" {
+		return fmt.Errorf("シークレットの値が空です")
+	}
+	_, store, err := u.store(request.ConfigPath)
+	if err != nil {
+		return err
+	}
+	if err := store.Set(request.Name, request.Value); err != nil {
+		return fmt.Errorf("シークレットの保存に失敗しました: %w", err)
+	}
+	return nil
+}
+
+func (u *SecretUsecase) List(configPath string) ([]string, error) {
+	_, store, err := u.store(configPath)
+	if err != nil {
+		return nil, err
+	}
+	names, err := store.List()
+	if err != nil {
+		return nil, fmt.Errorf("シークレット一覧の取得に失敗しました: %w", err)
+	}
+	return names, nil
+}
+
+func (u *SecretUsecase) Delete(configPath, name string) error {
+	_, store, err := u.store(configPath)
+	if err != nil {
+		return err
+	}
+	if err := store.Delete(name); err != nil {
+		return fmt.Errorf("シークレットの削除に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// Migrate は設定ファイルに平文で書かれた認証情報をストアへ移し、secret:// 参照に書き換えます
+func (u *SecretUsecase) Migrate(request SecretMigrateRequest) (*SecretMigrateResult, error) {
+	configPath, err := u.resolveConfigPath(request.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := u.loadConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("設定ファイルの読み込みに失敗しました: %w", err)
+	}
+
+	result := &SecretMigrateResult{ConfigPath: configPath}
+	for _, field := range cfg.SecretFields() {
+		if *field.Value == "" || secrets.IsRef(*field.Value) {
+			continue
+		}
+		result.Migrated = append(result.Migrated, SecretMigration{Path: field.Path, Ref: secrets.Ref(field.Name)})
+	}
+	if request.DryRun || len(result.Migrated) == 0 {
+		return result, nil
+	}
+
+	store, err := u.openStore(cfg.Secrets.StoreOptions())
+	if err != nil {
+		return nil, fmt.Errorf("シークレットストアを開けませんでした: %w", err)
+	}
+	for _, field := range cfg.SecretFields() {
+		if *field.Value == "" || secrets.IsRef(*field.Value) {
+			continue
+		}
+		if err := store.Set(field.Name, *field.Value); err != nil {
+			return nil, fmt.Errorf("%s の保存に失敗しました: %w", field.Path, err)
+		}
+		cfg.SetSecretRef(field.Path, secrets.Ref(field.Name))
+	}
+
+	if err := u.saveConfig(cfg, configPath); err != nil {
+		return nil, fmt.Errorf("設定ファイルの保存に失敗しました: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/secrets"
+)
+
+type memorySecretStore map[string]string
+
+func (s memorySecretStore) Get(name string) (string, error) { return s[name], nil }
+func (s memorySecretStore) Set(name, value string) error    { s[name] = value; return nil }
+func (s memorySecretStore) Delete(name string) error        { delete(s, name); return nil }
+func (s memorySecretStore) List() ([]string, error)         { return nil, nil }
+
+func TestSecretUsecaseMigrateMovesPlaintextCredentials(t *testing.T) {
+	cfg := &config.Config{
+		Slack:  config.ServiceConfig{AccessToken: "xoxp-token", ClientSecret: "secret://already"},
+		GitHub: config.ServiceConfig{ClientSecret: "gh-secret"},
+	}
+	store := memorySecretStore{}
+	var saved *config.Config
+	var savedPath string
+
+	usecase := &SecretUsecase{
+		resolveConfigPath: func(path string) (string, error) { return "/tmp/config.yaml", nil },
+		loadConfig:        func(path string) (*config.Config, error) { return cfg, nil },
+		saveConfig: func(c *config.Config, path string) error {
+			saved, savedPath = c, path
+			return nil
+		},
+		openStore: func(secrets.Options) (secrets.Store, error) { return store, nil },
+	}
+
+	result, err := usecase.Migrate(SecretMigrateRequest{})
+	if err != nil {
+		t.Fatalf("Migrate returned error: %v", err)
+	}
+	if len(result.Migrated) != 2 {
+		t.Fatalf("expected 2 migrated fields, got %+v", result.Migrated)
+	}
+	if result.Migrated[0].Path != "slack.access_token" || result.Migrated[0].Ref != "secret://slack" {
+		t.Fatalf("unexpected first migration: %+v", result.Migrated[0])
+	}
+	if store["slack"] != "xoxp-token" || store["github_client_secret"] != "gh-secret" {
+		t.Fatalf("unexpected store contents: %v", store)
+	}
+	if _, ok := store["already"]; ok {
+		t.Fatalf("existing reference should not be migrated")
+	}
+	if saved != cfg || savedPath != "/tmp/config.yaml" {
+		t.Fatalf("expected config to be saved to resolved path, got %q", savedPath)
+	}
+}
+
+func TestSecretUsecaseMigrateDryRunDoesNotWrite(t *testing.T) {
+	usecase := &SecretUsecase{
+		resolveConfigPath: func(path string) (string, error) { return path, nil },
+		loadConfig: func(path string) (*config.Config, error) {
+			return &config.Config{LLM: config.LLMConfig{APIKey: "sk-test"}}, nil
+		},
+		saveConfig: func(*config.Config, string) error {
+			t.Fatalf("saveConfig should not be called on dry run")
+			return nil
+		},
+		openStore: func(secrets.Options) (secrets.Store, error) {
+			t.Fatalf("openStore should not be called on dry run")
+			return nil, nil
+		},
+	}
+
+	result, err := usecase.Migrate(SecretMigrateRequest{ConfigPath: "config.yaml", DryRun: true})
+	if err != nil {
+		t.Fatalf("Migrate returned error: %v", err)
+	}
+	if len(result.Migrated) != 1 || result.Migrated[0].Ref != "secret://llm_api_key" {
+		t.Fatalf("unexpected dry run result: %+v", result.Migrated)
+	}
+}
@@ -0,0 +1,149 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/database"
+	"github.com/iriam/worklogr/internal/exporter"
+	"github.com/iriam/worklogr/internal/llm"
+	"github.com/iriam/worklogr/internal/redact"
+	"github.com/iriam/worklogr/internal/summary"
+)
+
+type SummarizeRequest struct {
+	ConfigPath     string
+	StartTime      time.Time
+	EndTime        time.Time
+	Services       []string
+	PromptTemplate string
+	OutputPath     string
+	MaxInputTokens int
+	Redact         bool
+}
+
+type SummarizeResult struct {
+	MatchedEventCount int
+	OutputPath        string
+	Chunks            int
+	Requests          int
+	RedactionStats    redact.Stats
+}
+
+type SummarizeUsecase struct {
+	runtime     *appRuntime
+	newProvider func(config.LLMConfig) (llm.Provider, error)
+	now         func() time.Time
+}
+
+func NewSummarizeUsecase() *SummarizeUsecase {
+	return &SummarizeUsecase{
+		runtime:     newAppRuntime(),
+		newProvider: llm.NewProvider,
+		now:         time.Now,
+	}
+}
+
+func (u *SummarizeUsecase) Run(ctx context.Context, request SummarizeRequest) (*SummarizeResult, error) {
+	return withDatabase(u.runtime, request.ConfigPath, func(cfg *config.Config, db *database.DatabaseManager) (*SummarizeResult, error) {
+		provider, err := u.newProvider(cfg.LLM)
+		if err != nil {
+			return nil, fmt.Errorf("LLMプロバイダーの初期化に失敗しました: %w", err)
+		}
+
+		summarizer := summary.NewSummarizer(provider, cfg.LLM.EffectiveMaxInputTokens())
+		if request.MaxInputTokens > 0 {
+			summarizer.MaxInputTokens = request.MaxInputTokens
+		}
+		if request.PromptTemplate != "" {
+			tmpl, err := summary.LoadPromptTemplate(request.PromptTemplate)
+			if err != nil {
+				return nil, fmt.Errorf("プロンプトテンプレートの読み込みに失敗しました: %w", err)
+			}
+			summarizer.PromptTemplate = tmpl
+		}
+
+		events, err := db.GetEvents(request.StartTime, request.EndTime, request.Services)
+		if err != nil {
+			return nil, fmt.Errorf("イベントの取得に失敗しました: %w", err)
+		}
+
+		result := &SummarizeResult{MatchedEventCount: len(events)}
+		if len(events) == 0 {
+			return result, nil
+		}
+
+		// 外部のLLMへ送信するため、export時と同じマスキングを適用する
+		if request.Redact || cfg.Redaction.AppliesAtExport() {
+			redactor, err := redact.New(cfg.Redaction)
+			if err != nil {
+				return nil, fmt.Errorf("redaction設定が無効です: %w", err)
+			}
+			result.RedactionStats = redactor.RedactEvents(events)
+		}
+
+		data := exporter.NewJSONExporter().BuildAIExportData(events)
+		summarized, err := summarizer.Summarize(ctx, data, request.StartTime, request.EndTime)
+		if err != nil {
+			return nil, fmt.Errorf("要約の生成に失敗しました: %w", err)
+		}
+		result.Chunks = summarized.Chunks
+		result.Requests = summarized.Requests
+
+		outputPath := request.OutputPath
+		if outputPath == "" {
+			outputPath = fmt.Sprintf("worklogr_summary_%s.md", u.now().Format("20060102_150405"))
+		}
+		if err := writeSummaryMarkdown(outputPath, request.StartTime, request.EndTime, provider.Name(), summarized.Markdown, events); err != nil {
+			return nil, err
+		}
+		result.OutputPath = outputPath
+
+		return result, nil
+	})
+}
+
+func writeSummaryMarkdown(outputPath string, start, end time.Time, providerName, body string, events []*config.Event) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("出力ディレクトリの作成に失敗しました: %w", err)
+	}
+
+	content := fmt.Sprintf("# 作業サマリー (%s 〜 %s)\n\n%s\n%s\n---\n_generated by worklogr summarize (%s)_\n",
+		start.Format("2006-01-02"),
+		end.Format("2006-01-02"),
+		body,
+		pinnedEventsMarkdown(events, start.Location()),
+		providerName,
+	)
+	if err := os.WriteFile(outputPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("要約ファイルの書き込みに失敗しました: %w", err)
+	}
+	return nil
+}
+
+// pinnedEventsMarkdown はピン留めしたイベントとメモを要約の末尾に載せる節を作ります（無ければ空）
+func pinnedEventsMarkdown(events []*config.Event, location *time.Location) string {
+	var builder strings.Builder
+	for _, event := range events {
+		if event.Annotation == nil || !event.Annotation.Pinned {
+			continue
+		}
+		if builder.Len() == 0 {
+			builder.WriteString("\n## ピン留めしたイベント\n\n")
+		}
+		fmt.Fprintf(&builder, "- %s [%s] %s", event.Timestamp.In(location).Format("2006-01-02 15:04"), event.Service, strings.ReplaceAll(event.Title, "\n", " "))
+		if permalink := EventPermalink(event); permalink != "" {
+			fmt.Fprintf(&builder, " (%s)", permalink)
+		}
+		builder.WriteString("\n")
+		if event.Annotation.Note != "" {
+			fmt.Fprintf(&builder, "  - メモ: %s\n", event.Annotation.Note)
+		}
+	}
+	return builder.String()
+}
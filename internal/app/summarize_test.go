@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/database"
+	"github.com/iriam/worklogr/internal/llm"
+)
+
+func TestSummarizeUsecaseRunWritesMarkdown(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "summarize.db")
+	db, err := database.NewDatabaseManager(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+
+	timestamp := time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)
+	if err := db.InsertEvent(&config.Event{
+		ID:        "event-1",
+		Service:   "slack",
+		Type:      "message",
+		Title:     "title",
+		Content:   "release prep",
+		Timestamp: timestamp,
+	}); err != nil {
+		t.Fatalf("failed to seed test database: %v", err)
+	}
+	if err := db.SaveAnnotation("event-1", &config.EventAnnotation{Pinned: true, Note: "リリース判定"}); err != nil {
+		t.Fatalf("failed to annotate event: %v", err)
+	}
+
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"- release prep"}}]}`))
+	}))
+	defer server.Close()
+
+	outputPath := filepath.Join(t.TempDir(), "out", "summary.md")
+	usecase := &SummarizeUsecase{
+		runtime: &appRuntime{
+			loadConfig: func(path string) (*config.Config, error) {
+				return &config.Config{
+					DatabasePath: dbPath,
+					LLM:          config.LLMConfig{Provider: "openai", BaseURL: server.URL, Model: "stub"},
+				}, nil
+			},
+			openDatabase: func(path string) (*database.DatabaseManager, error) {
+				return db, nil
+			},
+		},
+		newProvider: llm.NewProvider,
+		now:         time.Now,
+	}
+
+	result, err := usecase.Run(context.Background(), SummarizeRequest{
+		StartTime:  timestamp.Add(-time.Hour),
+		EndTime:    timestamp.Add(time.Hour),
+		OutputPath: outputPath,
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	if result.MatchedEventCount != 1 || result.Requests != 1 || result.OutputPath != outputPath {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if !strings.Contains(received, "release prep") || !strings.Contains(received, "リリース判定") {
+		t.Fatalf("expected request to include event content and note, got %s", received)
+	}
+
+	markdown, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read summary: %v", err)
+	}
+	if !strings.HasPrefix(string(markdown), "# 作業サマリー (2026-03-10") || !strings.Contains(string(markdown), "- release prep") ||
+		!strings.Contains(string(markdown), "## ピン留めしたイベント") || !strings.Contains(string(markdown), "  - メモ: リリース判定") {
+		t.Fatalf("unexpected markdown:\n%s", markdown)
+	}
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iriam/worklogr/internal/analysis"
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/database"
+	"github.com/iriam/worklogr/internal/exporter"
+)
+
+type TimesheetRequest struct {
+	ConfigPath string
+	StartTime  time.Time
+	EndTime    time.Time
+	Services   []string
+	Format     string
+	OutputPath string
+	MaxGap     time.Duration
+}
+
+type TimesheetResult struct {
+	Entries    []analysis.TimesheetEntry
+	TotalHours float64
+}
+
+type TimesheetUsecase struct {
+	runtime     *appRuntime
+	writeOutput func([]analysis.TimesheetEntry, TimesheetRequest) error
+}
+
+func NewTimesheetUsecase() *TimesheetUsecase {
+	return &TimesheetUsecase{
+		runtime:     newAppRuntime(),
+		writeOutput: writeTimesheet,
+	}
+}
+
+func (u *TimesheetUsecase) Run(request TimesheetRequest) (*TimesheetResult, error) {
+	return withDatabase(u.runtime, request.ConfigPath, func(cfg *config.Config, db *database.DatabaseManager) (*TimesheetResult, error) {
+		events, err := db.GetEvents(request.StartTime, request.EndTime, request.Services)
+		if err != nil {
+			return nil, fmt.Errorf("イベントの取得に失敗しました: %w", err)
+		}
+
+		options := analysis.DefaultSessionOptions()
+		if timezoneManager, err := cfg.GetTimezoneManager(); err == nil {
+			options.Location = timezoneManager.GetLocation()
+		}
+		if request.MaxGap > 0 {
+			options.MaxGap = request.MaxGap
+		}
+
+		sessions := analysis.BuildSessions(events, options)
+		entries := analysis.BuildTimesheet(sessions, options.Location)
+
+		result := &TimesheetResult{Entries: entries}
+		for _, entry := range entries {
+			result.TotalHours += entry.Hours
+		}
+
+		if len(entries) == 0 {
+			return result, nil
+		}
+
+		if err := u.writeOutput(entries, request); err != nil {
+			return nil, err
+		}
+
+		return result, nil
+	})
+}
+
+func writeTimesheet(entries []analysis.TimesheetEntry, request TimesheetRequest) error {
+	timesheetExporter := exporter.NewTimesheetExporter()
+
+	switch strings.ToLower(request.Format) {
+	case "csv", "":
+		if err := timesheetExporter.ExportToCSV(entries, request.OutputPath); err != nil {
+			return fmt.Errorf("タイムシートのCSVエクスポートに失敗しました: %w", err)
+		}
+	case "json":
+		if err := timesheetExporter.ExportToJSON(entries, request.StartTime, request.EndTime, request.OutputPath); err != nil {
+			return fmt.Errorf("タイムシートのJSONエクスポートに失敗しました: %w", err)
+		}
+	default:
+		return fmt.Errorf("サポートされていない形式です: %s。対応形式: csv, json", request.Format)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/iriam/worklogr/internal/analysis"
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/database"
+)
+
+func TestTimesheetUsecaseRunBuildsEntries(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "timesheet.db")
+	db, err := database.NewDatabaseManager(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+
+	base := time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)
+	for id, offset := range map[string]time.Duration{"commit-1": 0, "commit-2": 30 * time.Minute} {
+		if err := db.InsertEvent(&config.Event{
+			ID:        id,
+			Service:   "github",
+			Type:      "commit",
+			Title:     "commit",
+			Timestamp: base.Add(offset),
+			Metadata:  `{"repository":"acme/api"}`,
+		}); err != nil {
+			t.Fatalf("failed to seed test database: %v", err)
+		}
+	}
+
+	var written []analysis.TimesheetEntry
+	var writtenFormat string
+	usecase := &TimesheetUsecase{
+		runtime: &appRuntime{
+			loadConfig: func(path string) (*config.Config, error) {
+				return &config.Config{DatabasePath: dbPath, Timezone: "UTC"}, nil
+			},
+			openDatabase: func(path string) (*database.DatabaseManager, error) {
+				return db, nil
+			},
+		},
+		writeOutput: func(entries []analysis.TimesheetEntry, request TimesheetRequest) error {
+			written = entries
+			writtenFormat = request.Format
+			return nil
+		},
+	}
+
+	result, err := usecase.Run(TimesheetRequest{
+		StartTime: base.Add(-time.Hour),
+		EndTime:   base.Add(time.Hour),
+		Format:    "json",
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	if len(result.Entries) != 1 || len(written) != 1 || writtenFormat != "json" {
+		t.Fatalf("unexpected result: %+v written=%+v format=%q", result, written, writtenFormat)
+	}
+	if result.TotalHours != 0.75 {
+		t.Fatalf("expected 0.75h (30m + 15m lead-in), got %v", result.TotalHours)
+	}
+}
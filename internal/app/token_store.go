@@ -0,0 +1,79 @@
+package app
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/iriam/worklogr/internal/auth"
+	"github.com/iriam/worklogr/internal/config"
+)
+
+// openTokenStore は設定に従って `auth login` のトークンストアを開きます
+func openTokenStore(cfg *config.Config) (*auth.TokenStore, error) {
+	path, err := cfg.TokenStore.EffectivePath()
+	if err != nil {
+		return nil, err
+	}
+	envName := cfg.TokenStore.EffectivePassphraseEnv()
+	passphrase := os.Getenv(envName)
+	if passphrase == "" {
+		return nil, fmt.Errorf("トークンストアのパスフレーズが設定されていません（環境変数 %s を設定してください）", envName)
+	}
+	return auth.NewTokenStore(path, passphrase)
+}
+
+// loadConfigWithStoredTokens は設定を読み込み、access_token が未設定のサービスに
+// `auth login` で保存したトークンを補完します
+func loadConfigWithStoredTokens(configPath string) (*config.Config, error) {
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	applyStoredTokens(cfg, openTokenStore)
+	return cfg, nil
+}
+
+func applyStoredTokens(cfg *config.Config, open func(*config.Config) (*auth.TokenStore, error)) {
+	targets := map[string]*config.ServiceConfig{
+		"slack":  &cfg.Slack,
+		"github": &cfg.GitHub,
+	}
+	// Google Calendarは通常gcloud認証を使うため、Oktaのトークン交換を使う場合だけ補完する
+	if cfg.GoogleCal.UseOkta {
+		targets["calendar"] = &cfg.GoogleCal
+	}
+	needed := false
+	for _, service := range targets {
+		if service.AccessToken == "" {
+			needed = true
+		}
+	}
+	if !needed {
+		return
+	}
+
+	// トークンファイルが無い場合はパスフレーズ未設定でも警告しない
+	if path, err := cfg.TokenStore.EffectivePath(); err != nil {
+		return
+	} else if _, err := os.Stat(path); err != nil {
+		return
+	}
+
+	store, err := open(cfg)
+	if err != nil {
+		appLogger.Warnf("保存済みトークンを読み込めませんでした: %v", err)
+		return
+	}
+	for name, service := range targets {
+		if service.AccessToken != "" {
+			continue
+		}
+		token, err := store.GetToken(name)
+		if err != nil {
+			continue
+		}
+		service.AccessToken = token.AccessToken
+		service.RefreshToken = token.RefreshToken
+		service.TokenExpiresAt = token.ExpiresAt
+	}
+}
@@ -24,3 +24,12 @@ var appServiceDefinitions = []serviceDefinition{
 	{Name: "github", DisplayName: "GitHub"},
 	{Name: "google_calendar", DisplayName: "Google Calendar"},
 }
+
+func serviceDisplayName(name string) string {
+	for _, definition := range appServiceDefinitions {
+		if definition.Name == name {
+			return definition.DisplayName
+		}
+	}
+	return name
+}
@@ -26,6 +26,7 @@ type CalendarAuthManager struct {
 	clientID     string          // OAuthクライアントID
 	clientSecret string          // OAuthクライアントシークレット
 	useGCloud    bool            // gcloud認証を使用するかどうか
+	credentials  GoogleCredentialSource // gcloud認証の代わりに使用する認証情報ファイル
 }
 
 // NewCalendarAuthManager は新しいGoogle Calendar認証マネージャーを作成します
@@ -50,6 +51,14 @@ func NewCalendarAuthManagerWithGCloud(config *AuthConfig, store *TokenStore) *Ca
 	}
 }
 
+// NewCalendarAuthManagerWithCredentials はサービスアカウント鍵または外部アカウントの認証情報ファイルを
+// 使用するGoogle Calendar認証マネージャーを作成します（gcloud認証を行えないヘッドレス環境向け）
+func NewCalendarAuthManagerWithCredentials(config *AuthConfig, store *TokenStore, source GoogleCredentialSource) *CalendarAuthManager {
+	manager := NewCalendarAuthManagerWithGCloud(config, store)
+	manager.credentials = source
+	return manager
+}
+
 // ValidateToken はGoogle Calendarアクセストークンを検証します
 func (c *CalendarAuthManager) ValidateToken(ctx context.Context) (*AuthStatus, error) {
 	// gcloud認証を使用する場合
@@ -86,6 +95,7 @@ func (c *CalendarAuthManager) ValidateToken(ctx context.Context) (*AuthStatus, e
 
 	// 内部状態を更新
 	c.UpdateStatus(status.IsValid, status.ErrorMessage)
+	c.SetGrantedScopes(status.GrantedScopes)
 	
 	// 有効な場合はトークンを保存
 	if status.IsValid && c.store != nil {
@@ -100,8 +110,14 @@ func (c *CalendarAuthManager) ValidateToken(ctx context.Context) (*AuthStatus, e
 
 // validateGCloudToken はgcloud認証を使用してトークンを検証します
 func (c *CalendarAuthManager) validateGCloudToken(ctx context.Context) (*AuthStatus, error) {
-	// gcloud認証が利用可能かチェック
-	if !c.IsGCloudAuthenticated() {
+	// 認証情報ファイルは読み込みエラーをそのまま表示する
+	if c.credentials.CredentialsFile != "" {
+		if _, err := c.credentials.Credentials(ctx, calendar.CalendarReadonlyScope); err != nil {
+			c.UpdateStatus(false, err.Error())
+			return c.GetAuthStatus(), nil
+		}
+	} else if !c.IsGCloudAuthenticated() {
+		// gcloud認証が利用可能かチェック
 		c.UpdateStatus(false, "gcloud認証が利用できません")
 		return c.GetAuthStatus(), nil
 	}
@@ -123,11 +139,28 @@ func (c *CalendarAuthManager) validateGCloudToken(ctx context.Context) (*AuthSta
 
 	// 認証状態を更新
 	c.UpdateStatus(true, "")
+
+	// 付与スコープはtokeninfoから取得する（取得できない場合は不明のまま）
+	if info, err := c.validator.ValidateGoogleToken(ctx, token.AccessToken); err == nil && info.IsValid {
+		c.SetGrantedScopes(info.GrantedScopes)
+	}
 	return c.GetAuthStatus(), nil
 }
 
 // RefreshToken refreshes the Google Calendar access token
 func (c *CalendarAuthManager) RefreshToken(ctx context.Context) error {
+	// gcloud認証・認証情報ファイルはトークンを取得し直すことで更新する
+	if c.useGCloud {
+		status, err := c.validateGCloudToken(ctx)
+		if err != nil {
+			return err
+		}
+		if !status.IsValid {
+			return fmt.Errorf("%s", status.ErrorMessage)
+		}
+		return nil
+	}
+
 	if c.config.RefreshToken == "" {
 		return fmt.Errorf("リフレッシュトークンが設定されていません")
 	}
@@ -329,10 +362,10 @@ func (c *CalendarAuthManager) GetGoogleCredentials(scopes []string) (*oauth2.Tok
 	// gcloud認証から認証情報を取得を試行
 	ctx := context.Background()
 	
-	// まずApplication Default Credentials (ADC)を試行
-	creds, err := google.FindDefaultCredentials(ctx, scopes...)
+	// 認証情報ファイルが未設定の場合はApplication Default Credentials (ADC)を使用
+	creds, err := c.credentials.Credentials(ctx, scopes...)
 	if err != nil {
-		return nil, fmt.Errorf("デフォルト認証情報が見つかりません。'gcloud auth application-default login'を実行してください: %w", err)
+		return nil, err
 	}
 
 	// トークンソースを取得
@@ -356,6 +389,12 @@ func (c *CalendarAuthManager) GetCalendarToken() (*oauth2.Token, error) {
 
 // IsGCloudAuthenticated はgcloud認証が利用可能かチェックします
 func (c *CalendarAuthManager) IsGCloudAuthenticated() bool {
+	// 認証情報ファイルを使用する場合はgcloudの設定ディレクトリは不要
+	if c.credentials.CredentialsFile != "" {
+		_, err := c.credentials.Credentials(context.Background(), calendar.CalendarReadonlyScope)
+		return err == nil
+	}
+
 	// gcloud設定ディレクトリが存在するかチェック
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -410,6 +449,10 @@ func (c *CalendarAuthManager) SetupGCloudAuth() string {
 
 これらの手順を完了すると、worklogrは自動的にgcloud認証情報を使用します。
 
+CIや定期実行などgcloudにログインできない環境では、config.yaml の google_calendar_options で
+サービスアカウント鍵（ドメイン全体の委任を使う場合は impersonate_user も指定）または
+外部アカウント（Workload Identity連携）の認証情報ファイルを credentials_file に指定してください。
+
 注意: "insufficient authentication scopes"エラーが発生した場合は、
 正しいスコープで手順3を再実行してください。`
 }
@@ -418,13 +461,13 @@ func (c *CalendarAuthManager) SetupGCloudAuth() string {
 func (c *CalendarAuthManager) CreateCalendarService() (*calendar.Service, error) {
 	ctx := context.Background()
 	
-	// gcloudから認証情報を取得
-	creds, err := google.FindDefaultCredentials(ctx, 
+	// gcloud（または認証情報ファイル）から認証情報を取得
+	creds, err := c.credentials.Credentials(ctx,
 		calendar.CalendarReadonlyScope,
 		calendar.CalendarEventsReadonlyScope,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("デフォルト認証情報が見つかりません: %w", err)
+		return nil, err
 	}
 
 	// カレンダーサービスを作成
@@ -441,6 +484,11 @@ func (c *CalendarAuthManager) SetUseGCloud(useGCloud bool) {
 	c.useGCloud = useGCloud
 }
 
+// CredentialSource はgcloud認証の代わりに使用する認証情報ファイルの設定を返します
+func (c *CalendarAuthManager) CredentialSource() GoogleCredentialSource {
+	return c.credentials
+}
+
 // IsUsingGCloud はgcloud認証を使用しているかどうかを返します
 func (c *CalendarAuthManager) IsUsingGCloud() bool {
 	return c.useGCloud
@@ -44,10 +44,11 @@ func (g *GitHubAuthManager) ValidateToken(ctx context.Context) (*AuthStatus, err
 
 	// Update internal status
 	g.UpdateStatus(status.IsValid, status.ErrorMessage)
+	g.SetGrantedScopes(status.GrantedScopes)
 	
 	// Store token if valid
 	if status.IsValid && g.store != nil {
-		if err := g.store.StoreToken("github", g.config.AccessToken, g.config.RefreshToken, g.config.TokenExpiresAt, []string{"repo", "user"}); err != nil {
+		if err := g.store.StoreToken("github", g.config.AccessToken, g.config.RefreshToken, g.config.TokenExpiresAt, g.storedScopes(status.GrantedScopes, []string{"repo", "user"})); err != nil {
 			// Log error but don't fail validation
 			fmt.Printf("Warning: Failed to store GitHub token: %v\n", err)
 		}
@@ -71,9 +72,34 @@ func (g *GitHubAuthManager) RefreshToken(ctx context.Context) error {
 func (g *GitHubAuthManager) GetTokenInfo() *TokenInfo {
 	info := g.BaseAuthManager.GetTokenInfo()
 	info.Scopes = []string{"repo", "user", "read:org"}
+
+	// Prefer the scopes actually granted when the token is in the store
+	if g.store != nil {
+		if storedToken, err := g.store.GetToken("github"); err == nil {
+			if len(storedToken.Scopes) > 0 {
+				info.Scopes = storedToken.Scopes
+			}
+			info.LastRefresh = storedToken.LastRefresh
+		}
+	}
+
 	return info
 }
 
+// storedScopes returns the scopes to store: those granted by the API, then the
+// scopes already in the store, then fallback
+func (g *GitHubAuthManager) storedScopes(granted, fallback []string) []string {
+	if granted != nil {
+		return granted
+	}
+	if g.store != nil {
+		if storedToken, err := g.store.GetToken("github"); err == nil && len(storedToken.Scopes) > 0 {
+			return storedToken.Scopes
+		}
+	}
+	return fallback
+}
+
 // IsHealthy checks if the GitHub authentication is healthy
 func (g *GitHubAuthManager) IsHealthy(ctx context.Context) bool {
 	status, err := g.ValidateToken(ctx)
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"golang.org/x/oauth2/google"
+)
+
+// Google認証情報ファイルの種類
+const (
+	GoogleCredentialADC             = "gcloud"
+	GoogleCredentialServiceAccount  = "service_account"
+	GoogleCredentialExternalAccount = "external_account"
+	GoogleCredentialAuthorizedUser  = "authorized_user"
+)
+
+// GoogleCredentialSource はGoogle APIの認証情報の取得元です。
+// CredentialsFile が空の場合はgcloud認証（ADC）を使用します。
+type GoogleCredentialSource struct {
+	// CredentialsFile はサービスアカウント鍵、または外部アカウント（Workload Identity連携）のJSONファイルです
+	CredentialsFile string
+	// Subject はドメイン全体の委任で代理するユーザーのメールアドレスです（サービスアカウント鍵のみ）
+	Subject string
+}
+
+// Kind は認証情報の種類を返します
+func (s GoogleCredentialSource) Kind() (string, error) {
+	if s.CredentialsFile == "" {
+		return GoogleCredentialADC, nil
+	}
+	data, err := os.ReadFile(s.CredentialsFile)
+	if err != nil {
+		return "", fmt.Errorf("認証情報ファイルの読み込みに失敗しました: %w", err)
+	}
+	return credentialsFileType(data)
+}
+
+// Credentials は指定スコープのGoogle認証情報を返します
+func (s GoogleCredentialSource) Credentials(ctx context.Context, scopes ...string) (*google.Credentials, error) {
+	if s.CredentialsFile == "" {
+		if s.Subject != "" {
+			return nil, fmt.Errorf("impersonate_user を使用するには credentials_file にサービスアカウント鍵を指定してください")
+		}
+		creds, err := google.FindDefaultCredentials(ctx, scopes...)
+		if err != nil {
+			return nil, fmt.Errorf("デフォルト認証情報が見つかりません。'gcloud auth application-default login'を実行してください: %w", err)
+		}
+		return creds, nil
+	}
+
+	data, err := os.ReadFile(s.CredentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("認証情報ファイルの読み込みに失敗しました: %w", err)
+	}
+	kind, err := credentialsFileType(data)
+	if err != nil {
+		return nil, err
+	}
+	if s.Subject != "" && kind != GoogleCredentialServiceAccount {
+		return nil, fmt.Errorf("impersonate_user（ドメイン全体の委任）はサービスアカウント鍵でのみ使用できます（%s の種類: %s）", s.CredentialsFile, kind)
+	}
+
+	creds, err := google.CredentialsFromJSONWithParams(ctx, data, google.CredentialsParams{
+		Scopes:  scopes,
+		Subject: s.Subject,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("認証情報ファイル %s の解析に失敗しました: %w", s.CredentialsFile, err)
+	}
+	return creds, nil
+}
+
+// credentialsFileType は認証情報JSONの type フィールドを返します
+func credentialsFileType(data []byte) (string, error) {
+	var file struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return "", fmt.Errorf("認証情報ファイルがJSONではありません: %w", err)
+	}
+	switch file.Type {
+	case GoogleCredentialServiceAccount, GoogleCredentialExternalAccount, GoogleCredentialAuthorizedUser:
+		return file.Type, nil
+	case "":
+		return "", fmt.Errorf("認証情報ファイルに type がありません")
+	default:
+		return "", fmt.Errorf("未対応の認証情報の種類です: %s（対応: service_account, external_account, authorized_user）", file.Type)
+	}
+}
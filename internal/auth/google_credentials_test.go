@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeServiceAccountKey(t *testing.T, tokenURI string) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	data, _ := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "worklogr-test",
+		"private_key_id": "key-1",
+		"private_key":    string(keyPEM),
+		"client_email":   "collector@worklogr-test.iam.gserviceaccount.com",
+		"client_id":      "123",
+		"token_uri":      tokenURI,
+	})
+	path := filepath.Join(t.TempDir(), "service-account.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	return path
+}
+
+func TestGoogleCredentialSourceImpersonatesUserWithServiceAccount(t *testing.T) {
+	var subject string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		parts := strings.Split(r.Form.Get("assertion"), ".")
+		if len(parts) == 3 {
+			payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+			var claims struct {
+				Sub string `json:"sub"`
+			}
+			json.Unmarshal(payload, &claims)
+			subject = claims.Sub
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "sa-token", "token_type": "Bearer", "expires_in": 3600})
+	}))
+	defer server.Close()
+
+	source := GoogleCredentialSource{CredentialsFile: writeServiceAccountKey(t, server.URL), Subject: "member@example.com"}
+	if kind, err := source.Kind(); err != nil || kind != GoogleCredentialServiceAccount {
+		t.Fatalf("unexpected kind %q (%v)", kind, err)
+	}
+
+	creds, err := source.Credentials(context.Background(), "https://www.googleapis.com/auth/calendar.readonly")
+	if err != nil {
+		t.Fatalf("Credentials returned error: %v", err)
+	}
+	token, err := creds.TokenSource.Token()
+	if err != nil {
+		t.Fatalf("Token returned error: %v", err)
+	}
+	if token.AccessToken != "sa-token" || subject != "member@example.com" {
+		t.Fatalf("expected delegated token for member@example.com, got token %q subject %q", token.AccessToken, subject)
+	}
+}
+
+func TestGoogleCredentialSourceRejectsImpersonationWithoutServiceAccount(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "external.json")
+	data := `{"type":"external_account","audience":"//iam.googleapis.com/projects/1/locations/global/workloadIdentityPools/ci/providers/github","subject_token_type":"urn:ietf:params:oauth:token-type:jwt","token_url":"https://sts.googleapis.com/v1/token","credential_source":{"file":"/tmp/token"}}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("failed to write credentials: %v", err)
+	}
+
+	source := GoogleCredentialSource{CredentialsFile: path}
+	if kind, err := source.Kind(); err != nil || kind != GoogleCredentialExternalAccount {
+		t.Fatalf("unexpected kind %q (%v)", kind, err)
+	}
+	if _, err := source.Credentials(context.Background(), "https://www.googleapis.com/auth/calendar.readonly"); err != nil {
+		t.Fatalf("expected external account credentials to load, got %v", err)
+	}
+
+	source.Subject = "member@example.com"
+	if _, err := source.Credentials(context.Background(), "https://www.googleapis.com/auth/calendar.readonly"); err == nil {
+		t.Fatalf("expected impersonation with external account to be rejected")
+	}
+}
@@ -0,0 +1,385 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// OAuthProvider はブラウザログインに必要なエンドポイントと既定スコープです
+type OAuthProvider struct {
+	Name          string
+	AuthURL       string
+	TokenURL      string
+	DeviceAuthURL string // デバイスフロー非対応の場合は空
+	Scopes        []string
+	// ScopeParam は認可URLでスコープを渡すパラメータ名です（Slackのユーザートークンは user_scope）
+	ScopeParam string
+	// ScopeSeparator はスコープの区切り文字です
+	ScopeSeparator string
+}
+
+// SlackOAuthProvider はSlackのユーザートークン用プロバイダーを返します
+func SlackOAuthProvider() OAuthProvider {
+	return OAuthProvider{
+		Name:           "slack",
+		AuthURL:        "https://slack.com/oauth/v2/authorize",
+		TokenURL:       "https://slack.com/api/oauth.v2.access",
+		Scopes:         []string{"search:read", "users:read"},
+		ScopeParam:     "user_scope",
+		ScopeSeparator: ",",
+	}
+}
+
+// GitHubOAuthProvider はGitHub OAuth App用プロバイダーを返します
+func GitHubOAuthProvider() OAuthProvider {
+	return OAuthProvider{
+		Name:           "github",
+		AuthURL:        "https://github.com/login/oauth/authorize",
+		TokenURL:       "https://github.com/login/oauth/access_token",
+		DeviceAuthURL:  "https://github.com/login/device/code",
+		Scopes:         []string{"repo", "read:org", "user"},
+		ScopeParam:     "scope",
+		ScopeSeparator: " ",
+	}
+}
+
+// OAuthProviderFor はサービス名に対応するプロバイダーを返します
+func OAuthProviderFor(service string) (OAuthProvider, error) {
+	switch service {
+	case "slack":
+		return SlackOAuthProvider(), nil
+	case "github":
+		return GitHubOAuthProvider(), nil
+	default:
+		return OAuthProvider{}, fmt.Errorf("サービス %s はブラウザログインに対応していません（対応: slack, github）", service)
+	}
+}
+
+// LoginOptions はログインフローのオプションです
+type LoginOptions struct {
+	ClientID     string
+	ClientSecret string
+	// Scopes が空の場合はプロバイダーの既定スコープを使用します
+	Scopes []string
+	// ListenAddr はループバックリダイレクトを受け付けるアドレスです（デフォルト: 127.0.0.1:0）
+	ListenAddr string
+	// CallbackPath はリダイレクトを受け付けるパスです（デフォルト: /callback）
+	CallbackPath string
+	// OpenURL は認可URLをユーザーに提示します（ブラウザ起動・表示など）
+	OpenURL func(authURL string) error
+	// PromptDevice はデバイスフローのユーザーコードを提示します
+	PromptDevice func(verificationURI, userCode string)
+	HTTPClient   *http.Client
+}
+
+func (o LoginOptions) scopes(provider OAuthProvider) []string {
+	if len(o.Scopes) > 0 {
+		return o.Scopes
+	}
+	return provider.Scopes
+}
+
+// LoginResult はログインで取得したトークンです
+type LoginResult struct {
+	AccessToken  string
+	RefreshToken string
+	// IDToken はOIDCプロバイダーが返すIDトークンです（OAuthのみのプロバイダーでは空）
+	IDToken   string
+	TokenType string
+	ExpiresAt *time.Time
+	Scopes    []string
+}
+
+// Store は取得したトークンをトークンストアに保存します
+func (r *LoginResult) Store(store *TokenStore, service string) error {
+	return store.StoreToken(service, r.AccessToken, r.RefreshToken, r.ExpiresAt, r.Scopes)
+}
+
+// LoopbackLogin はローカルのリダイレクト受付サーバーとPKCEを使用して認可コードフローを実行します
+func LoopbackLogin(ctx context.Context, provider OAuthProvider, options LoginOptions) (*LoginResult, error) {
+	if options.ClientID == "" {
+		return nil, fmt.Errorf("%s.client_id が設定されていません", provider.Name)
+	}
+
+	listenAddr := options.ListenAddr
+	if listenAddr == "" {
+		listenAddr = "127.0.0.1:0"
+	}
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("リダイレクト受付サーバーの起動に失敗しました: %w", err)
+	}
+	defer listener.Close()
+
+	callbackPath := options.CallbackPath
+	if callbackPath == "" {
+		callbackPath = "/callback"
+	}
+	redirectURI := fmt.Sprintf("http://%s%s", listener.Addr().String(), callbackPath)
+	state, err := randomState()
+	if err != nil {
+		return nil, err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	type callbackResult struct {
+		code string
+		err  error
+	}
+	results := make(chan callbackResult, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(callbackPath, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		var result callbackResult
+		switch {
+		case query.Get("state") != state:
+			result.err = fmt.Errorf("stateが一致しません")
+		case query.Get("error") != "":
+			result.err = fmt.Errorf("認可が拒否されました: %s %s", query.Get("error"), query.Get("error_description"))
+		case query.Get("code") == "":
+			result.err = fmt.Errorf("認可コードが含まれていません")
+		default:
+			result.code = query.Get("code")
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if result.err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "worklogr: ログインに失敗しました: %v\n", result.err)
+		} else {
+			fmt.Fprintln(w, "worklogr: ログインが完了しました。このウィンドウを閉じてください。")
+		}
+		select {
+		case results <- result:
+		default:
+		}
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go server.Serve(listener)
+	defer server.Close()
+
+	authURL := buildAuthURL(provider, options, redirectURI, state, verifier)
+	if options.OpenURL != nil {
+		if err := options.OpenURL(authURL); err != nil {
+			return nil, err
+		}
+	}
+
+	var result callbackResult
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("ログインがタイムアウトまたはキャンセルされました: %w", ctx.Err())
+	case result = <-results:
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {result.code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {options.ClientID},
+		"code_verifier": {verifier},
+	}
+	if options.ClientSecret != "" {
+		form.Set("client_secret", options.ClientSecret)
+	}
+	return exchangeToken(ctx, options.HTTPClient, provider, form, options.scopes(provider))
+}
+
+func buildAuthURL(provider OAuthProvider, options LoginOptions, redirectURI, state, verifier string) string {
+	query := url.Values{
+		"client_id":             {options.ClientID},
+		"redirect_uri":          {redirectURI},
+		"response_type":         {"code"},
+		"state":                 {state},
+		"code_challenge":        {oauth2.S256ChallengeFromVerifier(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+	scopeParam := provider.ScopeParam
+	if scopeParam == "" {
+		scopeParam = "scope"
+	}
+	query.Set(scopeParam, strings.Join(options.scopes(provider), provider.ScopeSeparator))
+
+	separator := "?"
+	if strings.Contains(provider.AuthURL, "?") {
+		separator = "&"
+	}
+	return provider.AuthURL + separator + query.Encode()
+}
+
+func randomState() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+		return "", fmt.Errorf("stateの生成に失敗しました: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// tokenResponse はトークンエンドポイントのレスポンスです。
+// Slackのユーザートークンは authed_user 以下に返されるため両方を解釈します。
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	IDToken          string `json:"id_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Scope            string `json:"scope"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+	AuthedUser       *struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		TokenType    string `json:"token_type"`
+		ExpiresIn    int64  `json:"expires_in"`
+		Scope        string `json:"scope"`
+	} `json:"authed_user"`
+}
+
+// OAuthError はトークンエンドポイントが返したOAuthエラー（RFC 6749 5.2）です
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	return fmt.Sprintf("トークンの取得に失敗しました: %s %s", e.Code, e.Description)
+}
+
+func exchangeToken(ctx context.Context, client *http.Client, provider OAuthProvider, form url.Values, requested []string) (*LoginResult, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, provider.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("トークンリクエストの作成に失敗しました: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("トークンの取得に失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("トークンレスポンスの読み込みに失敗しました: %w", err)
+	}
+	var token tokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("トークンレスポンスの解析に失敗しました (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if token.Error != "" {
+		return nil, &OAuthError{Code: token.Error, Description: token.ErrorDescription}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("トークンの取得に失敗しました: HTTP %d", resp.StatusCode)
+	}
+
+	if token.AccessToken == "" && token.AuthedUser != nil {
+		token.AccessToken = token.AuthedUser.AccessToken
+		token.RefreshToken = token.AuthedUser.RefreshToken
+		token.TokenType = token.AuthedUser.TokenType
+		token.ExpiresIn = token.AuthedUser.ExpiresIn
+		token.Scope = token.AuthedUser.Scope
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("トークンレスポンスにアクセストークンが含まれていません")
+	}
+
+	result := &LoginResult{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		IDToken:      token.IDToken,
+		TokenType:    token.TokenType,
+		Scopes:       parseScopes(token.Scope),
+	}
+	if len(result.Scopes) == 0 {
+		result.Scopes = requested
+	}
+	if token.ExpiresIn > 0 {
+		expiresAt := time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+		result.ExpiresAt = &expiresAt
+	}
+	return result, nil
+}
+
+// parseScopes はカンマまたは空白区切りのスコープ文字列を分割します
+func parseScopes(scope string) []string {
+	return strings.FieldsFunc(scope, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
+
+// DeviceLogin はデバイス認可フロー（RFC 8628）でトークンを取得します
+func DeviceLogin(ctx context.Context, provider OAuthProvider, options LoginOptions) (*LoginResult, error) {
+	if provider.DeviceAuthURL == "" {
+		return nil, fmt.Errorf("サービス %s はデバイスフローに対応していません", provider.Name)
+	}
+	if options.ClientID == "" {
+		return nil, fmt.Errorf("%s.client_id が設定されていません", provider.Name)
+	}
+	if options.HTTPClient != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, options.HTTPClient)
+	}
+
+	scopes := options.scopes(provider)
+	oauthConfig := &oauth2.Config{
+		ClientID: options.ClientID,
+		Scopes:   scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:       provider.AuthURL,
+			TokenURL:      provider.TokenURL,
+			DeviceAuthURL: provider.DeviceAuthURL,
+			AuthStyle:     oauth2.AuthStyleInParams,
+		},
+	}
+
+	deviceAuth, err := oauthConfig.DeviceAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("デバイスコードの取得に失敗しました: %w", err)
+	}
+	if options.PromptDevice != nil {
+		verificationURI := deviceAuth.VerificationURIComplete
+		if verificationURI == "" {
+			verificationURI = deviceAuth.VerificationURI
+		}
+		options.PromptDevice(verificationURI, deviceAuth.UserCode)
+	}
+
+	token, err := oauthConfig.DeviceAccessToken(ctx, deviceAuth)
+	if err != nil {
+		return nil, fmt.Errorf("デバイスフローでのトークン取得に失敗しました: %w", err)
+	}
+
+	result := &LoginResult{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		TokenType:    token.TokenType,
+		Scopes:       scopes,
+	}
+	if granted, ok := token.Extra("scope").(string); ok && granted != "" {
+		result.Scopes = parseScopes(granted)
+	}
+	if !token.Expiry.IsZero() {
+		expiresAt := token.Expiry
+		result.ExpiresAt = &expiresAt
+	}
+	return result, nil
+}
@@ -0,0 +1,342 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// fakeAuthServer は認可・トークン・デバイスコードの各エンドポイントを持つテスト用サーバーです
+type fakeAuthServer struct {
+	*httptest.Server
+	mu         sync.Mutex
+	challenges map[string]string // code -> code_challenge
+	polls      int
+	slackStyle bool
+}
+
+func newFakeAuthServer(t *testing.T) *fakeAuthServer {
+	t.Helper()
+	fake := &fakeAuthServer{challenges: map[string]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+			http.Error(w, "missing pkce", http.StatusBadRequest)
+			return
+		}
+		fake.mu.Lock()
+		fake.challenges["code-123"] = query.Get("code_challenge")
+		fake.mu.Unlock()
+
+		redirect, _ := url.Parse(query.Get("redirect_uri"))
+		values := redirect.Query()
+		values.Set("code", "code-123")
+		values.Set("state", query.Get("state"))
+		redirect.RawQuery = values.Encode()
+		http.Redirect(w, r, redirect.String(), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Form.Get("grant_type") == "urn:ietf:params:oauth:grant-type:device_code" {
+			fake.mu.Lock()
+			fake.polls++
+			polls := fake.polls
+			fake.mu.Unlock()
+			if polls < 2 {
+				json.NewEncoder(w).Encode(map[string]string{"error": "authorization_pending"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"access_token": "device-token", "token_type": "bearer", "scope": "repo,read:org"})
+			return
+		}
+
+		fake.mu.Lock()
+		challenge := fake.challenges[r.Form.Get("code")]
+		fake.mu.Unlock()
+		if challenge == "" || oauth2.S256ChallengeFromVerifier(r.Form.Get("code_verifier")) != challenge {
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		if fake.slackStyle {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"ok":          true,
+				"authed_user": map[string]interface{}{"access_token": "xoxp-user", "token_type": "user", "scope": "search:read,users:read"},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "gho-token", "token_type": "bearer", "scope": "repo", "expires_in": 3600})
+	})
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"device_code":      "device-abc",
+			"user_code":        "ABCD-1234",
+			"verification_uri": "https://example.com/device",
+			"expires_in":       60,
+			"interval":         1,
+		})
+	})
+	fake.Server = httptest.NewServer(mux)
+	t.Cleanup(fake.Close)
+	return fake
+}
+
+func (f *fakeAuthServer) provider(name string) OAuthProvider {
+	return OAuthProvider{
+		Name:           name,
+		AuthURL:        f.URL + "/authorize",
+		TokenURL:       f.URL + "/token",
+		DeviceAuthURL:  f.URL + "/device",
+		Scopes:         []string{"repo"},
+		ScopeParam:     "scope",
+		ScopeSeparator: " ",
+	}
+}
+
+// followAuthURL はブラウザの代わりに認可URLを開き、ループバックサーバーへのリダイレクトを辿ります
+func followAuthURL(t *testing.T) func(string) error {
+	return func(authURL string) error {
+		go func() {
+			resp, err := http.Get(authURL)
+			if err != nil {
+				t.Errorf("failed to follow auth URL: %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+		return nil
+	}
+}
+
+func TestLoopbackLoginExchangesCodeWithPKCE(t *testing.T) {
+	fake := newFakeAuthServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := LoopbackLogin(ctx, fake.provider("github"), LoginOptions{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		OpenURL:      followAuthURL(t),
+	})
+	if err != nil {
+		t.Fatalf("LoopbackLogin returned error: %v", err)
+	}
+	if result.AccessToken != "gho-token" || result.ExpiresAt == nil {
+		t.Fatalf("unexpected login result: %+v", result)
+	}
+	if len(result.Scopes) != 1 || result.Scopes[0] != "repo" {
+		t.Fatalf("unexpected scopes: %v", result.Scopes)
+	}
+}
+
+func TestLoopbackLoginReadsSlackAuthedUserToken(t *testing.T) {
+	fake := newFakeAuthServer(t)
+	fake.slackStyle = true
+	provider := fake.provider("slack")
+	provider.ScopeParam = "user_scope"
+	provider.ScopeSeparator = ","
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var authURL string
+	open := followAuthURL(t)
+	result, err := LoopbackLogin(ctx, provider, LoginOptions{
+		ClientID: "client",
+		Scopes:   []string{"search:read", "users:read"},
+		OpenURL: func(u string) error {
+			authURL = u
+			return open(u)
+		},
+	})
+	if err != nil {
+		t.Fatalf("LoopbackLogin returned error: %v", err)
+	}
+	if !strings.Contains(authURL, "user_scope=search%3Aread%2Cusers%3Aread") {
+		t.Fatalf("expected user_scope in auth URL: %s", authURL)
+	}
+	if result.AccessToken != "xoxp-user" || len(result.Scopes) != 2 {
+		t.Fatalf("unexpected login result: %+v", result)
+	}
+}
+
+func TestLoopbackLoginRejectsStateMismatch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := LoopbackLogin(ctx, GitHubOAuthProvider(), LoginOptions{
+		ClientID: "client",
+		OpenURL: func(authURL string) error {
+			parsed, _ := url.Parse(authURL)
+			redirect := parsed.Query().Get("redirect_uri")
+			go func() {
+				resp, err := http.Get(redirect + "?code=x&state=forged")
+				if err == nil {
+					resp.Body.Close()
+				}
+			}()
+			return nil
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "state") {
+		t.Fatalf("expected state mismatch error, got %v", err)
+	}
+}
+
+func TestDeviceLoginPollsUntilAuthorized(t *testing.T) {
+	fake := newFakeAuthServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var prompted string
+	result, err := DeviceLogin(ctx, fake.provider("github"), LoginOptions{
+		ClientID: "client",
+		PromptDevice: func(verificationURI, userCode string) {
+			prompted = userCode
+		},
+	})
+	if err != nil {
+		t.Fatalf("DeviceLogin returned error: %v", err)
+	}
+	if prompted != "ABCD-1234" {
+		t.Fatalf("expected user code to be prompted, got %q", prompted)
+	}
+	if result.AccessToken != "device-token" || len(result.Scopes) != 2 || result.Scopes[1] != "read:org" {
+		t.Fatalf("unexpected device login result: %+v", result)
+	}
+}
+
+func TestDeviceLoginRequiresDeviceEndpoint(t *testing.T) {
+	if _, err := DeviceLogin(context.Background(), SlackOAuthProvider(), LoginOptions{ClientID: "client"}); err == nil {
+		t.Fatalf("expected slack device login to be rejected")
+	}
+}
+
+// newFakeOktaServer はOktaの default 認可サーバーのトークン・userinfoエンドポイントを模したサーバーです
+func newFakeOktaServer(t *testing.T, supportsExchange bool) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/default/v1/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Form.Get("grant_type") {
+		case "refresh_token":
+			if r.Form.Get("refresh_token") != "okta-refresh" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "okta-access-2", "token_type": "Bearer", "expires_in": 3600, "scope": "openid offline_access"})
+		case tokenExchangeGrantType:
+			if !supportsExchange {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "unsupported_grant_type", "error_description": "The grant type is not supported"})
+				return
+			}
+			if r.Form.Get("subject_token") != "okta-access-2" || r.Form.Get("subject_token_type") != accessTokenSubjectType {
+				t.Errorf("unexpected subject token: %v", r.Form)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "downstream-" + r.Form.Get("audience"), "token_type": "Bearer", "expires_in": 600, "scope": r.Form.Get("scope")})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "unsupported_grant_type"})
+		}
+	})
+	mux.HandleFunc("/oauth2/default/v1/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer okta-access-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"sub": "00u1", "email": "kazuki@example.com"})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newOktaTestManager(t *testing.T, server *httptest.Server) (*OktaAuthManager, *TokenStore) {
+	t.Helper()
+	store, err := NewTokenStore(filepath.Join(t.TempDir(), "tokens.json"), "passphrase")
+	if err != nil {
+		t.Fatalf("NewTokenStore returned error: %v", err)
+	}
+	expired := time.Now().Add(-time.Minute)
+	if err := store.StoreToken(oktaServiceName, "okta-access-1", "okta-refresh", &expired, []string{"openid", "offline_access"}); err != nil {
+		t.Fatalf("StoreToken returned error: %v", err)
+	}
+	manager := NewOktaAuthManager(OktaSettings{Domain: server.URL, ClientID: "okta-client"}, store)
+	manager.httpClient = server.Client()
+	return manager, store
+}
+
+func TestOktaAuthManagerRefreshesExpiredTokenAndExchanges(t *testing.T) {
+	server := newFakeOktaServer(t, true)
+	manager, store := newOktaTestManager(t, server)
+
+	status, err := manager.ValidateToken(context.Background())
+	if err != nil {
+		t.Fatalf("ValidateToken returned error: %v", err)
+	}
+	if !status.IsValid {
+		t.Fatalf("expected refreshed okta token to be valid, got %+v", status)
+	}
+
+	stored, err := store.GetToken(oktaServiceName)
+	if err != nil {
+		t.Fatalf("GetToken returned error: %v", err)
+	}
+	if stored.AccessToken != "okta-access-2" || stored.RefreshToken != "okta-refresh" || stored.LastRefresh == nil {
+		t.Fatalf("expected refreshed token to be cached with the original refresh token, got %+v", stored)
+	}
+
+	exchanged, err := manager.ExchangeToken(context.Background(), "api://slack", []string{"search:read"})
+	if err != nil {
+		t.Fatalf("ExchangeToken returned error: %v", err)
+	}
+	if exchanged.AccessToken != "downstream-api://slack" || exchanged.ExpiresAt == nil {
+		t.Fatalf("unexpected exchanged token: %+v", exchanged)
+	}
+}
+
+func TestOktaAuthManagerReportsUnsupportedTokenExchange(t *testing.T) {
+	server := newFakeOktaServer(t, false)
+	manager, _ := newOktaTestManager(t, server)
+
+	_, err := manager.ExchangeToken(context.Background(), "api://github", nil)
+	if !errors.Is(err, ErrTokenExchangeUnsupported) {
+		t.Fatalf("expected ErrTokenExchangeUnsupported, got %v", err)
+	}
+}
+
+func TestOktaSettingsEndpointsAndRedirect(t *testing.T) {
+	settings := OktaSettings{Domain: "example.okta.com", ClientID: "id", RedirectURI: "http://127.0.0.1:8976/authorization-code/callback"}
+	provider := settings.Provider()
+	if provider.AuthURL != "https://example.okta.com/oauth2/default/v1/authorize" {
+		t.Fatalf("unexpected authorize url: %s", provider.AuthURL)
+	}
+	settings.AuthorizationServer = "org"
+	if got := settings.Provider().TokenURL; got != "https://example.okta.com/oauth2/v1/token" {
+		t.Fatalf("unexpected org token url: %s", got)
+	}
+
+	listenAddr, callbackPath, err := settings.LoopbackAddress()
+	if err != nil || listenAddr != "127.0.0.1:8976" || callbackPath != "/authorization-code/callback" {
+		t.Fatalf("unexpected loopback address %q %q (%v)", listenAddr, callbackPath, err)
+	}
+	settings.RedirectURI = "https://worklogr.example.com/callback"
+	if _, _, err := settings.LoopbackAddress(); err == nil {
+		t.Fatalf("expected non-loopback redirect uri to be rejected")
+	}
+}
@@ -12,6 +12,8 @@ type AuthStatus struct {
 	LastChecked  time.Time  `json:"last_checked"`  // 最後に確認した時刻
 	ErrorMessage string     `json:"error_message,omitempty"` // エラーメッセージ
 	TokenType    string     `json:"token_type"`    // トークンタイプ: "access", "refresh", "gcloud"
+	// GrantedScopes はAPIが返した付与スコープです（nil の場合は不明）
+	GrantedScopes []string `json:"granted_scopes,omitempty"`
 }
 
 // AuthManager は統一認証管理のためのインターフェースです
@@ -98,6 +100,12 @@ func (b *BaseAuthManager) UpdateStatus(isValid bool, errorMessage string) {
 	b.status.LastChecked = time.Now()
 	b.status.ErrorMessage = errorMessage
 	b.status.ExpiresAt = b.config.TokenExpiresAt
+	b.status.GrantedScopes = nil
+}
+
+// SetGrantedScopes はAPIから取得した付与スコープを認証状態に設定します
+func (b *BaseAuthManager) SetGrantedScopes(scopes []string) {
+	b.status.GrantedScopes = scopes
 }
 
 // GetServiceName はサービス名を返します
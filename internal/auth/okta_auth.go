@@ -0,0 +1,301 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// oktaServiceName はトークンストアでOktaのトークンを保存するサービス名です
+const oktaServiceName = "okta"
+
+const (
+	tokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	accessTokenSubjectType = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+// ErrTokenExchangeUnsupported はOktaの組織・認可サーバーがトークン交換に対応していないことを表します
+var ErrTokenExchangeUnsupported = errors.New("Oktaの認可サーバーがトークン交換（RFC 8693）に対応していません")
+
+// OktaSettings はOkta OIDCの接続設定です
+type OktaSettings struct {
+	Domain       string
+	ClientID     string
+	ClientSecret string
+	RedirectURI  string
+	// AuthorizationServer はカスタム認可サーバーIDです（空の場合は "default"、"org" で組織の認可サーバー）
+	AuthorizationServer string
+	// Scopes が空の場合は openid, profile, email, offline_access を要求します
+	Scopes []string
+}
+
+// Validate は必須項目が設定されているかを確認します
+func (s OktaSettings) Validate() error {
+	if s.Domain == "" {
+		return fmt.Errorf("okta.domain が設定されていません")
+	}
+	if s.ClientID == "" {
+		return fmt.Errorf("okta.client_id が設定されていません")
+	}
+	return nil
+}
+
+// endpointBase は /v1/authorize などのエンドポイントの共通部分を返します
+func (s OktaSettings) endpointBase() string {
+	domain := strings.TrimSuffix(s.Domain, "/")
+	if !strings.HasPrefix(domain, "https://") && !strings.HasPrefix(domain, "http://") {
+		domain = "https://" + domain
+	}
+	switch s.AuthorizationServer {
+	case "":
+		return domain + "/oauth2/default"
+	case "org":
+		return domain + "/oauth2"
+	default:
+		return domain + "/oauth2/" + s.AuthorizationServer
+	}
+}
+
+// Provider はOktaの認可コードフロー・デバイスフロー用プロバイダーを返します
+func (s OktaSettings) Provider() OAuthProvider {
+	scopes := s.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "profile", "email", "offline_access"}
+	}
+	base := s.endpointBase()
+	return OAuthProvider{
+		Name:           oktaServiceName,
+		AuthURL:        base + "/v1/authorize",
+		TokenURL:       base + "/v1/token",
+		DeviceAuthURL:  base + "/v1/device/authorize",
+		Scopes:         scopes,
+		ScopeParam:     "scope",
+		ScopeSeparator: " ",
+	}
+}
+
+// LoopbackAddress は redirect_uri からリダイレクト受付サーバーのアドレスとパスを返します。
+// redirect_uri が未設定の場合は空文字を返します（空きポートと /callback を使用）。
+func (s OktaSettings) LoopbackAddress() (listenAddr, callbackPath string, err error) {
+	if s.RedirectURI == "" {
+		return "", "", nil
+	}
+	parsed, err := url.Parse(s.RedirectURI)
+	if err != nil {
+		return "", "", fmt.Errorf("okta.redirect_uri が不正です: %w", err)
+	}
+	host := parsed.Hostname()
+	ip := net.ParseIP(host)
+	if parsed.Scheme != "http" || !(host == "localhost" || (ip != nil && ip.IsLoopback())) {
+		return "", "", fmt.Errorf("okta.redirect_uri はループバックアドレス（http://127.0.0.1:<port>/...）である必要があります: %s", s.RedirectURI)
+	}
+	if parsed.Port() == "" {
+		return "", "", fmt.Errorf("okta.redirect_uri にポートを指定してください: %s", s.RedirectURI)
+	}
+	callbackPath = parsed.Path
+	if callbackPath == "" {
+		callbackPath = "/"
+	}
+	return parsed.Host, callbackPath, nil
+}
+
+// OktaAuthManager はOkta OIDCのトークン（リフレッシュトークンを含む）を管理します
+type OktaAuthManager struct {
+	*BaseAuthManager
+	settings    OktaSettings
+	store       *TokenStore
+	httpClient  *http.Client
+	lastRefresh *time.Time
+}
+
+// NewOktaAuthManager は新しいOkta認証マネージャーを作成します。
+// トークンは `auth login okta` でトークンストアに保存されたものを使用します。
+func NewOktaAuthManager(settings OktaSettings, store *TokenStore) *OktaAuthManager {
+	manager := &OktaAuthManager{
+		BaseAuthManager: NewBaseAuthManager(oktaServiceName, &AuthConfig{AutoRefresh: true}),
+		settings:        settings,
+		store:           store,
+		httpClient:      &http.Client{Timeout: 30 * time.Second},
+	}
+	_ = manager.UpdateTokenFromStore()
+	return manager
+}
+
+// ValidateToken はOktaのアクセストークンをuserinfoエンドポイントで検証します。
+// アクセストークンが期限切れの場合はリフレッシュトークンで更新してから検証します。
+func (o *OktaAuthManager) ValidateToken(ctx context.Context) (*AuthStatus, error) {
+	if o.config.AccessToken == "" && o.config.RefreshToken == "" {
+		o.UpdateStatus(false, "Oktaにログインしていません（worklogr auth login okta を実行してください）")
+		return o.GetAuthStatus(), nil
+	}
+
+	if o.config.AccessToken == "" || o.IsTokenExpired() {
+		if o.config.RefreshToken == "" {
+			o.UpdateStatus(false, "トークンの有効期限が切れています")
+			return o.GetAuthStatus(), nil
+		}
+		if err := o.RefreshToken(ctx); err != nil {
+			o.UpdateStatus(false, fmt.Sprintf("トークンのリフレッシュに失敗しました: %v", err))
+			return o.GetAuthStatus(), nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.settings.endpointBase()+"/v1/userinfo", nil)
+	if err != nil {
+		return nil, fmt.Errorf("リクエストの作成に失敗しました: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+o.config.AccessToken)
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		o.UpdateStatus(false, fmt.Sprintf("トークン検証エラー: %v", err))
+		return o.GetAuthStatus(), err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		o.UpdateStatus(true, "")
+	case http.StatusUnauthorized:
+		o.UpdateStatus(false, "トークンが無効です（再ログインしてください）")
+	default:
+		o.UpdateStatus(false, fmt.Sprintf("userinfoエンドポイントがエラーを返しました: HTTP %d", resp.StatusCode))
+	}
+	return o.GetAuthStatus(), nil
+}
+
+// RefreshToken はリフレッシュトークンでOktaのアクセストークンを更新し、トークンストアに保存します
+func (o *OktaAuthManager) RefreshToken(ctx context.Context) error {
+	if o.config.RefreshToken == "" {
+		return fmt.Errorf("リフレッシュトークンがありません（offline_access スコープでログインしてください）")
+	}
+	provider := o.settings.Provider()
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {o.config.RefreshToken},
+		"client_id":     {o.settings.ClientID},
+		"scope":         {strings.Join(provider.Scopes, " ")},
+	}
+	if o.settings.ClientSecret != "" {
+		form.Set("client_secret", o.settings.ClientSecret)
+	}
+
+	result, err := exchangeToken(ctx, o.httpClient, provider, form, provider.Scopes)
+	if err != nil {
+		return err
+	}
+	// リフレッシュトークンをローテーションしない設定では新しいトークンが返らない
+	if result.RefreshToken == "" {
+		result.RefreshToken = o.config.RefreshToken
+	}
+
+	o.config.AccessToken = result.AccessToken
+	o.config.RefreshToken = result.RefreshToken
+	o.config.TokenExpiresAt = result.ExpiresAt
+	now := time.Now()
+	o.lastRefresh = &now
+
+	if o.store != nil {
+		if err := result.Store(o.store, oktaServiceName); err != nil {
+			return fmt.Errorf("トークンの保存に失敗しました: %w", err)
+		}
+		if err := o.store.UpdateRefreshTime(oktaServiceName); err != nil {
+			return fmt.Errorf("リフレッシュ時刻の保存に失敗しました: %w", err)
+		}
+	}
+
+	o.UpdateStatus(true, "")
+	return nil
+}
+
+// ExchangeToken はOktaのアクセストークンを下流サービス向けのトークンに交換します（RFC 8693）。
+// 組織がトークン交換に対応していない場合は ErrTokenExchangeUnsupported を返します。
+func (o *OktaAuthManager) ExchangeToken(ctx context.Context, audience string, scopes []string) (*LoginResult, error) {
+	if audience == "" {
+		return nil, fmt.Errorf("トークン交換のaudience（okta_audience）が設定されていません")
+	}
+	if o.config.AccessToken == "" || o.IsTokenExpired() {
+		if err := o.RefreshToken(ctx); err != nil {
+			return nil, fmt.Errorf("Oktaトークンの更新に失敗しました: %w", err)
+		}
+	}
+
+	form := url.Values{
+		"grant_type":         {tokenExchangeGrantType},
+		"subject_token":      {o.config.AccessToken},
+		"subject_token_type": {accessTokenSubjectType},
+		"audience":           {audience},
+		"client_id":          {o.settings.ClientID},
+	}
+	if len(scopes) > 0 {
+		form.Set("scope", strings.Join(scopes, " "))
+	}
+	if o.settings.ClientSecret != "" {
+		form.Set("client_secret", o.settings.ClientSecret)
+	}
+
+	result, err := exchangeToken(ctx, o.httpClient, o.settings.Provider(), form, scopes)
+	if err != nil {
+		var oauthErr *OAuthError
+		if errors.As(err, &oauthErr) && (oauthErr.Code == "unsupported_grant_type" || oauthErr.Code == "unauthorized_client") {
+			return nil, fmt.Errorf("%w: %v", ErrTokenExchangeUnsupported, err)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetTokenInfo はOktaトークン情報を返します
+func (o *OktaAuthManager) GetTokenInfo() *TokenInfo {
+	info := o.BaseAuthManager.GetTokenInfo()
+	info.Scopes = o.settings.Provider().Scopes
+	info.LastRefresh = o.lastRefresh
+
+	if o.store != nil {
+		if storedToken, err := o.store.GetToken(oktaServiceName); err == nil {
+			if len(storedToken.Scopes) > 0 {
+				info.Scopes = storedToken.Scopes
+			}
+			if storedToken.LastRefresh != nil {
+				info.LastRefresh = storedToken.LastRefresh
+			}
+		}
+	}
+	return info
+}
+
+// UpdateTokenFromStore はトークンストアからOktaのトークンを読み込みます
+func (o *OktaAuthManager) UpdateTokenFromStore() error {
+	if o.store == nil {
+		return fmt.Errorf("トークンストアが設定されていません")
+	}
+
+	storedToken, err := o.store.GetToken(oktaServiceName)
+	if err != nil {
+		return fmt.Errorf("ストアからのトークン取得に失敗: %w", err)
+	}
+
+	o.config.AccessToken = storedToken.AccessToken
+	o.config.RefreshToken = storedToken.RefreshToken
+	o.config.TokenExpiresAt = storedToken.ExpiresAt
+	return nil
+}
+
+// ClearToken は保存されたOktaのトークンをクリアします
+func (o *OktaAuthManager) ClearToken() error {
+	o.config.AccessToken = ""
+	o.config.RefreshToken = ""
+	o.config.TokenExpiresAt = nil
+
+	o.UpdateStatus(false, "トークンがクリアされました")
+
+	if o.store != nil {
+		return o.store.DeleteToken(oktaServiceName)
+	}
+	return nil
+}
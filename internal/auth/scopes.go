@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// googleScopePrefix はGoogle OAuthスコープURLの共通プレフィックスです
+const googleScopePrefix = "https://www.googleapis.com/auth/"
+
+// ScopeRequirement は収集機能が必要とするOAuthスコープです
+type ScopeRequirement struct {
+	Service string   // slack / github / google_calendar
+	Feature string   // 機能名（表示用）
+	Scopes  []string // すべて必要なスコープ（Googleは "drive.readonly" のような短縮名も可）
+}
+
+// MissingScope は不足しているスコープと、それを必要とする機能です
+type MissingScope struct {
+	Feature string
+	Scopes  []string
+}
+
+// ScopeReport はサービスごとのスコープ検証結果です
+type ScopeReport struct {
+	Service string
+	// Checked はトークンから付与スコープを取得できたかどうかです
+	// （GitHubのfine-grained tokenなど、付与スコープを返さないトークンでは false）
+	Checked bool
+	Granted []string
+	Missing []MissingScope
+	Error   string
+}
+
+// OK は不足スコープが無いかどうかを返します
+func (r ScopeReport) OK() bool {
+	return len(r.Missing) == 0
+}
+
+// MissingScopeNames は不足しているスコープを重複なく返します
+func (r ScopeReport) MissingScopeNames() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, missing := range r.Missing {
+		for _, scope := range missing.Scopes {
+			if !seen[scope] {
+				seen[scope] = true
+				names = append(names, scope)
+			}
+		}
+	}
+	return names
+}
+
+// Hint は不足スコープを解消する手順を返します
+func (r ScopeReport) Hint() string {
+	names := r.MissingScopeNames()
+	if len(names) == 0 {
+		return ""
+	}
+	switch r.Service {
+	case "slack":
+		return fmt.Sprintf("SlackアプリのUser Token Scopesに %s を追加して再インストールするか、`worklogr auth login slack --scopes %s` を実行してください",
+			strings.Join(names, ", "), strings.Join(names, ","))
+	case "github":
+		return fmt.Sprintf("トークンに %s スコープを付与して再発行するか、`worklogr auth login github --scopes %s` を実行してください",
+			strings.Join(names, ", "), strings.Join(names, ","))
+	case "google_calendar":
+		scopes := []string{googleScopePrefix + "cloud-platform"}
+		for _, name := range names {
+			scopes = append(scopes, googleScopePrefix+name)
+		}
+		return fmt.Sprintf("`gcloud auth application-default login --scopes=%s` を実行してください", strings.Join(scopes, ","))
+	default:
+		return ""
+	}
+}
+
+// CheckScopes は付与スコープに対して、各機能で不足しているスコープを返します
+func CheckScopes(service string, granted []string, requirements []ScopeRequirement) []MissingScope {
+	var missing []MissingScope
+	for _, requirement := range requirements {
+		if requirement.Service != service {
+			continue
+		}
+		var lacking []string
+		for _, scope := range requirement.Scopes {
+			if !ScopeGranted(service, granted, scope) {
+				lacking = append(lacking, normalizeScope(service, scope))
+			}
+		}
+		if len(lacking) > 0 {
+			missing = append(missing, MissingScope{Feature: requirement.Feature, Scopes: lacking})
+		}
+	}
+	return missing
+}
+
+// scopeImplications は上位スコープが包含するスコープです
+var scopeImplications = map[string]map[string][]string{
+	"github": {
+		"repo":      {"repo:status", "repo_deployment", "public_repo", "repo:invite", "security_events"},
+		"user":      {"read:user", "user:email", "user:follow"},
+		"admin:org": {"write:org", "read:org"},
+		"write:org": {"read:org"},
+	},
+	"google_calendar": {
+		"calendar":          {"calendar.readonly", "calendar.events", "calendar.events.readonly"},
+		"calendar.readonly": {"calendar.events.readonly"},
+		"calendar.events":   {"calendar.events.readonly"},
+		"drive":             {"drive.readonly"},
+	},
+}
+
+// ScopeGranted は required が付与スコープ（上位スコープを含む）に含まれるかを判定します
+func ScopeGranted(service string, granted []string, required string) bool {
+	required = normalizeScope(service, required)
+	for _, scope := range granted {
+		scope = normalizeScope(service, scope)
+		if scope == required {
+			return true
+		}
+		for _, implied := range scopeImplications[service][scope] {
+			if implied == required {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// normalizeScope はGoogleのスコープURLを短縮名に揃えます
+func normalizeScope(service, scope string) string {
+	scope = strings.TrimSpace(scope)
+	if service == "google_calendar" || service == "calendar" {
+		return strings.TrimPrefix(scope, googleScopePrefix)
+	}
+	return scope
+}
+
+// parseScopeHeader は X-OAuth-Scopes ヘッダーの値を分割します。
+// ヘッダーが存在しない場合は nil（付与スコープ不明）を返します。
+func parseScopeHeader(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	scopes := []string{}
+	for _, value := range values {
+		scopes = append(scopes, parseScopes(value)...)
+	}
+	return scopes
+}
@@ -44,10 +44,11 @@ func (s *SlackAuthManager) ValidateToken(ctx context.Context) (*AuthStatus, erro
 
 	// 内部状態を更新
 	s.UpdateStatus(status.IsValid, status.ErrorMessage)
+	s.SetGrantedScopes(status.GrantedScopes)
 	
 	// 有効な場合はトークンを保存
 	if status.IsValid && s.store != nil {
-		if err := s.store.StoreToken("slack", s.config.AccessToken, s.config.RefreshToken, s.config.TokenExpiresAt, []string{"chat:write", "channels:read"}); err != nil {
+		if err := s.store.StoreToken("slack", s.config.AccessToken, s.config.RefreshToken, s.config.TokenExpiresAt, s.storedScopes(status.GrantedScopes, []string{"chat:write", "channels:read"})); err != nil {
 			// エラーをログに記録するが検証は失敗させない
 			fmt.Printf("警告: Slackトークンの保存に失敗しました: %v\n", err)
 		}
@@ -71,9 +72,33 @@ func (s *SlackAuthManager) RefreshToken(ctx context.Context) error {
 func (s *SlackAuthManager) GetTokenInfo() *TokenInfo {
 	info := s.BaseAuthManager.GetTokenInfo()
 	info.Scopes = []string{"chat:write", "channels:read", "users:read"}
+
+	// トークンストアに保存されている場合は実際に付与されたスコープを使用
+	if s.store != nil {
+		if storedToken, err := s.store.GetToken("slack"); err == nil {
+			if len(storedToken.Scopes) > 0 {
+				info.Scopes = storedToken.Scopes
+			}
+			info.LastRefresh = storedToken.LastRefresh
+		}
+	}
+
 	return info
 }
 
+// storedScopes は保存するスコープを返します（APIの付与スコープ → ストア保存済み → fallback の順）
+func (s *SlackAuthManager) storedScopes(granted, fallback []string) []string {
+	if granted != nil {
+		return granted
+	}
+	if s.store != nil {
+		if storedToken, err := s.store.GetToken("slack"); err == nil && len(storedToken.Scopes) > 0 {
+			return storedToken.Scopes
+		}
+	}
+	return fallback
+}
+
 // IsHealthy はSlack認証が正常かどうかをチェックします
 func (s *SlackAuthManager) IsHealthy(ctx context.Context) bool {
 	status, err := s.ValidateToken(ctx)
@@ -1,23 +1,33 @@
 package auth
 
 import (
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/iriam/worklogr/internal/secrets"
 )
 
+// tokenStoreVersion はscryptによる鍵導出を使用するストアファイルの形式バージョンです
+const tokenStoreVersion = 2
+
 // TokenStore は認証トークンの安全な保存と取得を管理します
 type TokenStore struct {
-	storePath string // ストアファイルのパス
-	key       []byte // 暗号化キー
+	storePath string            // ストアファイルのパス
+	key       []byte            // 暗号化キー（scryptで導出）
+	kdf       secrets.KDFParams // 鍵導出パラメータ
+	legacyKey []byte            // 旧形式（SHA-256）のキー。移行時の復号にのみ使用
+}
+
+// tokenStoreFile はストアファイルの形式です
+type tokenStoreFile struct {
+	Version int               `json:"version"`
+	KDF     secrets.KDFParams `json:"kdf"`
+	Tokens  []StoredToken     `json:"tokens"`
 }
 
 // StoredToken はセキュアストレージに保存されるトークンを表します
@@ -41,16 +51,69 @@ func NewTokenStore(storePath string, passphrase string) (*TokenStore, error) {
 		return nil, fmt.Errorf("ストアディレクトリの作成に失敗しました: %w", err)
 	}
 
-	// パスフレーズから暗号化キーを生成
-	hash := sha256.Sum256([]byte(passphrase))
-	key := hash[:]
+	// 既存ストアのKDFパラメータを使用し、無ければ新しいソルトで生成
+	kdf, err := readTokenStoreKDF(storePath)
+	if err != nil {
+		return nil, err
+	}
+	if kdf == nil {
+		params, err := secrets.DefaultKDFParams()
+		if err != nil {
+			return nil, fmt.Errorf("鍵導出パラメータの生成に失敗しました: %w", err)
+		}
+		kdf = &params
+	}
+
+	// パスフレーズから暗号化キーを導出
+	key, err := kdf.DeriveKey(passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("暗号化キーの導出に失敗しました: %w", err)
+	}
+	legacyHash := sha256.Sum256([]byte(passphrase))
 
 	return &TokenStore{
 		storePath: storePath,
 		key:       key,
+		kdf:       *kdf,
+		legacyKey: legacyHash[:],
 	}, nil
 }
 
+// readTokenStoreKDF は既存ストアファイルのKDFパラメータを返します（旧形式・未作成の場合は nil）
+func readTokenStoreKDF(storePath string) (*secrets.KDFParams, error) {
+	data, err := os.ReadFile(storePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("トークンストアの読み込みに失敗しました: %w", err)
+	}
+	if isLegacyTokenStore(data) {
+		return nil, nil
+	}
+
+	var file tokenStoreFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("トークンストアの解析に失敗しました: %w", err)
+	}
+	return &file.KDF, nil
+}
+
+// isLegacyTokenStore は旧形式（トークン配列のみ、SHA-256鍵）のストアかどうかを判定します
+func isLegacyTokenStore(data []byte) bool {
+	for _, b := range data {
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '[':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
 // StoreToken はサービスのトークンを安全に保存します
 func (ts *TokenStore) StoreToken(serviceName, accessToken, refreshToken string, expiresAt *time.Time, scopes []string) error {
 	// トークンデータ構造を作成
@@ -213,69 +276,60 @@ func (ts *TokenStore) UpdateRefreshTime(serviceName string) error {
 
 // encrypt はAES-GCMを使用してデータを暗号化します
 func (ts *TokenStore) encrypt(data []byte) ([]byte, error) {
-	block, err := aes.NewCipher(ts.key)
-	if err != nil {
-		return nil, err
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, err
-	}
-
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return nil, err
-	}
-
-	ciphertext := gcm.Seal(nonce, nonce, data, nil)
-	return ciphertext, nil
+	return secrets.Seal(ts.key, data)
 }
 
 // decrypt はAES-GCMを使用してデータを復号化します
 func (ts *TokenStore) decrypt(data []byte) ([]byte, error) {
-	block, err := aes.NewCipher(ts.key)
-	if err != nil {
-		return nil, err
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, err
-	}
-
-	nonceSize := gcm.NonceSize()
-	if len(data) < nonceSize {
-		return nil, fmt.Errorf("暗号文が短すぎます")
-	}
-
-	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	return plaintext, nil
+	return secrets.Unseal(ts.key, data)
 }
 
-// loadTokens はストアファイルからトークンを読み込みます
+// loadTokens はストアファイルからトークンを読み込みます。
+// 旧形式のストアは旧キーで復号して新しいキーで再暗号化し、次回保存時に新形式へ移行します。
 func (ts *TokenStore) loadTokens() ([]StoredToken, error) {
 	data, err := os.ReadFile(ts.storePath)
 	if err != nil {
 		return nil, err
 	}
 
+	if !isLegacyTokenStore(data) {
+		var file tokenStoreFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("トークンストアの解析に失敗しました: %w", err)
+		}
+		return file.Tokens, nil
+	}
+
 	var tokens []StoredToken
 	if err := json.Unmarshal(data, &tokens); err != nil {
 		return nil, fmt.Errorf("トークンストアの解析に失敗しました: %w", err)
 	}
+	for i := range tokens {
+		encryptedData, err := base64.StdEncoding.DecodeString(tokens[i].EncryptedData)
+		if err != nil {
+			return nil, fmt.Errorf("暗号化データのデコードに失敗しました: %w", err)
+		}
+		plaintext, err := secrets.Unseal(ts.legacyKey, encryptedData)
+		if err != nil {
+			return nil, fmt.Errorf("旧形式トークンの復号化に失敗しました: %w", err)
+		}
+		reencrypted, err := ts.encrypt(plaintext)
+		if err != nil {
+			return nil, fmt.Errorf("トークンの再暗号化に失敗しました: %w", err)
+		}
+		tokens[i].EncryptedData = base64.StdEncoding.EncodeToString(reencrypted)
+	}
 
 	return tokens, nil
 }
 
 // saveTokens はトークンをストアファイルに保存します
 func (ts *TokenStore) saveTokens(tokens []StoredToken) error {
-	data, err := json.MarshalIndent(tokens, "", "  ")
+	data, err := json.MarshalIndent(tokenStoreFile{
+		Version: tokenStoreVersion,
+		KDF:     ts.kdf,
+		Tokens:  tokens,
+	}, "", "  ")
 	if err != nil {
 		return fmt.Errorf("トークンのシリアライズに失敗しました: %w", err)
 	}
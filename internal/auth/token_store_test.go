@@ -1,11 +1,16 @@
 package auth
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/iriam/worklogr/internal/secrets"
 )
 
 func TestTokenStoreRoundTripAndRefreshTimestamp(t *testing.T) {
@@ -98,3 +103,62 @@ func TestTokenStoreListAndDeleteServices(t *testing.T) {
 		t.Fatalf("expected remaining service github, got %v", services)
 	}
 }
+
+func TestTokenStoreMigratesLegacySHA256Store(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "store.json")
+
+	legacyKey := sha256.Sum256([]byte("passphrase"))
+	payload, err := json.Marshal(struct {
+		AccessToken string `json:"access_token"`
+	}{AccessToken: "legacy-token"})
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+	sealed, err := secrets.Seal(legacyKey[:], payload)
+	if err != nil {
+		t.Fatalf("failed to seal payload: %v", err)
+	}
+	legacy, err := json.Marshal([]StoredToken{{
+		ServiceName:   "slack",
+		EncryptedData: base64.StdEncoding.EncodeToString(sealed),
+	}})
+	if err != nil {
+		t.Fatalf("failed to marshal legacy store: %v", err)
+	}
+	if err := os.WriteFile(storePath, legacy, 0600); err != nil {
+		t.Fatalf("failed to write legacy store: %v", err)
+	}
+
+	ts, err := NewTokenStore(storePath, "passphrase")
+	if err != nil {
+		t.Fatalf("NewTokenStore returned error: %v", err)
+	}
+	token, err := ts.GetToken("slack")
+	if err != nil {
+		t.Fatalf("GetToken returned error: %v", err)
+	}
+	if token.AccessToken != "legacy-token" {
+		t.Fatalf("unexpected access token: %q", token.AccessToken)
+	}
+
+	// 保存すると新形式（scrypt）に移行される
+	if err := ts.UpdateRefreshTime("slack"); err != nil {
+		t.Fatalf("UpdateRefreshTime returned error: %v", err)
+	}
+	raw, err := os.ReadFile(storePath)
+	if err != nil {
+		t.Fatalf("failed to read token store: %v", err)
+	}
+	if !strings.Contains(string(raw), `"scrypt"`) {
+		t.Fatalf("expected store to be rewritten with scrypt parameters: %s", raw)
+	}
+
+	reopened, err := NewTokenStore(storePath, "passphrase")
+	if err != nil {
+		t.Fatalf("NewTokenStore returned error: %v", err)
+	}
+	token, err = reopened.GetToken("slack")
+	if err != nil || token.AccessToken != "legacy-token" {
+		t.Fatalf("unexpected token after migration: %+v, %v", token, err)
+	}
+}
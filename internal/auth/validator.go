@@ -60,9 +60,10 @@ func (tv *TokenValidator) ValidateSlackToken(ctx context.Context, token string)
 	}
 
 	status := &AuthStatus{
-		IsValid:     slackResp.OK,
-		LastChecked: time.Now(),
-		TokenType:   "access",
+		IsValid:       slackResp.OK,
+		LastChecked:   time.Now(),
+		TokenType:     "access",
+		GrantedScopes: parseScopeHeader(resp.Header.Values("X-OAuth-Scopes")),
 	}
 
 	if !slackResp.OK {
@@ -98,6 +99,10 @@ func (tv *TokenValidator) ValidateGitHubToken(ctx context.Context, token string)
 		LastChecked: time.Now(),
 		TokenType:   "access",
 	}
+	if status.IsValid {
+		// fine-grained personal access token では X-OAuth-Scopes が返されない
+		status.GrantedScopes = parseScopeHeader(resp.Header.Values("X-OAuth-Scopes"))
+	}
 
 	if resp.StatusCode != http.StatusOK {
 		var errorResp struct {
@@ -157,9 +162,12 @@ func (tv *TokenValidator) ValidateGoogleToken(ctx context.Context, token string)
 		status.ErrorMessage = fmt.Sprintf("Google APIエラー: %s", tokenInfo.Error)
 	} else if resp.StatusCode != http.StatusOK {
 		status.ErrorMessage = fmt.Sprintf("HTTPエラー: %d", resp.StatusCode)
-	} else if tokenInfo.ExpiresIn > 0 {
-		expiresAt := time.Now().Add(time.Duration(tokenInfo.ExpiresIn) * time.Second)
-		status.ExpiresAt = &expiresAt
+	} else {
+		status.GrantedScopes = parseScopes(tokenInfo.Scope)
+		if tokenInfo.ExpiresIn > 0 {
+			expiresAt := time.Now().Add(time.Duration(tokenInfo.ExpiresIn) * time.Second)
+			status.ExpiresAt = &expiresAt
+		}
 	}
 
 	return status, nil
@@ -114,3 +114,64 @@ func TestCheckServiceHealthReturnsSuggestionOnInvalidToken(t *testing.T) {
 		t.Fatalf("expected health check suggestions for invalid token")
 	}
 }
+
+func TestValidateSlackTokenParsesGrantedScopes(t *testing.T) {
+	tv := NewTokenValidator()
+	tv.httpClient = newTestHTTPClient(func(req *http.Request) (*http.Response, error) {
+		resp := newJSONResponse(http.StatusOK, `{"ok":true,"user":"kazuki","team":"dev"}`)
+		resp.Header.Set("X-OAuth-Scopes", "users:read, search:read")
+		return resp, nil
+	})
+
+	status, err := tv.ValidateSlackToken(context.Background(), "xoxp-token")
+	if err != nil {
+		t.Fatalf("ValidateSlackToken returned error: %v", err)
+	}
+	if got := strings.Join(status.GrantedScopes, ","); got != "users:read,search:read" {
+		t.Fatalf("unexpected granted scopes: %q", got)
+	}
+}
+
+func TestValidateGitHubTokenWithoutScopeHeaderLeavesScopesUnknown(t *testing.T) {
+	tv := NewTokenValidator()
+	tv.httpClient = newTestHTTPClient(func(req *http.Request) (*http.Response, error) {
+		return newJSONResponse(http.StatusOK, `{"login":"kazuki"}`), nil
+	})
+
+	status, err := tv.ValidateGitHubToken(context.Background(), "github_pat_token")
+	if err != nil {
+		t.Fatalf("ValidateGitHubToken returned error: %v", err)
+	}
+	if !status.IsValid || status.GrantedScopes != nil {
+		t.Fatalf("expected valid status with unknown scopes, got %+v", status)
+	}
+}
+
+func TestCheckScopesReportsMissingScopesPerFeature(t *testing.T) {
+	requirements := []ScopeRequirement{
+		{Service: "google_calendar", Feature: "calendar", Scopes: []string{"calendar.readonly"}},
+		{Service: "google_calendar", Feature: "attachments", Scopes: []string{"drive.readonly"}},
+		{Service: "github", Feature: "repos", Scopes: []string{"repo"}},
+	}
+
+	granted := []string{"https://www.googleapis.com/auth/calendar", "openid"}
+	missing := CheckScopes("google_calendar", granted, requirements)
+	if len(missing) != 1 || missing[0].Feature != "attachments" || missing[0].Scopes[0] != "drive.readonly" {
+		t.Fatalf("unexpected missing scopes: %+v", missing)
+	}
+
+	report := ScopeReport{Service: "google_calendar", Checked: true, Granted: granted, Missing: missing}
+	if report.OK() {
+		t.Fatalf("expected report with missing scopes not to be OK")
+	}
+	if hint := report.Hint(); !strings.Contains(hint, "https://www.googleapis.com/auth/drive.readonly") {
+		t.Fatalf("expected gcloud hint to request drive.readonly, got %q", hint)
+	}
+
+	if missing := CheckScopes("github", []string{"repo", "read:org"}, requirements); len(missing) != 0 {
+		t.Fatalf("expected github repo scope to satisfy requirements, got %+v", missing)
+	}
+	if !ScopeGranted("github", []string{"admin:org"}, "read:org") {
+		t.Fatalf("expected admin:org to imply read:org")
+	}
+}
@@ -2,6 +2,7 @@ package collector
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -9,6 +10,8 @@ import (
 	"github.com/iriam/worklogr/internal/auth"
 	"github.com/iriam/worklogr/internal/config"
 	"github.com/iriam/worklogr/internal/database"
+	"github.com/iriam/worklogr/internal/linker"
+	"github.com/iriam/worklogr/internal/redact"
 	"github.com/iriam/worklogr/internal/services"
 	"github.com/iriam/worklogr/internal/utils"
 )
@@ -23,6 +26,11 @@ type EventCollector struct {
 	timezoneManager *utils.TimezoneManager
 	authManagers    map[string]auth.AuthManager
 	tokenStore      *auth.TokenStore
+	// validateToken と googleToken はスコープ検証で使用します（テストで差し替え可能）
+	validateToken func(ctx context.Context, serviceName, token string) (*auth.AuthStatus, error)
+	googleToken   func() (string, error)
+	// member は収集したイベントに付与するチームメンバー名です（users 設定時のみ）
+	member string
 }
 
 // ServiceClient はすべてのサービスクライアントのインターフェースです
@@ -148,9 +156,38 @@ func (ec *EventCollector) initializeAuthManagers() {
 			MaxRetries:             authConfig.MaxRetries,
 			RetryBackoffMultiplier: authConfig.RetryBackoffMultiplier,
 		}
+		// アクセストークンが設定されていない場合はgcloud認証（ADC）または認証情報ファイルを使用
 		calendarAuth := auth.NewCalendarAuthManager(authAuthConfig, ec.tokenStore, ec.config.GoogleCal.ClientID, ec.config.GoogleCal.ClientSecret)
+		if authAuthConfig.AccessToken == "" {
+			calendarAuth = auth.NewCalendarAuthManagerWithCredentials(authAuthConfig, ec.tokenStore, GoogleCredentialSource(ec.config))
+		}
 		ec.authManagers["calendar"] = calendarAuth
 	}
+
+	// Okta認証マネージャーを初期化（トークンは auth login okta でトークンストアに保存したもの）
+	if ec.config.Okta.Enabled {
+		ec.authManagers["okta"] = auth.NewOktaAuthManager(OktaSettings(ec.config), ec.tokenStore)
+	}
+}
+
+// GoogleCredentialSource は設定ファイルのGoogle認証情報ファイルの設定を返します（未設定の場合はgcloud認証）
+func GoogleCredentialSource(cfg *config.Config) auth.GoogleCredentialSource {
+	return auth.GoogleCredentialSource{
+		CredentialsFile: cfg.GoogleCalendarOptions.CredentialsFile,
+		Subject:         cfg.GoogleCalendarOptions.ImpersonateUser,
+	}
+}
+
+// OktaSettings は設定ファイルのOkta設定を認証マネージャー用の設定に変換します
+func OktaSettings(cfg *config.Config) auth.OktaSettings {
+	return auth.OktaSettings{
+		Domain:              cfg.Okta.Domain,
+		ClientID:            cfg.Okta.ClientID,
+		ClientSecret:        cfg.Okta.ClientSecret,
+		RedirectURI:         cfg.Okta.RedirectURI,
+		AuthorizationServer: cfg.Okta.AuthorizationServer,
+		Scopes:              cfg.Okta.Scopes,
+	}
 }
 
 // InitializeServices は有効なすべてのサービスクライアントを初期化します
@@ -181,7 +218,7 @@ func (ec *EventCollector) InitializeServices() error {
 
 	// Google Calendarクライアントを初期化
 	if ec.config.GoogleCal.Enabled {
-		// gcloud認証のみを使用
+		// gcloud認証（または認証情報ファイル）を使用
 		calendarClient, err := services.NewCalendarClient(ec.config)
 		if err != nil {
 			collectorLogger.Warnf("Google Calendarクライアントの初期化に失敗しました: %v", err)
@@ -245,6 +282,12 @@ func (ec *EventCollector) InitializeServicesFor(serviceNames []string) error {
 			}
 			calendarClient, err := services.NewCalendarClient(ec.config)
 			if err != nil {
+				if ec.config.GoogleCalendarOptions.CredentialsFile != "" {
+					return fmt.Errorf(
+						"google calendarクライアントの初期化に失敗しました: %w\nヒント: google_calendar_options.credentials_file の内容と、impersonate_user を使う場合はドメイン全体の委任の設定を確認してください",
+						err,
+					)
+				}
 				return fmt.Errorf(
 					"google calendarクライアントの初期化に失敗しました: %w\nヒント: `worklogr gcloud status` で状態確認し、必要なら `gcloud auth application-default login` を実行してください",
 					err,
@@ -282,8 +325,27 @@ func prioritizeCalendarService(serviceNames []string) []string {
 
 // CollectEvents は指定された時間範囲内で有効なすべてのサービスからイベントを収集します
 func (ec *EventCollector) CollectEvents(startTime, endTime time.Time, serviceNames []string) ([]*config.Event, error) {
+	byService, _, err := ec.collectByService(startTime, endTime, serviceNames)
+	if err != nil {
+		return nil, err
+	}
+
 	var allEvents []*config.Event
+	for _, events := range byService {
+		allEvents = append(allEvents, events...)
+	}
+
+	// イベントをタイムスタンプでソート
+	ec.sortEventsByTimestamp(allEvents)
+
+	collectorLogger.Infof("収集完了: 合計 %d 件", len(allEvents))
+	return allEvents, nil
+}
 
+// collectByService は各サービスから並列にイベントを収集し、収集に成功したサービスごとの結果を返します。
+// 失敗したサービスは結果に含まれません。一部の取得だけに失敗したサービスは、取得できたイベントを
+// 結果に含めたうえで partial に記録します。
+func (ec *EventCollector) collectByService(startTime, endTime time.Time, serviceNames []string) (map[string][]*config.Event, map[string]bool, error) {
 	// 収集対象のサービスを決定
 	servicesToCollect := ec.services
 	if len(serviceNames) > 0 {
@@ -298,7 +360,7 @@ func (ec *EventCollector) CollectEvents(startTime, endTime time.Time, serviceNam
 	}
 
 	if len(servicesToCollect) == 0 {
-		return nil, fmt.Errorf("収集可能なサービスがありません")
+		return nil, nil, fmt.Errorf("収集可能なサービスがありません")
 	}
 
 	collectorLogger.Infof(
@@ -310,6 +372,8 @@ func (ec *EventCollector) CollectEvents(startTime, endTime time.Time, serviceNam
 	// 各サービスからイベントを並列収集
 	var wg sync.WaitGroup
 	var mu sync.Mutex
+	byService := make(map[string][]*config.Event)
+	partial := make(map[string]bool)
 
 	for serviceName, client := range servicesToCollect {
 		serviceName := serviceName
@@ -323,7 +387,10 @@ func (ec *EventCollector) CollectEvents(startTime, endTime time.Time, serviceNam
 			serviceLogger.Infof("イベント収集を開始します")
 
 			events, err := client.CollectEvents(startTime, endTime)
-			if err != nil {
+			var partialErr *services.PartialCollectionError
+			if errors.As(err, &partialErr) {
+				serviceLogger.Warnf("一部の取得に失敗したため、見つからないイベントの削除済み記録は行いません: %v", err)
+			} else if err != nil {
 				serviceLogger.Errorf("イベント収集に失敗しました: %v", err)
 				return
 			}
@@ -331,30 +398,52 @@ func (ec *EventCollector) CollectEvents(startTime, endTime time.Time, serviceNam
 			serviceLogger.Infof("%d 件のイベントを収集しました", len(events))
 
 			mu.Lock()
-			allEvents = append(allEvents, events...)
+			byService[serviceName] = events
+			if partialErr != nil {
+				partial[serviceName] = true
+			}
 			mu.Unlock()
 		}()
 	}
 
 	wg.Wait()
+	return byService, partial, nil
+}
 
-	// イベントをタイムスタンプでソート
-	ec.sortEventsByTimestamp(allEvents)
-
-	collectorLogger.Infof("収集完了: 合計 %d 件", len(allEvents))
-	return allEvents, nil
+// SetMember は以降に保存するイベントを指定したチームメンバーのものとして記録します
+func (ec *EventCollector) SetMember(name string) {
+	ec.member = name
 }
 
 // CollectAndStore はイベントを収集してデータベースに保存します
 func (ec *EventCollector) CollectAndStore(startTime, endTime time.Time, serviceNames []string) error {
-	events, err := ec.CollectEvents(startTime, endTime, serviceNames)
+	byService, partial, err := ec.collectByService(startTime, endTime, serviceNames)
 	if err != nil {
 		return fmt.Errorf("イベント収集に失敗しました: %w", err)
 	}
 
+	var events []*config.Event
+	for _, serviceEvents := range byService {
+		ec.tagMember(serviceEvents)
+		events = append(events, serviceEvents...)
+	}
+	ec.sortEventsByTimestamp(events)
+	collectorLogger.Infof("収集完了: 合計 %d 件", len(events))
+
 	if len(events) == 0 {
 		collectorLogger.Infof("指定された時間範囲でイベントは見つかりませんでした")
-		return nil
+		return ec.markMissingDeleted(startTime, endTime, byService, partial)
+	}
+
+	// 保存前に秘匿情報をマスキング（stage: collect / both）
+	if ec.config.Redaction.AppliesAtCollect() {
+		redactor, err := redact.New(ec.config.Redaction)
+		if err != nil {
+			return fmt.Errorf("redaction設定が無効です: %w", err)
+		}
+		if stats := redactor.RedactEvents(events); stats.Total() > 0 {
+			collectorLogger.Infof("保存前に %d 件の秘匿情報をマスキングしました (%s)", stats.Total(), stats)
+		}
 	}
 
 	// イベントをデータベースに保存
@@ -364,6 +453,95 @@ func (ec *EventCollector) CollectAndStore(startTime, endTime time.Time, serviceN
 	}
 
 	collectorLogger.Infof("イベントを保存しました")
+
+	if err := ec.markMissingDeleted(startTime, endTime, byService, partial); err != nil {
+		return err
+	}
+
+	// 保存済みイベントを対象にサービス横断のリンク検出を実行
+	if ec.config.Linking.IsEnabled() {
+		if err := ec.LinkEvents(startTime, endTime); err != nil {
+			// リンク検出の失敗で収集結果を無効にはしない
+			collectorLogger.Warnf("イベントのリンク検出に失敗しました: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// markMissingDeleted は収集に成功したサービスについて、保存済みなのに今回の収集で返されなかった
+// イベントに deleted_at を記録します（元のサービスで削除されたものとみなします）。
+// 収集に失敗したサービスと、一部の取得に失敗した（partial）サービスのイベントは対象にしません。
+func (ec *EventCollector) markMissingDeleted(startTime, endTime time.Time, byService map[string][]*config.Event, partial map[string]bool) error {
+	for serviceName, events := range byService {
+		if partial[serviceName] {
+			continue
+		}
+		ids := make([]string, 0, len(events))
+		for _, event := range events {
+			ids = append(ids, event.ID)
+		}
+		marked, err := ec.db.MarkMissingDeleted(serviceName, ec.member, startTime, endTime, ids)
+		if err != nil {
+			return fmt.Errorf("削除済みイベントの記録に失敗しました (%s): %w", serviceName, err)
+		}
+		if marked > 0 {
+			collectorLogger.Infof("%s: 収集元で見つからなくなった %d 件のイベントを削除済みとして記録しました", serviceName, marked)
+		}
+	}
+	return nil
+}
+
+// tagMember はイベントにメンバー名を付与します。
+// 同じ会議やメッセージを複数メンバーが収集しても上書きされないよう、IDもメンバー名で区別します。
+func (ec *EventCollector) tagMember(events []*config.Event) {
+	if ec.member == "" {
+		return
+	}
+	for _, event := range events {
+		event.Member = ec.member
+		event.ID = memberEventID(ec.member, event.ID)
+	}
+}
+
+// memberEventID はメンバーごとに区別したイベントIDを返します
+func memberEventID(member, id string) string {
+	if member == "" {
+		return id
+	}
+	return member + "/" + id
+}
+
+// LinkEvents は保存済みイベント間の参照（Issueキー、PR URL、コミットSHA等）を検出して event_links に保存します。
+// 収集期間の前後 window_days 日分のイベントも対象に含め、期間をまたぐ参照も検出します。
+func (ec *EventCollector) LinkEvents(startTime, endTime time.Time) error {
+	window := time.Duration(ec.config.Linking.EffectiveWindowDays()) * 24 * time.Hour
+	events, err := ec.db.GetEvents(startTime.Add(-window), endTime.Add(window), nil)
+	if err != nil {
+		return fmt.Errorf("リンク対象イベントの取得に失敗しました: %w", err)
+	}
+
+	links := linker.NewLinker(ec.config.Linking).FindLinks(events)
+
+	eventIDs := make([]string, 0, len(events))
+	for _, event := range events {
+		eventIDs = append(eventIDs, event.ID)
+	}
+	records := make([]database.EventLinkRecord, 0, len(links))
+	for _, link := range links {
+		records = append(records, database.EventLinkRecord{
+			SourceID: link.SourceID,
+			TargetID: link.TargetID,
+			Relation: link.Relation,
+			Key:      link.Key,
+		})
+	}
+
+	if err := ec.db.ReplaceEventLinks(eventIDs, records); err != nil {
+		return fmt.Errorf("リンクの保存に失敗しました: %w", err)
+	}
+
+	collectorLogger.Infof("%d 件のイベント間リンクを検出しました", len(records))
 	return nil
 }
 
@@ -648,6 +826,9 @@ func (ec *EventCollector) UpdateAuthConfig(serviceName string, authConfig *auth.
 		ec.config.GitHub.UpdateFromAuthConfig(configAuthConfig)
 	case "calendar":
 		ec.config.GoogleCal.UpdateFromAuthConfig(configAuthConfig)
+	case "okta":
+		// Oktaのトークンはトークンストアで管理するため設定ファイルの値は更新しない
+		return nil
 	default:
 		return fmt.Errorf("未知のサービス: %s", serviceName)
 	}
@@ -1,12 +1,17 @@
 package collector
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/iriam/worklogr/internal/auth"
 	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/database"
+	"github.com/iriam/worklogr/internal/services"
 )
 
 type mockServiceClient struct {
@@ -14,6 +19,8 @@ type mockServiceClient struct {
 	err    error
 	delay  time.Duration
 	called int32
+	// failures が設定されていると、events とともに部分的な失敗を返します
+	failures []string
 }
 
 func (m *mockServiceClient) CollectEvents(startTime, endTime time.Time) ([]*config.Event, error) {
@@ -26,6 +33,9 @@ func (m *mockServiceClient) CollectEvents(startTime, endTime time.Time) ([]*conf
 	if m.err != nil {
 		return nil, m.err
 	}
+	if len(m.failures) > 0 {
+		return m.events, &services.PartialCollectionError{Failures: m.failures}
+	}
 
 	return m.events, nil
 }
@@ -148,3 +158,178 @@ func TestCollectEventsRespectsServiceFilter(t *testing.T) {
 		t.Fatalf("expected github service not called, got %d", got)
 	}
 }
+
+func TestVerifyScopesReportsMissingScopesPerFeature(t *testing.T) {
+	ec := &EventCollector{
+		config: &config.Config{
+			Slack:     config.ServiceConfig{Enabled: true, AccessToken: "xoxp-token"},
+			GitHub:    config.ServiceConfig{Enabled: true, AccessToken: "github_pat_token"},
+			GoogleCal: config.ServiceConfig{Enabled: true},
+		},
+		validateToken: func(ctx context.Context, serviceName, token string) (*auth.AuthStatus, error) {
+			switch serviceName {
+			case "slack":
+				return &auth.AuthStatus{IsValid: true, GrantedScopes: []string{"users:read"}}, nil
+			case "github":
+				// fine-grained token は付与スコープを返さない
+				return &auth.AuthStatus{IsValid: true}, nil
+			case "calendar":
+				if token != "gcloud-token" {
+					t.Fatalf("expected gcloud token for calendar, got %q", token)
+				}
+				return &auth.AuthStatus{IsValid: true, GrantedScopes: []string{"https://www.googleapis.com/auth/calendar.readonly"}}, nil
+			}
+			return nil, fmt.Errorf("unexpected service %s", serviceName)
+		},
+		googleToken: func() (string, error) { return "gcloud-token", nil },
+	}
+
+	reports := ec.VerifyScopes(context.Background(), []string{"slack", "github", "google_calendar"})
+	if len(reports) != 3 {
+		t.Fatalf("expected 3 reports, got %+v", reports)
+	}
+
+	slack := reports[0]
+	if !slack.Checked || len(slack.Missing) != 1 || slack.Missing[0].Scopes[0] != "search:read" {
+		t.Fatalf("expected slack to miss search:read, got %+v", slack)
+	}
+	if github := reports[1]; github.Checked || !github.OK() {
+		t.Fatalf("expected github scopes to be unchecked, got %+v", github)
+	}
+	calendar := reports[2]
+	if len(calendar.Missing) != 1 || calendar.Missing[0].Scopes[0] != "drive.readonly" {
+		t.Fatalf("expected calendar to miss drive.readonly for attachments, got %+v", calendar)
+	}
+
+	disabled := false
+	ec.config.GoogleCalendarOptions.FetchDriveAttachments = &disabled
+	reports = ec.VerifyScopes(context.Background(), []string{"google_calendar"})
+	if !reports[0].OK() {
+		t.Fatalf("expected drive.readonly not to be required when attachments are disabled, got %+v", reports[0])
+	}
+}
+
+func TestCollectAndStoreTagsEventsWithMember(t *testing.T) {
+	base := time.Date(2026, 2, 23, 10, 0, 0, 0, time.UTC)
+	db, err := database.NewDatabaseManager(filepath.Join(t.TempDir(), "collect.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	ec := NewEventCollector(&config.Config{Timezone: "UTC"}, db)
+	for _, member := range []string{"alice", "bob"} {
+		// 同じ会議を2人が収集するケース
+		ec.services = map[string]ServiceClient{
+			"test": &mockServiceClient{events: []*config.Event{makeEvent("meeting-1", base)}},
+		}
+		ec.SetMember(member)
+		if err := ec.CollectAndStore(base.Add(-time.Hour), base.Add(time.Hour), nil); err != nil {
+			t.Fatalf("CollectAndStore returned error: %v", err)
+		}
+	}
+
+	events, err := db.GetEvents(base.Add(-time.Hour), base.Add(time.Hour), nil)
+	if err != nil {
+		t.Fatalf("GetEvents returned error: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected one event per member, got %d", len(events))
+	}
+	got := map[string]string{}
+	for _, event := range events {
+		got[event.Member] = event.ID
+	}
+	if got["alice"] != "alice/meeting-1" || got["bob"] != "bob/meeting-1" {
+		t.Fatalf("unexpected member event IDs: %v", got)
+	}
+}
+
+func TestCollectAndStoreMarksEventsMissingFromSourceDeleted(t *testing.T) {
+	base := time.Date(2026, 2, 23, 10, 0, 0, 0, time.UTC)
+	db, err := database.NewDatabaseManager(filepath.Join(t.TempDir(), "collect.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	other := makeEvent("other-1", base)
+	other.Service = "other"
+	ec := NewEventCollector(&config.Config{Timezone: "UTC"}, db)
+	ec.services = map[string]ServiceClient{
+		"test":  &mockServiceClient{events: []*config.Event{makeEvent("meeting-1", base), makeEvent("meeting-2", base.Add(time.Minute))}},
+		"other": &mockServiceClient{events: []*config.Event{other}},
+	}
+	if err := ec.CollectAndStore(base.Add(-time.Hour), base.Add(time.Hour), nil); err != nil {
+		t.Fatalf("CollectAndStore returned error: %v", err)
+	}
+
+	// meeting-2 がキャンセルされ、other の収集は失敗したケース
+	ec.services = map[string]ServiceClient{
+		"test":  &mockServiceClient{events: []*config.Event{makeEvent("meeting-1", base)}},
+		"other": &mockServiceClient{err: fmt.Errorf("rate limited")},
+	}
+	if err := ec.CollectAndStore(base.Add(-time.Hour), base.Add(time.Hour), nil); err != nil {
+		t.Fatalf("CollectAndStore returned error: %v", err)
+	}
+
+	events, err := db.QueryEvents(base.Add(-time.Hour), base.Add(time.Hour), database.EventFilter{IncludeDeleted: true})
+	if err != nil {
+		t.Fatalf("QueryEvents returned error: %v", err)
+	}
+	deleted := map[string]bool{}
+	for _, event := range events {
+		deleted[event.ID] = event.DeletedAt != nil
+	}
+	want := map[string]bool{"meeting-1": false, "meeting-2": true, "other-1": false}
+	if fmt.Sprint(deleted) != fmt.Sprint(want) {
+		t.Fatalf("expected deleted flags %v, got %v", want, deleted)
+	}
+}
+
+func TestCollectAndStoreDoesNotMarkDeletedOnPartialCollection(t *testing.T) {
+	base := time.Date(2026, 2, 23, 10, 0, 0, 0, time.UTC)
+	db, err := database.NewDatabaseManager(filepath.Join(t.TempDir(), "collect.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	commit := makeEvent("commit-1", base)
+	issue := makeEvent("issue-1", base.Add(time.Minute))
+	commit.Service, issue.Service = "github", "github"
+	ec := NewEventCollector(&config.Config{Timezone: "UTC"}, db)
+	ec.services = map[string]ServiceClient{
+		"github": &mockServiceClient{events: []*config.Event{commit, issue}},
+	}
+	if err := ec.CollectAndStore(base.Add(-time.Hour), base.Add(time.Hour), nil); err != nil {
+		t.Fatalf("CollectAndStore returned error: %v", err)
+	}
+
+	// Issue検索だけが失敗し、コミットだけが返されたケース
+	updated := makeEvent("commit-1", base)
+	updated.Service = "github"
+	updated.Title = "updated title"
+	ec.services = map[string]ServiceClient{
+		"github": &mockServiceClient{events: []*config.Event{updated}, failures: []string{"Issue検索: rate limited"}},
+	}
+	if err := ec.CollectAndStore(base.Add(-time.Hour), base.Add(time.Hour), nil); err != nil {
+		t.Fatalf("CollectAndStore returned error: %v", err)
+	}
+
+	events, err := db.QueryEvents(base.Add(-time.Hour), base.Add(time.Hour), database.EventFilter{IncludeDeleted: true})
+	if err != nil {
+		t.Fatalf("QueryEvents returned error: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected both stored events, got %d", len(events))
+	}
+	for _, event := range events {
+		if event.DeletedAt != nil {
+			t.Fatalf("expected %s not to be marked deleted after a partial collection", event.ID)
+		}
+		if event.ID == "commit-1" && event.Title != "updated title" {
+			t.Fatalf("expected events from a partial collection to be stored, got title %q", event.Title)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/iriam/worklogr/internal/auth"
+	"github.com/iriam/worklogr/internal/config"
+)
+
+// ScopeRequirements は指定サービスの収集機能が必要とするスコープを返します
+func ScopeRequirements(cfg *config.Config, serviceNames []string) []auth.ScopeRequirement {
+	var requirements []auth.ScopeRequirement
+	for _, serviceName := range serviceNames {
+		switch serviceName {
+		case "slack":
+			requirements = append(requirements, auth.ScopeRequirement{
+				Service: "slack", Feature: "メッセージ検索", Scopes: []string{"search:read"},
+			})
+		case "github":
+			requirements = append(requirements, auth.ScopeRequirement{
+				Service: "github", Feature: "コミット・PR・Issue・リリースの取得（プライベートリポジトリを含む）", Scopes: []string{"repo"},
+			})
+		case "google_calendar":
+			requirements = append(requirements, auth.ScopeRequirement{
+				Service: "google_calendar", Feature: "カレンダー予定の取得", Scopes: []string{"calendar.readonly"},
+			})
+			if cfg.GoogleCalendarOptions.ShouldFetchDriveAttachments() {
+				requirements = append(requirements, auth.ScopeRequirement{
+					Service: "google_calendar", Feature: "添付ドキュメント本文の取得（google_calendar_options.fetch_drive_attachments）", Scopes: []string{"drive.readonly"},
+				})
+			}
+		}
+	}
+	return requirements
+}
+
+// VerifyScopes は収集前に各サービスのトークンの付与スコープを検証します。
+// 付与スコープを取得できないトークン（GitHubのfine-grained tokenなど）は Checked=false になります。
+func (ec *EventCollector) VerifyScopes(ctx context.Context, serviceNames []string) []auth.ScopeReport {
+	if len(serviceNames) == 0 {
+		serviceNames = ec.GetEnabledServices()
+	}
+	requirements := ScopeRequirements(ec.config, serviceNames)
+
+	validate := ec.validateToken
+	if validate == nil {
+		validate = auth.NewTokenValidator().ValidateTokenByService
+	}
+	googleToken := ec.googleToken
+	if googleToken == nil {
+		googleToken = func() (string, error) { return googleAccessToken(ec.config) }
+	}
+
+	reports := make([]auth.ScopeReport, 0, len(serviceNames))
+	for _, serviceName := range serviceNames {
+		report := auth.ScopeReport{Service: serviceName}
+
+		var validatorName, token string
+		switch serviceName {
+		case "slack":
+			validatorName, token = "slack", ec.config.Slack.AccessToken
+		case "github":
+			validatorName, token = "github", ec.config.GitHub.AccessToken
+		case "google_calendar":
+			validatorName, token = "calendar", ec.config.GoogleCal.AccessToken
+			if token == "" {
+				gcloudToken, err := googleToken()
+				if err != nil {
+					report.Error = err.Error()
+					reports = append(reports, report)
+					continue
+				}
+				token = gcloudToken
+			}
+		default:
+			continue
+		}
+		if token == "" {
+			report.Error = "アクセストークンが設定されていません"
+			reports = append(reports, report)
+			continue
+		}
+
+		status, err := validate(ctx, validatorName, token)
+		switch {
+		case err != nil:
+			report.Error = err.Error()
+		case !status.IsValid:
+			report.Error = status.ErrorMessage
+		case status.GrantedScopes != nil:
+			report.Checked = true
+			report.Granted = status.GrantedScopes
+			report.Missing = auth.CheckScopes(serviceName, status.GrantedScopes, requirements)
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// googleAccessToken はgcloud認証（ADC）または認証情報ファイルからアクセストークンを取得します
+func googleAccessToken(cfg *config.Config) (string, error) {
+	calendarAuth := auth.NewCalendarAuthManagerWithCredentials(&auth.AuthConfig{}, nil, GoogleCredentialSource(cfg))
+	token, err := calendarAuth.GetCalendarToken()
+	if err != nil {
+		return "", fmt.Errorf("google認証のトークン取得に失敗しました: %w", err)
+	}
+	return token.AccessToken, nil
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/iriam/worklogr/internal/secrets"
 	"github.com/iriam/worklogr/internal/utils"
 	"gopkg.in/yaml.v3"
 )
@@ -20,6 +21,9 @@ type ServiceConfig struct {
 	TokenExpiresAt         *time.Time    `yaml:"token_expires_at,omitempty"`
 	Enabled                bool          `yaml:"enabled"`
 	UseOkta                bool          `yaml:"use_okta"`
+	// OktaAudience is the audience requested when exchanging the Okta token for this service (use_okta)
+	OktaAudience           string        `yaml:"okta_audience,omitempty"`
+	OktaScopes             []string      `yaml:"okta_scopes,omitempty"`
 	AutoRefresh            bool          `yaml:"auto_refresh"`
 	ValidationInterval     time.Duration `yaml:"validation_interval"`
 	MaxRetries             int           `yaml:"max_retries"`
@@ -33,6 +37,9 @@ type OktaConfig struct {
 	ClientSecret string `yaml:"client_secret"`
 	RedirectURI  string `yaml:"redirect_uri"`
 	Enabled      bool   `yaml:"enabled"`
+	// AuthorizationServer is the custom authorization server ID ("default" when empty, "org" for the org server)
+	AuthorizationServer string   `yaml:"authorization_server,omitempty"`
+	Scopes              []string `yaml:"scopes,omitempty"`
 }
 
 // Config holds the application configuration
@@ -41,9 +48,21 @@ type Config struct {
 	GitHub       ServiceConfig `yaml:"github"`
 	GoogleCal    ServiceConfig `yaml:"google_calendar"`
 	GoogleCalendarOptions GoogleCalendarOptions `yaml:"google_calendar_options"`
+	Linking      LinkingOptions `yaml:"linking"`
+	Redaction    RedactionOptions `yaml:"redaction"`
+	Retention    RetentionOptions `yaml:"retention"`
+	Encryption   EncryptionConfig `yaml:"encryption"`
+	LLM          LLMConfig     `yaml:"llm"`
+	Secrets      SecretsConfig `yaml:"secrets"`
+	TokenStore   TokenStoreConfig `yaml:"token_store"`
 	Okta         OktaConfig    `yaml:"okta"`
+	// Users lists team members collected into the same database (optional)
+	Users        []UserConfig  `yaml:"users,omitempty"`
 	DatabasePath string        `yaml:"database_path"`
 	Timezone     string        `yaml:"timezone"`
+
+	// secretRefs maps field paths to the secret:// references they were loaded from.
+	secretRefs map[string]string
 }
 
 // GoogleCalendarOptions controls optional calendar collection behavior.
@@ -51,6 +70,12 @@ type Config struct {
 type GoogleCalendarOptions struct {
 	FetchDriveAttachments  *bool `yaml:"fetch_drive_attachments"`
 	AttachmentTextMaxChars int   `yaml:"attachment_text_max_chars"`
+	// CredentialsFile is a service-account key or external-account (workload identity federation)
+	// JSON file used instead of gcloud ADC, for headless runs.
+	CredentialsFile string `yaml:"credentials_file,omitempty"`
+	// ImpersonateUser is the user whose calendar is read via domain-wide delegation
+	// (service-account keys only).
+	ImpersonateUser string `yaml:"impersonate_user,omitempty"`
 }
 
 func (o GoogleCalendarOptions) ShouldFetchDriveAttachments() bool {
@@ -67,6 +92,264 @@ func (o GoogleCalendarOptions) EffectiveAttachmentTextMaxChars() int {
 	return o.AttachmentTextMaxChars
 }
 
+// LinkingOptions controls the post-collection cross-service linking pass.
+// Note: Enabled defaults to true when omitted.
+type LinkingOptions struct {
+	Enabled *bool `yaml:"enabled"`
+	// IssueKeyPrefixes restricts issue-key detection (e.g. "PROJ" for PROJ-123).
+	// When empty, any uppercase key of the form ABC-123 is accepted.
+	IssueKeyPrefixes []string `yaml:"issue_key_prefixes"`
+	// WindowDays extends the range of stored events considered for linking.
+	WindowDays int `yaml:"window_days"`
+}
+
+func (o LinkingOptions) IsEnabled() bool {
+	if o.Enabled == nil {
+		return true
+	}
+	return *o.Enabled
+}
+
+func (o LinkingOptions) EffectiveWindowDays() int {
+	if o.WindowDays <= 0 {
+		return 7
+	}
+	return o.WindowDays
+}
+
+// RedactionOptions controls scrubbing of secrets and PII from event text.
+// Note: Enabled defaults to false and Stage defaults to "export" when omitted.
+type RedactionOptions struct {
+	Enabled *bool `yaml:"enabled"`
+	// Stage selects where redaction runs: "collect" (before storage), "export", or "both".
+	Stage string `yaml:"stage"`
+	// Detectors lists built-in detectors to run (email, phone, api_key, secret_url).
+	// When empty, all built-in detectors are used.
+	Detectors []string `yaml:"detectors"`
+	// Rules are additional regex rules applied after the built-in detectors.
+	Rules []RedactionRule `yaml:"rules"`
+	// DropFields removes fields per service. "title", "content" and "attachments"
+	// clear the event fields; any other name is treated as a metadata key.
+	DropFields map[string][]string `yaml:"drop_fields"`
+}
+
+// RedactionRule is a user-defined regex redaction rule.
+type RedactionRule struct {
+	Name        string `yaml:"name"`
+	Pattern     string `yaml:"pattern"`
+	Replacement string `yaml:"replacement"`
+}
+
+const (
+	RedactionStageCollect = "collect"
+	RedactionStageExport  = "export"
+	RedactionStageBoth    = "both"
+)
+
+func (o RedactionOptions) IsEnabled() bool {
+	if o.Enabled == nil {
+		return false
+	}
+	return *o.Enabled
+}
+
+func (o RedactionOptions) EffectiveStage() string {
+	switch o.Stage {
+	case RedactionStageCollect, RedactionStageBoth:
+		return o.Stage
+	default:
+		return RedactionStageExport
+	}
+}
+
+// AppliesAtCollect reports whether events are redacted before they are stored.
+func (o RedactionOptions) AppliesAtCollect() bool {
+	stage := o.EffectiveStage()
+	return o.IsEnabled() && (stage == RedactionStageCollect || stage == RedactionStageBoth)
+}
+
+// AppliesAtExport reports whether events are redacted when they are exported.
+func (o RedactionOptions) AppliesAtExport() bool {
+	stage := o.EffectiveStage()
+	return o.IsEnabled() && (stage == RedactionStageExport || stage == RedactionStageBoth)
+}
+
+// RetentionOptions controls how long collected data is kept. `worklogr prune` applies it.
+// A value of 0 (or omitted) keeps data forever; in rules it inherits the less specific setting.
+type RetentionOptions struct {
+	// EventDays deletes events (with their attachments, links and annotations) older than this.
+	EventDays int `yaml:"event_days"`
+	// AttachmentTextDays clears the stored attachment text of older events, keeping the events.
+	AttachmentTextDays int `yaml:"attachment_text_days"`
+	// Rules override the defaults for a service, or for one event type of a service.
+	Rules []RetentionRule `yaml:"rules"`
+}
+
+// RetentionRule is a per-service (and optionally per-type) retention override.
+type RetentionRule struct {
+	Service            string `yaml:"service"`
+	Type               string `yaml:"type"`
+	EventDays          int    `yaml:"event_days"`
+	AttachmentTextDays int    `yaml:"attachment_text_days"`
+}
+
+// IsConfigured reports whether any retention period is set.
+func (o RetentionOptions) IsConfigured() bool {
+	for _, rule := range o.Resolved() {
+		if rule.EventDays > 0 || rule.AttachmentTextDays > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that rules name a service, days are not negative and no rule is repeated.
+func (o RetentionOptions) Validate() error {
+	if o.EventDays < 0 || o.AttachmentTextDays < 0 {
+		return fmt.Errorf("retention days must not be negative")
+	}
+	seen := make(map[string]bool, len(o.Rules))
+	for i, rule := range o.Rules {
+		if rule.Service == "" {
+			return fmt.Errorf("retention.rules[%d]: service is required", i)
+		}
+		if rule.EventDays < 0 || rule.AttachmentTextDays < 0 {
+			return fmt.Errorf("retention.rules[%d]: days must not be negative", i)
+		}
+		key := rule.Service + "/" + rule.Type
+		if seen[key] {
+			return fmt.Errorf("retention.rules[%d]: duplicate rule for %s", i, key)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// Resolved returns the rules with inherited periods filled in, most specific first:
+// service+type rules, then service rules, then the default (empty Service) last.
+func (o RetentionOptions) Resolved() []RetentionRule {
+	defaults := RetentionRule{EventDays: o.EventDays, AttachmentTextDays: o.AttachmentTextDays}
+	services := make(map[string]RetentionRule)
+	for _, rule := range o.Rules {
+		if rule.Type == "" {
+			services[rule.Service] = inheritRetention(rule, defaults)
+		}
+	}
+
+	var typed, untyped []RetentionRule
+	for _, rule := range o.Rules {
+		if rule.Type == "" {
+			untyped = append(untyped, services[rule.Service])
+			continue
+		}
+		parent, ok := services[rule.Service]
+		if !ok {
+			parent = defaults
+		}
+		typed = append(typed, inheritRetention(rule, parent))
+	}
+	return append(append(typed, untyped...), defaults)
+}
+
+func inheritRetention(rule, parent RetentionRule) RetentionRule {
+	if rule.EventDays == 0 {
+		rule.EventDays = parent.EventDays
+	}
+	if rule.AttachmentTextDays == 0 {
+		rule.AttachmentTextDays = parent.AttachmentTextDays
+	}
+	return rule
+}
+
+// EncryptionConfig enables application-level AES-GCM encryption of event content,
+// metadata and attachment text stored in the database.
+// Note: Enabled defaults to false when omitted.
+type EncryptionConfig struct {
+	Enabled *bool `yaml:"enabled"`
+	// Key is the passphrase the encryption key is derived from, usually a secret:// reference.
+	Key string `yaml:"key"`
+}
+
+func (c EncryptionConfig) IsEnabled() bool {
+	if c.Enabled == nil {
+		return false
+	}
+	return *c.Enabled
+}
+
+// Passphrase returns the key when encryption is enabled and "" otherwise.
+func (c EncryptionConfig) Passphrase() string {
+	if !c.IsEnabled() {
+		return ""
+	}
+	return c.Key
+}
+
+// LLMConfig holds the LLM endpoint used by the summarize command.
+type LLMConfig struct {
+	// Provider is one of "openai" (OpenAI-compatible), "gemini" or "ollama".
+	Provider string `yaml:"provider"`
+	BaseURL  string `yaml:"base_url"`
+	Model    string `yaml:"model"`
+	APIKey   string `yaml:"api_key"`
+	// APIKeyEnv names an environment variable holding the API key.
+	APIKeyEnv string `yaml:"api_key_env"`
+	// MaxInputTokens is the estimated token budget for a single request.
+	MaxInputTokens int           `yaml:"max_input_tokens"`
+	Timeout        time.Duration `yaml:"timeout"`
+}
+
+func (c LLMConfig) EffectiveMaxInputTokens() int {
+	if c.MaxInputTokens <= 0 {
+		return 24000
+	}
+	return c.MaxInputTokens
+}
+
+func (c LLMConfig) EffectiveTimeout() time.Duration {
+	if c.Timeout <= 0 {
+		return 120 * time.Second
+	}
+	return c.Timeout
+}
+
+// ResolveAPIKey returns the API key, preferring APIKeyEnv when it is set.
+func (c LLMConfig) ResolveAPIKey() string {
+	if c.APIKeyEnv != "" {
+		if value := os.Getenv(c.APIKeyEnv); value != "" {
+			return value
+		}
+	}
+	return c.APIKey
+}
+
+// TokenStoreConfig holds where OAuth tokens obtained by `auth login` are stored.
+type TokenStoreConfig struct {
+	// Path is the encrypted token file (default: ~/.worklogr/tokens.json).
+	Path string `yaml:"path"`
+	// PassphraseEnv names the environment variable holding the passphrase
+	// (default: WORKLOGR_SECRET_PASSPHRASE, shared with the secret file).
+	PassphraseEnv string `yaml:"passphrase_env"`
+}
+
+func (c TokenStoreConfig) EffectivePath() (string, error) {
+	if c.Path != "" {
+		return c.Path, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".worklogr", "tokens.json"), nil
+}
+
+func (c TokenStoreConfig) EffectivePassphraseEnv() string {
+	if c.PassphraseEnv == "" {
+		return secrets.DefaultPassphraseEnv
+	}
+	return c.PassphraseEnv
+}
+
 // Event represents a collected event from any service
 type Event struct {
 	ID        string    `json:"id" db:"id"`
@@ -77,8 +360,40 @@ type Event struct {
 	Timestamp time.Time `json:"timestamp" db:"timestamp"`
 	Metadata  string    `json:"metadata" db:"metadata"`
 	UserID    string    `json:"user_id" db:"user_id"`
+	// Member is the configured team member (users[].name) the event was collected for.
+	Member    string    `json:"member,omitempty" db:"member"`
+	// DeletedAt is set when a re-collection no longer found the event at its source.
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	// Common fields lifted from Metadata into indexed columns (see package eventmeta).
+	URL              string `json:"url,omitempty" db:"url"`
+	Repository       string `json:"repository,omitempty" db:"repository"`
+	Channel          string `json:"channel,omitempty" db:"channel"`
+	ChannelID        string `json:"channel_id,omitempty" db:"channel_id"`
+	ParticipantCount int    `json:"participant_count,omitempty" db:"participant_count"`
+	DurationMinutes  int    `json:"duration_minutes,omitempty" db:"duration_minutes"`
 	// Attachments are stored separately (see DB table event_attachments).
 	Attachments []EventAttachment `json:"attachments,omitempty" db:"-"`
+	// Related holds links to other events (see DB table event_links).
+	Related []EventLink `json:"related,omitempty" db:"-"`
+	// Annotation holds user curation (see DB table event_annotations).
+	Annotation *EventAnnotation `json:"annotation,omitempty" db:"-"`
+}
+
+// EventAnnotation is user-owned curation of an event. It is stored apart from the
+// event row so that re-collecting the event keeps it.
+type EventAnnotation struct {
+	// Hidden events are dropped from every export.
+	Hidden bool `json:"hidden,omitempty" db:"hidden"`
+	// Pinned marks the event as important for the report.
+	Pinned    bool      `json:"pinned,omitempty" db:"pinned"`
+	Note      string    `json:"note,omitempty" db:"note"`
+	Tags      []string  `json:"tags,omitempty" db:"tags"`
+	UpdatedAt time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// IsEmpty reports whether the annotation carries no curation.
+func (a *EventAnnotation) IsEmpty() bool {
+	return a == nil || (!a.Hidden && !a.Pinned && a.Note == "" && len(a.Tags) == 0)
 }
 
 // EventAttachment represents an attachment associated with an event.
@@ -92,26 +407,35 @@ type EventAttachment struct {
 	Truncated bool   `json:"truncated,omitempty" db:"truncated"`
 }
 
-// LoadConfig loads configuration from the specified file
+// EventLink represents a detected reference between two events, seen from
+// the perspective of the event that holds it.
+type EventLink struct {
+	EventID   string    `json:"event_id" db:"target_event_id"`
+	Service   string    `json:"service,omitempty" db:"service"`
+	Type      string    `json:"type,omitempty" db:"type"`
+	Title     string    `json:"title,omitempty" db:"title"`
+	Timestamp time.Time `json:"timestamp,omitempty" db:"timestamp"`
+	Relation  string    `json:"relation" db:"relation"`
+	Key       string    `json:"key" db:"ref_key"`
+}
+
+// LoadConfig loads configuration from the specified file and resolves secret:// references
 func LoadConfig(configPath string) (*Config, error) {
-	if configPath == "" {
-		// First try current directory
-		currentDir, err := os.Getwd()
-		if err == nil {
-			localConfigPath := filepath.Join(currentDir, "config.yaml")
-			if _, err := os.Stat(localConfigPath); err == nil {
-				configPath = localConfigPath
-			}
-		}
-		
-		// Fall back to home directory if no local config found
-		if configPath == "" {
-			homeDir, err := os.UserHomeDir()
-			if err != nil {
-				return nil, fmt.Errorf("failed to get home directory: %w", err)
-			}
-			configPath = filepath.Join(homeDir, ".worklogr", "config.yaml")
-		}
+	config, err := LoadConfigUnresolved(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.ResolveSecrets(secrets.Open); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// LoadConfigUnresolved loads configuration without resolving secret:// references
+func LoadConfigUnresolved(configPath string) (*Config, error) {
+	configPath, err := ResolveConfigPath(configPath)
+	if err != nil {
+		return nil, err
 	}
 
 	data, err := os.ReadFile(configPath)
@@ -146,9 +470,37 @@ func LoadConfig(configPath string) (*Config, error) {
 		config.GoogleCalendarOptions.AttachmentTextMaxChars = 100000
 	}
 
+	if err := config.ValidateUsers(); err != nil {
+		return nil, fmt.Errorf("invalid users configuration: %w", err)
+	}
+
 	return &config, nil
 }
 
+// ResolveConfigPath returns the config file used when configPath is empty:
+// ./config.yaml if it exists, otherwise ~/.worklogr/config.yaml
+func ResolveConfigPath(configPath string) (string, error) {
+	if configPath != "" {
+		return configPath, nil
+	}
+
+	// First try current directory
+	currentDir, err := os.Getwd()
+	if err == nil {
+		localConfigPath := filepath.Join(currentDir, "config.yaml")
+		if _, err := os.Stat(localConfigPath); err == nil {
+			return localConfigPath, nil
+		}
+	}
+
+	// Fall back to home directory if no local config found
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".worklogr", "config.yaml"), nil
+}
+
 // SaveConfig saves the configuration to the specified file
 func SaveConfig(config *Config, configPath string) error {
 	if configPath == "" {
@@ -164,7 +516,7 @@ func SaveConfig(config *Config, configPath string) error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	data, err := yaml.Marshal(config)
+	data, err := yaml.Marshal(config.withSecretRefs())
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
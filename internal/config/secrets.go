@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/iriam/worklogr/internal/secrets"
+)
+
+// SecretsConfig selects where secret:// references in the config are resolved from.
+type SecretsConfig struct {
+	// Backend is one of "file" (default), "env" or "command".
+	Backend string `yaml:"backend"`
+	// FilePath is the encrypted secret file (default: ~/.worklogr/secrets.json).
+	FilePath string `yaml:"file_path"`
+	// PassphraseEnv names the environment variable holding the file passphrase
+	// (default: WORKLOGR_SECRET_PASSPHRASE).
+	PassphraseEnv string `yaml:"passphrase_env"`
+	// EnvPrefix is the variable prefix for the env backend (default: WORKLOGR_SECRET_).
+	EnvPrefix string `yaml:"env_prefix"`
+	// Command is run for the command backend; {name} is replaced with the secret name.
+	Command string `yaml:"command"`
+}
+
+// StoreOptions converts the config to secrets.Options.
+func (c SecretsConfig) StoreOptions() secrets.Options {
+	return secrets.Options{
+		Backend:       c.Backend,
+		FilePath:      c.FilePath,
+		PassphraseEnv: c.PassphraseEnv,
+		EnvPrefix:     c.EnvPrefix,
+		Command:       c.Command,
+	}
+}
+
+// SecretField is a config value that may hold a credential or a secret:// reference.
+type SecretField struct {
+	// Path is the YAML path of the field (e.g. "slack.access_token").
+	Path string
+	// Name is the default secret name used when migrating the field into the store.
+	Name  string
+	Value *string
+}
+
+// SecretFields returns every credential field in the config.
+func (c *Config) SecretFields() []SecretField {
+	fields := []SecretField{
+		{Path: "okta.client_secret", Name: "okta_client_secret", Value: &c.Okta.ClientSecret},
+		{Path: "llm.api_key", Name: "llm_api_key", Value: &c.LLM.APIKey},
+		{Path: "encryption.key", Name: "database_key", Value: &c.Encryption.Key},
+	}
+	for _, service := range []struct {
+		key    string
+		config *ServiceConfig
+	}{
+		{"slack", &c.Slack},
+		{"github", &c.GitHub},
+		{"google_calendar", &c.GoogleCal},
+	} {
+		fields = append(fields,
+			SecretField{Path: service.key + ".access_token", Name: service.key, Value: &service.config.AccessToken},
+			SecretField{Path: service.key + ".refresh_token", Name: service.key + "_refresh_token", Value: &service.config.RefreshToken},
+			SecretField{Path: service.key + ".client_secret", Name: service.key + "_client_secret", Value: &service.config.ClientSecret},
+		)
+	}
+	for i := range c.Users {
+		user := &c.Users[i]
+		for _, service := range []struct {
+			key    string
+			config *UserServiceConfig
+		}{
+			{"slack", &user.Slack},
+			{"github", &user.GitHub},
+		} {
+			path := fmt.Sprintf("users[%d].%s", i, service.key)
+			name := "user_" + user.Name + "_" + service.key
+			fields = append(fields,
+				SecretField{Path: path + ".access_token", Name: name, Value: &service.config.AccessToken},
+				SecretField{Path: path + ".refresh_token", Name: name + "_refresh_token", Value: &service.config.RefreshToken},
+			)
+		}
+	}
+	return fields
+}
+
+// ResolveSecrets replaces secret:// references with values from the store.
+// The references are remembered so that SaveConfig writes them back instead of plaintext.
+func (c *Config) ResolveSecrets(openStore func(secrets.Options) (secrets.Store, error)) error {
+	var store secrets.Store
+	for _, field := range c.SecretFields() {
+		if !secrets.IsRef(*field.Value) {
+			continue
+		}
+		if store == nil {
+			opened, err := openStore(c.Secrets.StoreOptions())
+			if err != nil {
+				return fmt.Errorf("failed to open secret store: %w", err)
+			}
+			store = opened
+		}
+
+		ref := *field.Value
+		value, err := store.Get(secrets.RefName(ref))
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s (%s): %w", field.Path, ref, err)
+		}
+		if c.secretRefs == nil {
+			c.secretRefs = make(map[string]string)
+		}
+		c.secretRefs[field.Path] = ref
+		*field.Value = value
+	}
+	return nil
+}
+
+// SetSecretRef records that a field should be saved as a secret:// reference.
+func (c *Config) SetSecretRef(path, ref string) {
+	if c.secretRefs == nil {
+		c.secretRefs = make(map[string]string)
+	}
+	c.secretRefs[path] = ref
+}
+
+// SecretRef returns the secret:// reference a field was resolved from, or "" when the
+// field was not a reference.
+func (c *Config) SecretRef(path string) string {
+	return c.secretRefs[path]
+}
+
+// withSecretRefs returns a copy of the config whose credential fields hold the
+// original secret:// references rather than the resolved values.
+func (c *Config) withSecretRefs() *Config {
+	copied := *c
+	if len(c.secretRefs) == 0 {
+		return &copied
+	}
+	// Users is a slice; copy it so that restoring references does not touch c.
+	copied.Users = append([]UserConfig(nil), c.Users...)
+	for _, field := range copied.SecretFields() {
+		if ref, ok := c.secretRefs[field.Path]; ok {
+			*field.Value = ref
+		}
+	}
+	return &copied
+}
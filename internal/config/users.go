@@ -0,0 +1,165 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UserConfig maps a team member to their identity and credentials on each service.
+// Collection runs once per user, and every stored event is tagged with the user's Name.
+type UserConfig struct {
+	// Name is the unique member key stored with each event (events.member).
+	Name        string            `yaml:"name"`
+	DisplayName string            `yaml:"display_name,omitempty"`
+	Teams       []string          `yaml:"teams,omitempty"`
+	Slack       UserServiceConfig `yaml:"slack,omitempty"`
+	GitHub      UserServiceConfig `yaml:"github,omitempty"`
+	GoogleCal   UserServiceConfig `yaml:"google_calendar,omitempty"`
+}
+
+// UserServiceConfig holds one member's identity and credentials for a service.
+type UserServiceConfig struct {
+	// Identity is the member's account on the service (Slack user ID, GitHub login, Google email).
+	Identity     string `yaml:"identity,omitempty"`
+	AccessToken  string `yaml:"access_token,omitempty"`
+	RefreshToken string `yaml:"refresh_token,omitempty"`
+	// Disabled skips the service for this member even when it is enabled globally.
+	Disabled bool `yaml:"disabled,omitempty"`
+}
+
+// Label returns the display name, falling back to the member key.
+func (u UserConfig) Label() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	return u.Name
+}
+
+// InTeam reports whether the member belongs to the given team.
+func (u UserConfig) InTeam(team string) bool {
+	for _, t := range u.Teams {
+		if strings.EqualFold(t, team) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasUsers reports whether the config defines team members.
+func (c *Config) HasUsers() bool {
+	return len(c.Users) > 0
+}
+
+// FindUser returns the member with the given name.
+func (c *Config) FindUser(name string) (*UserConfig, bool) {
+	for i := range c.Users {
+		if strings.EqualFold(c.Users[i].Name, name) {
+			return &c.Users[i], true
+		}
+	}
+	return nil, false
+}
+
+// TeamNames returns every team referenced by the configured members, sorted.
+func (c *Config) TeamNames() []string {
+	seen := make(map[string]bool)
+	var teams []string
+	for _, user := range c.Users {
+		for _, team := range user.Teams {
+			if team == "" || seen[team] {
+				continue
+			}
+			seen[team] = true
+			teams = append(teams, team)
+		}
+	}
+	sort.Strings(teams)
+	return teams
+}
+
+// SelectUsers resolves --user / --team selections to member names in config order.
+// With no selection every configured member is returned.
+func (c *Config) SelectUsers(names, teams []string) ([]string, error) {
+	selected := make(map[string]bool)
+	for _, name := range names {
+		user, ok := c.FindUser(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("unknown user %q (not defined in users)", name)
+		}
+		selected[user.Name] = true
+	}
+	for _, team := range teams {
+		team = strings.TrimSpace(team)
+		found := false
+		for _, user := range c.Users {
+			if user.InTeam(team) {
+				selected[user.Name] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown team %q (no user belongs to it)", team)
+		}
+	}
+
+	var result []string
+	for _, user := range c.Users {
+		if len(names) == 0 && len(teams) == 0 || selected[user.Name] {
+			result = append(result, user.Name)
+		}
+	}
+	return result, nil
+}
+
+// ValidateUsers checks that member names are present and unique.
+func (c *Config) ValidateUsers() error {
+	seen := make(map[string]bool, len(c.Users))
+	for i, user := range c.Users {
+		if strings.TrimSpace(user.Name) == "" {
+			return fmt.Errorf("users[%d].name is required", i)
+		}
+		key := strings.ToLower(user.Name)
+		if seen[key] {
+			return fmt.Errorf("duplicate user name %q", user.Name)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// ForUser returns a copy of the config that collects as the given member.
+// Slack and GitHub use the member's access_token; Google Calendar impersonates the
+// member's email, which requires a service-account credentials_file.
+// Services without member credentials are disabled so that another identity's data is
+// never attributed to the member.
+func (c *Config) ForUser(name string) (*Config, error) {
+	user, ok := c.FindUser(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown user %q (not defined in users)", name)
+	}
+
+	copied := *c
+	copied.secretRefs = nil
+	applyUserService(&copied.Slack, user.Slack)
+	applyUserService(&copied.GitHub, user.GitHub)
+
+	// Google Calendar is read through domain-wide delegation rather than per-user tokens.
+	if user.GoogleCal.Disabled || user.GoogleCal.Identity == "" || copied.GoogleCalendarOptions.CredentialsFile == "" {
+		copied.GoogleCal.Enabled = false
+	} else {
+		copied.GoogleCalendarOptions.ImpersonateUser = user.GoogleCal.Identity
+	}
+
+	return &copied, nil
+}
+
+func applyUserService(target *ServiceConfig, user UserServiceConfig) {
+	if user.Disabled || user.AccessToken == "" {
+		target.Enabled = false
+		return
+	}
+	target.AccessToken = user.AccessToken
+	target.RefreshToken = user.RefreshToken
+	target.TokenExpiresAt = nil
+}
@@ -0,0 +1,158 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+)
+
+// GetAnnotation returns the annotation of an event, or nil when it has none.
+func (dm *DatabaseManager) GetAnnotation(eventID string) (*config.EventAnnotation, error) {
+	annotations, err := dm.GetAnnotations([]string{eventID})
+	if err != nil {
+		return nil, err
+	}
+	return annotations[eventID], nil
+}
+
+// GetAnnotations returns the annotations of the given events keyed by event ID.
+func (dm *DatabaseManager) GetAnnotations(eventIDs []string) (map[string]*config.EventAnnotation, error) {
+	annotations := make(map[string]*config.EventAnnotation)
+	err := forEachIDChunk(eventIDs, func(placeholders string, args []interface{}) error {
+		rows, err := dm.db.Query(`
+			SELECT event_id, hidden, pinned, note, tags, updated_at
+			FROM event_annotations
+			WHERE event_id IN (`+placeholders+`)
+		`, args...)
+		if err != nil {
+			return fmt.Errorf("failed to query event annotations: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var (
+				eventID   string
+				tags      string
+				updatedAt sql.NullTime
+			)
+			annotation := &config.EventAnnotation{}
+			if err := rows.Scan(&eventID, &annotation.Hidden, &annotation.Pinned, &annotation.Note, &tags, &updatedAt); err != nil {
+				return fmt.Errorf("failed to scan event annotation: %w", err)
+			}
+			if tags != "" {
+				if err := json.Unmarshal([]byte(tags), &annotation.Tags); err != nil {
+					return fmt.Errorf("invalid tags for event %s: %w", eventID, err)
+				}
+			}
+			if updatedAt.Valid {
+				annotation.UpdatedAt = updatedAt.Time
+			}
+			annotations[eventID] = annotation
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return annotations, nil
+}
+
+// SaveAnnotation stores the annotation of an event. An empty annotation removes it.
+func (dm *DatabaseManager) SaveAnnotation(eventID string, annotation *config.EventAnnotation) error {
+	if annotation.IsEmpty() {
+		if _, err := dm.db.Exec(`DELETE FROM event_annotations WHERE event_id = ?`, eventID); err != nil {
+			return fmt.Errorf("failed to delete event annotation: %w", err)
+		}
+		return nil
+	}
+
+	tags := annotation.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	encodedTags, err := json.Marshal(tags)
+	if err != nil {
+		return fmt.Errorf("failed to encode tags: %w", err)
+	}
+
+	if _, err := dm.db.Exec(`
+		INSERT INTO event_annotations (event_id, hidden, pinned, note, tags, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(event_id) DO UPDATE SET
+			hidden = excluded.hidden,
+			pinned = excluded.pinned,
+			note = excluded.note,
+			tags = excluded.tags,
+			updated_at = excluded.updated_at
+	`, eventID, annotation.Hidden, annotation.Pinned, annotation.Note, string(encodedTags), time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to save event annotation: %w", err)
+	}
+	return nil
+}
+
+// ClearPinnedAndHidden resets the pinned and hidden flags of the given events, keeping
+// notes and tags, and returns how many annotations changed.
+func (dm *DatabaseManager) ClearPinnedAndHidden(eventIDs []string) (int64, error) {
+	var count int64
+	updatedAt := time.Now().UTC()
+	err := forEachIDChunk(eventIDs, func(placeholders string, args []interface{}) error {
+		result, err := dm.db.Exec(`
+			UPDATE event_annotations SET hidden = 0, pinned = 0, updated_at = ?
+			WHERE (hidden = 1 OR pinned = 1) AND event_id IN (`+placeholders+`)
+		`, append([]interface{}{updatedAt}, args...)...)
+		if err != nil {
+			return fmt.Errorf("failed to clear event flags: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		count += affected
+
+		if _, err := dm.db.Exec(`
+			DELETE FROM event_annotations
+			WHERE hidden = 0 AND pinned = 0 AND note = '' AND tags = '[]' AND event_id IN (`+placeholders+`)
+		`, args...); err != nil {
+			return fmt.Errorf("failed to delete empty annotations: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// EventExists reports whether an event with the given ID is stored.
+func (dm *DatabaseManager) EventExists(eventID string) (bool, error) {
+	var count int
+	if err := dm.db.QueryRow(`SELECT COUNT(*) FROM events WHERE id = ?`, eventID).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to look up event: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (dm *DatabaseManager) populateAnnotations(events []*config.Event) error {
+	ids := make([]string, 0, len(events))
+	for _, e := range events {
+		if e != nil && e.ID != "" {
+			ids = append(ids, e.ID)
+		}
+	}
+	annotations, err := dm.GetAnnotations(ids)
+	if err != nil {
+		return err
+	}
+	for _, e := range events {
+		if e == nil {
+			continue
+		}
+		if annotation, ok := annotations[e.ID]; ok {
+			e.Annotation = annotation
+		}
+	}
+	return nil
+}
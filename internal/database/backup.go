@@ -0,0 +1,424 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	// backupStepPages is how many pages are copied per backup step. Locks are released
+	// between steps so that a running collection can keep writing.
+	backupStepPages = 256
+	// backupStepPause is the pause between steps, giving other connections a chance to lock
+	backupStepPause = 10 * time.Millisecond
+	// backupBusyTimeout is how long a backup waits on a locked database without progress
+	backupBusyTimeout = 30 * time.Second
+)
+
+// MergeStats reports what Merge took from the other database.
+type MergeStats struct {
+	// EventsAdded are events that only existed in the other database
+	EventsAdded int
+	// EventsUpdated are events present in both where the other copy was stored later
+	EventsUpdated int
+	// EventsKept are events present in both where this database's copy was kept
+	EventsKept int
+	// AttachmentsMerged and AnnotationsMerged count rows written (added or replaced)
+	AttachmentsMerged int
+	AnnotationsMerged int
+	LinksAdded        int
+}
+
+// Backup writes a consistent copy of the database to destPath using SQLite's online
+// backup API, so it is safe while a collection is writing to the same file.
+// The copy is written next to destPath and renamed into place when complete.
+func (dm *DatabaseManager) Backup(destPath string) error {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	tmpPath := destPath + ".tmp"
+	os.Remove(tmpPath)
+	if err := copyDatabaseToFile(dm.db, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to move backup into place: %w", err)
+	}
+	return nil
+}
+
+// Restore replaces the contents of the database with the backup at backupPath.
+// The backup is checked first; older schemas are migrated after the copy. An encrypted
+// backup must open with the current key, and a plaintext one is encrypted when
+// encryption is configured.
+func (dm *DatabaseManager) Restore(backupPath string) error {
+	src, err := openWorklogrDatabase(backupPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	passphrase := ""
+	if dm.cipher != nil {
+		passphrase = dm.cipher.passphrase
+	}
+	meta, err := readEncryptionMeta(src)
+	if err != nil {
+		return err
+	}
+	if meta != nil {
+		if passphrase == "" {
+			return fmt.Errorf("%s is encrypted; configure encryption.key to restore it", backupPath)
+		}
+		if _, err := meta.cipher(passphrase); err != nil {
+			return fmt.Errorf("%s: %w", backupPath, err)
+		}
+	}
+
+	if err := copyDatabase(src, dm.db); err != nil {
+		return err
+	}
+	if err := dm.CreateTables(); err != nil {
+		return err
+	}
+	dm.meta, dm.cipher = meta, nil
+	return dm.UseEncryption(passphrase)
+}
+
+// Merge unions the events, attachments, annotations and links of the worklogr database
+// at otherPath into this one. Events are matched by ID; when both databases have an
+// event, the copy with the later created_at (the one stored more recently) wins, together
+// with its attachments, and a replaced copy that differs is kept as a previous version.
+// Annotations are matched the same way by updated_at.
+// The other database is not modified.
+func (dm *DatabaseManager) Merge(otherPath string) (*MergeStats, error) {
+	src, err := openWorklogrDatabase(otherPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Work on a migrated copy so that databases written by older versions line up
+	// with the current schema without touching the original file.
+	tmpDir, err := os.MkdirTemp("", "worklogr-merge-")
+	if err != nil {
+		src.Close()
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	copyPath := filepath.Join(tmpDir, "other.db")
+	err = copyDatabaseToFile(src, copyPath)
+	src.Close()
+	if err != nil {
+		return nil, err
+	}
+	other, err := NewDatabaseManager(copyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare %s: %w", otherPath, err)
+	}
+	err = dm.decryptForMerge(other, otherPath)
+	other.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	conn, err := dm.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	// ATTACH is per connection, so everything below runs on conn
+	if _, err := conn.ExecContext(ctx, `ATTACH DATABASE ? AS other`, copyPath); err != nil {
+		return nil, fmt.Errorf("failed to attach %s: %w", otherPath, err)
+	}
+	defer conn.ExecContext(ctx, `DETACH DATABASE other`)
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stats, err := dm.mergeAttached(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if dm.cipher != nil {
+		// Rows taken from the other database are plaintext at this point
+		if err := recodeColumns(tx, dm.cipher.seal); err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, clearLiftedSQL); err != nil {
+			return nil, fmt.Errorf("failed to clear lifted columns: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit merge: %w", err)
+	}
+	return stats, nil
+}
+
+// decryptForMerge turns the temporary copy of an encrypted database into plaintext. The
+// copy must have been encrypted with the same key string as this database (its salt may
+// differ); merged rows are sealed again with this database's key.
+func (dm *DatabaseManager) decryptForMerge(other *DatabaseManager, otherPath string) error {
+	if other.meta == nil {
+		return nil
+	}
+	if dm.cipher == nil {
+		return fmt.Errorf("%s is encrypted; configure encryption.key to merge it", otherPath)
+	}
+	if err := other.UseEncryption(dm.cipher.passphrase); err != nil {
+		return fmt.Errorf("%s: %w", otherPath, err)
+	}
+	return other.Rekey("")
+}
+
+// mergeAttached copies rows from the attached "other" schema into main
+func (dm *DatabaseManager) mergeAttached(ctx context.Context, tx *sql.Tx) (*MergeStats, error) {
+	stats := &MergeStats{}
+
+	// Events taken from the other database: new IDs, and shared IDs stored there later
+	if _, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS temp.merge_winners`); err != nil {
+		return nil, fmt.Errorf("failed to reset merge state: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TEMP TABLE merge_winners AS
+		SELECT o.id AS id, e.id IS NOT NULL AS existed
+		FROM other.events o LEFT JOIN main.events e ON e.id = o.id
+		WHERE e.id IS NULL OR COALESCE(o.created_at, '') > COALESCE(e.created_at, '')
+	`); err != nil {
+		return nil, fmt.Errorf("failed to compare events: %w", err)
+	}
+
+	if err := tx.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM temp.merge_winners WHERE NOT existed),
+			(SELECT COUNT(*) FROM temp.merge_winners WHERE existed),
+			(SELECT COUNT(*) FROM other.events o JOIN main.events e ON e.id = o.id) -
+				(SELECT COUNT(*) FROM temp.merge_winners WHERE existed)
+	`).Scan(&stats.EventsAdded, &stats.EventsUpdated, &stats.EventsKept); err != nil {
+		return nil, fmt.Errorf("failed to count merged events: %w", err)
+	}
+
+	// A replaced event keeps its copy in main as a previous version, as a re-collection does
+	if err := dm.recordMergedVersions(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT OR REPLACE INTO main.events (id, service, type, title, content, timestamp, metadata, user_id, member, deleted_at,
+			url, repository, channel, channel_id, participant_count, duration_minutes, created_at)
+		SELECT id, service, type, title, content, timestamp, metadata, user_id, member, deleted_at,
+			url, repository, channel, channel_id, participant_count, duration_minutes, created_at
+		FROM other.events WHERE id IN (SELECT id FROM temp.merge_winners)
+	`); err != nil {
+		return nil, fmt.Errorf("failed to merge events: %w", err)
+	}
+
+	// The winning copy brings its attachments; other attachments are added when missing
+	attachments, err := execCount(ctx, tx, `
+		INSERT OR REPLACE INTO main.event_attachments (id, event_id, file_id, title, mime_type, export_as, text_full, truncated, created_at)
+		SELECT id, event_id, file_id, title, mime_type, export_as, text_full, truncated, created_at
+		FROM other.event_attachments WHERE event_id IN (SELECT id FROM temp.merge_winners)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge attachments: %w", err)
+	}
+	stats.AttachmentsMerged += attachments
+
+	attachments, err = execCount(ctx, tx, `
+		INSERT OR IGNORE INTO main.event_attachments (id, event_id, file_id, title, mime_type, export_as, text_full, truncated, created_at)
+		SELECT id, event_id, file_id, title, mime_type, export_as, text_full, truncated, created_at
+		FROM other.event_attachments WHERE event_id NOT IN (SELECT id FROM temp.merge_winners)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge attachments: %w", err)
+	}
+	stats.AttachmentsMerged += attachments
+
+	if stats.AnnotationsMerged, err = execCount(ctx, tx, `
+		INSERT OR REPLACE INTO main.event_annotations (event_id, hidden, pinned, note, tags, updated_at)
+		SELECT o.event_id, o.hidden, o.pinned, o.note, o.tags, o.updated_at
+		FROM other.event_annotations o LEFT JOIN main.event_annotations a ON a.event_id = o.event_id
+		WHERE a.event_id IS NULL OR COALESCE(o.updated_at, '') > COALESCE(a.updated_at, '')
+	`); err != nil {
+		return nil, fmt.Errorf("failed to merge annotations: %w", err)
+	}
+
+	if stats.LinksAdded, err = execCount(ctx, tx, `
+		INSERT OR IGNORE INTO main.event_links (source_event_id, target_event_id, relation, ref_key, created_at)
+		SELECT source_event_id, target_event_id, relation, ref_key, created_at FROM other.event_links
+	`); err != nil {
+		return nil, fmt.Errorf("failed to merge links: %w", err)
+	}
+
+	// Previous versions are history from either side; identical ones are kept once
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO main.event_versions (event_id, title, content, timestamp, metadata, seen_at, replaced_at)
+		SELECT o.event_id, o.title, o.content, o.timestamp, o.metadata, o.seen_at, o.replaced_at
+		FROM other.event_versions o
+		WHERE NOT EXISTS (
+			SELECT 1 FROM main.event_versions v
+			WHERE v.event_id = o.event_id AND v.seen_at IS o.seen_at AND v.replaced_at IS o.replaced_at
+		)
+	`); err != nil {
+		return nil, fmt.Errorf("failed to merge event versions: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DROP TABLE temp.merge_winners`); err != nil {
+		return nil, fmt.Errorf("failed to clean up merge state: %w", err)
+	}
+	return stats, nil
+}
+
+// recordMergedVersions records the main copy of each event about to be replaced by the
+// other database's copy, when the two differ.
+func (dm *DatabaseManager) recordMergedVersions(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `SELECT `+eventColumns+` FROM other.events
+		WHERE id IN (SELECT id FROM temp.merge_winners WHERE existed)`)
+	if err != nil {
+		return fmt.Errorf("failed to query replaced events: %w", err)
+	}
+	events, err := scanEvents(rows)
+	rows.Close()
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		if err := dm.recordVersionTx(tx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func execCount(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (int, error) {
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+// openWorklogrDatabase opens an existing database file read-only and checks that it
+// looks like a worklogr database.
+func openWorklogrDatabase(path string) (*sql.DB, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	var tables int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'events'`).Scan(&tables); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s is not a readable SQLite database: %w", path, err)
+	}
+	if tables == 0 {
+		db.Close()
+		return nil, fmt.Errorf("%s is not a worklogr database (no events table)", path)
+	}
+
+	var check string
+	if err := db.QueryRow(`PRAGMA quick_check`).Scan(&check); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to check %s: %w", path, err)
+	}
+	if check != "ok" {
+		db.Close()
+		return nil, fmt.Errorf("%s is damaged: %s", path, check)
+	}
+	return db, nil
+}
+
+// copyDatabaseToFile copies src into a new database file at destPath
+func copyDatabaseToFile(src *sql.DB, destPath string) error {
+	dest, err := sql.Open("sqlite3", destPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", destPath, err)
+	}
+	defer dest.Close()
+	return copyDatabase(src, dest)
+}
+
+// copyDatabase replaces the contents of dest with src through the online backup API
+func copyDatabase(src, dest *sql.DB) error {
+	ctx := context.Background()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get source connection: %w", err)
+	}
+	defer srcConn.Close()
+	destConn, err := dest.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get destination connection: %w", err)
+	}
+	defer destConn.Close()
+
+	return destConn.Raw(func(destDriver interface{}) error {
+		return srcConn.Raw(func(srcDriver interface{}) error {
+			destSQLite, ok := destDriver.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected destination driver %T", destDriver)
+			}
+			srcSQLite, ok := srcDriver.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected source driver %T", srcDriver)
+			}
+			return runBackup(destSQLite, srcSQLite)
+		})
+	})
+}
+
+// runBackup copies the main database of src into dest a few pages at a time
+func runBackup(dest, src *sqlite3.SQLiteConn) error {
+	backup, err := dest.Backup("main", src, "main")
+	if err != nil {
+		return fmt.Errorf("failed to start backup: %w", err)
+	}
+
+	lastRemaining := -1
+	lastProgress := time.Now()
+	for {
+		done, err := backup.Step(backupStepPages)
+		if err != nil {
+			backup.Finish()
+			return fmt.Errorf("backup step failed: %w", err)
+		}
+		if done {
+			break
+		}
+
+		// Step reports a locked database as "not done" without error; give up
+		// only when nothing has moved for a while.
+		if remaining := backup.Remaining(); remaining != lastRemaining {
+			lastRemaining = remaining
+			lastProgress = time.Now()
+		} else if time.Since(lastProgress) > backupBusyTimeout {
+			backup.Finish()
+			return fmt.Errorf("backup gave up: database stayed locked for %s", backupBusyTimeout)
+		}
+		time.Sleep(backupStepPause)
+	}
+
+	if err := backup.Finish(); err != nil {
+		return fmt.Errorf("failed to finish backup: %w", err)
+	}
+	return nil
+}
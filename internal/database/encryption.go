@@ -0,0 +1,357 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/eventmeta"
+	"github.com/iriam/worklogr/internal/secrets"
+)
+
+// sealedPrefix marks column values encrypted by columnCipher. Values without it are
+// plaintext, which lets a database be encrypted (or merged) row by row.
+const sealedPrefix = "enc:v1:"
+
+// encryptionCheck is the plaintext sealed into encryption_meta to detect a wrong key
+const encryptionCheck = "worklogr-database"
+
+// ErrWrongEncryptionKey is returned when the configured key does not open the database.
+var ErrWrongEncryptionKey = errors.New("encryption key does not match the database")
+
+// encryptedColumns are the columns holding message bodies, meeting notes and document text
+var encryptedColumns = []struct {
+	table   string
+	columns []string
+}{
+	{"events", []string{"content", "metadata"}},
+	{"event_attachments", []string{"text_full"}},
+	{"event_versions", []string{"content", "metadata"}},
+}
+
+// columnCipher encrypts column values with AES-GCM using a key derived from a passphrase
+// with the scrypt parameters stored in encryption_meta.
+type columnCipher struct {
+	passphrase string
+	key        []byte
+}
+
+func (c *columnCipher) seal(value string) (string, error) {
+	if c == nil || value == "" || strings.HasPrefix(value, sealedPrefix) {
+		return value, nil
+	}
+	sealed, err := secrets.Seal(c.key, []byte(value))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt value: %w", err)
+	}
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *columnCipher) open(value string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, sealedPrefix)
+	if !ok {
+		return value, nil
+	}
+	if c == nil {
+		return "", fmt.Errorf("database contains encrypted values but no encryption key is configured")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode encrypted value: %w", err)
+	}
+	plain, err := secrets.Unseal(c.key, sealed)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value: %w", err)
+	}
+	return string(plain), nil
+}
+
+// encryptionMeta is the single row of encryption_meta
+type encryptionMeta struct {
+	kdf   secrets.KDFParams
+	check string
+}
+
+func readEncryptionMeta(db *sql.DB) (*encryptionMeta, error) {
+	var tables int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'encryption_meta'`).Scan(&tables); err != nil {
+		return nil, fmt.Errorf("failed to inspect encryption_meta: %w", err)
+	}
+	if tables == 0 {
+		return nil, nil
+	}
+
+	var kdf, check string
+	err := db.QueryRow(`SELECT kdf, check_value FROM encryption_meta WHERE id = 1`).Scan(&kdf, &check)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption_meta: %w", err)
+	}
+
+	meta := &encryptionMeta{check: check}
+	if err := json.Unmarshal([]byte(kdf), &meta.kdf); err != nil {
+		return nil, fmt.Errorf("failed to parse encryption parameters: %w", err)
+	}
+	return meta, nil
+}
+
+// cipher derives the key for passphrase and checks it against the stored check value
+func (m *encryptionMeta) cipher(passphrase string) (*columnCipher, error) {
+	key, err := m.kdf.DeriveKey(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	c := &columnCipher{passphrase: passphrase, key: key}
+	if check, err := c.open(m.check); err != nil || check != encryptionCheck {
+		return nil, ErrWrongEncryptionKey
+	}
+	return c, nil
+}
+
+// newColumnCipher creates a cipher with fresh scrypt parameters and the meta row describing it
+func newColumnCipher(passphrase string) (*columnCipher, *encryptionMeta, error) {
+	params, err := secrets.DefaultKDFParams()
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := params.DeriveKey(passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	c := &columnCipher{passphrase: passphrase, key: key}
+	check, err := c.seal(encryptionCheck)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, &encryptionMeta{kdf: params, check: check}, nil
+}
+
+// UseEncryption configures application-level encryption of event content, metadata and
+// attachment text. An empty passphrase means encryption is off, which fails for a database
+// that is already encrypted. On a plaintext database a non-empty passphrase encrypts the
+// existing rows; on an encrypted database it must match the key used to encrypt it.
+func (dm *DatabaseManager) UseEncryption(passphrase string) error {
+	meta := dm.meta
+	switch {
+	case meta == nil && passphrase == "":
+		dm.cipher = nil
+		return nil
+	case meta == nil:
+		return dm.Rekey(passphrase)
+	case passphrase == "":
+		return fmt.Errorf("database is encrypted; configure encryption.key to open it")
+	}
+
+	c, err := meta.cipher(passphrase)
+	if err != nil {
+		return err
+	}
+	dm.cipher = c
+	// Lifted columns written by earlier versions would expose the encrypted metadata
+	if _, err := dm.db.Exec(clearLiftedSQL); err != nil {
+		return fmt.Errorf("failed to clear lifted columns: %w", err)
+	}
+	return nil
+}
+
+// Encrypted reports whether the database is opened with an encryption key.
+func (dm *DatabaseManager) Encrypted() bool {
+	return dm.cipher != nil
+}
+
+// Rekey re-encrypts every encrypted column with a key derived from newPassphrase, using
+// fresh scrypt parameters. An empty newPassphrase decrypts the database back to plaintext.
+// The database must already be opened with its current key (see UseEncryption).
+func (dm *DatabaseManager) Rekey(newPassphrase string) error {
+	var next *columnCipher
+	var meta *encryptionMeta
+	if newPassphrase != "" {
+		var err error
+		if next, meta, err = newColumnCipher(newPassphrase); err != nil {
+			return err
+		}
+	}
+
+	tx, err := dm.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	current := dm.cipher
+	if err := recodeColumns(tx, func(value string) (string, error) {
+		plain, err := current.open(value)
+		if err != nil {
+			return "", err
+		}
+		return next.seal(plain)
+	}); err != nil {
+		return err
+	}
+	if next != nil {
+		if _, err := tx.Exec(clearLiftedSQL); err != nil {
+			return fmt.Errorf("failed to clear lifted columns: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM encryption_meta`); err != nil {
+		return fmt.Errorf("failed to clear encryption_meta: %w", err)
+	}
+	if meta != nil {
+		kdf, err := json.Marshal(meta.kdf)
+		if err != nil {
+			return fmt.Errorf("failed to encode encryption parameters: %w", err)
+		}
+		if _, err := tx.Exec(`INSERT INTO encryption_meta (id, kdf, check_value) VALUES (1, ?, ?)`, string(kdf), meta.check); err != nil {
+			return fmt.Errorf("failed to write encryption_meta: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit rekey: %w", err)
+	}
+	dm.meta = meta
+	dm.cipher = next
+	// Decrypted back to plaintext: fill in the lifted columns again
+	return dm.liftMetadata()
+}
+
+// recodeColumns rewrites every value of the encrypted columns through transform.
+// Rows are read in rowid pages so that large databases are not loaded at once.
+func recodeColumns(tx *sql.Tx, transform func(string) (string, error)) error {
+	const pageSize = 500
+
+	for _, target := range encryptedColumns {
+		columns := strings.Join(target.columns, ", ")
+		assignments := strings.Join(target.columns, " = ?, ") + " = ?"
+
+		for lastRowID := int64(0); ; {
+			rows, err := tx.Query(`SELECT rowid, `+columns+` FROM `+target.table+` WHERE rowid > ? ORDER BY rowid LIMIT ?`, lastRowID, pageSize)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", target.table, err)
+			}
+
+			type row struct {
+				rowID  int64
+				values []sql.NullString
+			}
+			var page []row
+			for rows.Next() {
+				r := row{values: make([]sql.NullString, len(target.columns))}
+				dest := []interface{}{&r.rowID}
+				for i := range r.values {
+					dest = append(dest, &r.values[i])
+				}
+				if err := rows.Scan(dest...); err != nil {
+					rows.Close()
+					return fmt.Errorf("failed to scan %s: %w", target.table, err)
+				}
+				page = append(page, r)
+			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return fmt.Errorf("error iterating %s: %w", target.table, err)
+			}
+			if len(page) == 0 {
+				break
+			}
+
+			for _, r := range page {
+				args := make([]interface{}, 0, len(r.values)+1)
+				changed := false
+				for _, value := range r.values {
+					if !value.Valid {
+						args = append(args, nil)
+						continue
+					}
+					recoded, err := transform(value.String)
+					if err != nil {
+						return fmt.Errorf("%s row %d: %w", target.table, r.rowID, err)
+					}
+					changed = changed || recoded != value.String
+					args = append(args, recoded)
+				}
+				if !changed {
+					continue
+				}
+				args = append(args, r.rowID)
+				if _, err := tx.Exec(`UPDATE `+target.table+` SET `+assignments+` WHERE rowid = ?`, args...); err != nil {
+					return fmt.Errorf("failed to update %s: %w", target.table, err)
+				}
+			}
+			lastRowID = page[len(page)-1].rowID
+		}
+	}
+	return nil
+}
+
+// sealEvent returns the content and metadata to store, encrypted when encryption is on
+func (dm *DatabaseManager) sealEvent(event *config.Event) (string, string, error) {
+	content, err := dm.cipher.seal(event.Content)
+	if err != nil {
+		return "", "", fmt.Errorf("event %s: %w", event.ID, err)
+	}
+	metadata, err := dm.cipher.seal(event.Metadata)
+	if err != nil {
+		return "", "", fmt.Errorf("event %s: %w", event.ID, err)
+	}
+	return content, metadata, nil
+}
+
+// decryptEvents replaces encrypted content and metadata of scanned events with plaintext.
+// With encryption on the lifted columns are not stored, so they are lifted from the
+// decrypted metadata here.
+func (dm *DatabaseManager) decryptEvents(events []*config.Event) error {
+	for _, event := range events {
+		content, err := dm.cipher.open(event.Content)
+		if err != nil {
+			return fmt.Errorf("event %s: %w", event.ID, err)
+		}
+		metadata, err := dm.cipher.open(event.Metadata)
+		if err != nil {
+			return fmt.Errorf("event %s: %w", event.ID, err)
+		}
+		event.Content, event.Metadata = content, metadata
+		if dm.cipher != nil {
+			eventmeta.Lift(event)
+		}
+	}
+	return nil
+}
+
+// sqlFilter returns the part of filter that SQL can evaluate. Text and metadata conditions
+// cannot match encrypted columns, and repository and channel conditions cannot match the
+// lifted columns left empty with encryption on, so they are left to decryptAndFilter.
+func (dm *DatabaseManager) sqlFilter(filter EventFilter) EventFilter {
+	if dm.cipher != nil {
+		filter.Match = ""
+		filter.Metadata = nil
+		filter.Repositories = nil
+		filter.Channels = nil
+	}
+	return filter
+}
+
+// decryptAndFilter decrypts scanned events and applies the conditions removed by sqlFilter
+func (dm *DatabaseManager) decryptAndFilter(events []*config.Event, filter EventFilter) ([]*config.Event, error) {
+	if err := dm.decryptEvents(events); err != nil {
+		return nil, err
+	}
+	if dm.cipher == nil || !filter.hasContentConditions() {
+		return events, nil
+	}
+
+	matched := events[:0]
+	for _, event := range events {
+		if filter.matchesContent(event) {
+			matched = append(matched, event)
+		}
+	}
+	return matched, nil
+}
@@ -0,0 +1,278 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/iriam/worklogr/internal/config"
+)
+
+// EventFilter narrows the events returned by QueryEvents. Empty fields match everything.
+type EventFilter struct {
+	Services []string
+	// Members restricts results to events collected for these users (events.member).
+	Members []string
+	// Types keeps only these event types; ExcludeTypes drops them.
+	Types        []string
+	ExcludeTypes []string
+	// Repositories (owner/name) and Channels (Slack channel name or ID) match the
+	// columns lifted from metadata.
+	Repositories []string
+	Channels     []string
+	// Match is a case-insensitive substring matched against title and content.
+	Match string
+	// Metadata conditions are all required (AND).
+	Metadata []MetadataCondition
+	// Hidden events (see event_annotations) are dropped unless IncludeHidden is set.
+	IncludeHidden bool
+	// PinnedOnly keeps only pinned events.
+	PinnedOnly bool
+	// Tags keeps events annotated with any of these tags.
+	Tags []string
+	// Events marked deleted_at (no longer found at their source) are dropped unless
+	// IncludeDeleted is set. DeletedOnly keeps only those.
+	IncludeDeleted bool
+	DeletedOnly    bool
+}
+
+// MetadataCondition matches events whose metadata field at any of Paths equals any of Values.
+// Paths are JSON paths such as "$.repository".
+type MetadataCondition struct {
+	Paths  []string
+	Values []string
+}
+
+// metadataKeyPattern restricts keys accepted by ParseWhere to a safe JSON path subset.
+var metadataKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+
+// ParseWhere parses a "metadata.key=value" expression into a MetadataCondition.
+// Nested keys are separated by dots (metadata.pull_request.state=open).
+func ParseWhere(expr string) (MetadataCondition, error) {
+	field, value, ok := strings.Cut(expr, "=")
+	if !ok {
+		return MetadataCondition{}, fmt.Errorf("invalid filter %q: expected metadata.key=value", expr)
+	}
+	field = strings.TrimSpace(field)
+	key, ok := strings.CutPrefix(field, "metadata.")
+	if !ok {
+		return MetadataCondition{}, fmt.Errorf("invalid filter %q: only metadata.<key> fields are supported", expr)
+	}
+	if !metadataKeyPattern.MatchString(key) {
+		return MetadataCondition{}, fmt.Errorf("invalid metadata key %q", key)
+	}
+	return MetadataCondition{Paths: []string{"$." + key}, Values: []string{strings.TrimSpace(value)}}, nil
+}
+
+// where returns the SQL conditions (joined with AND by the caller) and their arguments.
+func (f EventFilter) where() ([]string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+
+	add := func(clause string, clauseArgs ...interface{}) {
+		clauses = append(clauses, clause)
+		args = append(args, clauseArgs...)
+	}
+
+	if len(f.Services) > 0 {
+		add("service IN ("+placeholders(len(f.Services))+")", stringArgs(f.Services)...)
+	}
+	if len(f.Members) > 0 {
+		add("member IN ("+placeholders(len(f.Members))+")", stringArgs(f.Members)...)
+	}
+	if len(f.Types) > 0 {
+		add("type IN ("+placeholders(len(f.Types))+")", stringArgs(f.Types)...)
+	}
+	if len(f.ExcludeTypes) > 0 {
+		add("type NOT IN ("+placeholders(len(f.ExcludeTypes))+")", stringArgs(f.ExcludeTypes)...)
+	}
+	if len(f.Repositories) > 0 {
+		add("repository IN ("+placeholders(len(f.Repositories))+")", stringArgs(f.Repositories)...)
+	}
+	if len(f.Channels) > 0 {
+		channels := stringArgs(f.Channels)
+		add("(channel IN ("+placeholders(len(f.Channels))+") OR channel_id IN ("+placeholders(len(f.Channels))+"))", append(channels, channels...)...)
+	}
+	if f.Match != "" {
+		pattern := "%" + escapeLike(f.Match) + "%"
+		add(`(title LIKE ? ESCAPE '\' OR content LIKE ? ESCAPE '\')`, pattern, pattern)
+	}
+	if !f.IncludeHidden {
+		add("id NOT IN (SELECT event_id FROM event_annotations WHERE hidden = 1)")
+	}
+	if f.DeletedOnly {
+		add("deleted_at IS NOT NULL")
+	} else if !f.IncludeDeleted {
+		add("deleted_at IS NULL")
+	}
+	if f.PinnedOnly {
+		add("id IN (SELECT event_id FROM event_annotations WHERE pinned = 1)")
+	}
+	if len(f.Tags) > 0 {
+		add(`id IN (SELECT a.event_id FROM event_annotations a, json_each(a.tags) t WHERE t.value IN (`+placeholders(len(f.Tags))+`))`, stringArgs(f.Tags)...)
+	}
+	for _, condition := range f.Metadata {
+		if len(condition.Paths) == 0 || len(condition.Values) == 0 {
+			continue
+		}
+		var alternatives []string
+		for _, path := range condition.Paths {
+			for _, value := range condition.Values {
+				clause, clauseArgs := metadataEquals(path, value)
+				alternatives = append(alternatives, clause)
+				args = append(args, clauseArgs...)
+			}
+		}
+		// metadata may be empty or non-JSON for old rows; json_extract would fail on those
+		clauses = append(clauses, "(json_valid(metadata) AND ("+strings.Join(alternatives, " OR ")+"))")
+	}
+
+	return clauses, args
+}
+
+// hasContentConditions reports whether the filter has conditions that matchesContent
+// evaluates when the database is encrypted
+func (f EventFilter) hasContentConditions() bool {
+	return f.Match != "" || len(f.Metadata) > 0 || len(f.Repositories) > 0 || len(f.Channels) > 0
+}
+
+// matchesContent evaluates Match, Metadata, Repositories and Channels against a decrypted
+// event in Go, the same way where() does in SQL. It is used when the database is encrypted.
+func (f EventFilter) matchesContent(event *config.Event) bool {
+	if len(f.Repositories) > 0 && !containsString(f.Repositories, event.Repository) {
+		return false
+	}
+	if len(f.Channels) > 0 && !containsString(f.Channels, event.Channel) && !containsString(f.Channels, event.ChannelID) {
+		return false
+	}
+	if f.Match != "" {
+		match := strings.ToLower(f.Match)
+		if !strings.Contains(strings.ToLower(event.Title), match) && !strings.Contains(strings.ToLower(event.Content), match) {
+			return false
+		}
+	}
+	if len(f.Metadata) == 0 {
+		return true
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(event.Metadata))
+	decoder.UseNumber()
+	var metadata interface{}
+	if err := decoder.Decode(&metadata); err != nil {
+		return false
+	}
+	for _, condition := range f.Metadata {
+		if len(condition.Paths) == 0 || len(condition.Values) == 0 {
+			continue
+		}
+		if !condition.matches(metadata) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c MetadataCondition) matches(metadata interface{}) bool {
+	for _, path := range c.Paths {
+		value, found := lookupJSONPath(metadata, path)
+		if !found {
+			continue
+		}
+		for _, want := range c.Values {
+			if jsonValueEquals(value, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lookupJSONPath resolves the "$.a.b" paths accepted by ParseWhere
+func lookupJSONPath(value interface{}, path string) (interface{}, bool) {
+	keys := strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if keys == "" {
+		return value, true
+	}
+	for _, key := range strings.Split(keys, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// jsonValueEquals mirrors metadataEquals: booleans and null by name, everything else as text
+func jsonValueEquals(value interface{}, want string) bool {
+	switch want {
+	case "true", "false", "null":
+		switch v := value.(type) {
+		case bool:
+			return (v && want == "true") || (!v && want == "false")
+		case nil:
+			return want == "null"
+		}
+		return false
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v == want
+	case json.Number:
+		return v.String() == want
+	case bool:
+		// SQLite's json_extract returns 1 / 0 for booleans
+		return (v && want == "1") || (!v && want == "0")
+	case nil:
+		return false
+	default:
+		var buffer bytes.Buffer
+		encoder := json.NewEncoder(&buffer)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(v); err != nil {
+			return false
+		}
+		return strings.TrimSuffix(buffer.String(), "\n") == want
+	}
+}
+
+// metadataEquals compares a metadata field as text. JSON booleans and null are
+// matched by their literal names ("true", "false", "null").
+func metadataEquals(path, value string) (string, []interface{}) {
+	switch value {
+	case "true", "false", "null":
+		return "json_type(metadata, ?) = ?", []interface{}{path, value}
+	default:
+		return "CAST(json_extract(metadata, ?) AS TEXT) = ?", []interface{}{path, value}
+	}
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func stringArgs(values []string) []interface{} {
+	args := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		args = append(args, value)
+	}
+	return args
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
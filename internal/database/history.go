@@ -0,0 +1,184 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+)
+
+// EventVersion is a previous version of an event, kept when a re-collection changed it.
+type EventVersion struct {
+	EventID   string
+	Title     string
+	Content   string
+	Timestamp time.Time
+	Metadata  string
+	// SeenAt is when this version was collected; ReplacedAt is when a newer one replaced it
+	SeenAt     time.Time
+	ReplacedAt time.Time
+}
+
+// recordVersionTx copies the stored row of event into event_versions when the incoming
+// event changes its title, content, timestamp or metadata. Stored values are copied as
+// they are, so encrypted columns stay encrypted.
+func (dm *DatabaseManager) recordVersionTx(tx *sql.Tx, event *config.Event) error {
+	var (
+		title                     string
+		storedContent, storedMeta sql.NullString
+		timestamp                 time.Time
+	)
+	err := tx.QueryRow(`SELECT title, content, timestamp, metadata FROM events WHERE id = ?`, event.ID).
+		Scan(&title, &storedContent, &timestamp, &storedMeta)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read stored event %s: %w", event.ID, err)
+	}
+
+	content, err := dm.cipher.open(storedContent.String)
+	if err != nil {
+		return fmt.Errorf("event %s: %w", event.ID, err)
+	}
+	metadata, err := dm.cipher.open(storedMeta.String)
+	if err != nil {
+		return fmt.Errorf("event %s: %w", event.ID, err)
+	}
+	if title == event.Title && content == event.Content && sameMetadata(metadata, event.Metadata) && timestamp.Equal(event.Timestamp) {
+		return nil
+	}
+
+	// created_at only has second resolution, so versions are not keyed on it; replaced_at
+	// is written with the full clock resolution to keep them in order.
+	if _, err := tx.Exec(`
+		INSERT INTO event_versions (event_id, title, content, timestamp, metadata, seen_at, replaced_at)
+		SELECT id, title, content, timestamp, metadata, created_at, ? FROM events WHERE id = ?
+	`, time.Now().UTC(), event.ID); err != nil {
+		return fmt.Errorf("failed to record previous version of %s: %w", event.ID, err)
+	}
+	return nil
+}
+
+// GetEvent returns the stored event with the given ID, including a deleted one, or nil
+func (dm *DatabaseManager) GetEvent(eventID string) (*config.Event, error) {
+	rows, err := dm.db.Query(`SELECT `+eventColumns+` FROM events WHERE id = ?`, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query event: %w", err)
+	}
+	defer rows.Close()
+
+	events, err := scanEvents(rows)
+	if err != nil || len(events) == 0 {
+		return nil, err
+	}
+	if err := dm.decryptEvents(events); err != nil {
+		return nil, err
+	}
+	if err := dm.hydrateEvents(events); err != nil {
+		return nil, err
+	}
+	return events[0], nil
+}
+
+// sameMetadata compares metadata as JSON, ignoring schema_version so that re-collecting
+// with a newer metadata schema alone does not record a version.
+func sameMetadata(a, b string) bool {
+	if a == b {
+		return true
+	}
+	var valueA, valueB map[string]interface{}
+	if json.Unmarshal([]byte(a), &valueA) != nil || json.Unmarshal([]byte(b), &valueB) != nil {
+		return false
+	}
+	delete(valueA, "schema_version")
+	delete(valueB, "schema_version")
+	return reflect.DeepEqual(valueA, valueB)
+}
+
+// EventVersions returns the previous versions of an event, oldest first.
+func (dm *DatabaseManager) EventVersions(eventID string) ([]EventVersion, error) {
+	rows, err := dm.db.Query(`
+		SELECT event_id, title, content, timestamp, metadata, seen_at, replaced_at
+		FROM event_versions WHERE event_id = ?
+		ORDER BY replaced_at ASC, id ASC
+	`, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query event versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []EventVersion
+	for rows.Next() {
+		var version EventVersion
+		var content, metadata sql.NullString
+		var seenAt, replacedAt sql.NullTime
+		if err := rows.Scan(&version.EventID, &version.Title, &content, &version.Timestamp, &metadata, &seenAt, &replacedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan event version: %w", err)
+		}
+		if version.Content, err = dm.cipher.open(content.String); err != nil {
+			return nil, err
+		}
+		if version.Metadata, err = dm.cipher.open(metadata.String); err != nil {
+			return nil, err
+		}
+		version.SeenAt, version.ReplacedAt = seenAt.Time, replacedAt.Time
+		versions = append(versions, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return versions, nil
+}
+
+// MarkMissingDeleted sets deleted_at on events of a service (and member) within the time
+// range that a complete re-collection no longer returned. seenIDs are the IDs it returned.
+// Events that show up again are restored by the next insert. It returns how many events
+// were newly marked.
+func (dm *DatabaseManager) MarkMissingDeleted(service, member string, startTime, endTime time.Time, seenIDs []string) (int, error) {
+	query, args := eventsQuery("id", startTime, endTime, EventFilter{
+		Services:      []string{service},
+		Members:       []string{member},
+		IncludeHidden: true,
+	})
+	rows, err := dm.db.Query(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query stored events: %w", err)
+	}
+	seen := make(map[string]bool, len(seenIDs))
+	for _, id := range seenIDs {
+		seen[id] = true
+	}
+	var missing []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan event id: %w", err)
+		}
+		if !seen[id] {
+			missing = append(missing, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	marked := 0
+	deletedAt := time.Now().UTC()
+	err = forEachIDChunk(missing, func(placeholders string, args []interface{}) error {
+		result, err := dm.db.Exec(`UPDATE events SET deleted_at = ? WHERE id IN (`+placeholders+`)`, append([]interface{}{deletedAt}, args...)...)
+		if err != nil {
+			return fmt.Errorf("failed to mark deleted events: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		marked += int(affected)
+		return err
+	})
+	return marked, err
+}
@@ -0,0 +1,186 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+)
+
+// iteratorBatchSize is how many full events (with attachments) are held in memory at once
+const iteratorBatchSize = 200
+
+// EventIterator streams the events matched by IterateEvents in timestamp order.
+//
+// Only a lightweight outline of every match (ID, service, type, member, timestamp) is kept
+// in memory; full rows, attachments, links and annotations are loaded in small batches as
+// the caller advances, so exporting long ranges with large attachments stays bounded.
+//
+//	it, err := dm.IterateEvents(start, end, filter)
+//	for it.Next() {
+//		event := it.Event()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type EventIterator struct {
+	dm      *DatabaseManager
+	outline []*config.Event
+	batch   []*config.Event
+	loaded  int
+	current *config.Event
+	err     error
+}
+
+// IterateEvents returns an iterator over events within a time range that match the filter.
+// The matching set is fixed when the iterator is created.
+func (dm *DatabaseManager) IterateEvents(startTime, endTime time.Time, filter EventFilter) (*EventIterator, error) {
+	if dm.cipher != nil && filter.hasContentConditions() {
+		return dm.iterateDecrypted(startTime, endTime, filter)
+	}
+
+	query, args := eventsQuery("id, service, type, timestamp, member", startTime, endTime, filter)
+	rows, err := dm.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	var outline []*config.Event
+	for rows.Next() {
+		event := &config.Event{}
+		if err := rows.Scan(&event.ID, &event.Service, &event.Type, &event.Timestamp, &event.Member); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		outline = append(outline, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return &EventIterator{dm: dm, outline: outline}, nil
+}
+
+// iterateDecrypted builds the outline for text or metadata filters on an encrypted database,
+// which have to be evaluated on decrypted rows. Attachments are still loaded in batches.
+func (dm *DatabaseManager) iterateDecrypted(startTime, endTime time.Time, filter EventFilter) (*EventIterator, error) {
+	query, args := eventsQuery(eventColumns, startTime, endTime, dm.sqlFilter(filter))
+	rows, err := dm.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	events, err := scanEvents(rows)
+	if err != nil {
+		return nil, err
+	}
+	if events, err = dm.decryptAndFilter(events, filter); err != nil {
+		return nil, err
+	}
+
+	outline := make([]*config.Event, 0, len(events))
+	for _, event := range events {
+		outline = append(outline, &config.Event{
+			ID:        event.ID,
+			Service:   event.Service,
+			Type:      event.Type,
+			Timestamp: event.Timestamp,
+			Member:    event.Member,
+		})
+	}
+	return &EventIterator{dm: dm, outline: outline}, nil
+}
+
+// Count returns the number of matched events.
+func (it *EventIterator) Count() int {
+	return len(it.outline)
+}
+
+// Outline returns every matched event in iteration order with only ID, Service, Type,
+// Member and Timestamp set. Exporters use it for summaries written before the events.
+func (it *EventIterator) Outline() []*config.Event {
+	return it.outline
+}
+
+// Next advances to the next event, loading the next batch when needed.
+// It returns false when the iteration is finished or an error occurred.
+func (it *EventIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	// a batch can come back empty when its events were deleted after the outline was read
+	for len(it.batch) == 0 {
+		if it.loaded >= len(it.outline) {
+			it.current = nil
+			return false
+		}
+		if err := it.loadBatch(); err != nil {
+			it.err = err
+			it.current = nil
+			return false
+		}
+	}
+	it.current = it.batch[0]
+	it.batch[0] = nil
+	it.batch = it.batch[1:]
+	return true
+}
+
+// Event returns the current event. Events are not retained by the iterator.
+func (it *EventIterator) Event() *config.Event {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *EventIterator) Err() error {
+	return it.err
+}
+
+func (it *EventIterator) loadBatch() error {
+	end := it.loaded + iteratorBatchSize
+	if end > len(it.outline) {
+		end = len(it.outline)
+	}
+	ids := make([]string, 0, end-it.loaded)
+	for _, event := range it.outline[it.loaded:end] {
+		ids = append(ids, event.ID)
+	}
+
+	byID := make(map[string]*config.Event, len(ids))
+	err := forEachIDChunk(ids, func(placeholders string, args []interface{}) error {
+		rows, err := it.dm.db.Query(`SELECT `+eventColumns+` FROM events WHERE id IN (`+placeholders+`)`, args...)
+		if err != nil {
+			return fmt.Errorf("failed to query events: %w", err)
+		}
+		defer rows.Close()
+
+		events, err := scanEvents(rows)
+		if err != nil {
+			return err
+		}
+		if err := it.dm.decryptEvents(events); err != nil {
+			return err
+		}
+		for _, event := range events {
+			byID[event.ID] = event
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Keep the outline order; events deleted since the outline was read are skipped
+	batch := make([]*config.Event, 0, len(ids))
+	for _, id := range ids {
+		if event, ok := byID[id]; ok {
+			batch = append(batch, event)
+		}
+	}
+	if err := it.dm.hydrateEvents(batch); err != nil {
+		return err
+	}
+
+	it.loaded = end
+	it.batch = batch
+	return nil
+}
@@ -0,0 +1,133 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/eventmeta"
+)
+
+// liftedColumns hold the eventmeta.Common fields of each event. They stay NULL on rows
+// stored before the columns existed until liftMetadata fills them in, and on every row
+// of an encrypted database, where they would expose the encrypted metadata in plaintext.
+var liftedColumns = []struct {
+	name       string
+	definition string
+}{
+	{"url", "TEXT"},
+	{"repository", "TEXT"},
+	{"channel", "TEXT"},
+	{"channel_id", "TEXT"},
+	{"participant_count", "INTEGER"},
+	{"duration_minutes", "INTEGER"},
+}
+
+func (dm *DatabaseManager) migrateLiftedColumns() error {
+	for _, column := range liftedColumns {
+		exists, err := dm.hasColumn("events", column.name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := dm.db.Exec(`ALTER TABLE events ADD COLUMN ` + column.name + ` ` + column.definition); err != nil {
+			return fmt.Errorf("failed to add events.%s column: %w", column.name, err)
+		}
+	}
+
+	for _, column := range []string{"repository", "channel", "channel_id"} {
+		if _, err := dm.db.Exec(`CREATE INDEX IF NOT EXISTS idx_events_` + column + ` ON events(` + column + `)`); err != nil {
+			return fmt.Errorf("failed to create %s index: %w", column, err)
+		}
+	}
+	return dm.liftMetadata()
+}
+
+// liftMetadata fills the lifted columns of rows that do not have them yet. Nothing is
+// filled in for an encrypted database: its lifted columns stay NULL, and scanning its
+// sealed rows on every open would only find rows that cannot be lifted.
+func (dm *DatabaseManager) liftMetadata() error {
+	const pageSize = 500
+
+	// encryption_meta is read here because CreateTables runs before dm.meta is set
+	meta, err := readEncryptionMeta(dm.db)
+	if err != nil {
+		return err
+	}
+	if meta != nil {
+		return nil
+	}
+
+	tx, err := dm.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for lastRowID := int64(0); ; {
+		rows, err := tx.Query(`
+			SELECT rowid, id, service, type, COALESCE(metadata, '') FROM events
+			WHERE repository IS NULL AND rowid > ? ORDER BY rowid LIMIT ?
+		`, lastRowID, pageSize)
+		if err != nil {
+			return fmt.Errorf("failed to read events to lift: %w", err)
+		}
+
+		var page []*config.Event
+		for rows.Next() {
+			event := &config.Event{}
+			if err := rows.Scan(&lastRowID, &event.ID, &event.Service, &event.Type, &event.Metadata); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan event: %w", err)
+			}
+			page = append(page, event)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating rows: %w", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+
+		for _, event := range page {
+			eventmeta.Lift(event)
+			if err := updateLiftedTx(tx, event); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit lifted columns: %w", err)
+	}
+	return nil
+}
+
+// liftedValues returns the lifted column values to store for an event, all NULL when
+// encryption is on. Readers lift them again from the decrypted metadata (decryptEvents).
+func (dm *DatabaseManager) liftedValues(event *config.Event) []interface{} {
+	if dm.cipher != nil {
+		return make([]interface{}, len(liftedColumns))
+	}
+	return []interface{}{event.URL, event.Repository, event.Channel, event.ChannelID, event.ParticipantCount, event.DurationMinutes}
+}
+
+// clearLiftedSQL empties the lifted columns when encryption is turned on
+const clearLiftedSQL = `
+	UPDATE events SET url = NULL, repository = NULL, channel = NULL, channel_id = NULL, participant_count = NULL, duration_minutes = NULL
+	WHERE url IS NOT NULL OR repository IS NOT NULL OR channel IS NOT NULL OR channel_id IS NOT NULL
+		OR participant_count IS NOT NULL OR duration_minutes IS NOT NULL
+`
+
+func updateLiftedTx(tx *sql.Tx, event *config.Event) error {
+	if _, err := tx.Exec(`
+		UPDATE events SET url = ?, repository = ?, channel = ?, channel_id = ?, participant_count = ?, duration_minutes = ?
+		WHERE id = ?
+	`, event.URL, event.Repository, event.Channel, event.ChannelID, event.ParticipantCount, event.DurationMinutes, event.ID); err != nil {
+		return fmt.Errorf("failed to update lifted columns of %s: %w", event.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iriam/worklogr/internal/config"
+)
+
+// EventLinkRecord is a directed link row stored in event_links.
+type EventLinkRecord struct {
+	SourceID string
+	TargetID string
+	Relation string
+	Key      string
+}
+
+// inverseRelations maps a stored relation to how it reads from the target side.
+var inverseRelations = map[string]string{
+	"references": "referenced_by",
+}
+
+// ReplaceEventLinks replaces the links between the given events with the provided links.
+// Only links whose source and target are both in eventIDs are removed: a link to an
+// event outside the set was found from a wider range and is kept.
+func (dm *DatabaseManager) ReplaceEventLinks(eventIDs []string, links []EventLinkRecord) error {
+	tx, err := dm.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	inSet := make(map[string]bool, len(eventIDs))
+	for _, id := range eventIDs {
+		inSet[id] = true
+	}
+	var stale []EventLinkRecord
+	if err := forEachIDChunk(eventIDs, func(placeholders string, args []interface{}) error {
+		rows, err := tx.Query(`SELECT source_event_id, target_event_id, relation, ref_key FROM event_links WHERE source_event_id IN (`+placeholders+`)`, args...)
+		if err != nil {
+			return fmt.Errorf("failed to query event links: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var link EventLinkRecord
+			if err := rows.Scan(&link.SourceID, &link.TargetID, &link.Relation, &link.Key); err != nil {
+				return fmt.Errorf("failed to scan event link: %w", err)
+			}
+			if inSet[link.TargetID] {
+				stale = append(stale, link)
+			}
+		}
+		return rows.Err()
+	}); err != nil {
+		return err
+	}
+
+	if len(stale) > 0 {
+		stmt, err := tx.Prepare(`
+			DELETE FROM event_links
+			WHERE source_event_id = ? AND target_event_id = ? AND relation = ? AND ref_key = ?
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to prepare link delete statement: %w", err)
+		}
+		defer stmt.Close()
+
+		for _, link := range stale {
+			if _, err := stmt.Exec(link.SourceID, link.TargetID, link.Relation, link.Key); err != nil {
+				return fmt.Errorf("failed to delete link %s -> %s: %w", link.SourceID, link.TargetID, err)
+			}
+		}
+	}
+
+	if len(links) > 0 {
+		stmt, err := tx.Prepare(`
+			INSERT OR REPLACE INTO event_links (source_event_id, target_event_id, relation, ref_key)
+			VALUES (?, ?, ?, ?)
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to prepare link statement: %w", err)
+		}
+		defer stmt.Close()
+
+		for _, link := range links {
+			if _, err := stmt.Exec(link.SourceID, link.TargetID, link.Relation, link.Key); err != nil {
+				return fmt.Errorf("failed to insert link %s -> %s: %w", link.SourceID, link.TargetID, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (dm *DatabaseManager) populateLinks(events []*config.Event) error {
+	eventByID := make(map[string]*config.Event, len(events))
+	ids := make([]string, 0, len(events))
+	for _, e := range events {
+		if e == nil || e.ID == "" {
+			continue
+		}
+		ids = append(ids, e.ID)
+		eventByID[e.ID] = e
+	}
+
+	return forEachIDChunk(ids, func(placeholders string, args []interface{}) error {
+		// Outgoing links join the target event, incoming links join the source event.
+		q := `
+			SELECT l.source_event_id, l.relation, l.ref_key, e.id, e.service, e.type, e.title, e.timestamp, 0
+			FROM event_links l
+			JOIN events e ON e.id = l.target_event_id
+			WHERE l.source_event_id IN (` + placeholders + `)
+			UNION ALL
+			SELECT l.target_event_id, l.relation, l.ref_key, e.id, e.service, e.type, e.title, e.timestamp, 1
+			FROM event_links l
+			JOIN events e ON e.id = l.source_event_id
+			WHERE l.target_event_id IN (` + placeholders + `)
+			ORDER BY 8 ASC
+		`
+
+		rows, err := dm.db.Query(q, append(append([]interface{}{}, args...), args...)...)
+		if err != nil {
+			return fmt.Errorf("failed to query event links: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var eventID, relation, key string
+			var link config.EventLink
+			var incoming int
+			if err := rows.Scan(&eventID, &relation, &key, &link.EventID, &link.Service, &link.Type, &link.Title, &link.Timestamp, &incoming); err != nil {
+				return fmt.Errorf("failed to scan event link row: %w", err)
+			}
+
+			link.Relation = relation
+			link.Key = key
+			if incoming != 0 {
+				if inverse, ok := inverseRelations[relation]; ok {
+					link.Relation = inverse
+				}
+			}
+
+			if e := eventByID[eventID]; e != nil {
+				e.Related = append(e.Related, link)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating event link rows: %w", err)
+		}
+		return nil
+	})
+}
+
+// forEachIDChunk calls fn with placeholder lists small enough that a query may
+// repeat them twice and still stay below SQLite's default variable limit (999).
+func forEachIDChunk(ids []string, fn func(placeholders string, args []interface{}) error) error {
+	const chunkSize = 450
+	for i := 0; i < len(ids); i += chunkSize {
+		end := i + chunkSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		chunk := ids[i:end]
+
+		placeholders := make([]string, 0, len(chunk))
+		args := make([]interface{}, 0, len(chunk))
+		for _, id := range chunk {
+			placeholders = append(placeholders, "?")
+			args = append(args, id)
+		}
+
+		if err := fn(strings.Join(placeholders, ","), args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RetentionRule is a cutoff applied to the events of a service, or of one event type of a
+// service. An empty Service matches every event. A zero cutoff keeps the data.
+type RetentionRule struct {
+	Service string
+	Type    string
+	// EventsBefore deletes events (with attachments, links and annotations) older than it
+	EventsBefore time.Time
+	// AttachmentTextBefore clears attachment text of events older than it
+	AttachmentTextBefore time.Time
+}
+
+// PruneStats reports what Prune removed (or would remove in a dry run).
+type PruneStats struct {
+	EventsDeleted int
+	// EventsByService breaks EventsDeleted down by service
+	EventsByService        map[string]int
+	AttachmentsDeleted     int
+	AttachmentTextsCleared int
+	LinksDeleted           int
+	AnnotationsDeleted     int
+}
+
+// Prune applies retention rules. For each event the first matching rule is used, so rules
+// must be ordered most specific first. Attachments, links, annotations and versions of deleted
+// events are removed with them, as are rows left behind by events deleted earlier.
+// With dryRun the changes are counted and rolled back.
+func (dm *DatabaseManager) Prune(rules []RetentionRule, dryRun bool) (*PruneStats, error) {
+	ctx := context.Background()
+	// The temporary table lives on one connection
+	conn, err := dm.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stats, err := pruneTx(ctx, tx, rules)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `DROP TABLE temp.prune_ids`); err != nil {
+		return nil, fmt.Errorf("failed to clean up prune state: %w", err)
+	}
+	if dryRun {
+		return stats, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit prune: %w", err)
+	}
+	return stats, nil
+}
+
+func pruneTx(ctx context.Context, tx *sql.Tx, rules []RetentionRule) (*PruneStats, error) {
+	stats := &PruneStats{EventsByService: make(map[string]int)}
+
+	eventCutoff, eventArgs := retentionCutoff(rules, func(rule RetentionRule) time.Time { return rule.EventsBefore })
+	if _, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS temp.prune_ids`); err != nil {
+		return nil, fmt.Errorf("failed to reset prune state: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TEMP TABLE prune_ids AS
+		SELECT id, service FROM events WHERE datetime(timestamp) < `+eventCutoff, eventArgs...); err != nil {
+		return nil, fmt.Errorf("failed to select expired events: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT service, COUNT(*) FROM temp.prune_ids GROUP BY service`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count expired events: %w", err)
+	}
+	for rows.Next() {
+		var service string
+		var count int
+		if err := rows.Scan(&service, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan expired events: %w", err)
+		}
+		stats.EventsByService[service] = count
+		stats.EventsDeleted += count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM events WHERE id IN (SELECT id FROM temp.prune_ids)`); err != nil {
+		return nil, fmt.Errorf("failed to delete expired events: %w", err)
+	}
+
+	// Rows of deleted events, including those orphaned before deletes cascaded
+	if stats.AttachmentsDeleted, err = execCount(ctx, tx, `
+		DELETE FROM event_attachments WHERE event_id NOT IN (SELECT id FROM events)
+	`); err != nil {
+		return nil, fmt.Errorf("failed to delete attachments: %w", err)
+	}
+	if stats.LinksDeleted, err = execCount(ctx, tx, `
+		DELETE FROM event_links
+		WHERE source_event_id NOT IN (SELECT id FROM events) OR target_event_id NOT IN (SELECT id FROM events)
+	`); err != nil {
+		return nil, fmt.Errorf("failed to delete links: %w", err)
+	}
+	if stats.AnnotationsDeleted, err = execCount(ctx, tx, `
+		DELETE FROM event_annotations WHERE event_id NOT IN (SELECT id FROM events)
+	`); err != nil {
+		return nil, fmt.Errorf("failed to delete annotations: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM event_versions WHERE event_id NOT IN (SELECT id FROM events)
+	`); err != nil {
+		return nil, fmt.Errorf("failed to delete event versions: %w", err)
+	}
+
+	textCutoff, textArgs := retentionCutoff(rules, func(rule RetentionRule) time.Time { return rule.AttachmentTextBefore })
+	if stats.AttachmentTextsCleared, err = execCount(ctx, tx, `
+		UPDATE event_attachments SET text_full = '', truncated = 0
+		WHERE COALESCE(text_full, '') != '' AND event_id IN (
+			SELECT id FROM events WHERE datetime(timestamp) < `+textCutoff+`
+		)
+	`, textArgs...); err != nil {
+		return nil, fmt.Errorf("failed to clear attachment text: %w", err)
+	}
+
+	return stats, nil
+}
+
+// retentionCutoff builds a CASE expression giving each event row its cutoff time.
+// Rules without a cutoff yield NULL, which never compares as older.
+func retentionCutoff(rules []RetentionRule, cutoff func(RetentionRule) time.Time) (string, []interface{}) {
+	var expr strings.Builder
+	var args []interface{}
+	expr.WriteString("CASE")
+
+	bind := func(t time.Time) {
+		if t.IsZero() {
+			args = append(args, nil)
+			return
+		}
+		args = append(args, t.UTC().Format("2006-01-02 15:04:05"))
+	}
+
+	fallback := time.Time{}
+	for _, rule := range rules {
+		switch {
+		case rule.Service == "":
+			fallback = cutoff(rule)
+			continue
+		case rule.Type == "":
+			expr.WriteString(" WHEN service = ? THEN datetime(?)")
+			args = append(args, rule.Service)
+		default:
+			expr.WriteString(" WHEN service = ? AND type = ? THEN datetime(?)")
+			args = append(args, rule.Service, rule.Type)
+		}
+		bind(cutoff(rule))
+	}
+	bind(fallback)
+	if len(args) == 1 {
+		return "datetime(?)", args
+	}
+	expr.WriteString(" ELSE datetime(?) END")
+	return expr.String(), args
+}
+
+// Vacuum rebuilds the database file so that space freed by Prune is returned to the disk
+func (dm *DatabaseManager) Vacuum() error {
+	if _, err := dm.db.Exec(`VACUUM`); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	return nil
+}
@@ -10,12 +10,17 @@ import (
 	"time"
 
 	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/eventmeta"
 	_ "github.com/mattn/go-sqlite3"
 )
 
 // DatabaseManager handles SQLite database operations
 type DatabaseManager struct {
 	db *sql.DB
+	// meta describes how the database is encrypted (nil for plaintext databases)
+	meta *encryptionMeta
+	// cipher encrypts sensitive columns when encryption is configured (see UseEncryption)
+	cipher *columnCipher
 }
 
 // NewDatabaseManager creates a new database manager
@@ -35,6 +40,10 @@ func NewDatabaseManager(dbPath string) (*DatabaseManager, error) {
 		db.Close()
 		return nil, fmt.Errorf("failed to create tables: %w", err)
 	}
+	if manager.meta, err = readEncryptionMeta(db); err != nil {
+		db.Close()
+		return nil, err
+	}
 
 	return manager, nil
 }
@@ -72,13 +81,106 @@ func (dm *DatabaseManager) CreateTables() error {
 	);
 
 	CREATE INDEX IF NOT EXISTS idx_event_attachments_event_id ON event_attachments(event_id);
+
+	CREATE TABLE IF NOT EXISTS event_links (
+		source_event_id TEXT NOT NULL,
+		target_event_id TEXT NOT NULL,
+		relation TEXT NOT NULL,
+		ref_key TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (source_event_id, target_event_id, relation, ref_key)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_event_links_target ON event_links(target_event_id);
+
+	CREATE TABLE IF NOT EXISTS event_annotations (
+		event_id TEXT PRIMARY KEY,
+		hidden INTEGER NOT NULL DEFAULT 0,
+		pinned INTEGER NOT NULL DEFAULT 0,
+		note TEXT NOT NULL DEFAULT '',
+		tags TEXT NOT NULL DEFAULT '[]',
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS event_versions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id TEXT NOT NULL,
+		title TEXT NOT NULL,
+		content TEXT,
+		timestamp DATETIME NOT NULL,
+		metadata TEXT,
+		seen_at DATETIME,
+		replaced_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_event_versions_event_id ON event_versions(event_id);
+
+	CREATE TABLE IF NOT EXISTS encryption_meta (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		kdf TEXT NOT NULL,
+		check_value TEXT NOT NULL
+	);
 	`
 
 	if _, err := dm.db.Exec(query); err != nil {
 		return fmt.Errorf("failed to create tables: %w", err)
 	}
 
-	return nil
+	return dm.migrate()
+}
+
+// migrate adds columns introduced after the initial schema to existing databases.
+func (dm *DatabaseManager) migrate() error {
+	hasMember, err := dm.hasColumn("events", "member")
+	if err != nil {
+		return err
+	}
+	if !hasMember {
+		if _, err := dm.db.Exec(`ALTER TABLE events ADD COLUMN member TEXT NOT NULL DEFAULT ''`); err != nil {
+			return fmt.Errorf("failed to add events.member column: %w", err)
+		}
+	}
+	if _, err := dm.db.Exec(`CREATE INDEX IF NOT EXISTS idx_events_member ON events(member)`); err != nil {
+		return fmt.Errorf("failed to create member index: %w", err)
+	}
+
+	hasDeletedAt, err := dm.hasColumn("events", "deleted_at")
+	if err != nil {
+		return err
+	}
+	if !hasDeletedAt {
+		if _, err := dm.db.Exec(`ALTER TABLE events ADD COLUMN deleted_at DATETIME`); err != nil {
+			return fmt.Errorf("failed to add events.deleted_at column: %w", err)
+		}
+	}
+
+	return dm.migrateLiftedColumns()
+}
+
+func (dm *DatabaseManager) hasColumn(table, column string) (bool, error) {
+	rows, err := dm.db.Query("PRAGMA table_info(" + table + ")")
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return false, fmt.Errorf("failed to scan table info: %w", err)
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
 }
 
 func attachmentRowID(eventID, fileID string) string {
@@ -97,24 +199,25 @@ func (dm *DatabaseManager) InsertEvent(event *config.Event) error {
 	defer tx.Rollback()
 
 	stmt, err := tx.Prepare(`
-		INSERT OR REPLACE INTO events (id, service, type, title, content, timestamp, metadata, user_id)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT OR REPLACE INTO events (id, service, type, title, content, timestamp, metadata, user_id, member, deleted_at,
+			url, repository, channel, channel_id, participant_count, duration_minutes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %w", err)
 	}
 	defer stmt.Close()
 
-	if _, err := stmt.Exec(
-		event.ID,
-		event.Service,
-		event.Type,
-		event.Title,
-		event.Content,
-		event.Timestamp,
-		event.Metadata,
-		event.UserID,
-	); err != nil {
+	eventmeta.Lift(event)
+	content, metadata, err := dm.sealEvent(event)
+	if err != nil {
+		return err
+	}
+	if err := dm.recordVersionTx(tx, event); err != nil {
+		return err
+	}
+	args := []interface{}{event.ID, event.Service, event.Type, event.Title, content, event.Timestamp, metadata, event.UserID, event.Member, event.DeletedAt}
+	if _, err := stmt.Exec(append(args, dm.liftedValues(event)...)...); err != nil {
 		return fmt.Errorf("failed to insert event: %w", err)
 	}
 
@@ -137,8 +240,9 @@ func (dm *DatabaseManager) InsertEvents(events []*config.Event) error {
 	defer tx.Rollback()
 
 	stmt, err := tx.Prepare(`
-		INSERT OR REPLACE INTO events (id, service, type, title, content, timestamp, metadata, user_id)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT OR REPLACE INTO events (id, service, type, title, content, timestamp, metadata, user_id, member, deleted_at,
+			url, repository, channel, channel_id, participant_count, duration_minutes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %w", err)
@@ -146,17 +250,16 @@ func (dm *DatabaseManager) InsertEvents(events []*config.Event) error {
 	defer stmt.Close()
 
 	for _, event := range events {
-		_, err := stmt.Exec(
-			event.ID,
-			event.Service,
-			event.Type,
-			event.Title,
-			event.Content,
-			event.Timestamp,
-			event.Metadata,
-			event.UserID,
-		)
+		eventmeta.Lift(event)
+		content, metadata, err := dm.sealEvent(event)
 		if err != nil {
+			return err
+		}
+		if err := dm.recordVersionTx(tx, event); err != nil {
+			return err
+		}
+		args := []interface{}{event.ID, event.Service, event.Type, event.Title, content, event.Timestamp, metadata, event.UserID, event.Member, event.DeletedAt}
+		if _, err := stmt.Exec(append(args, dm.liftedValues(event)...)...); err != nil {
 			return fmt.Errorf("failed to insert event %s: %w", event.ID, err)
 		}
 
@@ -195,6 +298,10 @@ func (dm *DatabaseManager) insertAttachmentsTx(tx *sql.Tx, event *config.Event)
 		if a.Truncated {
 			truncated = 1
 		}
+		textFull, err := dm.cipher.seal(a.TextFull)
+		if err != nil {
+			return fmt.Errorf("attachment of event %s: %w", event.ID, err)
+		}
 		if _, err := attachStmt.Exec(
 			attachmentRowID(event.ID, a.FileID),
 			event.ID,
@@ -202,7 +309,7 @@ func (dm *DatabaseManager) insertAttachmentsTx(tx *sql.Tx, event *config.Event)
 			a.Title,
 			a.MimeType,
 			a.ExportAs,
-			a.TextFull,
+			textFull,
 			truncated,
 		); err != nil {
 			return fmt.Errorf("failed to insert attachment for event %s: %w", event.ID, err)
@@ -214,40 +321,67 @@ func (dm *DatabaseManager) insertAttachmentsTx(tx *sql.Tx, event *config.Event)
 
 // GetEvents retrieves events within a time range
 func (dm *DatabaseManager) GetEvents(startTime, endTime time.Time, services []string) ([]*config.Event, error) {
+	return dm.QueryEvents(startTime, endTime, EventFilter{Services: services})
+}
+
+// QueryEvents retrieves events within a time range that match the filter
+func (dm *DatabaseManager) QueryEvents(startTime, endTime time.Time, filter EventFilter) ([]*config.Event, error) {
+	query, args := eventsQuery(eventColumns, startTime, endTime, dm.sqlFilter(filter))
+
+	rows, err := dm.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	events, err := scanEvents(rows)
+	if err != nil {
+		return nil, err
+	}
+	if events, err = dm.decryptAndFilter(events, filter); err != nil {
+		return nil, err
+	}
+
+	if err := dm.hydrateEvents(events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// eventColumns are the events columns read into config.Event by scanEvents
+const eventColumns = "id, service, type, title, content, timestamp, metadata, user_id, member, deleted_at, " +
+	"COALESCE(url, ''), COALESCE(repository, ''), COALESCE(channel, ''), COALESCE(channel_id, ''), " +
+	"COALESCE(participant_count, 0), COALESCE(duration_minutes, 0)"
+
+// eventsQuery builds the SELECT for events within a time range that match the filter, oldest first
+func eventsQuery(columns string, startTime, endTime time.Time, filter EventFilter) (string, []interface{}) {
 	// Convert times to UTC for consistent database comparison
 	startTimeUTC := startTime.UTC()
 	endTimeUTC := endTime.UTC()
-	
+
 	query := `
-	SELECT id, service, type, title, content, timestamp, metadata, user_id
+	SELECT ` + columns + `
 	FROM events
 	WHERE datetime(timestamp) >= datetime(?) AND datetime(timestamp) <= datetime(?)
 	`
 	args := []interface{}{startTimeUTC.Format("2006-01-02 15:04:05"), endTimeUTC.Format("2006-01-02 15:04:05")}
 
-	if len(services) > 0 {
-		placeholders := ""
-		for i, service := range services {
-			if i > 0 {
-				placeholders += ", "
-			}
-			placeholders += "?"
-			args = append(args, service)
-		}
-		query += " AND service IN (" + placeholders + ")"
+	clauses, filterArgs := filter.where()
+	for _, clause := range clauses {
+		query += " AND " + clause
 	}
+	args = append(args, filterArgs...)
 
 	query += " ORDER BY timestamp ASC"
+	return query, args
+}
 
-	rows, err := dm.db.Query(query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query events: %w", err)
-	}
-	defer rows.Close()
-
+// scanEvents reads rows selected with eventColumns
+func scanEvents(rows *sql.Rows) ([]*config.Event, error) {
 	var events []*config.Event
 	for rows.Next() {
 		event := &config.Event{}
+		var deletedAt sql.NullTime
 		err := rows.Scan(
 			&event.ID,
 			&event.Service,
@@ -257,23 +391,44 @@ func (dm *DatabaseManager) GetEvents(startTime, endTime time.Time, services []st
 			&event.Timestamp,
 			&event.Metadata,
 			&event.UserID,
+			&event.Member,
+			&deletedAt,
+			&event.URL,
+			&event.Repository,
+			&event.Channel,
+			&event.ChannelID,
+			&event.ParticipantCount,
+			&event.DurationMinutes,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan event: %w", err)
 		}
+		if deletedAt.Valid {
+			event.DeletedAt = &deletedAt.Time
+		}
 		events = append(events, event)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating rows: %w", err)
 	}
+	return events, nil
+}
 
+// hydrateEvents loads the attachments, links and annotations stored in separate tables
+func (dm *DatabaseManager) hydrateEvents(events []*config.Event) error {
 	// Hydrate attachments from separate table
 	if err := dm.populateAttachments(events); err != nil {
-		return nil, err
+		return err
 	}
 
-	return events, nil
+	// Hydrate cross-service links
+	if err := dm.populateLinks(events); err != nil {
+		return err
+	}
+
+	// Hydrate user annotations (pinned, notes, tags)
+	return dm.populateAnnotations(events)
 }
 
 func (dm *DatabaseManager) populateAttachments(events []*config.Event) error {
@@ -333,6 +488,10 @@ func (dm *DatabaseManager) populateAttachments(events []*config.Event) error {
 			if e == nil {
 				continue
 			}
+			if textFull, err = dm.cipher.open(textFull); err != nil {
+				rows.Close()
+				return fmt.Errorf("attachment of event %s: %w", eventID, err)
+			}
 			e.Attachments = append(e.Attachments, config.EventAttachment{
 				FileID:    fileID,
 				Title:     title,
@@ -357,23 +516,39 @@ func (dm *DatabaseManager) GetEventsByService(service string, startTime, endTime
 	return dm.GetEvents(startTime, endTime, []string{service})
 }
 
-// DeleteOldEvents deletes events older than the specified duration
-func (dm *DatabaseManager) DeleteOldEvents(olderThan time.Duration) error {
-	cutoff := time.Now().Add(-olderThan)
-	query := "DELETE FROM events WHERE timestamp < ?"
+// ExistingEventIDs returns which of the given event IDs are already stored
+func (dm *DatabaseManager) ExistingEventIDs(eventIDs []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	err := forEachIDChunk(eventIDs, func(placeholders string, args []interface{}) error {
+		rows, err := dm.db.Query(`SELECT id FROM events WHERE id IN (`+placeholders+`)`, args...)
+		if err != nil {
+			return fmt.Errorf("failed to query event ids: %w", err)
+		}
+		defer rows.Close()
 
-	result, err := dm.db.Exec(query, cutoff)
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				return fmt.Errorf("failed to scan event id: %w", err)
+			}
+			existing[id] = true
+		}
+		return rows.Err()
+	})
 	if err != nil {
-		return fmt.Errorf("failed to delete old events: %w", err)
+		return nil, err
 	}
+	return existing, nil
+}
 
-	rowsAffected, err := result.RowsAffected()
+// DeleteOldEvents deletes events older than the specified duration, together with their
+// attachments, links and annotations. It returns the number of deleted events.
+func (dm *DatabaseManager) DeleteOldEvents(olderThan time.Duration) (int, error) {
+	stats, err := dm.Prune([]RetentionRule{{EventsBefore: time.Now().Add(-olderThan)}}, false)
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return 0, err
 	}
-
-	fmt.Printf("Deleted %d old events\n", rowsAffected)
-	return nil
+	return stats.EventsDeleted, nil
 }
 
 // Close closes the database connection
@@ -1,7 +1,10 @@
 package database
 
 import (
+	"database/sql"
+	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -154,3 +157,1020 @@ func TestGetEventsReturnsSortedByTimestamp(t *testing.T) {
 		}
 	}
 }
+
+func TestReplaceEventLinksHydratesRelatedFromBothSides(t *testing.T) {
+	dm := newTestDatabaseManager(t)
+	base := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+
+	if err := dm.InsertEvents([]*config.Event{
+		testEvent("slack-1", "slack", base),
+		testEvent("pr-1", "github", base.Add(time.Hour)),
+	}); err != nil {
+		t.Fatalf("InsertEvents returned error: %v", err)
+	}
+
+	if err := dm.ReplaceEventLinks([]string{"slack-1", "pr-1"}, []EventLinkRecord{
+		{SourceID: "slack-1", TargetID: "pr-1", Relation: "references", Key: "github:acme/api#1"},
+	}); err != nil {
+		t.Fatalf("ReplaceEventLinks returned error: %v", err)
+	}
+
+	events, err := dm.GetEvents(base.Add(-time.Hour), base.Add(2*time.Hour), nil)
+	if err != nil {
+		t.Fatalf("GetEvents returned error: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+
+	slack, pr := events[0], events[1]
+	if len(slack.Related) != 1 || slack.Related[0].EventID != "pr-1" || slack.Related[0].Relation != "references" {
+		t.Fatalf("unexpected slack related links: %+v", slack.Related)
+	}
+	if slack.Related[0].Service != "github" || !slack.Related[0].Timestamp.Equal(base.Add(time.Hour)) {
+		t.Fatalf("expected related link to carry target event details, got %+v", slack.Related[0])
+	}
+	if len(pr.Related) != 1 || pr.Related[0].EventID != "slack-1" || pr.Related[0].Relation != "referenced_by" {
+		t.Fatalf("unexpected pr related links: %+v", pr.Related)
+	}
+
+	if err := dm.ReplaceEventLinks([]string{"slack-1", "pr-1"}, nil); err != nil {
+		t.Fatalf("ReplaceEventLinks returned error: %v", err)
+	}
+	events, err = dm.GetEvents(base.Add(-time.Hour), base.Add(2*time.Hour), nil)
+	if err != nil {
+		t.Fatalf("GetEvents returned error: %v", err)
+	}
+	if len(events[0].Related) != 0 || len(events[1].Related) != 0 {
+		t.Fatalf("expected links between slack-1 and pr-1 to be replaced, got %+v / %+v", events[0].Related, events[1].Related)
+	}
+}
+
+func TestReplaceEventLinksKeepsLinksToEventsOutsideTheSet(t *testing.T) {
+	dm := newTestDatabaseManager(t)
+	base := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+
+	if err := dm.InsertEvents([]*config.Event{
+		testEvent("slack-1", "slack", base),
+		testEvent("pr-1", "github", base.Add(time.Hour)),
+		testEvent("pr-2", "github", base.Add(2*time.Hour)),
+	}); err != nil {
+		t.Fatalf("InsertEvents returned error: %v", err)
+	}
+	if err := dm.ReplaceEventLinks([]string{"slack-1", "pr-1", "pr-2"}, []EventLinkRecord{
+		{SourceID: "slack-1", TargetID: "pr-1", Relation: "references", Key: "github:acme/api#1"},
+		{SourceID: "slack-1", TargetID: "pr-2", Relation: "references", Key: "github:acme/api#2"},
+	}); err != nil {
+		t.Fatalf("ReplaceEventLinks returned error: %v", err)
+	}
+
+	// A later run only sees slack-1 and pr-2 (pr-1 is outside its range) and drops the link to pr-2
+	if err := dm.ReplaceEventLinks([]string{"slack-1", "pr-2"}, nil); err != nil {
+		t.Fatalf("ReplaceEventLinks returned error: %v", err)
+	}
+
+	events, err := dm.GetEvents(base.Add(-time.Hour), base.Add(3*time.Hour), nil)
+	if err != nil {
+		t.Fatalf("GetEvents returned error: %v", err)
+	}
+	slack := events[0]
+	if len(slack.Related) != 1 || slack.Related[0].EventID != "pr-1" {
+		t.Fatalf("expected only the link to pr-1 to remain, got %+v", slack.Related)
+	}
+}
+
+func TestQueryEventsFiltersByMember(t *testing.T) {
+	dm := newTestDatabaseManager(t)
+	base := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	alice := testEvent("alice/event-1", "slack", base)
+	alice.Member = "alice"
+	bob := testEvent("bob/event-1", "slack", base.Add(time.Minute))
+	bob.Member = "bob"
+	solo := testEvent("event-2", "github", base.Add(2*time.Minute))
+
+	if err := dm.InsertEvents([]*config.Event{alice, bob, solo}); err != nil {
+		t.Fatalf("InsertEvents returned error: %v", err)
+	}
+
+	events, err := dm.QueryEvents(base.Add(-time.Hour), base.Add(time.Hour), EventFilter{Members: []string{"bob"}})
+	if err != nil {
+		t.Fatalf("QueryEvents returned error: %v", err)
+	}
+	if len(events) != 1 || events[0].ID != "bob/event-1" || events[0].Member != "bob" {
+		t.Fatalf("expected only bob's event, got %+v", events)
+	}
+
+	all, err := dm.GetEvents(base.Add(-time.Hour), base.Add(time.Hour), nil)
+	if err != nil {
+		t.Fatalf("GetEvents returned error: %v", err)
+	}
+	if len(all) != 3 || all[2].Member != "" {
+		t.Fatalf("expected all events with empty member for single-user rows, got %+v", all)
+	}
+}
+
+func TestNewDatabaseManagerAddsMemberColumnToExistingDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+	legacy, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open legacy database: %v", err)
+	}
+	if _, err := legacy.Exec(`
+		CREATE TABLE events (
+			id TEXT PRIMARY KEY,
+			service TEXT NOT NULL,
+			type TEXT NOT NULL,
+			title TEXT NOT NULL,
+			content TEXT,
+			timestamp DATETIME NOT NULL,
+			metadata TEXT,
+			user_id TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		INSERT INTO events (id, service, type, title, content, timestamp, metadata, user_id)
+		VALUES ('old-1', 'slack', 'message', 'old', '', '2026-03-01 10:00:00', '', 'U1');
+	`); err != nil {
+		t.Fatalf("failed to create legacy schema: %v", err)
+	}
+	legacy.Close()
+
+	dm, err := NewDatabaseManager(dbPath)
+	if err != nil {
+		t.Fatalf("NewDatabaseManager returned error: %v", err)
+	}
+	defer dm.Close()
+
+	events, err := dm.GetEvents(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), nil)
+	if err != nil {
+		t.Fatalf("GetEvents returned error: %v", err)
+	}
+	if len(events) != 1 || events[0].ID != "old-1" || events[0].Member != "" {
+		t.Fatalf("expected legacy event to be readable after migration, got %+v", events)
+	}
+}
+
+func TestQueryEventsAppliesTypeTextAndMetadataFilters(t *testing.T) {
+	dm := newTestDatabaseManager(t)
+	base := time.Date(2026, 3, 3, 10, 0, 0, 0, time.UTC)
+
+	pr := testEvent("pr-1", "github", base)
+	pr.Type = "pull_request"
+	pr.Title = "Fix 100% CPU usage"
+	pr.Metadata = `{"repository":"octo/app","state":"merged","draft":false,"pull_request":{"number":42}}`
+	commit := testEvent("commit-1", "github", base.Add(time.Minute))
+	commit.Type = "commit"
+	commit.Metadata = `{"repository":"octo/lib"}`
+	message := testEvent("msg-1", "slack", base.Add(2*time.Minute))
+	message.Content = "Deploy finished"
+	message.Metadata = `{"channel_name":"dev","channel_id":"C123"}`
+	broken := testEvent("broken-1", "slack", base.Add(3*time.Minute))
+	broken.Metadata = "not json"
+
+	if err := dm.InsertEvents([]*config.Event{pr, commit, message, broken}); err != nil {
+		t.Fatalf("InsertEvents returned error: %v", err)
+	}
+
+	whereState, err := ParseWhere("metadata.state=merged")
+	if err != nil {
+		t.Fatalf("ParseWhere returned error: %v", err)
+	}
+	whereNumber, _ := ParseWhere("metadata.pull_request.number=42")
+	whereDraft, _ := ParseWhere("metadata.draft=false")
+
+	tests := []struct {
+		name   string
+		filter EventFilter
+		want   []string
+	}{
+		{"type", EventFilter{Types: []string{"commit"}}, []string{"commit-1"}},
+		{"exclude type", EventFilter{ExcludeTypes: []string{"message"}}, []string{"pr-1", "commit-1"}},
+		{"match is case-insensitive", EventFilter{Match: "deploy"}, []string{"msg-1"}},
+		{"match escapes wildcards", EventFilter{Match: "100%"}, []string{"pr-1"}},
+		{"repo", EventFilter{Metadata: []MetadataCondition{{Paths: []string{"$.repository"}, Values: []string{"octo/lib", "octo/other"}}}}, []string{"commit-1"}},
+		{"channel by id or name", EventFilter{Metadata: []MetadataCondition{{Paths: []string{"$.channel_name", "$.channel_id"}, Values: []string{"C123"}}}}, []string{"msg-1"}},
+		{"where", EventFilter{Metadata: []MetadataCondition{whereState}}, []string{"pr-1"}},
+		{"nested number and boolean", EventFilter{Metadata: []MetadataCondition{whereNumber, whereDraft}}, []string{"pr-1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := dm.QueryEvents(base.Add(-time.Hour), base.Add(time.Hour), tt.filter)
+			if err != nil {
+				t.Fatalf("QueryEvents returned error: %v", err)
+			}
+			var got []string
+			for _, event := range events {
+				got = append(got, event.ID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseWhereRejectsInvalidExpressions(t *testing.T) {
+	for _, expr := range []string{"state=merged", "metadata.state", "metadata.a'b=1", "metadata.=x"} {
+		if _, err := ParseWhere(expr); err == nil {
+			t.Fatalf("expected ParseWhere(%q) to return error", expr)
+		}
+	}
+}
+
+func TestAnnotationsSurviveRecollectionAndFilterEvents(t *testing.T) {
+	dm := newTestDatabaseManager(t)
+	base := time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)
+	if err := dm.InsertEvents([]*config.Event{
+		testEvent("a", "slack", base),
+		testEvent("b", "slack", base.Add(time.Minute)),
+		testEvent("c", "github", base.Add(2*time.Minute)),
+	}); err != nil {
+		t.Fatalf("InsertEvents returned error: %v", err)
+	}
+
+	if err := dm.SaveAnnotation("a", &config.EventAnnotation{Pinned: true, Note: "key decision", Tags: []string{"release", "infra"}}); err != nil {
+		t.Fatalf("SaveAnnotation returned error: %v", err)
+	}
+	if err := dm.SaveAnnotation("b", &config.EventAnnotation{Hidden: true}); err != nil {
+		t.Fatalf("SaveAnnotation returned error: %v", err)
+	}
+	// re-collecting replaces the event rows but must keep annotations
+	if err := dm.InsertEvents([]*config.Event{testEvent("a", "slack", base), testEvent("b", "slack", base.Add(time.Minute))}); err != nil {
+		t.Fatalf("InsertEvents returned error: %v", err)
+	}
+
+	start, end := base.Add(-time.Hour), base.Add(time.Hour)
+	tests := []struct {
+		name   string
+		filter EventFilter
+		want   []string
+	}{
+		{name: "hidden skipped by default", filter: EventFilter{}, want: []string{"a", "c"}},
+		{name: "include hidden", filter: EventFilter{IncludeHidden: true}, want: []string{"a", "b", "c"}},
+		{name: "pinned only", filter: EventFilter{PinnedOnly: true}, want: []string{"a"}},
+		{name: "tag", filter: EventFilter{Tags: []string{"infra", "other"}}, want: []string{"a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := dm.QueryEvents(start, end, tt.filter)
+			if err != nil {
+				t.Fatalf("QueryEvents returned error: %v", err)
+			}
+			var got []string
+			for _, event := range events {
+				got = append(got, event.ID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	events, err := dm.GetEvents(start, end, nil)
+	if err != nil {
+		t.Fatalf("GetEvents returned error: %v", err)
+	}
+	if annotation := events[0].Annotation; annotation == nil || !annotation.Pinned || annotation.Note != "key decision" || strings.Join(annotation.Tags, ",") != "release,infra" {
+		t.Fatalf("expected annotation to be hydrated, got %+v", events[0].Annotation)
+	}
+
+	// Only the given events are cleared
+	if err := dm.SaveAnnotation("c", &config.EventAnnotation{Pinned: true}); err != nil {
+		t.Fatalf("SaveAnnotation returned error: %v", err)
+	}
+	count, err := dm.ClearPinnedAndHidden([]string{"a", "b"})
+	if err != nil {
+		t.Fatalf("ClearPinnedAndHidden returned error: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 cleared annotations, got %d", count)
+	}
+	annotations, err := dm.GetAnnotations([]string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("GetAnnotations returned error: %v", err)
+	}
+	if _, ok := annotations["b"]; ok || annotations["a"] == nil || annotations["a"].Note != "key decision" {
+		t.Fatalf("expected notes to be kept and empty annotations removed, got %+v", annotations)
+	}
+	if annotations["c"] == nil || !annotations["c"].Pinned {
+		t.Fatalf("expected events outside the given IDs to stay pinned, got %+v", annotations["c"])
+	}
+}
+
+func TestIterateEventsStreamsInBatchesWithHydration(t *testing.T) {
+	dm := newTestDatabaseManager(t)
+	base := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	total := iteratorBatchSize*2 + 17
+	var events []*config.Event
+	for i := 0; i < total; i++ {
+		event := testEvent(fmt.Sprintf("event-%04d", i), "slack", base.Add(time.Duration(i)*time.Minute))
+		if i%100 == 0 {
+			event.Attachments = []config.EventAttachment{{FileID: "file", Title: "notes", TextFull: "body"}}
+		}
+		events = append(events, event)
+	}
+	events = append(events, testEvent("other", "github", base))
+	if err := dm.InsertEvents(events); err != nil {
+		t.Fatalf("InsertEvents returned error: %v", err)
+	}
+	if err := dm.SaveAnnotation("event-0001", &config.EventAnnotation{Hidden: true}); err != nil {
+		t.Fatalf("SaveAnnotation returned error: %v", err)
+	}
+
+	iterator, err := dm.IterateEvents(base.Add(-time.Hour), base.Add(24*time.Hour), EventFilter{Services: []string{"slack"}})
+	if err != nil {
+		t.Fatalf("IterateEvents returned error: %v", err)
+	}
+	if iterator.Count() != total-1 || len(iterator.Outline()) != total-1 {
+		t.Fatalf("expected %d matched events, got %d", total-1, iterator.Count())
+	}
+
+	// events deleted after the outline was read are skipped
+	if _, err := dm.db.Exec(`DELETE FROM events WHERE id = 'event-0300'`); err != nil {
+		t.Fatalf("failed to delete event: %v", err)
+	}
+
+	var ids []string
+	withAttachments := 0
+	for iterator.Next() {
+		event := iterator.Event()
+		if event.Title == "" || event.Content == "" {
+			t.Fatalf("expected full event row, got %+v", event)
+		}
+		if len(event.Attachments) > 0 {
+			withAttachments++
+		}
+		ids = append(ids, event.ID)
+	}
+	if err := iterator.Err(); err != nil {
+		t.Fatalf("iteration returned error: %v", err)
+	}
+
+	if len(ids) != total-2 {
+		t.Fatalf("expected %d events, got %d", total-2, len(ids))
+	}
+	if ids[0] != "event-0000" || ids[1] != "event-0002" || ids[len(ids)-1] != fmt.Sprintf("event-%04d", total-1) {
+		t.Fatalf("unexpected order: first=%v last=%s", ids[:2], ids[len(ids)-1])
+	}
+	if withAttachments != 4 {
+		t.Fatalf("expected attachments for 4 events, got %d", withAttachments)
+	}
+	if iterator.Next() {
+		t.Fatalf("expected iterator to stay exhausted")
+	}
+}
+
+func TestBackupAndRestoreRoundTrip(t *testing.T) {
+	dm := newTestDatabaseManager(t)
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	if err := dm.InsertEvent(testEvent("event-1", "slack", base, config.EventAttachment{FileID: "file-1", TextFull: "hello"})); err != nil {
+		t.Fatalf("InsertEvent returned error: %v", err)
+	}
+	if err := dm.SaveAnnotation("event-1", &config.EventAnnotation{Pinned: true, Note: "keep"}); err != nil {
+		t.Fatalf("SaveAnnotation returned error: %v", err)
+	}
+
+	backupPath := filepath.Join(t.TempDir(), "backups", "worklogr.db")
+	if err := dm.Backup(backupPath); err != nil {
+		t.Fatalf("Backup returned error: %v", err)
+	}
+
+	// Changes after the backup are undone by the restore
+	if err := dm.InsertEvent(testEvent("event-2", "slack", base.Add(time.Hour))); err != nil {
+		t.Fatalf("InsertEvent returned error: %v", err)
+	}
+	if err := dm.Restore(backupPath); err != nil {
+		t.Fatalf("Restore returned error: %v", err)
+	}
+
+	events, err := dm.GetEvents(base.Add(-time.Hour), base.Add(2*time.Hour), nil)
+	if err != nil {
+		t.Fatalf("GetEvents returned error: %v", err)
+	}
+	if len(events) != 1 || events[0].ID != "event-1" {
+		t.Fatalf("expected only event-1 after restore, got %+v", events)
+	}
+	if len(events[0].Attachments) != 1 || events[0].Attachments[0].TextFull != "hello" {
+		t.Fatalf("expected attachment to be restored, got %+v", events[0].Attachments)
+	}
+	if events[0].Annotation == nil || !events[0].Annotation.Pinned || events[0].Annotation.Note != "keep" {
+		t.Fatalf("expected annotation to be restored, got %+v", events[0].Annotation)
+	}
+}
+
+func TestRestoreRejectsNonWorklogrDatabase(t *testing.T) {
+	dm := newTestDatabaseManager(t)
+
+	otherPath := filepath.Join(t.TempDir(), "other.db")
+	other, err := sql.Open("sqlite3", otherPath)
+	if err != nil {
+		t.Fatalf("failed to open other database: %v", err)
+	}
+	if _, err := other.Exec(`CREATE TABLE notes (id TEXT)`); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	other.Close()
+
+	if err := dm.Restore(otherPath); err == nil || !strings.Contains(err.Error(), "not a worklogr database") {
+		t.Fatalf("expected not a worklogr database error, got %v", err)
+	}
+	if err := dm.Restore(filepath.Join(t.TempDir(), "missing.db")); err == nil {
+		t.Fatalf("expected error for missing backup")
+	}
+}
+
+func TestMergeUnionsEventsAndResolvesConflictsByCreatedAt(t *testing.T) {
+	dm := newTestDatabaseManager(t)
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	otherPath := filepath.Join(t.TempDir(), "other.db")
+	other, err := NewDatabaseManager(otherPath)
+	if err != nil {
+		t.Fatalf("failed to create other database: %v", err)
+	}
+	defer other.Close()
+
+	// shared-newer: the other copy was stored later and wins with its attachments
+	// shared-older: this database's copy was stored later and is kept
+	local := []*config.Event{
+		testEvent("only-local", "slack", base),
+		testEvent("shared-newer", "slack", base.Add(time.Hour), config.EventAttachment{FileID: "local-file", TextFull: "local"}),
+		testEvent("shared-older", "slack", base.Add(2*time.Hour)),
+	}
+	if err := dm.InsertEvents(local); err != nil {
+		t.Fatalf("InsertEvents returned error: %v", err)
+	}
+
+	remote := []*config.Event{
+		testEvent("only-other", "github", base.Add(3*time.Hour), config.EventAttachment{FileID: "other-file", TextFull: "other"}),
+		testEvent("shared-newer", "slack", base.Add(time.Hour), config.EventAttachment{FileID: "remote-file", TextFull: "remote"}),
+		testEvent("shared-older", "slack", base.Add(2*time.Hour)),
+	}
+	remote[1].Title = "remote title"
+	remote[2].Title = "stale title"
+	if err := other.InsertEvents(remote); err != nil {
+		t.Fatalf("InsertEvents returned error: %v", err)
+	}
+	if err := other.SaveAnnotation("only-other", &config.EventAnnotation{Tags: []string{"remote"}}); err != nil {
+		t.Fatalf("SaveAnnotation returned error: %v", err)
+	}
+
+	setCreatedAt := func(db *DatabaseManager, id, createdAt string) {
+		t.Helper()
+		if _, err := db.db.Exec(`UPDATE events SET created_at = ? WHERE id = ?`, createdAt, id); err != nil {
+			t.Fatalf("failed to set created_at: %v", err)
+		}
+	}
+	setCreatedAt(dm, "shared-newer", "2026-03-02 10:00:00")
+	setCreatedAt(other, "shared-newer", "2026-03-05 10:00:00")
+	setCreatedAt(dm, "shared-older", "2026-03-05 10:00:00")
+	setCreatedAt(other, "shared-older", "2026-03-02 10:00:00")
+
+	stats, err := dm.Merge(otherPath)
+	if err != nil {
+		t.Fatalf("Merge returned error: %v", err)
+	}
+	if stats.EventsAdded != 1 || stats.EventsUpdated != 1 || stats.EventsKept != 1 {
+		t.Fatalf("unexpected event stats: %+v", stats)
+	}
+	if stats.AttachmentsMerged != 2 || stats.AnnotationsMerged != 1 {
+		t.Fatalf("unexpected attachment/annotation stats: %+v", stats)
+	}
+
+	events, err := dm.GetEvents(base.Add(-time.Hour), base.Add(4*time.Hour), nil)
+	if err != nil {
+		t.Fatalf("GetEvents returned error: %v", err)
+	}
+	byID := make(map[string]*config.Event, len(events))
+	for _, event := range events {
+		byID[event.ID] = event
+	}
+	if len(byID) != 4 {
+		t.Fatalf("expected 4 merged events, got %d", len(byID))
+	}
+	if byID["shared-newer"].Title != "remote title" {
+		t.Fatalf("expected newer remote copy to win, got %q", byID["shared-newer"].Title)
+	}
+	versions, err := dm.EventVersions("shared-newer")
+	if err != nil {
+		t.Fatalf("EventVersions returned error: %v", err)
+	}
+	if len(versions) != 1 || versions[0].Title != "title-shared-newer" {
+		t.Fatalf("expected the replaced local copy to be kept as a version, got %+v", versions)
+	}
+	if versions, err := dm.EventVersions("shared-older"); err != nil || len(versions) != 0 {
+		t.Fatalf("expected no versions for the kept local copy, got %+v (%v)", versions, err)
+	}
+	if len(byID["shared-newer"].Attachments) != 2 {
+		t.Fatalf("expected attachments of both copies, got %+v", byID["shared-newer"].Attachments)
+	}
+	if byID["shared-older"].Title != "title-shared-older" {
+		t.Fatalf("expected newer local copy to be kept, got %q", byID["shared-older"].Title)
+	}
+	if ann := byID["only-other"].Annotation; ann == nil || len(ann.Tags) != 1 || ann.Tags[0] != "remote" {
+		t.Fatalf("expected annotation from other database, got %+v", ann)
+	}
+
+	// Merging again changes nothing
+	again, err := dm.Merge(otherPath)
+	if err != nil {
+		t.Fatalf("second Merge returned error: %v", err)
+	}
+	if again.EventsAdded != 0 || again.EventsUpdated != 0 || again.EventsKept != 3 {
+		t.Fatalf("expected second merge to keep everything, got %+v", again)
+	}
+	if versions, err := dm.EventVersions("shared-newer"); err != nil || len(versions) != 1 {
+		t.Fatalf("expected second merge to record no versions, got %+v (%v)", versions, err)
+	}
+}
+
+func TestPruneAppliesMostSpecificRuleAndCascades(t *testing.T) {
+	dm := newTestDatabaseManager(t)
+	now := time.Now().UTC()
+	old := now.AddDate(0, 0, -200)
+	recent := now.AddDate(0, 0, -30)
+
+	events := []*config.Event{
+		testEvent("slack-old", "slack", old, config.EventAttachment{FileID: "file-1", TextFull: "secret"}),
+		testEvent("slack-recent", "slack", recent),
+		testEvent("github-old", "github", old),
+		testEvent("calendar-old", "google_calendar", old, config.EventAttachment{FileID: "file-2", TextFull: "minutes"}),
+		testEvent("calendar-recent", "google_calendar", recent, config.EventAttachment{FileID: "file-3", TextFull: "agenda"}),
+	}
+	events[3].Type = "event_attended"
+	events[4].Type = "event_attended"
+	if err := dm.InsertEvents(events); err != nil {
+		t.Fatalf("InsertEvents returned error: %v", err)
+	}
+	if err := dm.SaveAnnotation("slack-old", &config.EventAnnotation{Pinned: true}); err != nil {
+		t.Fatalf("SaveAnnotation returned error: %v", err)
+	}
+	if err := dm.ReplaceEventLinks([]string{"slack-old"}, []EventLinkRecord{{SourceID: "slack-old", TargetID: "github-old", Relation: "references", Key: "PROJ-1"}}); err != nil {
+		t.Fatalf("ReplaceEventLinks returned error: %v", err)
+	}
+	// An attachment left behind by an event deleted without cascading
+	if _, err := dm.db.Exec(`INSERT INTO event_attachments (id, event_id, file_id) VALUES ('gone__file', 'gone', 'file')`); err != nil {
+		t.Fatalf("failed to insert orphan attachment: %v", err)
+	}
+
+	// Slack: 180 days, calendar attendance: attachment text 14 days, everything else: 365 days
+	rules := []RetentionRule{
+		{Service: "google_calendar", Type: "event_attended", EventsBefore: now.AddDate(0, 0, -365), AttachmentTextBefore: now.AddDate(0, 0, -14)},
+		{Service: "slack", EventsBefore: now.AddDate(0, 0, -180)},
+		{EventsBefore: now.AddDate(0, 0, -365)},
+	}
+
+	dryRun, err := dm.Prune(rules, true)
+	if err != nil {
+		t.Fatalf("dry-run Prune returned error: %v", err)
+	}
+	if dryRun.EventsDeleted != 1 || dryRun.EventsByService["slack"] != 1 {
+		t.Fatalf("unexpected dry-run stats: %+v", dryRun)
+	}
+	if exists, _ := dm.EventExists("slack-old"); !exists {
+		t.Fatalf("dry run must not delete events")
+	}
+
+	stats, err := dm.Prune(rules, false)
+	if err != nil {
+		t.Fatalf("Prune returned error: %v", err)
+	}
+	if stats.EventsDeleted != 1 || stats.AttachmentsDeleted != 2 || stats.LinksDeleted != 1 || stats.AnnotationsDeleted != 1 {
+		t.Fatalf("unexpected prune stats: %+v", stats)
+	}
+	if stats.AttachmentTextsCleared != 2 {
+		t.Fatalf("expected attachment text of both calendar events to be cleared, got %+v", stats)
+	}
+
+	remaining, err := dm.GetEvents(old.Add(-time.Hour), now, nil)
+	if err != nil {
+		t.Fatalf("GetEvents returned error: %v", err)
+	}
+	if len(remaining) != 4 {
+		t.Fatalf("expected 4 remaining events, got %d", len(remaining))
+	}
+	for _, event := range remaining {
+		for _, attachment := range event.Attachments {
+			if attachment.TextFull != "" {
+				t.Fatalf("expected attachment text of %s to be cleared", event.ID)
+			}
+		}
+	}
+
+	if err := dm.Vacuum(); err != nil {
+		t.Fatalf("Vacuum returned error: %v", err)
+	}
+}
+
+func TestDeleteOldEventsRemovesAttachments(t *testing.T) {
+	dm := newTestDatabaseManager(t)
+	old := time.Now().AddDate(-3, 0, 0)
+
+	if err := dm.InsertEvent(testEvent("old", "slack", old, config.EventAttachment{FileID: "file-1", TextFull: "text"})); err != nil {
+		t.Fatalf("InsertEvent returned error: %v", err)
+	}
+
+	deleted, err := dm.DeleteOldEvents(2 * 365 * 24 * time.Hour)
+	if err != nil {
+		t.Fatalf("DeleteOldEvents returned error: %v", err)
+	}
+	if deleted != 1 {
+		t.Fatalf("expected 1 deleted event, got %d", deleted)
+	}
+
+	var attachments int
+	if err := dm.db.QueryRow(`SELECT COUNT(*) FROM event_attachments`).Scan(&attachments); err != nil {
+		t.Fatalf("failed to count attachments: %v", err)
+	}
+	if attachments != 0 {
+		t.Fatalf("expected attachments to be deleted with the event, got %d", attachments)
+	}
+}
+
+func TestEncryptionSealsColumnsAndRekeys(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "encrypted.db")
+	dm, err := NewDatabaseManager(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	// A row stored before encryption was turned on is encrypted by UseEncryption
+	before := testEvent("dm-1", "slack", base, config.EventAttachment{FileID: "file-1", TextFull: "meeting notes"})
+	before.Content = "private message"
+	before.Metadata = `{"channel":"D123"}`
+	if err := dm.InsertEvent(before); err != nil {
+		t.Fatalf("InsertEvent returned error: %v", err)
+	}
+	if err := dm.UseEncryption("passphrase-1"); err != nil {
+		t.Fatalf("UseEncryption returned error: %v", err)
+	}
+	if err := dm.InsertEvent(testEvent("dm-2", "slack", base.Add(time.Hour))); err != nil {
+		t.Fatalf("InsertEvent returned error: %v", err)
+	}
+
+	var content, metadata, textFull string
+	if err := dm.db.QueryRow(`SELECT content, metadata FROM events WHERE id = 'dm-1'`).Scan(&content, &metadata); err != nil {
+		t.Fatalf("failed to read raw row: %v", err)
+	}
+	if err := dm.db.QueryRow(`SELECT text_full FROM event_attachments`).Scan(&textFull); err != nil {
+		t.Fatalf("failed to read raw attachment: %v", err)
+	}
+	for _, raw := range []string{content, metadata, textFull} {
+		if !strings.HasPrefix(raw, sealedPrefix) {
+			t.Fatalf("expected column to be encrypted, got %q", raw)
+		}
+	}
+
+	filter := EventFilter{Match: "PRIVATE", Metadata: []MetadataCondition{{Paths: []string{"$.channel"}, Values: []string{"D123"}}}}
+	events, err := dm.QueryEvents(base.Add(-time.Hour), base.Add(2*time.Hour), filter)
+	if err != nil {
+		t.Fatalf("QueryEvents returned error: %v", err)
+	}
+	if len(events) != 1 || events[0].Content != "private message" || events[0].Attachments[0].TextFull != "meeting notes" {
+		t.Fatalf("expected decrypted dm-1, got %+v", events)
+	}
+	it, err := dm.IterateEvents(base.Add(-time.Hour), base.Add(2*time.Hour), filter)
+	if err != nil {
+		t.Fatalf("IterateEvents returned error: %v", err)
+	}
+	if it.Count() != 1 || !it.Next() || it.Event().Metadata != `{"channel":"D123"}` {
+		t.Fatalf("expected iterator to yield decrypted dm-1")
+	}
+	dm.Close()
+
+	reopen := func(passphrase string) (*DatabaseManager, error) {
+		t.Helper()
+		dm, err := NewDatabaseManager(dbPath)
+		if err != nil {
+			t.Fatalf("failed to reopen database: %v", err)
+		}
+		if err := dm.UseEncryption(passphrase); err != nil {
+			dm.Close()
+			return nil, err
+		}
+		return dm, nil
+	}
+	if _, err := reopen(""); err == nil {
+		t.Fatalf("expected encrypted database to require a key")
+	}
+	if _, err := reopen("wrong"); err != ErrWrongEncryptionKey {
+		t.Fatalf("expected wrong key error, got %v", err)
+	}
+
+	dm, err = reopen("passphrase-1")
+	if err != nil {
+		t.Fatalf("failed to open with key: %v", err)
+	}
+	if err := dm.Rekey("passphrase-2"); err != nil {
+		t.Fatalf("Rekey returned error: %v", err)
+	}
+	dm.Close()
+	if _, err := reopen("passphrase-1"); err != ErrWrongEncryptionKey {
+		t.Fatalf("expected old key to stop working, got %v", err)
+	}
+
+	dm, err = reopen("passphrase-2")
+	if err != nil {
+		t.Fatalf("failed to open with new key: %v", err)
+	}
+	defer dm.Close()
+	if err := dm.Rekey(""); err != nil {
+		t.Fatalf("decrypting Rekey returned error: %v", err)
+	}
+	if err := dm.db.QueryRow(`SELECT content FROM events WHERE id = 'dm-1'`).Scan(&content); err != nil {
+		t.Fatalf("failed to read raw row: %v", err)
+	}
+	if content != "private message" {
+		t.Fatalf("expected plaintext after decrypting, got %q", content)
+	}
+}
+
+func TestMergeReencryptsRowsFromOtherDatabase(t *testing.T) {
+	dm := newTestDatabaseManager(t)
+	if err := dm.UseEncryption("shared"); err != nil {
+		t.Fatalf("UseEncryption returned error: %v", err)
+	}
+
+	// Encrypted separately (different salt) with the same key string
+	otherPath := filepath.Join(t.TempDir(), "other.db")
+	other, err := NewDatabaseManager(otherPath)
+	if err != nil {
+		t.Fatalf("failed to create other database: %v", err)
+	}
+	if err := other.UseEncryption("shared"); err != nil {
+		t.Fatalf("UseEncryption returned error: %v", err)
+	}
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	if err := other.InsertEvent(testEvent("remote", "slack", base)); err != nil {
+		t.Fatalf("InsertEvent returned error: %v", err)
+	}
+	other.Close()
+
+	if _, err := dm.Merge(otherPath); err != nil {
+		t.Fatalf("Merge returned error: %v", err)
+	}
+	events, err := dm.GetEvents(base.Add(-time.Hour), base.Add(time.Hour), nil)
+	if err != nil {
+		t.Fatalf("GetEvents returned error: %v", err)
+	}
+	if len(events) != 1 || events[0].Content != "content-remote" {
+		t.Fatalf("expected merged event to decrypt with this database's key, got %+v", events)
+	}
+}
+
+func TestRecollectionKeepsEveryVersionWithinOneSecond(t *testing.T) {
+	dm := newTestDatabaseManager(t)
+	base := time.Date(2026, 3, 12, 10, 0, 0, 0, time.UTC)
+	if err := dm.InsertEvent(testEvent("a", "slack", base)); err != nil {
+		t.Fatalf("InsertEvent returned error: %v", err)
+	}
+	// A row stored without content or metadata must still be comparable
+	if _, err := dm.db.Exec(`UPDATE events SET content = NULL, metadata = NULL WHERE id = 'a'`); err != nil {
+		t.Fatalf("failed to clear content: %v", err)
+	}
+
+	for _, title := range []string{"first edit", "second edit"} {
+		edited := testEvent("a", "slack", base)
+		edited.Title = title
+		if err := dm.InsertEvents([]*config.Event{edited}); err != nil {
+			t.Fatalf("InsertEvents returned error: %v", err)
+		}
+	}
+
+	versions, err := dm.EventVersions("a")
+	if err != nil {
+		t.Fatalf("EventVersions returned error: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("expected both previous versions to be kept, got %+v", versions)
+	}
+	if versions[0].Title != "title-a" || versions[0].Content != "" || versions[1].Title != "first edit" {
+		t.Fatalf("unexpected versions: %+v", versions)
+	}
+}
+
+func TestRecollectionRecordsVersionsAndTombstonesMissingEvents(t *testing.T) {
+	dm := newTestDatabaseManager(t)
+	base := time.Date(2026, 3, 12, 10, 0, 0, 0, time.UTC)
+	if err := dm.InsertEvents([]*config.Event{
+		testEvent("a", "slack", base),
+		testEvent("b", "slack", base.Add(time.Minute)),
+		testEvent("c", "github", base.Add(2*time.Minute)),
+	}); err != nil {
+		t.Fatalf("InsertEvents returned error: %v", err)
+	}
+
+	// An unchanged re-collection keeps no version; an edit keeps the previous one
+	edited := testEvent("a", "slack", base)
+	edited.Title = "edited title"
+	if err := dm.InsertEvents([]*config.Event{edited, testEvent("b", "slack", base.Add(time.Minute))}); err != nil {
+		t.Fatalf("InsertEvents returned error: %v", err)
+	}
+	versions, err := dm.EventVersions("a")
+	if err != nil {
+		t.Fatalf("EventVersions returned error: %v", err)
+	}
+	if len(versions) != 1 || versions[0].Title != "title-a" || versions[0].Content != "content-a" || versions[0].SeenAt.IsZero() {
+		t.Fatalf("expected the previous version of a, got %+v", versions)
+	}
+	if versions, err := dm.EventVersions("b"); err != nil || len(versions) != 0 {
+		t.Fatalf("expected no versions for unchanged b, got %+v (%v)", versions, err)
+	}
+
+	// Only slack is tombstoned, and only within the collected range
+	start, end := base.Add(-time.Hour), base.Add(time.Hour)
+	marked, err := dm.MarkMissingDeleted("slack", "", start, end, []string{"a"})
+	if err != nil {
+		t.Fatalf("MarkMissingDeleted returned error: %v", err)
+	}
+	if marked != 1 {
+		t.Fatalf("expected 1 event to be marked deleted, got %d", marked)
+	}
+
+	tests := []struct {
+		name   string
+		filter EventFilter
+		want   []string
+	}{
+		{name: "deleted skipped by default", filter: EventFilter{}, want: []string{"a", "c"}},
+		{name: "include deleted", filter: EventFilter{IncludeDeleted: true}, want: []string{"a", "b", "c"}},
+		{name: "deleted only", filter: EventFilter{DeletedOnly: true}, want: []string{"b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := dm.QueryEvents(start, end, tt.filter)
+			if err != nil {
+				t.Fatalf("QueryEvents returned error: %v", err)
+			}
+			var got []string
+			for _, event := range events {
+				got = append(got, event.ID)
+				if (event.DeletedAt != nil) != (event.ID == "b") {
+					t.Fatalf("unexpected deleted_at on %s: %v", event.ID, event.DeletedAt)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	// An event that shows up again is restored
+	if err := dm.InsertEvents([]*config.Event{testEvent("b", "slack", base.Add(time.Minute))}); err != nil {
+		t.Fatalf("InsertEvents returned error: %v", err)
+	}
+	event, err := dm.GetEvent("b")
+	if err != nil {
+		t.Fatalf("GetEvent returned error: %v", err)
+	}
+	if event == nil || event.DeletedAt != nil {
+		t.Fatalf("expected b to be restored, got %+v", event)
+	}
+}
+
+func TestLiftedColumnsAreStoredAndBackfilled(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "lifted.db")
+	dm, err := NewDatabaseManager(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	base := time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC)
+
+	message := testEvent("msg-1", "slack", base)
+	message.Metadata = `{"channel_id":"C1","channel_name":"dev","permalink":"https://slack.example/p1"}`
+	pr := testEvent("pr-1", "github", base.Add(time.Minute))
+	pr.Type = "pull_request_created"
+	pr.Metadata = `{"repository":"octo/app","number":1,"url":"https://github.com/octo/app/pull/1"}`
+	if err := dm.InsertEvents([]*config.Event{message, pr}); err != nil {
+		t.Fatalf("InsertEvents returned error: %v", err)
+	}
+
+	// Rows stored before the columns existed are filled in when the database is opened
+	if _, err := dm.db.Exec(`UPDATE events SET url = NULL, repository = NULL, channel = NULL, channel_id = NULL WHERE id = 'pr-1'`); err != nil {
+		t.Fatalf("failed to clear lifted columns: %v", err)
+	}
+	if err := dm.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	dm, err = NewDatabaseManager(dbPath)
+	if err != nil {
+		t.Fatalf("failed to reopen database: %v", err)
+	}
+	defer dm.Close()
+
+	start, end := base.Add(-time.Hour), base.Add(time.Hour)
+	events, err := dm.QueryEvents(start, end, EventFilter{Repositories: []string{"octo/app"}})
+	if err != nil {
+		t.Fatalf("QueryEvents returned error: %v", err)
+	}
+	if len(events) != 1 || events[0].ID != "pr-1" || events[0].URL != "https://github.com/octo/app/pull/1" {
+		t.Fatalf("expected pr-1 with its lifted URL, got %+v", events)
+	}
+
+	for _, channel := range []string{"dev", "C1"} {
+		events, err := dm.QueryEvents(start, end, EventFilter{Channels: []string{channel}})
+		if err != nil {
+			t.Fatalf("QueryEvents returned error: %v", err)
+		}
+		if len(events) != 1 || events[0].ID != "msg-1" || events[0].Channel != "dev" || events[0].ChannelID != "C1" {
+			t.Fatalf("expected msg-1 for channel %q, got %+v", channel, events)
+		}
+	}
+}
+
+func TestLiftedColumnsAreNotStoredWhenEncrypted(t *testing.T) {
+	dm := newTestDatabaseManager(t)
+	base := time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC)
+
+	// Stored in plaintext before encryption was turned on
+	message := testEvent("msg-1", "slack", base)
+	message.Metadata = `{"channel_id":"C1","channel_name":"dev","permalink":"https://slack.example/p1"}`
+	if err := dm.InsertEvent(message); err != nil {
+		t.Fatalf("InsertEvent returned error: %v", err)
+	}
+	if err := dm.UseEncryption("passphrase"); err != nil {
+		t.Fatalf("UseEncryption returned error: %v", err)
+	}
+	pr := testEvent("pr-1", "github", base.Add(time.Minute))
+	pr.Type = "pull_request_created"
+	pr.Metadata = `{"repository":"octo/app","number":1,"url":"https://github.com/octo/app/pull/1"}`
+	if err := dm.InsertEvents([]*config.Event{pr}); err != nil {
+		t.Fatalf("InsertEvents returned error: %v", err)
+	}
+
+	var lifted int
+	if err := dm.db.QueryRow(`
+		SELECT COUNT(*) FROM events
+		WHERE url IS NOT NULL OR repository IS NOT NULL OR channel IS NOT NULL OR channel_id IS NOT NULL
+	`).Scan(&lifted); err != nil {
+		t.Fatalf("failed to read raw rows: %v", err)
+	}
+	if lifted != 0 {
+		t.Fatalf("expected lifted columns to stay NULL in an encrypted database, %d rows have them", lifted)
+	}
+
+	start, end := base.Add(-time.Hour), base.Add(time.Hour)
+	events, err := dm.QueryEvents(start, end, EventFilter{Repositories: []string{"octo/app"}})
+	if err != nil {
+		t.Fatalf("QueryEvents returned error: %v", err)
+	}
+	if len(events) != 1 || events[0].ID != "pr-1" || events[0].URL != "https://github.com/octo/app/pull/1" {
+		t.Fatalf("expected pr-1 with its URL lifted from decrypted metadata, got %+v", events)
+	}
+	it, err := dm.IterateEvents(start, end, EventFilter{Channels: []string{"C1"}})
+	if err != nil {
+		t.Fatalf("IterateEvents returned error: %v", err)
+	}
+	if it.Count() != 1 || !it.Next() || it.Event().Channel != "dev" {
+		t.Fatalf("expected iterator to match msg-1 by channel ID")
+	}
+
+	// Decrypting fills the columns in again
+	if err := dm.Rekey(""); err != nil {
+		t.Fatalf("decrypting Rekey returned error: %v", err)
+	}
+	var repository string
+	if err := dm.db.QueryRow(`SELECT repository FROM events WHERE id = 'pr-1'`).Scan(&repository); err != nil {
+		t.Fatalf("failed to read raw row: %v", err)
+	}
+	if repository != "octo/app" {
+		t.Fatalf("expected repository to be lifted after decrypting, got %q", repository)
+	}
+}
+
+func TestOpeningEncryptedDatabaseDoesNotLiftMetadata(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "encrypted.db")
+	dm, err := NewDatabaseManager(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	if err := dm.UseEncryption("passphrase"); err != nil {
+		t.Fatalf("UseEncryption returned error: %v", err)
+	}
+	// A row a lift pass could fill in: plaintext metadata with NULL lifted columns
+	if _, err := dm.db.Exec(`
+		INSERT INTO events (id, service, type, title, content, timestamp, metadata)
+		VALUES ('pr-1', 'github', 'pull_request_created', 'PR', '', ?, '{"repository":"octo/app","number":1}')
+	`, time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("failed to insert raw row: %v", err)
+	}
+	dm.Close()
+
+	for i := 0; i < 2; i++ {
+		dm, err := NewDatabaseManager(dbPath)
+		if err != nil {
+			t.Fatalf("failed to reopen database: %v", err)
+		}
+		var repository sql.NullString
+		err = dm.db.QueryRow(`SELECT repository FROM events WHERE id = 'pr-1'`).Scan(&repository)
+		dm.Close()
+		if err != nil {
+			t.Fatalf("failed to read raw row: %v", err)
+		}
+		if repository.Valid {
+			t.Fatalf("expected no lift pass on an encrypted database, repository is %q", repository.String)
+		}
+	}
+}
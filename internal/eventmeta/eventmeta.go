@@ -0,0 +1,305 @@
+// Package eventmeta defines the typed metadata stored with each kind of event, and the
+// common fields lifted from it into indexed event columns.
+package eventmeta
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+)
+
+// SchemaVersion is written to every metadata object as schema_version. Bump it when a
+// field is renamed or changes type, and keep Decode able to read the older shape.
+const SchemaVersion = 1
+
+// Metadata is the typed metadata of one kind of event.
+type Metadata interface {
+	// Common returns the fields lifted into event columns
+	Common() Common
+	setSchemaVersion(version int)
+}
+
+// Header is embedded in every metadata type.
+type Header struct {
+	SchemaVersion int `json:"schema_version"`
+}
+
+func (h *Header) setSchemaVersion(version int) { h.SchemaVersion = version }
+
+// Common holds the fields shared across services. They are stored in indexed columns of
+// events so that filters and exporters do not need to know each service's metadata keys.
+type Common struct {
+	// URL is the web link of the event (permalink, PR/issue/commit URL, calendar link)
+	URL        string
+	Repository string
+	// Channel is the Slack channel name, ChannelID its ID
+	Channel          string
+	ChannelID        string
+	ParticipantCount int
+	DurationMinutes  int
+}
+
+// IsZero reports whether no common field is set.
+func (c Common) IsZero() bool {
+	return c == Common{}
+}
+
+// SlackMessage is the metadata of Slack message events.
+type SlackMessage struct {
+	Header
+	ChannelID   string `json:"channel_id"`
+	ChannelName string `json:"channel_name"`
+	MessageTS   string `json:"message_ts"`
+	Permalink   string `json:"permalink"`
+	ViaSearch   bool   `json:"via_search"`
+}
+
+func (m *SlackMessage) Common() Common {
+	return Common{URL: m.Permalink, Channel: m.ChannelName, ChannelID: m.ChannelID}
+}
+
+// GitHubCommit is the metadata of commit events. The line counts are only known for
+// commits listed per repository, not for search results.
+type GitHubCommit struct {
+	Header
+	Repository   string `json:"repository"`
+	SHA          string `json:"sha"`
+	URL          string `json:"url"`
+	Additions    int    `json:"additions,omitempty"`
+	Deletions    int    `json:"deletions,omitempty"`
+	ChangedFiles int    `json:"changed_files,omitempty"`
+}
+
+func (m *GitHubCommit) Common() Common {
+	return Common{URL: m.URL, Repository: m.Repository}
+}
+
+// GitHubPullRequest is the metadata of pull_request_* events. Branches and line counts
+// are only known for pull requests listed per repository.
+type GitHubPullRequest struct {
+	Header
+	Repository string `json:"repository"`
+	Number     int    `json:"number"`
+	Action     string `json:"action"`
+	URL        string `json:"url"`
+	State      string `json:"state"`
+	Base       string `json:"base,omitempty"`
+	Head       string `json:"head,omitempty"`
+	Additions  int    `json:"additions,omitempty"`
+	Deletions  int    `json:"deletions,omitempty"`
+	Commits    int    `json:"commits,omitempty"`
+}
+
+func (m *GitHubPullRequest) Common() Common {
+	return Common{URL: m.URL, Repository: m.Repository}
+}
+
+// GitHubReview is the metadata of pull_request_review events.
+type GitHubReview struct {
+	Header
+	Repository  string `json:"repository"`
+	PRNumber    int    `json:"pr_number"`
+	ReviewID    int64  `json:"review_id"`
+	ReviewState string `json:"review_state"`
+	URL         string `json:"url"`
+	PRURL       string `json:"pr_url,omitempty"`
+}
+
+func (m *GitHubReview) Common() Common {
+	return Common{URL: m.URL, Repository: m.Repository}
+}
+
+// GitHubIssue is the metadata of issue_* events.
+type GitHubIssue struct {
+	Header
+	Repository string `json:"repository"`
+	Number     int    `json:"number"`
+	Action     string `json:"action"`
+	URL        string `json:"url"`
+	State      string `json:"state"`
+	Labels     Labels `json:"labels,omitempty"`
+}
+
+func (m *GitHubIssue) Common() Common {
+	return Common{URL: m.URL, Repository: m.Repository}
+}
+
+// Labels are issue label names.
+type Labels []string
+
+// UnmarshalJSON also reads metadata written before schema_version 1, which stored the
+// full GitHub label objects.
+func (l *Labels) UnmarshalJSON(data []byte) error {
+	var names []string
+	if err := json.Unmarshal(data, &names); err == nil {
+		*l = names
+		return nil
+	}
+	var objects []struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &objects); err != nil {
+		return err
+	}
+	*l = make(Labels, 0, len(objects))
+	for _, object := range objects {
+		*l = append(*l, object.Name)
+	}
+	return nil
+}
+
+// GitHubRelease is the metadata of release_created events.
+type GitHubRelease struct {
+	Header
+	Repository string `json:"repository"`
+	TagName    string `json:"tag_name"`
+	Name       string `json:"name"`
+	URL        string `json:"url"`
+	Prerelease bool   `json:"prerelease"`
+	Draft      bool   `json:"draft"`
+}
+
+func (m *GitHubRelease) Common() Common {
+	return Common{URL: m.URL, Repository: m.Repository}
+}
+
+// CalendarEvent is the metadata of Google Calendar events.
+type CalendarEvent struct {
+	Header
+	CalendarID   string    `json:"calendar_id"`
+	CalendarName string    `json:"calendar_name"`
+	EventID      string    `json:"event_id"`
+	Action       string    `json:"action"`
+	StartTime    time.Time `json:"start_time"`
+	EndTime      time.Time `json:"end_time"`
+	Location     string    `json:"location"`
+	Description  string    `json:"description"`
+	HTMLLink     string    `json:"html_link"`
+	// GeminiNoteFiles references attachments stored in event_attachments
+	GeminiNoteFiles []NoteFile `json:"gemini_note_files,omitempty"`
+	Attendees       []Attendee `json:"attendees,omitempty"`
+	AttendeeCount   int        `json:"attendee_count,omitempty"`
+	Organizer       *Person    `json:"organizer,omitempty"`
+	Recurring       bool       `json:"recurring"`
+	RecurrenceRules []string   `json:"recurrence_rules,omitempty"`
+	MeetingLinks    []string   `json:"meeting_links,omitempty"`
+	EventType       string     `json:"event_type,omitempty"`
+	Visibility      string     `json:"visibility"`
+	AllDay          bool       `json:"all_day"`
+	DurationMinutes int        `json:"duration_minutes,omitempty"`
+}
+
+func (m *CalendarEvent) Common() Common {
+	return Common{URL: m.HTMLLink, ParticipantCount: m.AttendeeCount, DurationMinutes: m.DurationMinutes}
+}
+
+// NoteFile is a lightweight reference to a calendar attachment.
+type NoteFile struct {
+	FileID    string `json:"file_id"`
+	Title     string `json:"title"`
+	MimeType  string `json:"mime_type"`
+	Truncated bool   `json:"truncated"`
+}
+
+// Attendee is a calendar event attendee.
+type Attendee struct {
+	Email          string `json:"email"`
+	Name           string `json:"name,omitempty"`
+	ResponseStatus string `json:"response_status"`
+}
+
+// Person is a calendar event organizer.
+type Person struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+// New returns an empty metadata value for the service and event type, or nil when the
+// event type has no typed metadata.
+func New(service, eventType string) Metadata {
+	switch service {
+	case "slack":
+		if eventType == "message" {
+			return &SlackMessage{}
+		}
+	case "github":
+		switch {
+		case eventType == "commit":
+			return &GitHubCommit{}
+		case eventType == "pull_request_review":
+			return &GitHubReview{}
+		case strings.HasPrefix(eventType, "pull_request_"):
+			return &GitHubPullRequest{}
+		case strings.HasPrefix(eventType, "issue_"):
+			return &GitHubIssue{}
+		case strings.HasPrefix(eventType, "release_"):
+			return &GitHubRelease{}
+		}
+	case "google_calendar":
+		return &CalendarEvent{}
+	}
+	return nil
+}
+
+// Encode stamps the schema version and returns the metadata as stored in events.metadata.
+func Encode(metadata Metadata) string {
+	metadata.setSchemaVersion(SchemaVersion)
+	data, _ := json.Marshal(metadata)
+	return string(data)
+}
+
+// Decode parses the metadata of an event into its typed form. It returns nil when the
+// event has no metadata or its type has no typed metadata.
+func Decode(event *config.Event) (Metadata, error) {
+	metadata := New(event.Service, event.Type)
+	if metadata == nil || strings.TrimSpace(event.Metadata) == "" {
+		return nil, nil
+	}
+	if err := json.Unmarshal([]byte(event.Metadata), metadata); err != nil {
+		return nil, fmt.Errorf("invalid %s %s metadata: %w", event.Service, event.Type, err)
+	}
+	return metadata, nil
+}
+
+// CommonOf returns the common fields of an event: the lifted fields when they are set,
+// otherwise those derived from its metadata.
+func CommonOf(event *config.Event) Common {
+	common := Common{
+		URL:              event.URL,
+		Repository:       event.Repository,
+		Channel:          event.Channel,
+		ChannelID:        event.ChannelID,
+		ParticipantCount: event.ParticipantCount,
+		DurationMinutes:  event.DurationMinutes,
+	}
+	if !common.IsZero() {
+		return common
+	}
+	metadata, err := Decode(event)
+	if err != nil || metadata == nil {
+		return common
+	}
+	return metadata.Common()
+}
+
+// Lift sets the lifted fields of an event from its metadata unless they are already set.
+func Lift(event *config.Event) {
+	common := CommonOf(event)
+	event.URL = common.URL
+	event.Repository = common.Repository
+	event.Channel = common.Channel
+	event.ChannelID = common.ChannelID
+	event.ParticipantCount = common.ParticipantCount
+	event.DurationMinutes = common.DurationMinutes
+}
+
+// Relift replaces the lifted fields of an event with those derived from its current
+// metadata, after the metadata was rewritten (for example by redaction).
+func Relift(event *config.Event) {
+	event.URL, event.Repository, event.Channel, event.ChannelID = "", "", "", ""
+	event.ParticipantCount, event.DurationMinutes = 0, 0
+	Lift(event)
+}
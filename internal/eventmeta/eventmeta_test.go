@@ -0,0 +1,98 @@
+package eventmeta
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/iriam/worklogr/internal/config"
+)
+
+func TestEncodeStampsSchemaVersionAndDecodesByEventType(t *testing.T) {
+	event := &config.Event{
+		Service: "github",
+		Type:    "pull_request_merged",
+		Metadata: Encode(&GitHubPullRequest{
+			Repository: "octo/app",
+			Number:     42,
+			Action:     "merged",
+			URL:        "https://github.com/octo/app/pull/42",
+		}),
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(event.Metadata), &raw); err != nil {
+		t.Fatalf("metadata is not JSON: %v", err)
+	}
+	if raw["schema_version"] != float64(SchemaVersion) || raw["repository"] != "octo/app" {
+		t.Fatalf("unexpected encoded metadata: %s", event.Metadata)
+	}
+
+	metadata, err := Decode(event)
+	if err != nil {
+		t.Fatalf("Decode returned error: %v", err)
+	}
+	pr, ok := metadata.(*GitHubPullRequest)
+	if !ok || pr.Number != 42 || pr.SchemaVersion != SchemaVersion {
+		t.Fatalf("expected typed pull request metadata, got %#v", metadata)
+	}
+
+	if metadata, err := Decode(&config.Event{Service: "notion", Type: "page", Metadata: `{"x":1}`}); err != nil || metadata != nil {
+		t.Fatalf("expected no typed metadata for unknown event types, got %#v (%v)", metadata, err)
+	}
+	if _, err := Decode(&config.Event{Service: "slack", Type: "message", Metadata: `{"channel_id":1}`}); err == nil {
+		t.Fatalf("expected mistyped metadata to return error")
+	}
+}
+
+func TestDecodeReadsLegacyLabelObjects(t *testing.T) {
+	event := &config.Event{
+		Service:  "github",
+		Type:     "issue_created",
+		Metadata: `{"repository":"octo/app","number":7,"labels":[{"id":1,"name":"bug"},{"id":2,"name":"infra"}]}`,
+	}
+	metadata, err := Decode(event)
+	if err != nil {
+		t.Fatalf("Decode returned error: %v", err)
+	}
+	issue := metadata.(*GitHubIssue)
+	if len(issue.Labels) != 2 || issue.Labels[0] != "bug" || issue.Labels[1] != "infra" {
+		t.Fatalf("expected label names, got %v", issue.Labels)
+	}
+}
+
+func TestLiftFillsCommonFieldsPerService(t *testing.T) {
+	tests := []struct {
+		name  string
+		event *config.Event
+		want  Common
+	}{
+		{
+			name:  "slack",
+			event: &config.Event{Service: "slack", Type: "message", Metadata: `{"channel_id":"C1","channel_name":"dev","permalink":"https://slack.example/p1"}`},
+			want:  Common{URL: "https://slack.example/p1", Channel: "dev", ChannelID: "C1"},
+		},
+		{
+			name:  "github review",
+			event: &config.Event{Service: "github", Type: "pull_request_review", Metadata: `{"repository":"octo/app","pr_number":3,"url":"https://github.com/octo/app/pull/3#r1"}`},
+			want:  Common{URL: "https://github.com/octo/app/pull/3#r1", Repository: "octo/app"},
+		},
+		{
+			name:  "calendar",
+			event: &config.Event{Service: "google_calendar", Type: "event_attended", Metadata: `{"html_link":"https://calendar.example/e1","attendee_count":4,"duration_minutes":30}`},
+			want:  Common{URL: "https://calendar.example/e1", ParticipantCount: 4, DurationMinutes: 30},
+		},
+		{
+			name:  "already lifted",
+			event: &config.Event{Service: "github", Type: "commit", Repository: "octo/other", Metadata: `{"repository":"octo/app"}`},
+			want:  Common{Repository: "octo/other"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Lift(tt.event)
+			if got := CommonOf(tt.event); got != tt.want {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
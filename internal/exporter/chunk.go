@@ -0,0 +1,478 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/iriam/worklogr/internal/config"
+)
+
+// Chunk grouping modes for ChunkOptions.GroupBy.
+const (
+	ChunkByNone    = ""
+	ChunkByDay     = "day"
+	ChunkByService = "service"
+	ChunkByMember  = "member"
+)
+
+// ChunkOptions controls how AI events are split into size-limited chunks.
+// A zero MaxTokens/MaxBytes means no limit on that dimension.
+type ChunkOptions struct {
+	MaxTokens int
+	MaxBytes  int
+	GroupBy   string
+	// Location is used to decide day boundaries when GroupBy is "day".
+	Location *time.Location
+}
+
+// Enabled reports whether any size limit is set
+func (o ChunkOptions) Enabled() bool {
+	return o.MaxTokens > 0 || o.MaxBytes > 0
+}
+
+// Validate checks the option values
+func (o ChunkOptions) Validate() error {
+	if o.MaxTokens < 0 || o.MaxBytes < 0 {
+		return fmt.Errorf("chunk limits must not be negative")
+	}
+	switch o.GroupBy {
+	case ChunkByNone, ChunkByDay, ChunkByService, ChunkByMember:
+		return nil
+	default:
+		return fmt.Errorf("unsupported chunk grouping: %s (supported: day, service, member)", o.GroupBy)
+	}
+}
+
+// TrimRecord describes a field that was truncated or dropped to fit a chunk
+type TrimRecord struct {
+	EventID       string `json:"event_id"`
+	Field         string `json:"field"`
+	Action        string `json:"action"`
+	OriginalChars int    `json:"original_chars,omitempty"`
+	KeptChars     int    `json:"kept_chars,omitempty"`
+}
+
+// ChunkManifest describes the contents of a single chunk
+type ChunkManifest struct {
+	Chunk           int          `json:"chunk"`
+	TotalChunks     int          `json:"total_chunks"`
+	Group           string       `json:"group,omitempty"`
+	EventCount      int          `json:"event_count"`
+	FirstEventID    string       `json:"first_event_id,omitempty"`
+	LastEventID     string       `json:"last_event_id,omitempty"`
+	EstimatedTokens int          `json:"estimated_tokens"`
+	Bytes           int          `json:"bytes"`
+	Trimmed         []TrimRecord `json:"trimmed,omitempty"`
+	// OversizedEventIDs lists events that still exceed the budget after all trimming steps
+	OversizedEventIDs []string `json:"oversized_event_ids,omitempty"`
+}
+
+// AIChunk is an ordered slice of AI events that fits the configured budget
+type AIChunk struct {
+	Events   []AIEvent
+	Manifest ChunkManifest
+}
+
+// chunkSize is the estimated serialized size of an event
+type chunkSize struct {
+	tokens int
+	bytes  int
+}
+
+func (s chunkSize) add(other chunkSize) chunkSize {
+	return chunkSize{tokens: s.tokens + other.tokens, bytes: s.bytes + other.bytes}
+}
+
+func (s chunkSize) fits(options ChunkOptions) bool {
+	if options.MaxTokens > 0 && s.tokens > options.MaxTokens {
+		return false
+	}
+	if options.MaxBytes > 0 && s.bytes > options.MaxBytes {
+		return false
+	}
+	return true
+}
+
+// EstimateTokens returns a rough token count used for model context budgeting.
+// ASCII text averages about four characters per token, while other scripts
+// (e.g. Japanese) are closer to one token per character.
+func EstimateTokens(text string) int {
+	ascii := 0
+	other := 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+3)/4 + other
+}
+
+// measureAIEvent estimates the size of an event as it appears in an indented events array
+func measureAIEvent(event AIEvent) chunkSize {
+	data, err := json.MarshalIndent(event, "    ", "  ")
+	if err != nil {
+		return chunkSize{}
+	}
+	// account for the ",\n    " separator between array elements
+	return chunkSize{tokens: EstimateTokens(string(data)) + 2, bytes: len(data) + 6}
+}
+
+// trimStep removes or shortens one low-value field of an event that exceeds the budget
+type trimStep func(event *AIEvent) []TrimRecord
+
+// trimSteps are applied in order: attachment text, related links, attachments, context, content
+var trimSteps = []trimStep{
+	truncateAttachments(8000),
+	truncateAttachments(2000),
+	dropContextKey("related"),
+	dropContextKey("attachments"),
+	dropContext,
+	truncateContent(2000),
+	truncateContent(500),
+}
+
+// reservedChunkSize returns room kept free in each chunk for summary, statistics and manifest
+func reservedChunkSize(options ChunkOptions) ChunkOptions {
+	limited := options
+	if limited.MaxTokens > 0 {
+		limited.MaxTokens -= minInt(1000, limited.MaxTokens/4)
+	}
+	if limited.MaxBytes > 0 {
+		limited.MaxBytes -= minInt(4000, limited.MaxBytes/4)
+	}
+	return limited
+}
+
+// ChunkAIEvents splits AI events into ordered chunks that fit the budget.
+// Events are grouped by day or service first; events that do not fit on their own are
+// trimmed progressively and every trim is recorded in the chunk manifest.
+func ChunkAIEvents(events []AIEvent, options ChunkOptions) []AIChunk {
+	if len(events) == 0 {
+		return nil
+	}
+	limit := reservedChunkSize(options)
+
+	var chunks []AIChunk
+	for _, group := range groupAIEvents(events, options) {
+		var current *AIChunk
+		var used chunkSize
+		for _, event := range group.events {
+			size := measureAIEvent(event)
+
+			var trimmed []TrimRecord
+			if options.Enabled() && !size.fits(limit) {
+				event = cloneAIEvent(event)
+				for _, step := range trimSteps {
+					for _, record := range step(&event) {
+						trimmed = addTrimRecord(trimmed, record)
+					}
+					size = measureAIEvent(event)
+					if size.fits(limit) {
+						break
+					}
+				}
+			}
+
+			if current == nil || (options.Enabled() && len(current.Events) > 0 && !used.add(size).fits(limit)) {
+				chunks = append(chunks, AIChunk{Manifest: ChunkManifest{Group: group.key}})
+				current = &chunks[len(chunks)-1]
+				used = chunkSize{}
+			}
+
+			current.Events = append(current.Events, event)
+			current.Manifest.Trimmed = append(current.Manifest.Trimmed, trimmed...)
+			if !size.fits(limit) {
+				current.Manifest.OversizedEventIDs = append(current.Manifest.OversizedEventIDs, event.ID)
+			}
+			used = used.add(size)
+			current.Manifest.EstimatedTokens = used.tokens
+			current.Manifest.Bytes = used.bytes
+		}
+		current = nil
+	}
+
+	for i := range chunks {
+		manifest := &chunks[i].Manifest
+		manifest.Chunk = i + 1
+		manifest.TotalChunks = len(chunks)
+		manifest.EventCount = len(chunks[i].Events)
+		manifest.FirstEventID = chunks[i].Events[0].ID
+		manifest.LastEventID = chunks[i].Events[len(chunks[i].Events)-1].ID
+	}
+	return chunks
+}
+
+type aiEventGroup struct {
+	key    string
+	events []AIEvent
+}
+
+// groupAIEvents groups events by day, service or member while keeping the original order within each group
+func groupAIEvents(events []AIEvent, options ChunkOptions) []aiEventGroup {
+	if options.GroupBy == ChunkByNone {
+		return []aiEventGroup{{events: events}}
+	}
+
+	loc := options.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	byKey := make(map[string][]AIEvent)
+	var keys []string
+	for _, event := range events {
+		key := event.Service
+		if options.GroupBy == ChunkByMember {
+			key = event.Member
+		}
+		if options.GroupBy == ChunkByDay {
+			key = event.Timestamp
+			if ts, err := time.Parse(time.RFC3339, event.Timestamp); err == nil {
+				key = ts.In(loc).Format("2006-01-02")
+			} else if len(key) >= 10 {
+				key = key[:10]
+			}
+		}
+		if _, exists := byKey[key]; !exists {
+			keys = append(keys, key)
+		}
+		byKey[key] = append(byKey[key], event)
+	}
+	sort.Strings(keys)
+
+	groups := make([]aiEventGroup, 0, len(keys))
+	for _, key := range keys {
+		groups = append(groups, aiEventGroup{key: key, events: byKey[key]})
+	}
+	return groups
+}
+
+// cloneAIEvent copies the parts of an event that trimming may modify
+func cloneAIEvent(event AIEvent) AIEvent {
+	if event.Context != nil {
+		context := make(map[string]interface{}, len(event.Context))
+		for key, value := range event.Context {
+			context[key] = value
+		}
+		event.Context = context
+	}
+	return event
+}
+
+// addTrimRecord appends the record of a trim step. A field shortened by several steps
+// (8000 then 2000 characters) is reported once, with its original and final length.
+func addTrimRecord(records []TrimRecord, record TrimRecord) []TrimRecord {
+	for i, existing := range records {
+		if existing.EventID != record.EventID || existing.Field != record.Field {
+			continue
+		}
+		if record.Action == "truncated" && existing.OriginalChars > 0 {
+			record.OriginalChars = existing.OriginalChars
+		}
+		records[i] = record
+		return records
+	}
+	return append(records, record)
+}
+
+func truncateAttachments(maxChars int) trimStep {
+	return func(event *AIEvent) []TrimRecord {
+		attachments, ok := event.Context["attachments"].([]config.EventAttachment)
+		if !ok {
+			return nil
+		}
+
+		var records []TrimRecord
+		copied := make([]config.EventAttachment, len(attachments))
+		copy(copied, attachments)
+		for i := range copied {
+			original := utf8.RuneCountInString(copied[i].TextFull)
+			if original <= maxChars {
+				continue
+			}
+			copied[i].TextFull = truncateRunes(copied[i].TextFull, maxChars)
+			copied[i].Truncated = true
+			records = append(records, TrimRecord{
+				EventID:       event.ID,
+				Field:         fmt.Sprintf("context.attachments[%d].text_full", i),
+				Action:        "truncated",
+				OriginalChars: original,
+				KeptChars:     maxChars,
+			})
+		}
+		event.Context["attachments"] = copied
+		return records
+	}
+}
+
+func dropContextKey(key string) trimStep {
+	return func(event *AIEvent) []TrimRecord {
+		if _, ok := event.Context[key]; !ok {
+			return nil
+		}
+		delete(event.Context, key)
+		return []TrimRecord{{EventID: event.ID, Field: "context." + key, Action: "dropped"}}
+	}
+}
+
+func dropContext(event *AIEvent) []TrimRecord {
+	if len(event.Context) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(event.Context))
+	for key := range event.Context {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	records := make([]TrimRecord, 0, len(keys))
+	for _, key := range keys {
+		records = append(records, TrimRecord{EventID: event.ID, Field: "context." + key, Action: "dropped"})
+	}
+	event.Context = nil
+	return records
+}
+
+func truncateContent(maxChars int) trimStep {
+	return func(event *AIEvent) []TrimRecord {
+		original := utf8.RuneCountInString(event.Content)
+		if original <= maxChars {
+			return nil
+		}
+		event.Content = truncateRunes(event.Content, maxChars)
+		return []TrimRecord{{
+			EventID:       event.ID,
+			Field:         "content",
+			Action:        "truncated",
+			OriginalChars: original,
+			KeptChars:     maxChars,
+		}}
+	}
+}
+
+func truncateRunes(text string, maxChars int) string {
+	count := 0
+	for i := range text {
+		if count == maxChars {
+			return text[:i] + "…"
+		}
+		count++
+	}
+	return text
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// AIChunkIndex is written next to chunk files and lists every chunk in order
+type AIChunkIndex struct {
+	ExportedAt  string              `json:"exported_at"`
+	TotalEvents int                 `json:"total_events"`
+	TotalChunks int                 `json:"total_chunks"`
+	MaxTokens   int                 `json:"max_tokens,omitempty"`
+	MaxBytes    int                 `json:"max_bytes,omitempty"`
+	GroupBy     string              `json:"group_by,omitempty"`
+	Chunks      []AIChunkIndexEntry `json:"chunks"`
+}
+
+// AIChunkIndexEntry pairs a chunk file with its manifest
+type AIChunkIndexEntry struct {
+	File     string        `json:"file"`
+	Manifest ChunkManifest `json:"manifest"`
+}
+
+// ExportForAIChunked exports events in AI format split into ordered chunk files.
+// Files are named <base>_001.json, <base>_002.json, ... and an index is written to <base>_manifest.json.
+func (je *JSONExporter) ExportForAIChunked(events []*config.Event, outputPath string, options ChunkOptions) ([]string, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	if outputPath == "" {
+		outputPath = fmt.Sprintf("worklogr_ai_ready_%s.json", time.Now().Format("20060102_150405"))
+	}
+	base := strings.TrimSuffix(outputPath, filepath.Ext(outputPath))
+
+	// Ensure directory exists
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	eventByID := make(map[string]*config.Event, len(events))
+	for _, event := range events {
+		eventByID[event.ID] = event
+	}
+
+	chunks := ChunkAIEvents(je.convertEventsForAI(events), options)
+	exportedAt := time.Now().Format(time.RFC3339)
+	index := &AIChunkIndex{
+		ExportedAt:  exportedAt,
+		TotalEvents: len(events),
+		TotalChunks: len(chunks),
+		MaxTokens:   options.MaxTokens,
+		MaxBytes:    options.MaxBytes,
+		GroupBy:     options.GroupBy,
+	}
+
+	var paths []string
+	for i := range chunks {
+		chunk := &chunks[i]
+		chunkEvents := make([]*config.Event, 0, len(chunk.Events))
+		for _, aiEvent := range chunk.Events {
+			if event, ok := eventByID[aiEvent.ID]; ok {
+				chunkEvents = append(chunkEvents, event)
+			}
+		}
+
+		data := &AIExportData{
+			Summary: AIExportSummary{
+				SchemaVersion: SchemaVersion,
+				TotalEvents:   len(chunk.Events),
+				DateRange:     je.calculateTimeRange(chunkEvents),
+				ServicesUsed:  je.getServiceNames(chunkEvents),
+				ExportedAt:    exportedAt,
+				Purpose:       "Daily report generation for AI processing",
+			},
+			Manifest:   &chunk.Manifest,
+			Events:     chunk.Events,
+			Statistics: je.generateStatistics(chunkEvents),
+		}
+
+		jsonData, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal chunk %d to JSON: %w", i+1, err)
+		}
+
+		chunkPath := fmt.Sprintf("%s_%03d.json", base, i+1)
+		if err := os.WriteFile(chunkPath, jsonData, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write chunk file: %w", err)
+		}
+		paths = append(paths, chunkPath)
+		index.Chunks = append(index.Chunks, AIChunkIndexEntry{File: filepath.Base(chunkPath), Manifest: chunk.Manifest})
+	}
+
+	indexData, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal chunk index to JSON: %w", err)
+	}
+	indexPath := base + "_manifest.json"
+	if err := os.WriteFile(indexPath, indexData, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write chunk index: %w", err)
+	}
+
+	fmt.Printf("AI-ready events exported to %d chunk(s): %s_*.json\n", len(chunks), base)
+	fmt.Printf("Chunk manifest: %s\n", indexPath)
+	fmt.Printf("Total events: %d\n", len(events))
+	return paths, nil
+}
@@ -2,14 +2,15 @@ package exporter
 
 import (
 	"encoding/csv"
-	"encoding/json"
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/eventmeta"
 )
 
 // CSVExporter handles CSV export functionality
@@ -22,58 +23,7 @@ func NewCSVExporter() *CSVExporter {
 
 // ExportToCSV exports events to a CSV file
 func (ce *CSVExporter) ExportToCSV(events []*config.Event, outputPath string) error {
-	if outputPath == "" {
-		outputPath = fmt.Sprintf("worklogr_events_%s.csv", time.Now().Format("20060102_150405"))
-	}
-
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
-
-	// Create CSV file
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create CSV file: %w", err)
-	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	// Write header
-	header := []string{
-		"Timestamp",
-		"Service",
-		"Type",
-		"Title",
-		"Content",
-		"UserID",
-		"Metadata",
-	}
-	if err := writer.Write(header); err != nil {
-		return fmt.Errorf("failed to write CSV header: %w", err)
-	}
-
-	// Write events
-	for _, event := range events {
-		record := []string{
-			event.Timestamp.Format(time.RFC3339),
-			event.Service,
-			event.Type,
-			event.Title,
-			event.Content,
-			event.UserID,
-			event.Metadata,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("failed to write CSV record: %w", err)
-		}
-	}
-
-	fmt.Printf("Events exported to CSV: %s\n", outputPath)
-	fmt.Printf("Total events: %d\n", len(events))
-	return nil
+	return ce.StreamToCSV(NewSliceSource(events), outputPath)
 }
 
 // ExportToCSVWithSummary exports events to CSV with a summary sheet
@@ -81,25 +31,25 @@ func (ce *CSVExporter) ExportToCSVWithSummary(events []*config.Event, outputPath
 	if outputPath == "" {
 		outputPath = fmt.Sprintf("worklogr_events_summary_%s.csv", time.Now().Format("20060102_150405"))
 	}
-
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
-
-	// Create CSV file
-	file, err := os.Create(outputPath)
+	count, err := writeFile(outputPath, func(w io.Writer) (int, error) {
+		return ce.WriteCSVWithSummary(w, events)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create CSV file: %w", err)
+		return err
 	}
-	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	fmt.Printf("Events with summary exported to CSV: %s\n", outputPath)
+	fmt.Printf("Total events: %d\n", count)
+	return nil
+}
+
+// WriteCSVWithSummary writes the summary section followed by the events section to w
+func (ce *CSVExporter) WriteCSVWithSummary(w io.Writer, events []*config.Event) (int, error) {
+	writer := csv.NewWriter(w)
 
 	// Write summary section
 	if err := ce.writeSummarySection(writer, events); err != nil {
-		return fmt.Errorf("failed to write summary section: %w", err)
+		return 0, fmt.Errorf("failed to write summary section: %w", err)
 	}
 
 	// Write empty line
@@ -107,34 +57,38 @@ func (ce *CSVExporter) ExportToCSVWithSummary(events []*config.Event, outputPath
 
 	// Write events section
 	if err := ce.writeEventsSection(writer, events); err != nil {
-		return fmt.Errorf("failed to write events section: %w", err)
+		return 0, fmt.Errorf("failed to write events section: %w", err)
 	}
 
-	fmt.Printf("Events with summary exported to CSV: %s\n", outputPath)
-	fmt.Printf("Total events: %d\n", len(events))
-	return nil
+	writer.Flush()
+	return len(events), writer.Error()
 }
 
 // ExportForSpreadsheet exports events in a format optimized for spreadsheet analysis
 func (ce *CSVExporter) ExportForSpreadsheet(events []*config.Event, outputPath string) error {
+	return ce.StreamForSpreadsheet(NewSliceSource(events), outputPath)
+}
+
+// StreamForSpreadsheet writes the spreadsheet-optimized CSV of a source to a file
+func (ce *CSVExporter) StreamForSpreadsheet(source EventSource, outputPath string) error {
 	if outputPath == "" {
 		outputPath = fmt.Sprintf("worklogr_spreadsheet_%s.csv", time.Now().Format("20060102_150405"))
 	}
-
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
-
-	// Create CSV file
-	file, err := os.Create(outputPath)
+	count, err := writeFile(outputPath, func(w io.Writer) (int, error) {
+		return ce.WriteSpreadsheet(w, source)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create CSV file: %w", err)
+		return err
 	}
-	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	fmt.Printf("Spreadsheet-optimized events exported to CSV: %s\n", outputPath)
+	fmt.Printf("Total events: %d\n", count)
+	return nil
+}
+
+// WriteSpreadsheet streams events as CSV with additional analysis columns
+func (ce *CSVExporter) WriteSpreadsheet(w io.Writer, source EventSource) (int, error) {
+	writer := csv.NewWriter(w)
 
 	// Write enhanced header for spreadsheet analysis
 	header := []string{
@@ -151,13 +105,20 @@ func (ce *CSVExporter) ExportForSpreadsheet(events []*config.Event, outputPath s
 		"ContentLength",
 		"HasMetadata",
 		"Metadata",
+		"URL",
+		"Repository",
+		"Channel",
+		"DurationMinutes",
 	}
 	if err := writer.Write(header); err != nil {
-		return fmt.Errorf("failed to write CSV header: %w", err)
+		return 0, fmt.Errorf("failed to write CSV header: %w", err)
 	}
 
 	// Write events with additional analysis columns
-	for _, event := range events {
+	count := 0
+	for source.Next() {
+		event := source.Event()
+		common := eventmeta.CommonOf(event)
 		record := []string{
 			event.Timestamp.Format("2006-01-02"),
 			event.Timestamp.Format("15:04:05"),
@@ -172,40 +133,53 @@ func (ce *CSVExporter) ExportForSpreadsheet(events []*config.Event, outputPath s
 			strconv.Itoa(len(event.Content)),
 			strconv.FormatBool(event.Metadata != ""),
 			event.Metadata,
+			common.URL,
+			common.Repository,
+			common.Channel,
+			strconv.Itoa(common.DurationMinutes),
 		}
 		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("failed to write CSV record: %w", err)
+			return count, fmt.Errorf("failed to write CSV record: %w", err)
 		}
+		count++
+	}
+	if err := source.Err(); err != nil {
+		return count, err
 	}
 
-	fmt.Printf("Spreadsheet-optimized events exported to CSV: %s\n", outputPath)
-	fmt.Printf("Total events: %d\n", len(events))
-	return nil
+	writer.Flush()
+	return count, writer.Error()
 }
 
 // ExportDailySummary exports a daily summary in CSV format
 func (ce *CSVExporter) ExportDailySummary(events []*config.Event, outputPath string) error {
+	return ce.StreamDailySummary(NewSliceSource(events), outputPath)
+}
+
+// StreamDailySummary writes the daily summary CSV of a source to a file
+func (ce *CSVExporter) StreamDailySummary(source EventSource, outputPath string) error {
 	if outputPath == "" {
 		outputPath = fmt.Sprintf("worklogr_daily_summary_%s.csv", time.Now().Format("20060102_150405"))
 	}
+	if _, err := writeFile(outputPath, func(w io.Writer) (int, error) {
+		return ce.WriteDailySummary(w, source)
+	}); err != nil {
+		return err
+	}
 
-	// Group events by date and service
-	dailySummary := ce.generateDailySummary(events)
+	fmt.Printf("Daily summary exported to CSV: %s\n", outputPath)
+	return nil
+}
 
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
+// WriteDailySummary writes one row per date and service, ordered by date and
+// service. Only the outline is needed, so the events themselves are not read.
+func (ce *CSVExporter) WriteDailySummary(w io.Writer, source EventSource) (int, error) {
+	outline := source.Outline()
 
-	// Create CSV file
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create CSV file: %w", err)
-	}
-	defer file.Close()
+	// Group events by date and service
+	dailySummary := ce.generateDailySummary(outline)
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	writer := csv.NewWriter(w)
 
 	// Write header
 	header := []string{
@@ -218,12 +192,23 @@ func (ce *CSVExporter) ExportDailySummary(events []*config.Event, outputPath str
 		"Summary",
 	}
 	if err := writer.Write(header); err != nil {
-		return fmt.Errorf("failed to write CSV header: %w", err)
+		return 0, fmt.Errorf("failed to write CSV header: %w", err)
 	}
 
 	// Write daily summary records
-	for date, services := range dailySummary {
-		for service, summary := range services {
+	dates := make([]string, 0, len(dailySummary))
+	for date := range dailySummary {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	for _, date := range dates {
+		services := make([]string, 0, len(dailySummary[date]))
+		for service := range dailySummary[date] {
+			services = append(services, service)
+		}
+		sort.Strings(services)
+		for _, service := range services {
+			summary := dailySummary[date][service]
 			record := []string{
 				date,
 				service,
@@ -234,13 +219,13 @@ func (ce *CSVExporter) ExportDailySummary(events []*config.Event, outputPath str
 				summary.Summary,
 			}
 			if err := writer.Write(record); err != nil {
-				return fmt.Errorf("failed to write CSV record: %w", err)
+				return 0, fmt.Errorf("failed to write CSV record: %w", err)
 			}
 		}
 	}
 
-	fmt.Printf("Daily summary exported to CSV: %s\n", outputPath)
-	return nil
+	writer.Flush()
+	return len(outline), writer.Error()
 }
 
 // DailySummary represents a summary for a specific date and service
@@ -350,6 +335,16 @@ func (ce *CSVExporter) calculateTimeRange(events []*config.Event) *TimeRange {
 	}
 }
 
+// hasMembers reports whether any event was collected for a configured user
+func hasMembers(events []*config.Event) bool {
+	for _, event := range events {
+		if event.Member != "" {
+			return true
+		}
+	}
+	return false
+}
+
 // getServiceStatistics returns event count by service
 func (ce *CSVExporter) getServiceStatistics(events []*config.Event) map[string]int {
 	stats := make(map[string]int)
@@ -457,22 +452,19 @@ func (ce *CSVExporter) ValidateCSV(filePath string) error {
 	return nil
 }
 
-// ConvertJSONToCSV converts a JSON export file to CSV format
+// ConvertJSONToCSV converts a json, json-events or ndjson export file to CSV format
 func (ce *CSVExporter) ConvertJSONToCSV(jsonPath, csvPath string) error {
-	// Read JSON file
-	jsonData, err := os.ReadFile(jsonPath)
+	file, err := os.Open(jsonPath)
 	if err != nil {
 		return fmt.Errorf("failed to read JSON file: %w", err)
 	}
+	defer file.Close()
 
-	// Parse JSON
-	var exportData struct {
-		Events []*config.Event `json:"events"`
-	}
-	if err := json.Unmarshal(jsonData, &exportData); err != nil {
-		return fmt.Errorf("failed to parse JSON: %w", err)
+	events, err := ReadEvents(file)
+	if err != nil {
+		return err
 	}
 
 	// Export to CSV
-	return ce.ExportToCSV(exportData.Events, csvPath)
+	return ce.ExportToCSV(events, csvPath)
 }
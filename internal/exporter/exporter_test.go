@@ -1,10 +1,15 @@
 package exporter
 
 import (
+	"archive/zip"
+	"bytes"
 	"encoding/csv"
 	"encoding/json"
+	"encoding/xml"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -137,6 +142,46 @@ func TestJSONExporterConvertEventsForAIIncludesMetadataAndAttachments(t *testing
 	}
 }
 
+func TestJSONExporterConvertEventsForAIIncludesRelatedLinks(t *testing.T) {
+	exporter := NewJSONExporter()
+	events := sampleEvents()
+	events[1].Related = []config.EventLink{
+		{EventID: "event-1", Service: "slack", Title: "Daily update", Relation: "referenced_by", Key: "github:acme/worklogr#1"},
+	}
+
+	aiEvents := exporter.convertEventsForAI(events)
+	if aiEvents[1].ID != "event-2" {
+		t.Fatalf("expected AI event to carry its id, got %q", aiEvents[1].ID)
+	}
+
+	related, ok := aiEvents[1].Context["related"].([]config.EventLink)
+	if !ok || len(related) != 1 || related[0].EventID != "event-1" {
+		t.Fatalf("expected related links in AI context, got %+v", aiEvents[1].Context["related"])
+	}
+	if _, ok := aiEvents[0].Context["related"]; ok {
+		t.Fatalf("expected no related key for events without links")
+	}
+}
+
+func TestJSONExporterConvertEventsForAIIncludesAnnotation(t *testing.T) {
+	exporter := NewJSONExporter()
+	events := sampleEvents()
+	events[0].Annotation = &config.EventAnnotation{Pinned: true, Note: "release blocker", Tags: []string{"release"}}
+	events[1].Annotation = &config.EventAnnotation{}
+
+	aiEvents := exporter.convertEventsForAI(events)
+	annotation, ok := aiEvents[0].Context["annotation"].(map[string]interface{})
+	if !ok || annotation["pinned"] != true || annotation["note"] != "release blocker" {
+		t.Fatalf("expected annotation in AI context, got %+v", aiEvents[0].Context["annotation"])
+	}
+	if _, ok := annotation["hidden"]; ok {
+		t.Fatalf("expected unset flags to be omitted, got %+v", annotation)
+	}
+	if _, ok := aiEvents[1].Context["annotation"]; ok {
+		t.Fatalf("expected no annotation key for empty annotations")
+	}
+}
+
 func TestJSONExporterExportToJSONStringWithEmptyEvents(t *testing.T) {
 	exporter := NewJSONExporter()
 
@@ -250,3 +295,506 @@ func TestCSVExporterGenerateDailySummaryAggregatesByDateAndService(t *testing.T)
 		t.Fatalf("expected 1 github event, got %d", day["github"].EventCount)
 	}
 }
+
+func TestEstimateTokens(t *testing.T) {
+	if got := EstimateTokens("abcdefgh"); got != 2 {
+		t.Fatalf("expected 2 tokens for 8 ASCII chars, got %d", got)
+	}
+	if got := EstimateTokens("日本語"); got != 3 {
+		t.Fatalf("expected 3 tokens for 3 Japanese chars, got %d", got)
+	}
+}
+
+func TestChunkAIEventsGroupsByDayAndTrimsOversizedEvents(t *testing.T) {
+	events := []AIEvent{
+		{ID: "a", Timestamp: "2026-03-01T09:00:00Z", Service: "slack", Content: "short"},
+		{
+			ID:        "big",
+			Timestamp: "2026-03-01T10:00:00Z",
+			Service:   "google_calendar",
+			Content:   "meeting",
+			Context: map[string]interface{}{
+				"attachments": []config.EventAttachment{{FileID: "doc", TextFull: strings.Repeat("n", 20000)}},
+				"related":     []config.EventLink{{EventID: "a"}},
+			},
+		},
+		{ID: "b", Timestamp: "2026-03-02T09:00:00Z", Service: "github", Content: "next day"},
+	}
+
+	chunks := ChunkAIEvents(events, ChunkOptions{MaxTokens: 2000, GroupBy: ChunkByDay, Location: time.UTC})
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(chunks))
+	}
+	if chunks[0].Manifest.Group != "2026-03-01" || chunks[0].Manifest.EventCount != 2 || chunks[1].Manifest.Group != "2026-03-02" {
+		t.Fatalf("unexpected manifests: %+v / %+v", chunks[0].Manifest, chunks[1].Manifest)
+	}
+	if chunks[0].Manifest.TotalChunks != 2 || chunks[1].Manifest.Chunk != 2 {
+		t.Fatalf("unexpected chunk numbering: %+v", chunks[1].Manifest)
+	}
+
+	trimmed := chunks[0].Manifest.Trimmed
+	// 8000字と2000字の2段階の切り詰めは1件にまとめ、元の長さと最終的な長さを記録する
+	if len(trimmed) != 1 || trimmed[0].Field != "context.attachments[0].text_full" || trimmed[0].OriginalChars != 20000 || trimmed[0].KeptChars != 2000 {
+		t.Fatalf("unexpected trim records: %+v", trimmed)
+	}
+	if chunks[0].Manifest.EstimatedTokens > 2000 {
+		t.Fatalf("expected chunk to fit budget, got %d tokens", chunks[0].Manifest.EstimatedTokens)
+	}
+
+	// 元のイベントは変更されない
+	original := events[1].Context["attachments"].([]config.EventAttachment)
+	if len(original[0].TextFull) != 20000 {
+		t.Fatalf("expected original attachment to be untouched")
+	}
+}
+
+func TestExportForAIChunkedWritesChunksAndIndex(t *testing.T) {
+	exporter := NewJSONExporter()
+	outputPath := filepath.Join(t.TempDir(), "ai.json")
+
+	paths, err := exporter.ExportForAIChunked(sampleEvents(), outputPath, ChunkOptions{MaxBytes: 100000, GroupBy: ChunkByService})
+	if err != nil {
+		t.Fatalf("ExportForAIChunked returned error: %v", err)
+	}
+	if len(paths) != 2 || filepath.Base(paths[0]) != "ai_001.json" {
+		t.Fatalf("unexpected chunk paths: %v", paths)
+	}
+
+	var chunk AIExportData
+	data, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatalf("failed to read chunk: %v", err)
+	}
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		t.Fatalf("failed to decode chunk: %v", err)
+	}
+	if chunk.Manifest == nil || chunk.Manifest.Group != "github" || chunk.Summary.TotalEvents != 1 {
+		t.Fatalf("unexpected chunk: %+v", chunk)
+	}
+
+	var index AIChunkIndex
+	data, err = os.ReadFile(filepath.Join(filepath.Dir(outputPath), "ai_manifest.json"))
+	if err != nil {
+		t.Fatalf("failed to read chunk index: %v", err)
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatalf("failed to decode chunk index: %v", err)
+	}
+	if index.TotalChunks != 2 || index.Chunks[1].File != "ai_002.json" || index.GroupBy != "service" {
+		t.Fatalf("unexpected chunk index: %+v", index)
+	}
+}
+
+// outlineSource mimics database.EventIterator: the outline only carries summary fields
+type outlineSource struct {
+	EventSource
+	outline []*config.Event
+}
+
+func newOutlineSource(events []*config.Event) *outlineSource {
+	source := &outlineSource{EventSource: NewSliceSource(events)}
+	for _, event := range events {
+		source.outline = append(source.outline, &config.Event{
+			ID: event.ID, Service: event.Service, Type: event.Type, Member: event.Member, Timestamp: event.Timestamp,
+		})
+	}
+	return source
+}
+
+func (s *outlineSource) Outline() []*config.Event { return s.outline }
+
+// withoutLines drops lines containing the given marker (e.g. the export timestamp)
+func withoutLines(text, marker string) string {
+	var kept []string
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if !strings.Contains(line, marker) {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+func TestWriteJSONMatchesIndentedExportData(t *testing.T) {
+	exporter := NewJSONExporter()
+	events := sampleEvents()
+
+	var streamed strings.Builder
+	count, err := exporter.WriteJSON(&streamed, newOutlineSource(events))
+	if err != nil {
+		t.Fatalf("WriteJSON returned error: %v", err)
+	}
+	if count != len(events) {
+		t.Fatalf("expected %d events written, got %d", len(events), count)
+	}
+
+	expected, err := json.MarshalIndent(&ExportData{
+		ExportedAt: time.Now(),
+		EventCount: len(events),
+		TimeRange:  exporter.calculateTimeRange(events),
+		Services:   exporter.getServicesSummary(events),
+		Events:     events,
+		Metadata:   exporter.createMetadata(events),
+	}, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal expected data: %v", err)
+	}
+	if got, want := withoutLines(streamed.String(), `"exported_at"`), withoutLines(string(expected), `"exported_at"`); got != want {
+		t.Fatalf("streamed JSON differs from MarshalIndent:\n%s\n---\n%s", got, want)
+	}
+}
+
+func TestWriteEventsOnlyMatchesIndentedEvents(t *testing.T) {
+	exporter := NewJSONExporter()
+	for _, events := range [][]*config.Event{sampleEvents(), {}} {
+		var streamed strings.Builder
+		count, err := exporter.WriteEventsOnly(&streamed, newOutlineSource(events))
+		if err != nil {
+			t.Fatalf("WriteEventsOnly returned error: %v", err)
+		}
+		if count != len(events) {
+			t.Fatalf("expected %d events written, got %d", len(events), count)
+		}
+
+		expected, err := json.MarshalIndent(events, "", "  ")
+		if err != nil {
+			t.Fatalf("failed to marshal expected events: %v", err)
+		}
+		if got, want := streamed.String(), string(expected)+"\n"; got != want {
+			t.Fatalf("streamed events differ from MarshalIndent:\n%s\n---\n%s", got, want)
+		}
+	}
+}
+
+func TestWriteForAIMatchesBuildAIExportData(t *testing.T) {
+	exporter := NewJSONExporter()
+	events := sampleEvents()
+	events[0].Member = "alice"
+
+	var streamed strings.Builder
+	if _, err := exporter.WriteForAI(&streamed, newOutlineSource(events)); err != nil {
+		t.Fatalf("WriteForAI returned error: %v", err)
+	}
+
+	expected, err := json.MarshalIndent(exporter.BuildAIExportData(events), "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal expected data: %v", err)
+	}
+	if got, want := withoutLines(streamed.String(), `"exported_at"`), withoutLines(string(expected), `"exported_at"`); got != want {
+		t.Fatalf("streamed json-ai differs from BuildAIExportData:\n%s\n---\n%s", got, want)
+	}
+}
+
+func TestWriteNDJSONAndCSVStreamEveryEvent(t *testing.T) {
+	events := sampleEvents()
+	events[1].Member = "bob"
+
+	var ndjson strings.Builder
+	if _, err := NewJSONExporter().WriteNDJSON(&ndjson, NewSliceSource(events)); err != nil {
+		t.Fatalf("WriteNDJSON returned error: %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(ndjson.String(), "\n"), "\n")
+	if len(lines) != len(events) {
+		t.Fatalf("expected %d lines, got %d", len(events), len(lines))
+	}
+	var decoded config.Event
+	if err := json.Unmarshal([]byte(lines[0]), &decoded); err != nil {
+		t.Fatalf("failed to decode NDJSON line: %v", err)
+	}
+	if decoded.ID != "event-1" || len(decoded.Attachments) != 1 {
+		t.Fatalf("unexpected NDJSON event: %+v", decoded)
+	}
+
+	var csvOutput strings.Builder
+	if _, err := NewCSVExporter().WriteCSV(&csvOutput, newOutlineSource(events)); err != nil {
+		t.Fatalf("WriteCSV returned error: %v", err)
+	}
+	records, err := csv.NewReader(strings.NewReader(csvOutput.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse CSV: %v", err)
+	}
+	if len(records) != len(events)+1 || records[0][len(records[0])-1] != "Member" || records[2][7] != "bob" {
+		t.Fatalf("unexpected CSV records: %v", records)
+	}
+}
+
+func TestExportsValidateAgainstSchemas(t *testing.T) {
+	exporter := NewJSONExporter()
+	dir := t.TempDir()
+	deletedAt := time.Date(2026, 3, 5, 9, 0, 0, 0, time.UTC)
+	events := sampleEvents()
+	events[0].Member = "alice"
+	events[0].Annotation = &config.EventAnnotation{Pinned: true, Note: "release", Tags: []string{"release"}, UpdatedAt: deletedAt}
+	events[1].DeletedAt = &deletedAt
+	events[1].Repository = "iriam/worklogr"
+	events[1].Related = []config.EventLink{{EventID: "event-1", Relation: "mentions", Key: "#1", Timestamp: events[0].Timestamp}}
+
+	write := func(name string, fn func(*os.File) error) string {
+		path := filepath.Join(dir, name)
+		file, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+		defer file.Close()
+		if err := fn(file); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	for name, source := range map[string][]*config.Event{"full": events, "empty": nil} {
+		jsonPath := write(name+".json", func(file *os.File) error {
+			_, err := exporter.WriteJSON(file, NewSliceSource(source))
+			return err
+		})
+		aiPath := write(name+"_ai.json", func(file *os.File) error {
+			_, err := exporter.WriteForAI(file, NewSliceSource(source))
+			return err
+		})
+		ndjsonPath := write(name+".ndjson", func(file *os.File) error {
+			_, err := exporter.WriteNDJSON(file, NewSliceSource(source))
+			return err
+		})
+
+		for _, path := range []string{jsonPath, aiPath} {
+			if err := exporter.ValidateJSON(path); err != nil {
+				t.Fatalf("%s does not validate: %v", filepath.Base(path), err)
+			}
+		}
+		file, err := os.Open(ndjsonPath)
+		if err != nil {
+			t.Fatalf("failed to open ndjson: %v", err)
+		}
+		err = ValidateNDJSON(file)
+		file.Close()
+		if err != nil {
+			t.Fatalf("%s does not validate: %v", filepath.Base(ndjsonPath), err)
+		}
+	}
+
+	chunks, err := exporter.ExportForAIChunked(events, filepath.Join(dir, "chunked.json"), ChunkOptions{MaxBytes: 100000, GroupBy: ChunkByService})
+	if err != nil {
+		t.Fatalf("ExportForAIChunked returned error: %v", err)
+	}
+	for _, path := range chunks {
+		if err := exporter.ValidateJSON(path); err != nil {
+			t.Fatalf("%s does not validate: %v", filepath.Base(path), err)
+		}
+	}
+
+	var stamped ExportData
+	data, err := os.ReadFile(filepath.Join(dir, "full.json"))
+	if err != nil {
+		t.Fatalf("failed to read export: %v", err)
+	}
+	if err := json.Unmarshal(data, &stamped); err != nil {
+		t.Fatalf("failed to decode export: %v", err)
+	}
+	if stamped.Metadata == nil || stamped.Metadata.Version != SchemaVersion {
+		t.Fatalf("expected metadata.version %s, got %+v", SchemaVersion, stamped.Metadata)
+	}
+}
+
+func TestValidateJSONReportsSchemaViolations(t *testing.T) {
+	exporter := NewJSONExporter()
+	jsonString, err := exporter.ExportToJSONString(sampleEvents())
+	if err != nil {
+		t.Fatalf("ExportToJSONString returned error: %v", err)
+	}
+
+	var document map[string]interface{}
+	if err := json.Unmarshal([]byte(jsonString), &document); err != nil {
+		t.Fatalf("failed to decode export: %v", err)
+	}
+	first := document["events"].([]interface{})[0].(map[string]interface{})
+	first["timestamp"] = "yesterday"
+	delete(first, "id")
+	first["score"] = 3
+	document["event_count"] = "2"
+
+	path := filepath.Join(t.TempDir(), "broken.json")
+	writeDocument := func() {
+		data, err := json.Marshal(document)
+		if err != nil {
+			t.Fatalf("failed to encode document: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("failed to write document: %v", err)
+		}
+	}
+	writeDocument()
+
+	err = exporter.ValidateJSON(path)
+	validationErr, ok := err.(*SchemaValidationError)
+	if !ok {
+		t.Fatalf("expected SchemaValidationError, got %v", err)
+	}
+	for _, want := range []string{
+		`/event_count: expected integer, got string`,
+		`/events/0: missing required field "id"`,
+		`/events/0/score: unexpected field`,
+		`/events/0/timestamp: "yesterday" is not an RFC 3339 date-time`,
+	} {
+		if !strings.Contains(validationErr.Error(), want) {
+			t.Fatalf("expected problem %q, got %v", want, validationErr.Problems)
+		}
+	}
+
+	document["metadata"].(map[string]interface{})["version"] = "2.0"
+	writeDocument()
+	if err := exporter.ValidateJSON(path); err == nil || !strings.Contains(err.Error(), "schema version 2.0") {
+		t.Fatalf("expected incompatible version error, got %v", err)
+	}
+}
+
+func TestSchemaIsSelfContained(t *testing.T) {
+	for _, format := range SchemaFormats {
+		data, err := Schema(format)
+		if err != nil {
+			t.Fatalf("Schema(%s) returned error: %v", format, err)
+		}
+		var document map[string]interface{}
+		if err := json.Unmarshal(data, &document); err != nil {
+			t.Fatalf("schema %s is not valid JSON: %v", format, err)
+		}
+		if document["$id"] != "urn:worklogr:export:"+format+":"+SchemaVersion || document["$defs"] == nil {
+			t.Fatalf("unexpected schema header for %s: %v", format, document["$id"])
+		}
+	}
+	if _, err := Schema("csv"); err == nil {
+		t.Fatalf("expected error for a format without a schema")
+	}
+}
+
+// xlsxTestCell is a parsed worksheet cell
+type xlsxTestCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Style  string `xml:"s,attr"`
+	Value  string `xml:"v"`
+	Inline string `xml:"is>t"`
+}
+
+type xlsxTestSheet struct {
+	Rows []struct {
+		Cells []xlsxTestCell `xml:"c"`
+	} `xml:"sheetData>row"`
+	Hyperlinks []struct {
+		Ref string `xml:"ref,attr"`
+		ID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"hyperlinks>hyperlink"`
+}
+
+func readXLSXPart(t *testing.T, archive *zip.Reader, name string) []byte {
+	t.Helper()
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", name, err)
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		return data
+	}
+	t.Fatalf("workbook has no part %s", name)
+	return nil
+}
+
+func xlsxCellByRef(sheet xlsxTestSheet, ref string) xlsxTestCell {
+	for _, row := range sheet.Rows {
+		for _, cell := range row.Cells {
+			if cell.Ref == ref {
+				return cell
+			}
+		}
+	}
+	return xlsxTestCell{}
+}
+
+func TestWriteXLSXWritesSheetsWithDateCellsAndHyperlinks(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	events := sampleEvents()
+	events[0].Title = "朝会のメモ\x01"
+	events[1].Type = "pull_request_created"
+	events[1].Metadata = `{"schema_version":1,"repository":"iriam/worklogr","url":"https://github.com/iriam/worklogr/pull/1?a=1&b=2"}`
+	events = append(events, &config.Event{
+		ID: "event-3", Service: "slack", Type: "message", Title: "next day",
+		Timestamp: time.Date(2026, 3, 4, 16, 30, 0, 0, time.UTC), Metadata: "{}",
+	})
+
+	xlsxExporter := NewXLSXExporter()
+	xlsxExporter.Location = tokyo
+	var buffer bytes.Buffer
+	count, err := xlsxExporter.WriteXLSX(&buffer, newOutlineSource(events))
+	if err != nil {
+		t.Fatalf("WriteXLSX returned error: %v", err)
+	}
+	if count != len(events) {
+		t.Fatalf("expected %d events written, got %d", len(events), count)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatalf("workbook is not a zip archive: %v", err)
+	}
+	for _, file := range archive.File {
+		decoder := xml.NewDecoder(bytes.NewReader(readXLSXPart(t, archive, file.Name)))
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s is not well-formed XML: %v", file.Name, err)
+			}
+		}
+	}
+	if workbook := string(readXLSXPart(t, archive, "xl/workbook.xml")); !strings.Contains(workbook, `name="Daily Summary"`) || !strings.Contains(workbook, `name="Attachments"`) {
+		t.Fatalf("unexpected sheets: %s", workbook)
+	}
+
+	var eventsSheet xlsxTestSheet
+	if err := xml.Unmarshal(readXLSXPart(t, archive, "xl/worksheets/sheet1.xml"), &eventsSheet); err != nil {
+		t.Fatalf("failed to parse events sheet: %v", err)
+	}
+	if len(eventsSheet.Rows) != len(events)+1 || xlsxCellByRef(eventsSheet, "A1").Inline != "Timestamp" {
+		t.Fatalf("unexpected events sheet rows: %+v", eventsSheet.Rows)
+	}
+	// 2026-03-04 09:00 UTC is 18:00 in Tokyo: serial 46085 + 0.75
+	if cell := xlsxCellByRef(eventsSheet, "A2"); cell.Value != "46085.75" || cell.Style != "1" || cell.Type != "" {
+		t.Fatalf("expected a date-time cell, got %+v", cell)
+	}
+	if cell := xlsxCellByRef(eventsSheet, "E2"); cell.Inline != "朝会のメモ\uFFFD" {
+		t.Fatalf("expected Japanese title with invalid characters replaced, got %q", cell.Inline)
+	}
+	if len(eventsSheet.Hyperlinks) != 2 || eventsSheet.Hyperlinks[0].Ref != "E3" || eventsSheet.Hyperlinks[1].Ref != "K3" {
+		t.Fatalf("unexpected hyperlinks: %+v", eventsSheet.Hyperlinks)
+	}
+	if rels := string(readXLSXPart(t, archive, "xl/worksheets/_rels/sheet1.xml.rels")); !strings.Contains(rels, `Target="https://github.com/iriam/worklogr/pull/1?a=1&amp;b=2" TargetMode="External"`) {
+		t.Fatalf("unexpected hyperlink relationships: %s", rels)
+	}
+
+	// event-3 is on the next day in Tokyo, so the daily summary has two slack rows
+	var dailySheet xlsxTestSheet
+	if err := xml.Unmarshal(readXLSXPart(t, archive, "xl/worksheets/sheet2.xml"), &dailySheet); err != nil {
+		t.Fatalf("failed to parse daily summary sheet: %v", err)
+	}
+	var daily []string
+	for _, row := range dailySheet.Rows[1:] {
+		daily = append(daily, row.Cells[0].Value[:5]+" "+row.Cells[1].Inline)
+	}
+	if strings.Join(daily, ",") != "46085 github,46085 slack,46086 slack" {
+		t.Fatalf("unexpected daily summary rows: %v", daily)
+	}
+
+	var attachmentsSheet xlsxTestSheet
+	if err := xml.Unmarshal(readXLSXPart(t, archive, "xl/worksheets/sheet4.xml"), &attachmentsSheet); err != nil {
+		t.Fatalf("failed to parse attachments sheet: %v", err)
+	}
+	if len(attachmentsSheet.Rows) != 2 || xlsxCellByRef(attachmentsSheet, "H2").Inline != "attachment body" {
+		t.Fatalf("unexpected attachments sheet: %+v", attachmentsSheet.Rows)
+	}
+}
@@ -5,10 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/eventmeta"
 )
 
 // JSONExporter handles JSON export functionality
@@ -21,39 +22,7 @@ func NewJSONExporter() *JSONExporter {
 
 // ExportToJSON exports events to a JSON file
 func (je *JSONExporter) ExportToJSON(events []*config.Event, outputPath string) error {
-	if outputPath == "" {
-		outputPath = fmt.Sprintf("worklogr_events_%s.json", time.Now().Format("20060102_150405"))
-	}
-
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
-
-	// Create export data structure
-	exportData := &ExportData{
-		ExportedAt:   time.Now(),
-		EventCount:   len(events),
-		TimeRange:    je.calculateTimeRange(events),
-		Services:     je.getServicesSummary(events),
-		Events:       events,
-		Metadata:     je.createMetadata(events),
-	}
-
-	// Marshal to JSON with indentation
-	jsonData, err := json.MarshalIndent(exportData, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal events to JSON: %w", err)
-	}
-
-	// Write to file
-	if err := os.WriteFile(outputPath, jsonData, 0644); err != nil {
-		return fmt.Errorf("failed to write JSON file: %w", err)
-	}
-
-	fmt.Printf("Events exported to JSON: %s\n", outputPath)
-	fmt.Printf("Total events: %d\n", len(events))
-	return nil
+	return je.StreamToJSON(NewSliceSource(events), outputPath)
 }
 
 // ExportToJSONString exports events to a JSON string
@@ -77,69 +46,33 @@ func (je *JSONExporter) ExportToJSONString(events []*config.Event) (string, erro
 
 // ExportEventsOnly exports only the events array without metadata
 func (je *JSONExporter) ExportEventsOnly(events []*config.Event, outputPath string) error {
-	if outputPath == "" {
-		outputPath = fmt.Sprintf("worklogr_events_simple_%s.json", time.Now().Format("20060102_150405"))
-	}
-
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
-
-	// Marshal events directly
-	jsonData, err := json.MarshalIndent(events, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal events to JSON: %w", err)
-	}
-
-	// Write to file
-	if err := os.WriteFile(outputPath, jsonData, 0644); err != nil {
-		return fmt.Errorf("failed to write JSON file: %w", err)
-	}
-
-	fmt.Printf("Events exported to JSON (simple format): %s\n", outputPath)
-	fmt.Printf("Total events: %d\n", len(events))
-	return nil
+	return je.StreamEventsOnly(NewSliceSource(events), outputPath)
 }
 
 // ExportForAI exports events in a format optimized for AI processing
 func (je *JSONExporter) ExportForAI(events []*config.Event, outputPath string) error {
-	if outputPath == "" {
-		outputPath = fmt.Sprintf("worklogr_ai_ready_%s.json", time.Now().Format("20060102_150405"))
-	}
+	return je.StreamForAI(NewSliceSource(events), outputPath)
+}
 
-	// Create AI-optimized format
-	aiData := &AIExportData{
-		Summary: AIExportSummary{
-			TotalEvents:    len(events),
-			DateRange:      je.calculateTimeRange(events),
-			ServicesUsed:   je.getServiceNames(events),
-			ExportedAt:     time.Now().Format(time.RFC3339),
-			Purpose:        "Daily report generation for AI processing",
-		},
+// BuildAIExportData builds the AI-optimized export structure in memory
+func (je *JSONExporter) BuildAIExportData(events []*config.Event) *AIExportData {
+	return &AIExportData{
+		Summary: je.buildAISummary(events),
 		Events: je.convertEventsForAI(events),
 		Statistics: je.generateStatistics(events),
 	}
+}
 
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
-
-	// Marshal to JSON
-	jsonData, err := json.MarshalIndent(aiData, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal AI data to JSON: %w", err)
-	}
-
-	// Write to file
-	if err := os.WriteFile(outputPath, jsonData, 0644); err != nil {
-		return fmt.Errorf("failed to write JSON file: %w", err)
+// buildAISummary builds the json-ai summary block
+func (je *JSONExporter) buildAISummary(events []*config.Event) AIExportSummary {
+	return AIExportSummary{
+		SchemaVersion:  SchemaVersion,
+		TotalEvents:    len(events),
+		DateRange:      je.calculateTimeRange(events),
+		ServicesUsed:   je.getServiceNames(events),
+		ExportedAt:     time.Now().Format(time.RFC3339),
+		Purpose:        "Daily report generation for AI processing",
 	}
-
-	fmt.Printf("AI-ready events exported to JSON: %s\n", outputPath)
-	fmt.Printf("Total events: %d\n", len(events))
-	return nil
 }
 
 // ExportData represents the complete export structure
@@ -155,12 +88,15 @@ type ExportData struct {
 // AIExportData represents AI-optimized export structure
 type AIExportData struct {
 	Summary    AIExportSummary `json:"summary"`
+	Manifest   *ChunkManifest  `json:"manifest,omitempty"`
 	Events     []AIEvent       `json:"events"`
 	Statistics *Statistics     `json:"statistics"`
 }
 
 // AIExportSummary provides a summary for AI processing
 type AIExportSummary struct {
+	// SchemaVersion is the json-ai schema version the file was written with (see `worklogr schema`)
+	SchemaVersion string `json:"schema_version,omitempty"`
 	TotalEvents  int      `json:"total_events"`
 	DateRange    *TimeRange `json:"date_range"`
 	ServicesUsed []string `json:"services_used"`
@@ -170,11 +106,17 @@ type AIExportSummary struct {
 
 // AIEvent represents an event optimized for AI processing
 type AIEvent struct {
+	ID          string                 `json:"id"`
+	Member      string                 `json:"member,omitempty"`
 	Timestamp   string                 `json:"timestamp"`
 	Service     string                 `json:"service"`
 	Type        string                 `json:"type"`
 	Title       string                 `json:"title"`
 	Content     string                 `json:"content"`
+	// URL, Repository and Channel are the common fields lifted from metadata
+	URL         string                 `json:"url,omitempty"`
+	Repository  string                 `json:"repository,omitempty"`
+	Channel     string                 `json:"channel,omitempty"`
 	Context     map[string]interface{} `json:"context,omitempty"`
 }
 
@@ -192,6 +134,7 @@ type ServiceSummary struct {
 
 // ExportMetadata contains metadata about the export
 type ExportMetadata struct {
+	// Version is the json schema version the file was written with (see `worklogr schema`)
 	Version     string            `json:"version"`
 	Generator   string            `json:"generator"`
 	Format      string            `json:"format"`
@@ -205,6 +148,8 @@ type Statistics struct {
 	EventsByType    map[string]int `json:"events_by_type"`
 	EventsByHour    map[int]int    `json:"events_by_hour"`
 	EventsByDay     map[string]int `json:"events_by_day"`
+	// EventsByMember is only set for team exports (events collected for configured users)
+	EventsByMember  map[string]int `json:"events_by_member,omitempty"`
 }
 
 // calculateTimeRange calculates the time range of events
@@ -271,48 +216,104 @@ func (je *JSONExporter) getServiceNames(events []*config.Event) []string {
 		serviceMap[event.Service] = true
 	}
 
-	var services []string
+	services := make([]string, 0, len(serviceMap))
 	for service := range serviceMap {
 		services = append(services, service)
 	}
+	sort.Strings(services)
 
 	return services
 }
 
 // convertEventsForAI converts events to AI-optimized format
 func (je *JSONExporter) convertEventsForAI(events []*config.Event) []AIEvent {
-	var aiEvents []AIEvent
+	aiEvents := make([]AIEvent, 0, len(events))
 
 	for _, event := range events {
-		aiEvent := AIEvent{
-			Timestamp: event.Timestamp.Format(time.RFC3339),
-			Service:   event.Service,
-			Type:      event.Type,
-			Title:     event.Title,
-			Content:   event.Content,
+		aiEvents = append(aiEvents, je.convertEventForAI(event))
+	}
+
+	return aiEvents
+}
+
+// convertEventForAI converts a single event to the AI-optimized format
+func (je *JSONExporter) convertEventForAI(event *config.Event) AIEvent {
+	aiEvent := AIEvent{
+		ID:        event.ID,
+		Member:    event.Member,
+		Timestamp: event.Timestamp.Format(time.RFC3339),
+		Service:   event.Service,
+		Type:      event.Type,
+		Title:     event.Title,
+		Content:   event.Content,
+	}
+
+	common := eventmeta.CommonOf(event)
+	aiEvent.URL, aiEvent.Repository, aiEvent.Channel = common.URL, common.Repository, common.Channel
+	if aiEvent.Channel == "" {
+		aiEvent.Channel = common.ChannelID
+	}
+
+	// Parse metadata if available
+	if event.Metadata != "" {
+		var metadata map[string]interface{}
+		if err := json.Unmarshal([]byte(event.Metadata), &metadata); err == nil {
+			aiEvent.Context = metadata
 		}
+	}
 
-		// Parse metadata if available
-		if event.Metadata != "" {
-			var metadata map[string]interface{}
-			if err := json.Unmarshal([]byte(event.Metadata), &metadata); err == nil {
-				aiEvent.Context = metadata
-			}
+	// Attachments are stored separately and hydrated by DB layer.
+	// Include them in AI context when present.
+	if len(event.Attachments) > 0 {
+		if aiEvent.Context == nil {
+			aiEvent.Context = map[string]interface{}{}
 		}
+		aiEvent.Context["attachments"] = event.Attachments
+	}
 
-		// Attachments are stored separately and hydrated by DB layer.
-		// Include them in AI context when present.
-		if len(event.Attachments) > 0 {
-			if aiEvent.Context == nil {
-				aiEvent.Context = map[string]interface{}{}
-			}
-			aiEvent.Context["attachments"] = event.Attachments
+	// Links to related events (detected by the post-collection linking pass).
+	if len(event.Related) > 0 {
+		if aiEvent.Context == nil {
+			aiEvent.Context = map[string]interface{}{}
+		}
+		aiEvent.Context["related"] = event.Related
+	}
+
+	// User curation (pin / note / tags) so the summary can prioritise pinned events.
+	if !event.Annotation.IsEmpty() {
+		if aiEvent.Context == nil {
+			aiEvent.Context = map[string]interface{}{}
 		}
+		aiEvent.Context["annotation"] = aiAnnotation(event.Annotation)
+	}
 
-		aiEvents = append(aiEvents, aiEvent)
+	// Tombstone: the event was no longer found at its source on a later collection.
+	if event.DeletedAt != nil {
+		if aiEvent.Context == nil {
+			aiEvent.Context = map[string]interface{}{}
+		}
+		aiEvent.Context["deleted_at"] = event.DeletedAt.Format(time.RFC3339)
 	}
 
-	return aiEvents
+	return aiEvent
+}
+
+// aiAnnotation keeps only the curation fields that are meaningful to a summarizer
+func aiAnnotation(annotation *config.EventAnnotation) map[string]interface{} {
+	context := map[string]interface{}{}
+	if annotation.Pinned {
+		context["pinned"] = true
+	}
+	if annotation.Hidden {
+		context["hidden"] = true
+	}
+	if annotation.Note != "" {
+		context["note"] = annotation.Note
+	}
+	if len(annotation.Tags) > 0 {
+		context["tags"] = annotation.Tags
+	}
+	return context
 }
 
 // generateStatistics generates statistical information about events
@@ -338,6 +339,14 @@ func (je *JSONExporter) generateStatistics(events []*config.Event) *Statistics {
 		// Count by day
 		day := event.Timestamp.Format("2006-01-02")
 		stats.EventsByDay[day]++
+
+		// Count by member
+		if event.Member != "" {
+			if stats.EventsByMember == nil {
+				stats.EventsByMember = make(map[string]int)
+			}
+			stats.EventsByMember[event.Member]++
+		}
 	}
 
 	return stats
@@ -346,24 +355,41 @@ func (je *JSONExporter) generateStatistics(events []*config.Event) *Statistics {
 // createMetadata creates export metadata
 func (je *JSONExporter) createMetadata(events []*config.Event) *ExportMetadata {
 	return &ExportMetadata{
-		Version:     "1.0",
+		Version:     SchemaVersion,
 		Generator:   "worklogr",
 		Format:      "json",
 		Compression: "none",
 	}
 }
 
-// ValidateJSON validates that the exported JSON is valid
+// ValidateJSON validates an exported json or json-ai file against its schema
+// (see Schema). The format is detected with DetectJSONFormat.
 func (je *JSONExporter) ValidateJSON(filePath string) error {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to read JSON file: %w", err)
 	}
 
-	var exportData ExportData
-	if err := json.Unmarshal(data, &exportData); err != nil {
+	var header struct {
+		Summary *struct {
+			SchemaVersion string `json:"schema_version"`
+		} `json:"summary"`
+		Metadata *struct {
+			Version string `json:"version"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
 		return fmt.Errorf("invalid JSON format: %w", err)
 	}
 
-	return nil
+	format, version := DetectJSONFormat(data), ""
+	if header.Summary != nil {
+		version = header.Summary.SchemaVersion
+	} else if header.Metadata != nil {
+		version = header.Metadata.Version
+	}
+	if err := checkSchemaVersion(format, version); err != nil {
+		return err
+	}
+	return ValidateSchema(format, data)
 }
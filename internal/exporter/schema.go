@@ -0,0 +1,387 @@
+package exporter
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaVersion is the version of the export schemas written into every json
+// (metadata.version) and json-ai (summary.schema_version) export. The major part
+// changes when a field is removed, renamed or changes type; the minor part when an
+// optional field is added. Files written before the schemas existed carry "1.0".
+const SchemaVersion = "1.1"
+
+// SchemaFormats lists the export formats that have a published schema
+var SchemaFormats = []string{"json", "json-ai", "ndjson"}
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// maxSchemaProblems caps how many violations a single validation reports
+const maxSchemaProblems = 20
+
+// SchemaValidationError lists every place where a document does not match its schema
+type SchemaValidationError struct {
+	Format   string
+	Problems []string
+}
+
+func (e *SchemaValidationError) Error() string {
+	return fmt.Sprintf("%s export does not match schema %s: %s", e.Format, SchemaVersion, strings.Join(e.Problems, "; "))
+}
+
+// Schema returns the JSON Schema (draft 2020-12) for an export format, with the
+// shared definitions inlined under $defs so the document is self-contained.
+func Schema(format string) ([]byte, error) {
+	document, err := loadSchemaDocument(format)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(document, "", "  ")
+}
+
+func loadSchemaDocument(format string) (map[string]interface{}, error) {
+	if !isSchemaFormat(format) {
+		return nil, fmt.Errorf("no schema for format %q (available: %s)", format, strings.Join(SchemaFormats, ", "))
+	}
+	var document, defs map[string]interface{}
+	if err := readSchemaFile(format+".schema.json", &document); err != nil {
+		return nil, err
+	}
+	if err := readSchemaFile("defs.json", &defs); err != nil {
+		return nil, err
+	}
+	document["$id"] = fmt.Sprintf("urn:worklogr:export:%s:%s", format, SchemaVersion)
+	document["$defs"] = defs
+	return document, nil
+}
+
+func readSchemaFile(name string, target interface{}) error {
+	data, err := schemaFiles.ReadFile("schemas/" + name)
+	if err != nil {
+		return fmt.Errorf("failed to read schema %s: %w", name, err)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("failed to parse schema %s: %w", name, err)
+	}
+	return nil
+}
+
+func isSchemaFormat(format string) bool {
+	for _, known := range SchemaFormats {
+		if known == format {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateSchema checks a single JSON document against the schema of format.
+// For ndjson the document is one line (one event).
+func ValidateSchema(format string, data []byte) error {
+	validator, err := newSchemaValidator(format)
+	if err != nil {
+		return err
+	}
+	return validator.validateDocument(data, "")
+}
+
+// ValidateNDJSON checks every line of an ndjson export against the event schema
+func ValidateNDJSON(r io.Reader) error {
+	validator, err := newSchemaValidator("ndjson")
+	if err != nil {
+		return err
+	}
+	reader := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return fmt.Errorf("failed to read ndjson: %w", readErr)
+		}
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			if err := validator.validateDocument(data, fmt.Sprintf("line %d", line)); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+	}
+}
+
+// DetectJSONFormat tells a json-ai export (which has a top-level "summary" object)
+// apart from a json export
+func DetectJSONFormat(data []byte) string {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err == nil {
+		if _, ok := top["summary"]; ok {
+			return "json-ai"
+		}
+	}
+	return "json"
+}
+
+// checkSchemaVersion rejects files written with an incompatible major schema version
+func checkSchemaVersion(format, version string) error {
+	if version == "" {
+		return nil
+	}
+	current, _, _ := strings.Cut(SchemaVersion, ".")
+	major, _, _ := strings.Cut(version, ".")
+	if major != current {
+		return fmt.Errorf("%s export was written with schema version %s, which is not compatible with %s", format, version, SchemaVersion)
+	}
+	return nil
+}
+
+// schemaNode is the subset of JSON Schema used by the export schemas
+type schemaNode struct {
+	Ref                  string                 `json:"$ref"`
+	Type                 schemaTypes            `json:"type"`
+	Properties           map[string]*schemaNode `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties *schemaNode            `json:"additionalProperties"`
+	Items                *schemaNode            `json:"items"`
+	AnyOf                []*schemaNode          `json:"anyOf"`
+	Const                interface{}            `json:"const"`
+	Enum                 []interface{}          `json:"enum"`
+	Format               string                 `json:"format"`
+	Minimum              *float64               `json:"minimum"`
+	Defs                 map[string]*schemaNode `json:"$defs"`
+
+	// forbidden is set for the boolean schema false
+	forbidden bool
+}
+
+func (n *schemaNode) UnmarshalJSON(data []byte) error {
+	var allowed bool
+	if err := json.Unmarshal(data, &allowed); err == nil {
+		n.forbidden = !allowed
+		return nil
+	}
+	type plain schemaNode
+	return json.Unmarshal(data, (*plain)(n))
+}
+
+// schemaTypes accepts both "type": "string" and "type": ["string", "null"]
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+	var several []string
+	if err := json.Unmarshal(data, &several); err != nil {
+		return err
+	}
+	*t = several
+	return nil
+}
+
+type schemaValidator struct {
+	format   string
+	root     *schemaNode
+	problems []string
+}
+
+func newSchemaValidator(format string) (*schemaValidator, error) {
+	data, err := Schema(format)
+	if err != nil {
+		return nil, err
+	}
+	root := &schemaNode{}
+	if err := json.Unmarshal(data, root); err != nil {
+		return nil, fmt.Errorf("failed to load schema %s: %w", format, err)
+	}
+	return &schemaValidator{format: format, root: root}, nil
+}
+
+func (v *schemaValidator) validateDocument(data []byte, location string) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		if location != "" {
+			return fmt.Errorf("invalid JSON format at %s: %w", location, err)
+		}
+		return fmt.Errorf("invalid JSON format: %w", err)
+	}
+
+	v.problems = nil
+	v.validate(v.root, value, "")
+	if len(v.problems) > 0 {
+		if location != "" {
+			for i, problem := range v.problems {
+				v.problems[i] = location + " " + problem
+			}
+		}
+		return &SchemaValidationError{Format: v.format, Problems: v.problems}
+	}
+	return nil
+}
+
+func (v *schemaValidator) report(path, format string, args ...interface{}) {
+	if len(v.problems) >= maxSchemaProblems {
+		return
+	}
+	if path == "" {
+		path = "/"
+	}
+	v.problems = append(v.problems, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (v *schemaValidator) resolve(node *schemaNode) *schemaNode {
+	for node.Ref != "" {
+		name := strings.TrimPrefix(node.Ref, "#/$defs/")
+		target, ok := v.root.Defs[name]
+		if !ok {
+			v.report("", "schema references unknown definition %s", node.Ref)
+			return &schemaNode{}
+		}
+		node = target
+	}
+	return node
+}
+
+func (v *schemaValidator) validate(node *schemaNode, value interface{}, path string) {
+	node = v.resolve(node)
+	if node.forbidden {
+		v.report(path, "unexpected field")
+		return
+	}
+
+	if len(node.AnyOf) > 0 {
+		matched := false
+		for _, option := range node.AnyOf {
+			if v.matches(option, value, path) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.report(path, "%s does not match any allowed shape", jsonTypeOf(value))
+		}
+		return
+	}
+
+	if len(node.Type) > 0 && !typeAllowed(node.Type, value) {
+		v.report(path, "expected %s, got %s", strings.Join(node.Type, " or "), jsonTypeOf(value))
+		return
+	}
+	if node.Const != nil && !jsonEqual(node.Const, value) {
+		v.report(path, "expected %v", node.Const)
+	}
+	if len(node.Enum) > 0 {
+		found := false
+		for _, candidate := range node.Enum {
+			if jsonEqual(candidate, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.report(path, "%v is not one of %v", value, node.Enum)
+		}
+	}
+
+	switch typed := value.(type) {
+	case string:
+		if node.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, typed); err != nil {
+				v.report(path, "%q is not an RFC 3339 date-time", typed)
+			}
+		}
+	case json.Number:
+		if node.Minimum != nil {
+			if number, err := typed.Float64(); err == nil && number < *node.Minimum {
+				v.report(path, "%s is less than %v", typed, *node.Minimum)
+			}
+		}
+	case []interface{}:
+		if node.Items != nil {
+			for i, item := range typed {
+				v.validate(node.Items, item, path+"/"+strconv.Itoa(i))
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range node.Required {
+			if _, ok := typed[name]; !ok {
+				v.report(path, "missing required field %q", name)
+			}
+		}
+		names := make([]string, 0, len(typed))
+		for name := range typed {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := path + "/" + name
+			if property, ok := node.Properties[name]; ok {
+				v.validate(property, typed[name], child)
+			} else if node.AdditionalProperties != nil {
+				v.validate(node.AdditionalProperties, typed[name], child)
+			}
+		}
+	}
+}
+
+// matches reports whether value satisfies node without recording problems
+func (v *schemaValidator) matches(node *schemaNode, value interface{}, path string) bool {
+	saved := v.problems
+	v.problems = nil
+	v.validate(node, value, path)
+	ok := len(v.problems) == 0
+	v.problems = saved
+	return ok
+}
+
+func typeAllowed(types schemaTypes, value interface{}) bool {
+	actual := jsonTypeOf(value)
+	for _, expected := range types {
+		if expected == actual || (expected == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonTypeOf(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if number, err := typed.Float64(); err == nil && number == math.Trunc(number) && !strings.ContainsAny(string(typed), ".eE") {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func jsonEqual(expected, actual interface{}) bool {
+	if number, ok := actual.(json.Number); ok {
+		if value, err := number.Float64(); err == nil {
+			actual = value
+		}
+	}
+	return fmt.Sprint(expected) == fmt.Sprint(actual)
+}
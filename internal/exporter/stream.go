@@ -0,0 +1,460 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+)
+
+// EventSource yields events one at a time so that exports do not hold every event
+// (and its attachments) in memory. database.EventIterator implements it, and
+// NewSliceSource adapts events that are already loaded.
+type EventSource interface {
+	// Outline returns every event in iteration order with at least ID, Service, Type,
+	// Member and Timestamp set. It is used for summaries written before the events.
+	Outline() []*config.Event
+	Next() bool
+	Event() *config.Event
+	Err() error
+}
+
+// sliceSource is an EventSource over an in-memory slice
+type sliceSource struct {
+	events []*config.Event
+	pos    int
+}
+
+// NewSliceSource returns an EventSource that yields the given events in order
+func NewSliceSource(events []*config.Event) EventSource {
+	return &sliceSource{events: events, pos: -1}
+}
+
+func (s *sliceSource) Outline() []*config.Event { return s.events }
+
+func (s *sliceSource) Next() bool {
+	if s.pos+1 >= len(s.events) {
+		s.pos = len(s.events)
+		return false
+	}
+	s.pos++
+	return true
+}
+
+func (s *sliceSource) Event() *config.Event {
+	if s.pos < 0 || s.pos >= len(s.events) {
+		return nil
+	}
+	return s.events[s.pos]
+}
+
+func (s *sliceSource) Err() error { return nil }
+
+// ReadAll drains a source into a slice, for formats that need every event at once
+func ReadAll(source EventSource) ([]*config.Event, error) {
+	events := make([]*config.Event, 0, len(source.Outline()))
+	for source.Next() {
+		events = append(events, source.Event())
+	}
+	if err := source.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// ReadEvents reads events back from a json, json-events or ndjson export. A json-ai
+// export cannot be read back because it drops fields such as metadata and user_id.
+func ReadEvents(r io.Reader) ([]*config.Event, error) {
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	events := []*config.Event{}
+	for value := 1; ; value++ {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err == io.EOF {
+			return events, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+
+		// json-events: a plain array of events
+		if raw[0] == '[' {
+			var list []*config.Event
+			if err := json.Unmarshal(raw, &list); err != nil {
+				return nil, fmt.Errorf("invalid events array: %w", err)
+			}
+			events = append(events, list...)
+			continue
+		}
+
+		var envelope struct {
+			Summary json.RawMessage `json:"summary"`
+			Events  json.RawMessage `json:"events"`
+		}
+		if err := json.Unmarshal(raw, &envelope); err != nil {
+			return nil, fmt.Errorf("value %d is not a JSON object or array: %w", value, err)
+		}
+		switch {
+		case envelope.Summary != nil:
+			return nil, fmt.Errorf("json-ai exports cannot be read back; use a json or ndjson export")
+		case envelope.Events != nil:
+			var list []*config.Event
+			if err := json.Unmarshal(envelope.Events, &list); err != nil {
+				return nil, fmt.Errorf("invalid events in json export: %w", err)
+			}
+			events = append(events, list...)
+		default:
+			// ndjson: one event per value
+			var event config.Event
+			if err := json.Unmarshal(raw, &event); err != nil || event.ID == "" {
+				return nil, fmt.Errorf("value %d is not an event (an object with an id)", value)
+			}
+			events = append(events, &event)
+		}
+	}
+}
+
+// WriteJSON streams the ExportData envelope to w. The output is identical to
+// marshalling ExportData with two-space indentation.
+func (je *JSONExporter) WriteJSON(w io.Writer, source EventSource) (int, error) {
+	outline := source.Outline()
+	object := newJSONObjectWriter(w)
+	object.Field("exported_at", time.Now())
+	object.Field("event_count", len(outline))
+	object.Field("time_range", je.calculateTimeRange(outline))
+	object.Field("services", je.getServicesSummary(outline))
+	object.BeginArray("events")
+	count := 0
+	for source.Next() {
+		object.Element(source.Event())
+		count++
+	}
+	object.EndArray()
+	object.Field("metadata", je.createMetadata(outline))
+	if err := source.Err(); err != nil {
+		return count, err
+	}
+	return count, object.Close()
+}
+
+// WriteEventsOnly streams the events array without the ExportData envelope. The
+// output is identical to marshalling the events with two-space indentation.
+func (je *JSONExporter) WriteEventsOnly(w io.Writer, source EventSource) (int, error) {
+	buffered := bufio.NewWriter(w)
+	buffered.WriteString("[")
+	count := 0
+	for source.Next() {
+		data, err := json.MarshalIndent(source.Event(), "  ", "  ")
+		if err != nil {
+			return count, fmt.Errorf("failed to marshal event: %w", err)
+		}
+		if count > 0 {
+			buffered.WriteString(",")
+		}
+		buffered.WriteString("\n  ")
+		if _, err := buffered.Write(data); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := source.Err(); err != nil {
+		return count, err
+	}
+	if count > 0 {
+		buffered.WriteString("\n")
+	}
+	buffered.WriteString("]\n")
+	return count, buffered.Flush()
+}
+
+// WriteNDJSON streams one compact config.Event per line (JSON Lines), including
+// attachments and annotations. `worklogr import` reads the same format back.
+func (je *JSONExporter) WriteNDJSON(w io.Writer, source EventSource) (int, error) {
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+	count := 0
+	for source.Next() {
+		if err := encoder.Encode(source.Event()); err != nil {
+			return count, fmt.Errorf("failed to write event: %w", err)
+		}
+		count++
+	}
+	if err := source.Err(); err != nil {
+		return count, err
+	}
+	return count, buffered.Flush()
+}
+
+// WriteForAI streams the json-ai structure to w, converting one event at a time
+func (je *JSONExporter) WriteForAI(w io.Writer, source EventSource) (int, error) {
+	outline := source.Outline()
+	object := newJSONObjectWriter(w)
+	object.Field("summary", je.buildAISummary(outline))
+	object.BeginArray("events")
+	count := 0
+	for source.Next() {
+		object.Element(je.convertEventForAI(source.Event()))
+		count++
+	}
+	object.EndArray()
+	object.Field("statistics", je.generateStatistics(outline))
+	if err := source.Err(); err != nil {
+		return count, err
+	}
+	return count, object.Close()
+}
+
+// WriteCSV streams events as CSV rows. The Member column is added when any event in
+// the outline was collected for a configured user, as in ExportToCSV.
+func (ce *CSVExporter) WriteCSV(w io.Writer, source EventSource) (int, error) {
+	writer := csv.NewWriter(w)
+
+	header := []string{
+		"Timestamp",
+		"Service",
+		"Type",
+		"Title",
+		"Content",
+		"UserID",
+		"Metadata",
+	}
+	// Team exports (events collected for configured users) carry the member column
+	withMember := hasMembers(source.Outline())
+	if withMember {
+		header = append(header, "Member")
+	}
+	if err := writer.Write(header); err != nil {
+		return 0, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	count := 0
+	for source.Next() {
+		event := source.Event()
+		record := []string{
+			event.Timestamp.Format(time.RFC3339),
+			event.Service,
+			event.Type,
+			event.Title,
+			event.Content,
+			event.UserID,
+			event.Metadata,
+		}
+		if withMember {
+			record = append(record, event.Member)
+		}
+		if err := writer.Write(record); err != nil {
+			return count, fmt.Errorf("failed to write CSV record: %w", err)
+		}
+		count++
+	}
+	if err := source.Err(); err != nil {
+		return count, err
+	}
+
+	writer.Flush()
+	return count, writer.Error()
+}
+
+// StreamToJSON writes the JSON export of a source to a file
+func (je *JSONExporter) StreamToJSON(source EventSource, outputPath string) error {
+	if outputPath == "" {
+		outputPath = fmt.Sprintf("worklogr_events_%s.json", time.Now().Format("20060102_150405"))
+	}
+	count, err := writeFile(outputPath, func(w io.Writer) (int, error) {
+		return je.WriteJSON(w, source)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Events exported to JSON: %s\n", outputPath)
+	fmt.Printf("Total events: %d\n", count)
+	return nil
+}
+
+// StreamEventsOnly writes the events array of a source to a file (see WriteEventsOnly)
+func (je *JSONExporter) StreamEventsOnly(source EventSource, outputPath string) error {
+	if outputPath == "" {
+		outputPath = fmt.Sprintf("worklogr_events_simple_%s.json", time.Now().Format("20060102_150405"))
+	}
+	count, err := writeFile(outputPath, func(w io.Writer) (int, error) {
+		return je.WriteEventsOnly(w, source)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Events exported to JSON (simple format): %s\n", outputPath)
+	fmt.Printf("Total events: %d\n", count)
+	return nil
+}
+
+// StreamToNDJSON writes one event per line to a file (see WriteNDJSON)
+func (je *JSONExporter) StreamToNDJSON(source EventSource, outputPath string) error {
+	if outputPath == "" {
+		outputPath = fmt.Sprintf("worklogr_events_%s.ndjson", time.Now().Format("20060102_150405"))
+	}
+	count, err := writeFile(outputPath, func(w io.Writer) (int, error) {
+		return je.WriteNDJSON(w, source)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Events exported to NDJSON: %s\n", outputPath)
+	fmt.Printf("Total events: %d\n", count)
+	return nil
+}
+
+// StreamForAI writes the json-ai export of a source to a file
+func (je *JSONExporter) StreamForAI(source EventSource, outputPath string) error {
+	if outputPath == "" {
+		outputPath = fmt.Sprintf("worklogr_ai_ready_%s.json", time.Now().Format("20060102_150405"))
+	}
+	count, err := writeFile(outputPath, func(w io.Writer) (int, error) {
+		return je.WriteForAI(w, source)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("AI-ready events exported to JSON: %s\n", outputPath)
+	fmt.Printf("Total events: %d\n", count)
+	return nil
+}
+
+// StreamToCSV writes the CSV export of a source to a file
+func (ce *CSVExporter) StreamToCSV(source EventSource, outputPath string) error {
+	if outputPath == "" {
+		outputPath = fmt.Sprintf("worklogr_events_%s.csv", time.Now().Format("20060102_150405"))
+	}
+	count, err := writeFile(outputPath, func(w io.Writer) (int, error) {
+		return ce.WriteCSV(w, source)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Events exported to CSV: %s\n", outputPath)
+	fmt.Printf("Total events: %d\n", count)
+	return nil
+}
+
+// writeFile creates outputPath (and its directory) and streams into it through a buffer
+func writeFile(outputPath string, write func(w io.Writer) (int, error)) (int, error) {
+	// Ensure directory exists
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	buffered := bufio.NewWriter(file)
+	count, err := write(buffered)
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	if err != nil {
+		return count, fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+	return count, nil
+}
+
+// jsonObjectWriter writes a JSON object field by field with the same layout as
+// json.MarshalIndent(v, "", "  "), so large arrays can be streamed element by element.
+// The first error is kept and returned by Close.
+type jsonObjectWriter struct {
+	w      *bufio.Writer
+	fields int
+	items  int
+	err    error
+}
+
+func newJSONObjectWriter(w io.Writer) *jsonObjectWriter {
+	object := &jsonObjectWriter{w: bufio.NewWriter(w)}
+	object.write("{")
+	return object
+}
+
+// Field writes a complete field
+func (o *jsonObjectWriter) Field(name string, value interface{}) {
+	o.fieldName(name)
+	o.value(value, "  ")
+}
+
+// BeginArray starts an array field whose elements are written with Element
+func (o *jsonObjectWriter) BeginArray(name string) {
+	o.fieldName(name)
+	o.write("[")
+	o.items = 0
+}
+
+// Element writes one array element
+func (o *jsonObjectWriter) Element(value interface{}) {
+	if o.items > 0 {
+		o.write(",")
+	}
+	o.write("\n    ")
+	o.value(value, "    ")
+	o.items++
+}
+
+// EndArray closes the array started by BeginArray
+func (o *jsonObjectWriter) EndArray() {
+	if o.items > 0 {
+		o.write("\n  ")
+	}
+	o.write("]")
+}
+
+// Close ends the object and flushes the output
+func (o *jsonObjectWriter) Close() error {
+	o.write("\n}\n")
+	if o.err != nil {
+		return o.err
+	}
+	return o.w.Flush()
+}
+
+func (o *jsonObjectWriter) fieldName(name string) {
+	if o.fields > 0 {
+		o.write(",")
+	}
+	o.write("\n  ")
+	o.value(name, "")
+	o.write(": ")
+	o.fields++
+}
+
+func (o *jsonObjectWriter) value(value interface{}, prefix string) {
+	if o.err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(value, prefix, "  ")
+	if err != nil {
+		o.err = fmt.Errorf("failed to marshal JSON: %w", err)
+		return
+	}
+	if _, err := o.w.Write(data); err != nil {
+		o.err = err
+	}
+}
+
+func (o *jsonObjectWriter) write(text string) {
+	if o.err != nil {
+		return
+	}
+	if _, err := o.w.WriteString(text); err != nil {
+		o.err = err
+	}
+}
@@ -0,0 +1,112 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/iriam/worklogr/internal/analysis"
+)
+
+// TimesheetExporter handles timesheet (estimated hours) export
+type TimesheetExporter struct{}
+
+// NewTimesheetExporter creates a new timesheet exporter
+func NewTimesheetExporter() *TimesheetExporter {
+	return &TimesheetExporter{}
+}
+
+// TimesheetExportData represents the JSON timesheet structure
+type TimesheetExportData struct {
+	ExportedAt  time.Time                 `json:"exported_at"`
+	Range       TimeRange                 `json:"range"`
+	TotalHours  float64                   `json:"total_hours"`
+	HoursByDay  map[string]float64        `json:"hours_by_day"`
+	Entries     []analysis.TimesheetEntry `json:"entries"`
+	Methodology string                    `json:"methodology"`
+}
+
+// ExportToCSV exports timesheet entries to a CSV file
+func (te *TimesheetExporter) ExportToCSV(entries []analysis.TimesheetEntry, outputPath string) error {
+	if outputPath == "" {
+		outputPath = fmt.Sprintf("worklogr_timesheet_%s.csv", time.Now().Format("20060102_150405"))
+	}
+
+	// Ensure directory exists
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	header := []string{"Date", "Project", "Service", "Hours", "Sessions", "Events"}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, entry := range entries {
+		record := []string{
+			entry.Date,
+			entry.Project,
+			entry.Service,
+			strconv.FormatFloat(entry.Hours, 'f', 2, 64),
+			strconv.Itoa(entry.Sessions),
+			strconv.Itoa(entry.Events),
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV record: %w", err)
+		}
+	}
+
+	fmt.Printf("Timesheet exported to CSV: %s\n", outputPath)
+	fmt.Printf("Total entries: %d\n", len(entries))
+	return nil
+}
+
+// ExportToJSON exports timesheet entries to a JSON file
+func (te *TimesheetExporter) ExportToJSON(entries []analysis.TimesheetEntry, start, end time.Time, outputPath string) error {
+	if outputPath == "" {
+		outputPath = fmt.Sprintf("worklogr_timesheet_%s.json", time.Now().Format("20060102_150405"))
+	}
+
+	// Ensure directory exists
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	data := &TimesheetExportData{
+		ExportedAt:  time.Now(),
+		Range:       TimeRange{Start: start, End: end},
+		HoursByDay:  make(map[string]float64),
+		Entries:     entries,
+		Methodology: "Events are clustered into sessions per repository/channel by timestamp gaps; meetings use duration_minutes and take precedence over overlapping sessions.",
+	}
+	for _, entry := range entries {
+		data.TotalHours += entry.Hours
+		data.HoursByDay[entry.Date] += entry.Hours
+	}
+
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal timesheet to JSON: %w", err)
+	}
+
+	if err := os.WriteFile(outputPath, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write JSON file: %w", err)
+	}
+
+	fmt.Printf("Timesheet exported to JSON: %s\n", outputPath)
+	fmt.Printf("Total entries: %d\n", len(entries))
+	return nil
+}
@@ -0,0 +1,631 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/eventmeta"
+)
+
+// XLSXExporter writes an Excel workbook with sheets for events, the daily
+// summary, per-service statistics and attachment text. Timestamps are written
+// as date cells in Location (time.Local when nil) because Excel dates carry no
+// time zone. The workbook is plain SpreadsheetML written with archive/zip.
+type XLSXExporter struct {
+	Location *time.Location
+}
+
+// NewXLSXExporter creates a new Excel exporter
+func NewXLSXExporter() *XLSXExporter {
+	return &XLSXExporter{}
+}
+
+const (
+	// xlsxMaxCellChars is Excel's limit on the length of a cell's text
+	xlsxMaxCellChars = 32767
+	// xlsxMaxURLChars is Excel's limit on the length of a hyperlink target
+	xlsxMaxURLChars = 2079
+	// xlsxMaxHyperlinks is Excel's limit on hyperlinks per worksheet
+	xlsxMaxHyperlinks = 65530
+)
+
+// Cell styles, indexes into cellXfs in xlsxStyles
+const (
+	xlsxStyleDefault = iota
+	xlsxStyleDateTime
+	xlsxStyleDate
+	xlsxStyleTime
+	xlsxStyleHeader
+	xlsxStyleLink
+	xlsxStylePercent
+)
+
+var xlsxSheetNames = []string{"Events", "Daily Summary", "Services", "Attachments"}
+
+// ExportToXLSX exports events to an Excel workbook
+func (xe *XLSXExporter) ExportToXLSX(events []*config.Event, outputPath string) error {
+	return xe.StreamToXLSX(NewSliceSource(events), outputPath)
+}
+
+// StreamToXLSX writes the Excel export of a source to a file
+func (xe *XLSXExporter) StreamToXLSX(source EventSource, outputPath string) error {
+	if outputPath == "" {
+		outputPath = fmt.Sprintf("worklogr_events_%s.xlsx", time.Now().Format("20060102_150405"))
+	}
+	count, err := writeFile(outputPath, func(w io.Writer) (int, error) {
+		return xe.WriteXLSX(w, source)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Events exported to XLSX: %s\n", outputPath)
+	fmt.Printf("Total events: %d\n", count)
+	return nil
+}
+
+// WriteXLSX streams the workbook to w. The events sheet is written one event at a
+// time; the summary sheets only need the outline. Attachment rows are written to
+// a temporary file while the events sheet is streamed and copied in at the end.
+func (xe *XLSXExporter) WriteXLSX(w io.Writer, source EventSource) (int, error) {
+	archive := zip.NewWriter(w)
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes()},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook()},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels()},
+		{"xl/styles.xml", xlsxStyles},
+	} {
+		if err := writeZipPart(archive, part.name, part.content); err != nil {
+			return 0, err
+		}
+	}
+
+	loc := xe.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	outline := localizedOutline(source.Outline(), loc)
+
+	attachments, err := xe.newAttachmentsSheet(archive)
+	if err != nil {
+		return 0, err
+	}
+	defer attachments.discard()
+
+	count, err := xe.writeEventsSheet(archive, source, attachments, hasMembers(outline), loc)
+	if err != nil {
+		return count, err
+	}
+	if err := xe.writeDailySummarySheet(archive, outline); err != nil {
+		return count, err
+	}
+	if err := xe.writeServicesSheet(archive, outline); err != nil {
+		return count, err
+	}
+	if err := attachments.close(); err != nil {
+		return count, err
+	}
+	return count, archive.Close()
+}
+
+func (xe *XLSXExporter) writeEventsSheet(archive *zip.Writer, source EventSource, attachments *xlsxSheet, withMember bool, loc *time.Location) (int, error) {
+	header := []string{"Timestamp", "Date", "Service", "Type", "Title", "Content"}
+	widths := []float64{17, 11, 10, 18, 40, 60}
+	if withMember {
+		header = append(header, "Member")
+		widths = append(widths, 14)
+	}
+	header = append(header, "UserID", "Repository", "Channel", "DurationMinutes", "URL", "ID")
+	widths = append(widths, 14, 22, 18, 10, 40, 30)
+
+	sheet, err := newXLSXSheet(archive, 1, widths)
+	if err != nil {
+		return 0, err
+	}
+	sheet.headerRow(header)
+
+	count := 0
+	for source.Next() {
+		event := source.Event()
+		common := eventmeta.CommonOf(event)
+		local := event.Timestamp.In(loc)
+
+		cells := []xlsxCell{
+			xlsxTime(local, xlsxStyleDateTime),
+			xlsxTime(local, xlsxStyleDate),
+			xlsxText(event.Service),
+			xlsxText(event.Type),
+			xlsxLink(event.Title, common.URL),
+			xlsxText(event.Content),
+		}
+		if withMember {
+			cells = append(cells, xlsxText(event.Member))
+		}
+		channel := common.Channel
+		if channel == "" {
+			channel = common.ChannelID
+		}
+		cells = append(cells,
+			xlsxText(event.UserID),
+			xlsxText(common.Repository),
+			xlsxText(channel),
+			xlsxInt(common.DurationMinutes),
+			xlsxLink(common.URL, common.URL),
+			xlsxText(event.ID),
+		)
+		sheet.row(cells)
+
+		for _, attachment := range event.Attachments {
+			attachments.row([]xlsxCell{
+				xlsxText(event.ID),
+				xlsxTime(local, xlsxStyleDateTime),
+				xlsxText(event.Service),
+				xlsxLink(event.Title, common.URL),
+				xlsxText(attachment.Title),
+				xlsxText(attachment.MimeType),
+				xlsxBool(attachment.Truncated),
+				xlsxText(attachment.TextFull),
+			})
+		}
+		count++
+	}
+	if err := source.Err(); err != nil {
+		return count, err
+	}
+	return count, sheet.close()
+}
+
+func (xe *XLSXExporter) writeDailySummarySheet(archive *zip.Writer, outline []*config.Event) error {
+	sheet, err := newXLSXSheet(archive, 2, []float64{11, 10, 11, 40, 10, 10, 24})
+	if err != nil {
+		return err
+	}
+	sheet.headerRow([]string{"Date", "Service", "EventCount", "EventTypes", "FirstEvent", "LastEvent", "Summary"})
+
+	csvExporter := NewCSVExporter()
+	daily := csvExporter.generateDailySummary(outline)
+	dates := make([]string, 0, len(daily))
+	for date := range daily {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	for _, date := range dates {
+		services := make([]string, 0, len(daily[date]))
+		for service := range daily[date] {
+			services = append(services, service)
+		}
+		sort.Strings(services)
+		for _, service := range services {
+			summary := daily[date][service]
+			sheet.row([]xlsxCell{
+				xlsxTime(summary.FirstEvent, xlsxStyleDate),
+				xlsxText(service),
+				xlsxInt(summary.EventCount),
+				xlsxText(csvExporter.joinStrings(summary.EventTypes, "; ")),
+				xlsxTime(summary.FirstEvent, xlsxStyleTime),
+				xlsxTime(summary.LastEvent, xlsxStyleTime),
+				xlsxText(summary.Summary),
+			})
+		}
+	}
+	return sheet.close()
+}
+
+// xlsxServiceStats aggregates the outline of one service
+type xlsxServiceStats struct {
+	count  int
+	types  map[string]int
+	days   map[string]bool
+	first  time.Time
+	last   time.Time
+	member map[string]bool
+}
+
+func (xe *XLSXExporter) writeServicesSheet(archive *zip.Writer, outline []*config.Event) error {
+	sheet, err := newXLSXSheet(archive, 3, []float64{12, 11, 10, 11, 10, 17, 17, 60})
+	if err != nil {
+		return err
+	}
+	sheet.headerRow([]string{"Service", "EventCount", "Percentage", "ActiveDays", "Members", "FirstEvent", "LastEvent", "EventTypes"})
+
+	stats := make(map[string]*xlsxServiceStats)
+	for _, event := range outline {
+		service := stats[event.Service]
+		if service == nil {
+			service = &xlsxServiceStats{
+				types: map[string]int{}, days: map[string]bool{}, member: map[string]bool{},
+				first: event.Timestamp, last: event.Timestamp,
+			}
+			stats[event.Service] = service
+		}
+		service.count++
+		service.types[event.Type]++
+		service.days[event.Timestamp.Format("2006-01-02")] = true
+		if event.Member != "" {
+			service.member[event.Member] = true
+		}
+		if event.Timestamp.Before(service.first) {
+			service.first = event.Timestamp
+		}
+		if event.Timestamp.After(service.last) {
+			service.last = event.Timestamp
+		}
+	}
+
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		service := stats[name]
+		types := make([]string, 0, len(service.types))
+		for eventType := range service.types {
+			types = append(types, eventType)
+		}
+		sort.Slice(types, func(i, j int) bool {
+			if service.types[types[i]] != service.types[types[j]] {
+				return service.types[types[i]] > service.types[types[j]]
+			}
+			return types[i] < types[j]
+		})
+		for i, eventType := range types {
+			types[i] = fmt.Sprintf("%s (%d)", eventType, service.types[eventType])
+		}
+
+		sheet.row([]xlsxCell{
+			xlsxText(name),
+			xlsxInt(service.count),
+			xlsxFloat(float64(service.count)/float64(len(outline)), xlsxStylePercent),
+			xlsxInt(len(service.days)),
+			xlsxInt(len(service.member)),
+			xlsxTime(service.first, xlsxStyleDateTime),
+			xlsxTime(service.last, xlsxStyleDateTime),
+			xlsxText(strings.Join(types, "; ")),
+		})
+	}
+	return sheet.close()
+}
+
+// newAttachmentsSheet starts the attachments sheet in a temporary file, so that
+// its rows can be written while the events sheet is being streamed
+func (xe *XLSXExporter) newAttachmentsSheet(archive *zip.Writer) (*xlsxSheet, error) {
+	spool, err := os.CreateTemp("", "worklogr-xlsx-*.xml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary worksheet: %w", err)
+	}
+	buffer := bufio.NewWriter(spool)
+	sheet := &xlsxSheet{archive: archive, index: 4, w: buffer, spool: spool, buffer: buffer}
+	sheet.start([]float64{30, 17, 10, 30, 30, 24, 10, 80})
+	sheet.headerRow([]string{"EventID", "EventTimestamp", "Service", "EventTitle", "AttachmentTitle", "MimeType", "Truncated", "Text"})
+	return sheet, nil
+}
+
+// localizedOutline copies the outline with timestamps in loc, so that day
+// boundaries in the summaries match the dates shown in the events sheet
+func localizedOutline(outline []*config.Event, loc *time.Location) []*config.Event {
+	localized := make([]*config.Event, len(outline))
+	for i, event := range outline {
+		copied := *event
+		copied.Timestamp = event.Timestamp.In(loc)
+		localized[i] = &copied
+	}
+	return localized
+}
+
+// xlsxCell is a single cell value; empty cells are not written
+type xlsxCell struct {
+	kind  byte // 's' inline string, 'n' number, 'b' boolean
+	text  string
+	style int
+	url   string
+}
+
+func xlsxText(value string) xlsxCell { return xlsxCell{kind: 's', text: value} }
+
+func xlsxInt(value int) xlsxCell {
+	if value == 0 {
+		return xlsxCell{}
+	}
+	return xlsxCell{kind: 'n', text: strconv.Itoa(value)}
+}
+
+func xlsxFloat(value float64, style int) xlsxCell {
+	return xlsxCell{kind: 'n', text: strconv.FormatFloat(value, 'f', -1, 64), style: style}
+}
+
+func xlsxBool(value bool) xlsxCell {
+	if !value {
+		return xlsxCell{}
+	}
+	return xlsxCell{kind: 'b', text: "1"}
+}
+
+// xlsxTime writes t as an Excel date serial (days since 1899-12-30) of its wall clock
+func xlsxTime(t time.Time, style int) xlsxCell {
+	if t.IsZero() {
+		return xlsxCell{}
+	}
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	serial := wall.Sub(time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)).Seconds() / 86400
+	return xlsxFloat(serial, style)
+}
+
+// xlsxLink is a text cell that links to url when url is a usable web link
+func xlsxLink(text, url string) xlsxCell {
+	cell := xlsxText(text)
+	if text != "" && (strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://")) && len(url) <= xlsxMaxURLChars {
+		cell.url = url
+		cell.style = xlsxStyleLink
+	}
+	return cell
+}
+
+// xlsxSheet streams one worksheet into the archive, or into a temporary file
+// (spool) that close copies into the archive. The first error is kept and
+// returned by close.
+type xlsxSheet struct {
+	archive *zip.Writer
+	index   int
+	w       io.Writer
+	spool   *os.File
+	buffer  *bufio.Writer
+	rows    int
+	links   []string
+	refs    []string
+	err     error
+}
+
+func newXLSXSheet(archive *zip.Writer, index int, widths []float64) (*xlsxSheet, error) {
+	w, err := createZipPart(archive, xlsxSheetPath(index))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create worksheet: %w", err)
+	}
+	sheet := &xlsxSheet{archive: archive, index: index, w: w}
+	sheet.start(widths)
+	return sheet, nil
+}
+
+func xlsxSheetPath(index int) string {
+	return fmt.Sprintf("xl/worksheets/sheet%d.xml", index)
+}
+
+// start writes the worksheet preamble and column widths
+func (s *xlsxSheet) start(widths []float64) {
+	s.write(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`)
+	// Keep the header row visible while scrolling
+	s.write(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	s.write(`<cols>`)
+	for i, width := range widths {
+		s.write(fmt.Sprintf(`<col min="%d" max="%d" width="%g" customWidth="1"/>`, i+1, i+1, width))
+	}
+	s.write(`</cols><sheetData>`)
+}
+
+func (s *xlsxSheet) write(text string) {
+	if s.err != nil {
+		return
+	}
+	_, s.err = io.WriteString(s.w, text)
+}
+
+func (s *xlsxSheet) headerRow(names []string) {
+	cells := make([]xlsxCell, len(names))
+	for i, name := range names {
+		cells[i] = xlsxCell{kind: 's', text: name, style: xlsxStyleHeader}
+	}
+	s.row(cells)
+}
+
+func (s *xlsxSheet) row(cells []xlsxCell) {
+	s.rows++
+	var b strings.Builder
+	fmt.Fprintf(&b, `<row r="%d">`, s.rows)
+	for i, cell := range cells {
+		if cell.kind == 0 || (cell.kind == 's' && cell.text == "") {
+			continue
+		}
+		ref := xlsxColumnName(i) + strconv.Itoa(s.rows)
+		style := ""
+		if cell.style != xlsxStyleDefault {
+			style = fmt.Sprintf(` s="%d"`, cell.style)
+		}
+		switch cell.kind {
+		case 's':
+			fmt.Fprintf(&b, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">`, ref, style)
+			xml.EscapeText(&b, []byte(truncateCellText(cell.text)))
+			b.WriteString(`</t></is></c>`)
+		case 'b':
+			fmt.Fprintf(&b, `<c r="%s"%s t="b"><v>%s</v></c>`, ref, style, cell.text)
+		default:
+			fmt.Fprintf(&b, `<c r="%s"%s><v>%s</v></c>`, ref, style, cell.text)
+		}
+		if cell.url != "" && len(s.links) < xlsxMaxHyperlinks {
+			s.links = append(s.links, cell.url)
+			s.refs = append(s.refs, ref)
+		}
+	}
+	b.WriteString(`</row>`)
+	s.write(b.String())
+}
+
+// close finishes the worksheet and writes its hyperlink relationships
+func (s *xlsxSheet) close() error {
+	s.write(`</sheetData>`)
+	if len(s.links) > 0 {
+		s.write(`<hyperlinks>`)
+		for i, ref := range s.refs {
+			s.write(fmt.Sprintf(`<hyperlink ref="%s" r:id="rId%d"/>`, ref, i+1))
+		}
+		s.write(`</hyperlinks>`)
+	}
+	s.write(`</worksheet>`)
+	if s.err != nil {
+		return fmt.Errorf("failed to write worksheet: %w", s.err)
+	}
+	if s.spool != nil {
+		if err := s.copySpool(); err != nil {
+			return err
+		}
+	}
+	if len(s.links) == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+	b.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i, link := range s.links {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="`, i+1)
+		xml.EscapeText(&b, []byte(link))
+		b.WriteString(`" TargetMode="External"/>`)
+	}
+	b.WriteString(`</Relationships>`)
+	return writeZipPart(s.archive, fmt.Sprintf("xl/worksheets/_rels/sheet%d.xml.rels", s.index), b.String())
+}
+
+// copySpool copies a worksheet written to a temporary file into the archive
+func (s *xlsxSheet) copySpool() error {
+	if err := s.buffer.Flush(); err != nil {
+		return fmt.Errorf("failed to write temporary worksheet: %w", err)
+	}
+	if _, err := s.spool.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read temporary worksheet: %w", err)
+	}
+	w, err := createZipPart(s.archive, xlsxSheetPath(s.index))
+	if err != nil {
+		return fmt.Errorf("failed to create worksheet: %w", err)
+	}
+	if _, err := io.Copy(w, s.spool); err != nil {
+		return fmt.Errorf("failed to write worksheet: %w", err)
+	}
+	return nil
+}
+
+// discard removes the temporary file of a spooled worksheet
+func (s *xlsxSheet) discard() {
+	if s.spool == nil {
+		return
+	}
+	s.spool.Close()
+	os.Remove(s.spool.Name())
+}
+
+// truncateCellText keeps text within Excel's cell limit, which counts UTF-16 code units
+func truncateCellText(text string) string {
+	if len(text) <= xlsxMaxCellChars {
+		return text
+	}
+	units := 0
+	for i, r := range text {
+		units++
+		if r >= 0x10000 {
+			units++ // surrogate pair
+		}
+		if units > xlsxMaxCellChars-1 {
+			return text[:i] + "…"
+		}
+	}
+	return text
+}
+
+// xlsxColumnName converts a zero-based column index to A, B, ..., Z, AA, ...
+func xlsxColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// createZipPart adds a compressed entry stamped with the current time
+func createZipPart(archive *zip.Writer, name string) (io.Writer, error) {
+	return archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+}
+
+func writeZipPart(archive *zip.Writer, name, content string) error {
+	w, err := createZipPart(archive, name)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", name, err)
+	}
+	if _, err := io.WriteString(w, content); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+func xlsxContentTypes() string {
+	var b strings.Builder
+	b.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := range xlsxSheetNames {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	b.WriteString(`</Types>`)
+	return b.String()
+}
+
+const xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+func xlsxWorkbook() string {
+	var b strings.Builder
+	b.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, name := range xlsxSheetNames {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, name, i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	return b.String()
+}
+
+func xlsxWorkbookRels() string {
+	var b strings.Builder
+	b.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := range xlsxSheetNames {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(xlsxSheetNames)+1)
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+// xlsxStyles defines the cellXfs referenced by the xlsxStyle* constants, in order
+const xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="3">` +
+	`<numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm"/>` +
+	`<numFmt numFmtId="165" formatCode="yyyy-mm-dd"/>` +
+	`<numFmt numFmtId="166" formatCode="hh:mm"/>` +
+	`</numFmts>` +
+	`<fonts count="3">` +
+	`<font><sz val="11"/><name val="Calibri"/><family val="2"/></font>` +
+	`<font><b/><sz val="11"/><name val="Calibri"/><family val="2"/></font>` +
+	`<font><u/><sz val="11"/><color rgb="FF0563C1"/><name val="Calibri"/><family val="2"/></font>` +
+	`</fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="7">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="166" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="0" fontId="2" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="10" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
@@ -0,0 +1,273 @@
+// Package linker detects references between collected events across services.
+package linker
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/eventmeta"
+)
+
+// Relation types stored in event_links.
+const (
+	// RelationReferences はソースイベントがターゲットイベント自体（PR/Issue/コミット）に言及していることを表します
+	RelationReferences = "references"
+	// RelationSameItem は両イベントが同一のPR/Issueに関するイベントであることを表します
+	RelationSameItem = "same_item"
+	// RelationSharedKey は両イベントが同じIssueキー（例: PROJ-123）に言及していることを表します
+	RelationSharedKey = "shared_key"
+)
+
+// maxEventsPerKey は1つのキーに紐づくイベント数の上限です（組み合わせ爆発を防ぐため）
+const maxEventsPerKey = 50
+
+var (
+	githubURLPattern = regexp.MustCompile(`https?://github\.com/([\w.-]+)/([\w.-]+)/(?:pull|issues)/(\d+)`)
+	githubRefPattern = regexp.MustCompile(`(?:^|[\s(\[])([\w.-]+/[\w.-]+)#(\d+)\b`)
+	issueKeyPattern  = regexp.MustCompile(`\b([A-Z][A-Z0-9]{1,9})-(\d+)\b`)
+	shaPattern       = regexp.MustCompile(`\b[0-9a-f]{7,40}\b`)
+)
+
+// issueKeyDenylist はIssueキーと誤検出しやすい一般的な表記です
+var issueKeyDenylist = map[string]bool{
+	"UTF": true, "ISO": true, "SHA": true, "RFC": true, "CVE": true, "HTTP": true, "TLS": true,
+}
+
+// Link は検出された2イベント間の関連です
+type Link struct {
+	SourceID string
+	TargetID string
+	Relation string
+	Key      string
+}
+
+// Linker はイベント間の参照を検出します
+type Linker struct {
+	issueKeyPrefixes map[string]bool
+}
+
+// NewLinker は新しいリンカーを作成します
+func NewLinker(options config.LinkingOptions) *Linker {
+	prefixes := make(map[string]bool, len(options.IssueKeyPrefixes))
+	for _, prefix := range options.IssueKeyPrefixes {
+		prefix = strings.ToUpper(strings.TrimSpace(prefix))
+		if prefix != "" {
+			prefixes[prefix] = true
+		}
+	}
+	return &Linker{issueKeyPrefixes: prefixes}
+}
+
+// eventKeys はイベントが「自身である」キーと「言及している」キーを保持します
+type eventKeys struct {
+	owns      map[string]bool
+	mentions  map[string]bool
+	shas      []string
+	commitSHA string
+}
+
+// FindLinks はイベント集合から関連を検出します。結果は決定的な順序で返されます。
+func (l *Linker) FindLinks(events []*config.Event) []Link {
+	keysByEvent := make(map[string]*eventKeys, len(events))
+	owners := make(map[string][]string)
+	mentioners := make(map[string][]string)
+	commitsBySHA := make(map[string]string)
+
+	for _, event := range events {
+		if event == nil || event.ID == "" {
+			continue
+		}
+		keys := l.extractKeys(event)
+		keysByEvent[event.ID] = keys
+		for key := range keys.owns {
+			owners[key] = append(owners[key], event.ID)
+		}
+		if keys.commitSHA != "" {
+			commitsBySHA[keys.commitSHA] = event.ID
+		}
+	}
+
+	for _, event := range events {
+		if event == nil || event.ID == "" {
+			continue
+		}
+		keys := keysByEvent[event.ID]
+		for _, candidate := range keys.shas {
+			for sha, commitID := range commitsBySHA {
+				if commitID != event.ID && strings.HasPrefix(sha, candidate) {
+					keys.mentions[commitKey(sha)] = true
+				}
+			}
+		}
+		for key := range keys.mentions {
+			mentioners[key] = append(mentioners[key], event.ID)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var links []Link
+	add := func(link Link) {
+		if link.SourceID == link.TargetID {
+			return
+		}
+		id := link.SourceID + "|" + link.TargetID + "|" + link.Relation + "|" + link.Key
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		links = append(links, link)
+	}
+
+	for key, ownerIDs := range owners {
+		ownerIDs = capIDs(ownerIDs)
+		for i := 0; i < len(ownerIDs); i++ {
+			for j := i + 1; j < len(ownerIDs); j++ {
+				source, target := orderedPair(ownerIDs[i], ownerIDs[j])
+				add(Link{SourceID: source, TargetID: target, Relation: RelationSameItem, Key: key})
+			}
+		}
+		for _, mentionerID := range capIDs(mentioners[key]) {
+			if keysByEvent[mentionerID].owns[key] {
+				continue
+			}
+			for _, ownerID := range ownerIDs {
+				add(Link{SourceID: mentionerID, TargetID: ownerID, Relation: RelationReferences, Key: key})
+			}
+		}
+	}
+
+	for key, mentionerIDs := range mentioners {
+		if len(owners[key]) > 0 {
+			continue
+		}
+		mentionerIDs = capIDs(mentionerIDs)
+		for i := 0; i < len(mentionerIDs); i++ {
+			for j := i + 1; j < len(mentionerIDs); j++ {
+				source, target := orderedPair(mentionerIDs[i], mentionerIDs[j])
+				add(Link{SourceID: source, TargetID: target, Relation: RelationSharedKey, Key: key})
+			}
+		}
+	}
+
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].SourceID != links[j].SourceID {
+			return links[i].SourceID < links[j].SourceID
+		}
+		if links[i].TargetID != links[j].TargetID {
+			return links[i].TargetID < links[j].TargetID
+		}
+		return links[i].Key < links[j].Key
+	})
+
+	return links
+}
+
+// extractKeys はイベントのタイトル・本文・メタデータ・添付本文からキーを抽出します
+func (l *Linker) extractKeys(event *config.Event) *eventKeys {
+	keys := &eventKeys{
+		owns:     make(map[string]bool),
+		mentions: make(map[string]bool),
+	}
+
+	var texts []string
+	texts = append(texts, event.Title, event.Content, eventmeta.CommonOf(event).URL)
+
+	metadata, _ := eventmeta.Decode(event)
+	own := func(repository string, number int) {
+		if repository != "" && number > 0 {
+			keys.owns[githubKey(repository, number)] = true
+		}
+	}
+	switch metadata := metadata.(type) {
+	case *eventmeta.GitHubPullRequest:
+		own(metadata.Repository, metadata.Number)
+		texts = append(texts, metadata.Head)
+	case *eventmeta.GitHubIssue:
+		own(metadata.Repository, metadata.Number)
+	case *eventmeta.GitHubReview:
+		own(metadata.Repository, metadata.PRNumber)
+		texts = append(texts, metadata.PRURL)
+	case *eventmeta.GitHubCommit:
+		if len(metadata.SHA) >= 12 {
+			keys.commitSHA = strings.ToLower(metadata.SHA)
+			keys.owns[commitKey(keys.commitSHA)] = true
+		}
+	case *eventmeta.CalendarEvent:
+		texts = append(texts, metadata.Description)
+	}
+	for _, attachment := range event.Attachments {
+		texts = append(texts, attachment.Title, attachment.TextFull)
+	}
+
+	for _, text := range texts {
+		if text == "" {
+			continue
+		}
+		for _, match := range githubURLPattern.FindAllStringSubmatch(text, -1) {
+			keys.mentions[githubKey(match[1]+"/"+match[2], atoi(match[3]))] = true
+		}
+		for _, match := range githubRefPattern.FindAllStringSubmatch(text, -1) {
+			keys.mentions[githubKey(match[1], atoi(match[2]))] = true
+		}
+		for _, match := range issueKeyPattern.FindAllStringSubmatch(text, -1) {
+			if l.acceptsIssuePrefix(match[1]) {
+				keys.mentions["issue:"+match[1]+"-"+match[2]] = true
+			}
+		}
+		if event.Type != "commit" {
+			for _, candidate := range shaPattern.FindAllString(text, -1) {
+				// 数字のみの並び（電話番号・タイムスタンプ等）はSHAとみなさない
+				if strings.ContainsAny(candidate, "abcdef") {
+					keys.shas = append(keys.shas, candidate)
+				}
+			}
+		}
+	}
+
+	// 自分自身のURL（metadata.url 等）は言及扱いにしない
+	for key := range keys.owns {
+		delete(keys.mentions, key)
+	}
+
+	return keys
+}
+
+func (l *Linker) acceptsIssuePrefix(prefix string) bool {
+	if len(l.issueKeyPrefixes) > 0 {
+		return l.issueKeyPrefixes[prefix]
+	}
+	return !issueKeyDenylist[prefix]
+}
+
+func githubKey(repository string, number int) string {
+	return fmt.Sprintf("github:%s#%d", strings.ToLower(repository), number)
+}
+
+func commitKey(sha string) string {
+	return "commit:" + sha[:12]
+}
+
+func atoi(s string) int {
+	n := 0
+	for _, r := range s {
+		n = n*10 + int(r-'0')
+	}
+	return n
+}
+
+func orderedPair(a, b string) (string, string) {
+	if a < b {
+		return a, b
+	}
+	return b, a
+}
+
+func capIDs(ids []string) []string {
+	if len(ids) > maxEventsPerKey {
+		return ids[:maxEventsPerKey]
+	}
+	return ids
+}
@@ -0,0 +1,97 @@
+package linker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iriam/worklogr/internal/config"
+)
+
+func hasLink(links []Link, want Link) bool {
+	for _, link := range links {
+		if link == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestFindLinksDetectsCrossServiceReferences(t *testing.T) {
+	base := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	events := []*config.Event{
+		{
+			ID:        "pr",
+			Service:   "github",
+			Type:      "pull_request_created",
+			Title:     "Created PR #42 in acme/api",
+			Content:   "PROJ-7 add retry",
+			Timestamp: base,
+			Metadata:  `{"repository":"acme/api","number":42,"url":"https://github.com/acme/api/pull/42"}`,
+		},
+		{
+			ID:        "review",
+			Service:   "github",
+			Type:      "pull_request_review",
+			Title:     "Reviewed PR #42 in acme/api",
+			Timestamp: base.Add(time.Hour),
+			Metadata:  `{"repository":"acme/api","pr_number":42}`,
+		},
+		{
+			ID:        "slack",
+			Service:   "slack",
+			Type:      "message",
+			Content:   "please review https://github.com/acme/api/pull/42 (fixes abc1234def)",
+			Timestamp: base.Add(2 * time.Hour),
+		},
+		{
+			ID:        "commit",
+			Service:   "github",
+			Type:      "commit",
+			Content:   "PROJ-7: retry on 429",
+			Timestamp: base.Add(3 * time.Hour),
+			Metadata:  `{"repository":"acme/api","sha":"abc1234def567890"}`,
+		},
+		{
+			ID:        "meeting",
+			Service:   "google_calendar",
+			Type:      "event_attended",
+			Content:   "Sync",
+			Timestamp: base.Add(4 * time.Hour),
+			Attachments: []config.EventAttachment{
+				{FileID: "doc", TextFull: "We discussed acme/api#42 and UTF-8 handling"},
+			},
+		},
+	}
+
+	links := NewLinker(config.LinkingOptions{}).FindLinks(events)
+
+	for _, want := range []Link{
+		{SourceID: "pr", TargetID: "review", Relation: RelationSameItem, Key: "github:acme/api#42"},
+		{SourceID: "slack", TargetID: "pr", Relation: RelationReferences, Key: "github:acme/api#42"},
+		{SourceID: "meeting", TargetID: "pr", Relation: RelationReferences, Key: "github:acme/api#42"},
+		{SourceID: "slack", TargetID: "commit", Relation: RelationReferences, Key: "commit:abc1234def56"},
+		{SourceID: "commit", TargetID: "pr", Relation: RelationSharedKey, Key: "issue:PROJ-7"},
+	} {
+		if !hasLink(links, want) {
+			t.Fatalf("expected link %+v, got %+v", want, links)
+		}
+	}
+
+	for _, link := range links {
+		if link.Key == "issue:UTF-8" {
+			t.Fatalf("expected UTF-8 not to be treated as an issue key: %+v", link)
+		}
+	}
+}
+
+func TestFindLinksHonoursIssueKeyPrefixes(t *testing.T) {
+	events := []*config.Event{
+		{ID: "a", Service: "slack", Content: "OPS-1 and PROJ-2"},
+		{ID: "b", Service: "github", Content: "OPS-1 and PROJ-2"},
+	}
+
+	links := NewLinker(config.LinkingOptions{IssueKeyPrefixes: []string{"proj"}}).FindLinks(events)
+	if len(links) != 1 || links[0].Key != "issue:PROJ-2" {
+		t.Fatalf("expected only PROJ-2 link, got %+v", links)
+	}
+}
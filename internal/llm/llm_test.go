@@ -0,0 +1,130 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/iriam/worklogr/internal/config"
+)
+
+func TestOpenAIProviderGenerate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Fatalf("unexpected authorization header: %q", got)
+		}
+		var body openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if body.Model != "gpt-test" || len(body.Messages) != 2 || body.Messages[0].Role != "system" || body.Messages[1].Content != "hello" {
+			t.Fatalf("unexpected request body: %+v", body)
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"summary"}}]}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(config.LLMConfig{Provider: "openai", BaseURL: server.URL + "/v1/", Model: "gpt-test", APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("NewProvider returned error: %v", err)
+	}
+
+	text, err := provider.Generate(context.Background(), Request{System: "sys", Prompt: "hello"})
+	if err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+	if text != "summary" {
+		t.Fatalf("unexpected text: %q", text)
+	}
+}
+
+func TestGeminiProviderGenerate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1beta/models/gemini-test:generateContent" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.Header.Get("x-goog-api-key"); got != "gemini-key" {
+			t.Fatalf("unexpected api key header: %q", got)
+		}
+		var body geminiRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if body.SystemInstruction == nil || body.Contents[0].Parts[0].Text != "hello" {
+			t.Fatalf("unexpected request body: %+v", body)
+		}
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"part1 "},{"text":"part2"}]}}]}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(config.LLMConfig{Provider: "gemini", BaseURL: server.URL, Model: "gemini-test", APIKey: "gemini-key"})
+	if err != nil {
+		t.Fatalf("NewProvider returned error: %v", err)
+	}
+
+	text, err := provider.Generate(context.Background(), Request{System: "sys", Prompt: "hello"})
+	if err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+	if text != "part1 part2" {
+		t.Fatalf("unexpected text: %q", text)
+	}
+}
+
+func TestOllamaProviderGenerateReportsHTTPErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		http.Error(w, "model not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(config.LLMConfig{Provider: "ollama", BaseURL: server.URL, Model: "llama3"})
+	if err != nil {
+		t.Fatalf("NewProvider returned error: %v", err)
+	}
+
+	if _, err := provider.Generate(context.Background(), Request{Prompt: "hello"}); err == nil {
+		t.Fatalf("expected error for non-2xx response")
+	}
+}
+
+func TestNewProviderValidatesConfig(t *testing.T) {
+	t.Setenv("WORKLOGR_TEST_LLM_KEY", "")
+	for _, cfg := range []config.LLMConfig{
+		{Provider: "unknown", Model: "x"},
+		{Provider: "openai"},
+		{Provider: "gemini", APIKeyEnv: "WORKLOGR_TEST_LLM_KEY"},
+	} {
+		if _, err := NewProvider(cfg); err == nil {
+			t.Fatalf("expected NewProvider to reject %+v", cfg)
+		}
+	}
+}
+
+func TestTruncateKeepsRunesWhole(t *testing.T) {
+	tests := []struct {
+		text string
+		max  int
+		want string
+	}{
+		{text: "short", max: 10, want: "short"},
+		{text: "abcdef", max: 3, want: "abc..."},
+		// "エラー" is 3 bytes per rune; cutting at 4 bytes keeps only the first rune
+		{text: "エラー", max: 4, want: "エ..."},
+		{text: "エラー", max: 6, want: "エラ..."},
+	}
+	for _, tt := range tests {
+		got := truncate(tt.text, tt.max)
+		if got != tt.want || !utf8.ValidString(got) {
+			t.Fatalf("truncate(%q, %d) = %q, want %q", tt.text, tt.max, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Package llm provides minimal clients for LLM chat/completion endpoints.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/iriam/worklogr/internal/config"
+)
+
+// Provider names accepted in config.LLMConfig.Provider.
+const (
+	ProviderOpenAI = "openai"
+	ProviderGemini = "gemini"
+	ProviderOllama = "ollama"
+)
+
+// Request は1回の生成リクエストです
+type Request struct {
+	System string
+	Prompt string
+}
+
+// Provider はテキスト生成を行うLLMエンドポイントです
+type Provider interface {
+	Name() string
+	Generate(ctx context.Context, request Request) (string, error)
+}
+
+// NewProvider は設定からプロバイダーを作成します
+func NewProvider(cfg config.LLMConfig) (Provider, error) {
+	client := &http.Client{Timeout: cfg.EffectiveTimeout()}
+	apiKey := cfg.ResolveAPIKey()
+
+	switch strings.ToLower(cfg.Provider) {
+	case ProviderOpenAI, "":
+		if cfg.Model == "" {
+			return nil, fmt.Errorf("llm.model is required for provider %s", ProviderOpenAI)
+		}
+		return &OpenAIProvider{
+			BaseURL:    defaultString(cfg.BaseURL, "https://api.openai.com/v1"),
+			Model:      cfg.Model,
+			APIKey:     apiKey,
+			HTTPClient: client,
+		}, nil
+	case ProviderGemini:
+		if apiKey == "" {
+			return nil, fmt.Errorf("llm.api_key (or api_key_env) is required for provider %s", ProviderGemini)
+		}
+		return &GeminiProvider{
+			BaseURL:    defaultString(cfg.BaseURL, "https://generativelanguage.googleapis.com"),
+			Model:      defaultString(cfg.Model, "gemini-1.5-flash"),
+			APIKey:     apiKey,
+			HTTPClient: client,
+		}, nil
+	case ProviderOllama:
+		if cfg.Model == "" {
+			return nil, fmt.Errorf("llm.model is required for provider %s", ProviderOllama)
+		}
+		return &OllamaProvider{
+			BaseURL:    defaultString(cfg.BaseURL, "http://localhost:11434"),
+			Model:      cfg.Model,
+			HTTPClient: client,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported llm provider: %s (supported: openai, gemini, ollama)", cfg.Provider)
+	}
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return strings.TrimRight(value, "/")
+}
+
+// postJSON はJSONリクエストを送信し、レスポンスをoutにデコードします
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, truncate(string(data), 500))
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func truncate(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	// Step back to a rune boundary so that multi-byte characters are not split
+	for max > 0 && !utf8.RuneStart(text[max]) {
+		max--
+	}
+	return text[:max] + "..."
+}
@@ -0,0 +1,146 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// OpenAIProvider はOpenAI互換の /chat/completions エンドポイントを使用します
+type OpenAIProvider struct {
+	BaseURL    string
+	Model      string
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+func (p *OpenAIProvider) Name() string { return ProviderOpenAI }
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	Model    string          `json:"model"`
+	Messages []openAIMessage `json:"messages"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+}
+
+func (p *OpenAIProvider) Generate(ctx context.Context, request Request) (string, error) {
+	var messages []openAIMessage
+	if request.System != "" {
+		messages = append(messages, openAIMessage{Role: "system", Content: request.System})
+	}
+	messages = append(messages, openAIMessage{Role: "user", Content: request.Prompt})
+
+	headers := map[string]string{}
+	if p.APIKey != "" {
+		headers["Authorization"] = "Bearer " + p.APIKey
+	}
+
+	var response openAIResponse
+	if err := postJSON(ctx, p.HTTPClient, p.BaseURL+"/chat/completions", headers, openAIRequest{Model: p.Model, Messages: messages}, &response); err != nil {
+		return "", fmt.Errorf("openai: %w", err)
+	}
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("openai: response contained no choices")
+	}
+	return response.Choices[0].Message.Content, nil
+}
+
+// GeminiProvider はGemini API の generateContent エンドポイントを使用します
+type GeminiProvider struct {
+	BaseURL    string
+	Model      string
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+func (p *GeminiProvider) Name() string { return ProviderGemini }
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiRequest struct {
+	SystemInstruction *geminiContent  `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent `json:"contents"`
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content geminiContent `json:"content"`
+	} `json:"candidates"`
+}
+
+func (p *GeminiProvider) Generate(ctx context.Context, request Request) (string, error) {
+	body := geminiRequest{
+		Contents: []geminiContent{{Role: "user", Parts: []geminiPart{{Text: request.Prompt}}}},
+	}
+	if request.System != "" {
+		body.SystemInstruction = &geminiContent{Parts: []geminiPart{{Text: request.System}}}
+	}
+
+	endpoint := fmt.Sprintf("%s/v1beta/models/%s:generateContent", p.BaseURL, url.PathEscape(p.Model))
+	headers := map[string]string{"x-goog-api-key": p.APIKey}
+
+	var response geminiResponse
+	if err := postJSON(ctx, p.HTTPClient, endpoint, headers, body, &response); err != nil {
+		return "", fmt.Errorf("gemini: %w", err)
+	}
+	if len(response.Candidates) == 0 {
+		return "", fmt.Errorf("gemini: response contained no candidates")
+	}
+
+	var builder strings.Builder
+	for _, part := range response.Candidates[0].Content.Parts {
+		builder.WriteString(part.Text)
+	}
+	return builder.String(), nil
+}
+
+// OllamaProvider はローカルのOllama /api/chat エンドポイントを使用します
+type OllamaProvider struct {
+	BaseURL    string
+	Model      string
+	HTTPClient *http.Client
+}
+
+func (p *OllamaProvider) Name() string { return ProviderOllama }
+
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []openAIMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+}
+
+type ollamaResponse struct {
+	Message openAIMessage `json:"message"`
+}
+
+func (p *OllamaProvider) Generate(ctx context.Context, request Request) (string, error) {
+	var messages []openAIMessage
+	if request.System != "" {
+		messages = append(messages, openAIMessage{Role: "system", Content: request.System})
+	}
+	messages = append(messages, openAIMessage{Role: "user", Content: request.Prompt})
+
+	var response ollamaResponse
+	if err := postJSON(ctx, p.HTTPClient, p.BaseURL+"/api/chat", nil, ollamaRequest{Model: p.Model, Messages: messages}, &response); err != nil {
+		return "", fmt.Errorf("ollama: %w", err)
+	}
+	return response.Message.Content, nil
+}
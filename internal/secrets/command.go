@@ -0,0 +1,69 @@
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// CommandStore は外部コマンド（pass, op read など）の標準出力からシークレットを読み取ります。
+// コマンド中の {name} はシークレット名に置換されます（例: "pass show worklogr/{name}"）。
+type CommandStore struct {
+	command string
+	// run はコマンドを実行して標準出力を返します（テストで差し替え可能）
+	run func(name string, args ...string) ([]byte, error)
+}
+
+// NewCommandStore は新しいコマンドストアを作成します
+func NewCommandStore(command string) *CommandStore {
+	return &CommandStore{command: command, run: runCommand}
+}
+
+func runCommand(name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%w: %s", err, message)
+		}
+		return nil, err
+	}
+	return output, nil
+}
+
+func (s *CommandStore) Get(name string) (string, error) {
+	fields := strings.Fields(s.command)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("secret command is empty")
+	}
+	for i := range fields {
+		fields[i] = strings.ReplaceAll(fields[i], "{name}", name)
+	}
+
+	output, err := s.run(fields[0], fields[1:]...)
+	if err != nil {
+		return "", fmt.Errorf("secret command failed for %s: %w", name, err)
+	}
+	// pass は複数行（1行目がパスワード）を返すため、先頭行のみを使用する
+	value, _, _ := strings.Cut(string(output), "\n")
+	value = strings.TrimRight(value, "\r")
+	if value == "" {
+		return "", fmt.Errorf("%w: %s (command returned empty output)", ErrNotFound, name)
+	}
+	return value, nil
+}
+
+func (s *CommandStore) Set(name, value string) error {
+	return fmt.Errorf("%w: store %s with your password manager instead", ErrReadOnly, name)
+}
+
+func (s *CommandStore) Delete(name string) error {
+	return fmt.Errorf("%w: remove %s with your password manager instead", ErrReadOnly, name)
+}
+
+func (s *CommandStore) List() ([]string, error) {
+	return nil, fmt.Errorf("%w: listing is not supported by the command backend", ErrReadOnly)
+}
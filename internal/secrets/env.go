@@ -0,0 +1,66 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultEnvPrefix is the prefix used by EnvStore when none is configured.
+const DefaultEnvPrefix = "WORKLOGR_SECRET_"
+
+// EnvStore は環境変数からシークレットを読み取ります（例: secret://slack → WORKLOGR_SECRET_SLACK）
+type EnvStore struct {
+	prefix string
+}
+
+// NewEnvStore は新しい環境変数ストアを作成します
+func NewEnvStore(prefix string) *EnvStore {
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+	return &EnvStore{prefix: prefix}
+}
+
+// VariableName returns the environment variable consulted for a secret name.
+func (s *EnvStore) VariableName(name string) string {
+	var builder strings.Builder
+	builder.WriteString(s.prefix)
+	for _, r := range strings.ToUpper(name) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			builder.WriteRune(r)
+		} else {
+			builder.WriteRune('_')
+		}
+	}
+	return builder.String()
+}
+
+func (s *EnvStore) Get(name string) (string, error) {
+	value, ok := os.LookupEnv(s.VariableName(name))
+	if !ok || value == "" {
+		return "", fmt.Errorf("%w: %s (set %s)", ErrNotFound, name, s.VariableName(name))
+	}
+	return value, nil
+}
+
+func (s *EnvStore) Set(name, value string) error {
+	return fmt.Errorf("%w: set %s in your environment instead", ErrReadOnly, s.VariableName(name))
+}
+
+func (s *EnvStore) Delete(name string) error {
+	return fmt.Errorf("%w: unset %s in your environment instead", ErrReadOnly, s.VariableName(name))
+}
+
+func (s *EnvStore) List() ([]string, error) {
+	var names []string
+	for _, entry := range os.Environ() {
+		key, _, _ := strings.Cut(entry, "=")
+		if strings.HasPrefix(key, s.prefix) && len(key) > len(s.prefix) {
+			names = append(names, strings.ToLower(strings.TrimPrefix(key, s.prefix)))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
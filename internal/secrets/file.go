@@ -0,0 +1,233 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// KDFParams are the scrypt parameters stored alongside encrypted data.
+type KDFParams struct {
+	Algorithm string `json:"algorithm"`
+	Salt      string `json:"salt"`
+	N         int    `json:"n"`
+	R         int    `json:"r"`
+	P         int    `json:"p"`
+}
+
+// DefaultKDFParams returns fresh scrypt parameters with a random salt.
+func DefaultKDFParams() (KDFParams, error) {
+	salt := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return KDFParams{}, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return KDFParams{
+		Algorithm: "scrypt",
+		Salt:      base64.StdEncoding.EncodeToString(salt),
+		N:         1 << 15,
+		R:         8,
+		P:         1,
+	}, nil
+}
+
+// DeriveKey derives a 32-byte AES key from the passphrase.
+func (p KDFParams) DeriveKey(passphrase string) ([]byte, error) {
+	if p.Algorithm != "scrypt" {
+		return nil, fmt.Errorf("unsupported key derivation: %s", p.Algorithm)
+	}
+	salt, err := base64.StdEncoding.DecodeString(p.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt: %w", err)
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, p.N, p.R, p.P, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return key, nil
+}
+
+// Seal encrypts data with AES-GCM, prefixing the random nonce.
+func Seal(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+// Unseal decrypts data produced by Seal.
+func Unseal(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// secretFile is the on-disk format of FileStore
+type secretFile struct {
+	Version int       `json:"version"`
+	KDF     KDFParams `json:"kdf"`
+	// Check is a sealed known value used to detect a wrong passphrase before writing
+	Check   string            `json:"check"`
+	Secrets map[string]string `json:"secrets"`
+}
+
+// passphraseCheck is the plaintext sealed into secretFile.Check
+const passphraseCheck = "worklogr-secrets"
+
+// FileStore はパスフレーズから scrypt で導出した鍵で暗号化したファイルにシークレットを保存します
+type FileStore struct {
+	path       string
+	passphrase string
+	// newParams は新規ファイル作成時のKDFパラメータを返します（テストで差し替え可能）
+	newParams func() (KDFParams, error)
+}
+
+// NewFileStore は新しいファイルストアを作成します
+func NewFileStore(path, passphrase string) *FileStore {
+	return &FileStore{path: path, passphrase: passphrase, newParams: DefaultKDFParams}
+}
+
+func (s *FileStore) Get(name string) (string, error) {
+	file, key, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	encoded, ok := file.Secrets[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode secret %s: %w", name, err)
+	}
+	plain, err := Unseal(key, sealed)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret %s: %w", name, err)
+	}
+	return string(plain), nil
+}
+
+func (s *FileStore) Set(name, value string) error {
+	file, key, err := s.load()
+	if err != nil {
+		return err
+	}
+	sealed, err := Seal(key, []byte(value))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt secret %s: %w", name, err)
+	}
+	file.Secrets[name] = base64.StdEncoding.EncodeToString(sealed)
+	return s.save(file)
+}
+
+func (s *FileStore) Delete(name string) error {
+	file, _, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := file.Secrets[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	delete(file.Secrets, name)
+	return s.save(file)
+}
+
+func (s *FileStore) List() ([]string, error) {
+	file, _, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(file.Secrets))
+	for name := range file.Secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// load はファイルを読み込み、鍵を導出します。ファイルが無い場合は新しいKDFパラメータで初期化します。
+func (s *FileStore) load() (*secretFile, []byte, error) {
+	file := &secretFile{Version: 1, Secrets: map[string]string{}}
+
+	data, err := os.ReadFile(s.path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, file); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse secret file: %w", err)
+		}
+		if file.Secrets == nil {
+			file.Secrets = map[string]string{}
+		}
+	case os.IsNotExist(err):
+		params, err := s.newParams()
+		if err != nil {
+			return nil, nil, err
+		}
+		file.KDF = params
+	default:
+		return nil, nil, fmt.Errorf("failed to read secret file: %w", err)
+	}
+
+	key, err := file.KDF.DeriveKey(s.passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if file.Check == "" {
+		sealed, err := Seal(key, []byte(passphraseCheck))
+		if err != nil {
+			return nil, nil, err
+		}
+		file.Check = base64.StdEncoding.EncodeToString(sealed)
+	} else {
+		sealed, err := base64.StdEncoding.DecodeString(file.Check)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid secret file check value: %w", err)
+		}
+		if plain, err := Unseal(key, sealed); err != nil || string(plain) != passphraseCheck {
+			return nil, nil, fmt.Errorf("wrong passphrase for secret file %s", s.path)
+		}
+	}
+	return file, key, nil
+}
+
+func (s *FileStore) save(file *secretFile) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create secret directory: %w", err)
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal secret file: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write secret file: %w", err)
+	}
+	return nil
+}
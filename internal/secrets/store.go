@@ -0,0 +1,99 @@
+// Package secrets stores service credentials outside the plaintext config file.
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RefPrefix is the scheme used in config values that point at a stored secret (e.g. "secret://slack").
+const RefPrefix = "secret://"
+
+// Backend names accepted in Options.Backend.
+const (
+	BackendFile    = "file"
+	BackendEnv     = "env"
+	BackendCommand = "command"
+)
+
+// DefaultPassphraseEnv is the environment variable holding the file backend passphrase.
+const DefaultPassphraseEnv = "WORKLOGR_SECRET_PASSPHRASE"
+
+// ErrNotFound is returned when a secret does not exist in the store.
+var ErrNotFound = errors.New("secret not found")
+
+// ErrReadOnly is returned by backends that cannot write secrets.
+var ErrReadOnly = errors.New("secret backend is read-only")
+
+// Store はシークレットの保存先を抽象化します
+type Store interface {
+	Get(name string) (string, error)
+	Set(name, value string) error
+	Delete(name string) error
+	List() ([]string, error)
+}
+
+// Options はシークレットストアの設定です
+type Options struct {
+	// Backend は file（デフォルト）, env, command のいずれかです
+	Backend string
+	// FilePath は file バックエンドの保存先です（デフォルト: ~/.worklogr/secrets.json）
+	FilePath string
+	// PassphraseEnv は file バックエンドのパスフレーズを保持する環境変数名です
+	PassphraseEnv string
+	// EnvPrefix は env バックエンドで参照する環境変数のプレフィックスです（デフォルト: WORKLOGR_SECRET_）
+	EnvPrefix string
+	// Command は command バックエンドで実行するコマンドです（{name} がシークレット名に置換されます）
+	Command string
+}
+
+// IsRef reports whether value is a secret reference such as "secret://slack".
+func IsRef(value string) bool {
+	return strings.HasPrefix(value, RefPrefix)
+}
+
+// RefName returns the secret name of a reference.
+func RefName(value string) string {
+	return strings.TrimPrefix(value, RefPrefix)
+}
+
+// Ref builds a reference for the given secret name.
+func Ref(name string) string {
+	return RefPrefix + name
+}
+
+// Open はオプションに応じたシークレットストアを作成します
+func Open(options Options) (Store, error) {
+	switch strings.ToLower(options.Backend) {
+	case BackendFile, "":
+		path := options.FilePath
+		if path == "" {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get home directory: %w", err)
+			}
+			path = filepath.Join(homeDir, ".worklogr", "secrets.json")
+		}
+		envName := options.PassphraseEnv
+		if envName == "" {
+			envName = DefaultPassphraseEnv
+		}
+		passphrase := os.Getenv(envName)
+		if passphrase == "" {
+			return nil, fmt.Errorf("passphrase for secret file is not set (export %s)", envName)
+		}
+		return NewFileStore(path, passphrase), nil
+	case BackendEnv:
+		return NewEnvStore(options.EnvPrefix), nil
+	case BackendCommand:
+		if options.Command == "" {
+			return nil, fmt.Errorf("secrets.command is required for backend %s", BackendCommand)
+		}
+		return NewCommandStore(options.Command), nil
+	default:
+		return nil, fmt.Errorf("unsupported secret backend: %s (supported: file, env, command)", options.Backend)
+	}
+}
@@ -3,14 +3,14 @@ package services
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"time"
 
+	"github.com/iriam/worklogr/internal/auth"
 	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/eventmeta"
 	"github.com/iriam/worklogr/internal/utils"
-	"golang.org/x/oauth2/google"
 	"google.golang.org/api/calendar/v3"
 	"google.golang.org/api/drive/v3"
 	"google.golang.org/api/option"
@@ -30,7 +30,8 @@ type CalendarClient struct {
 	options         config.GoogleCalendarOptions
 }
 
-// NewCalendarClient はgcloud認証を使用して新しいGoogle Calendarクライアントを作成します
+// NewCalendarClient はgcloud認証（または google_calendar_options.credentials_file）を使用して
+// 新しいGoogle Calendarクライアントを作成します
 func NewCalendarClient(cfg *config.Config) (*CalendarClient, error) {
 	return NewCalendarClientWithGCloud(cfg)
 }
@@ -39,27 +40,31 @@ func NewCalendarClient(cfg *config.Config) (*CalendarClient, error) {
 func NewCalendarClientWithGCloud(cfg *config.Config) (*CalendarClient, error) {
 	ctx := context.Background()
 	
-	// まずgcloud認証の使用を試行
-	creds, err := google.FindDefaultCredentials(ctx, 
+	// 認証情報ファイルが設定されていればそれを、なければgcloud認証（ADC）を使用
+	var source auth.GoogleCredentialSource
+	if cfg != nil {
+		source = auth.GoogleCredentialSource{
+			CredentialsFile: cfg.GoogleCalendarOptions.CredentialsFile,
+			Subject:         cfg.GoogleCalendarOptions.ImpersonateUser,
+		}
+	}
+	creds, err := source.Credentials(ctx,
 		calendar.CalendarReadonlyScope,
 		calendar.CalendarEventsReadonlyScope,
 		drive.DriveReadonlyScope,
 	)
-	
-	var service *calendar.Service
-	if err == nil {
-		// gcloud認証情報を使用
-		service, err = calendar.NewService(ctx, option.WithCredentials(creds))
-		if err != nil {
-			return nil, fmt.Errorf("gcloud認証でカレンダーサービスの作成に失敗しました: %w", err)
-		}
-	} else {
-		return nil, fmt.Errorf("gcloud認証が利用できません。'gcloud auth application-default login'を実行してください: %w", err)
+	if err != nil {
+		return nil, fmt.Errorf("google認証情報を取得できません: %w", err)
+	}
+
+	service, err := calendar.NewService(ctx, option.WithCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("カレンダーサービスの作成に失敗しました: %w", err)
 	}
 
 	driveService, err := drive.NewService(ctx, option.WithCredentials(creds))
 	if err != nil {
-		return nil, fmt.Errorf("gcloud認証でDriveサービスの作成に失敗しました: %w", err)
+		return nil, fmt.Errorf("Driveサービスの作成に失敗しました: %w", err)
 	}
 
 	// 認証を確認するためユーザーのプライマリカレンダーを取得
@@ -388,97 +393,70 @@ func (cc *CalendarClient) getEventUpdater(event *calendar.Event) string {
 
 // createEventMetadata creates metadata for a calendar event
 func (cc *CalendarClient) createEventMetadata(cal *calendar.CalendarListEntry, event *calendar.Event, startTime, endTime time.Time, action string, attachments []config.EventAttachment) string {
-	metadata := map[string]interface{}{
-		"calendar_id":   cal.Id,
-		"calendar_name": cal.Summary,
-		"event_id":      event.Id,
-		"action":        action,
-		"start_time":    startTime.Format(time.RFC3339),
-		"end_time":      endTime.Format(time.RFC3339),
-		"location":      event.Location,
-		"description":   event.Description,
-		"html_link":     event.HtmlLink,
+	metadata := &eventmeta.CalendarEvent{
+		CalendarID:   cal.Id,
+		CalendarName: cal.Summary,
+		EventID:      event.Id,
+		Action:       action,
+		StartTime:    startTime,
+		EndTime:      endTime,
+		Location:     event.Location,
+		Description:  event.Description,
+		HTMLLink:     event.HtmlLink,
+		EventType:    event.EventType,
+		Visibility:   event.Visibility,
 	}
 
 	// Attachments are stored separately; keep only lightweight references in metadata.
-	if len(attachments) > 0 {
-		var refs []map[string]interface{}
-		for _, a := range attachments {
-			refs = append(refs, map[string]interface{}{
-				"file_id":   a.FileID,
-				"title":     a.Title,
-				"mime_type": a.MimeType,
-				"truncated": a.Truncated,
-			})
-		}
-		metadata["gemini_note_files"] = refs
+	for _, a := range attachments {
+		metadata.GeminiNoteFiles = append(metadata.GeminiNoteFiles, eventmeta.NoteFile{
+			FileID:    a.FileID,
+			Title:     a.Title,
+			MimeType:  a.MimeType,
+			Truncated: a.Truncated,
+		})
 	}
 
 	// Add attendee information
-	if len(event.Attendees) > 0 {
-		var attendees []map[string]interface{}
-		for _, attendee := range event.Attendees {
-			attendeeInfo := map[string]interface{}{
-				"email":           attendee.Email,
-				"response_status": attendee.ResponseStatus,
-			}
-			if attendee.DisplayName != "" {
-				attendeeInfo["name"] = attendee.DisplayName
-			}
-			attendees = append(attendees, attendeeInfo)
-		}
-		metadata["attendees"] = attendees
-		metadata["attendee_count"] = len(attendees)
+	for _, attendee := range event.Attendees {
+		metadata.Attendees = append(metadata.Attendees, eventmeta.Attendee{
+			Email:          attendee.Email,
+			Name:           attendee.DisplayName,
+			ResponseStatus: attendee.ResponseStatus,
+		})
 	}
+	metadata.AttendeeCount = len(metadata.Attendees)
 
 	// Add organizer information
 	if event.Organizer != nil {
-		metadata["organizer"] = map[string]interface{}{
-			"email": event.Organizer.Email,
-			"name":  event.Organizer.DisplayName,
+		metadata.Organizer = &eventmeta.Person{
+			Email: event.Organizer.Email,
+			Name:  event.Organizer.DisplayName,
 		}
 	}
 
 	// Add recurrence information
-	if len(event.Recurrence) > 0 {
-		metadata["recurring"] = true
-		metadata["recurrence_rules"] = event.Recurrence
-	} else {
-		metadata["recurring"] = false
-	}
+	metadata.Recurring = len(event.Recurrence) > 0
+	metadata.RecurrenceRules = event.Recurrence
 
 	// Add meeting information
-	if event.ConferenceData != nil && len(event.ConferenceData.EntryPoints) > 0 {
-		var meetingLinks []string
+	if event.ConferenceData != nil {
 		for _, entryPoint := range event.ConferenceData.EntryPoints {
 			if entryPoint.Uri != "" {
-				meetingLinks = append(meetingLinks, entryPoint.Uri)
+				metadata.MeetingLinks = append(metadata.MeetingLinks, entryPoint.Uri)
 			}
 		}
-		if len(meetingLinks) > 0 {
-			metadata["meeting_links"] = meetingLinks
-		}
 	}
 
-	// Add event type information
-	if event.EventType != "" {
-		metadata["event_type"] = event.EventType
-	}
-
-	// Add visibility
-	metadata["visibility"] = event.Visibility
-
 	// Add all-day event flag
-	metadata["all_day"] = (event.Start.Date != "" && event.End.Date != "")
+	metadata.AllDay = event.Start.Date != "" && event.End.Date != ""
 
 	// Calculate duration
 	if !startTime.IsZero() && !endTime.IsZero() {
-		duration := endTime.Sub(startTime)
-		metadata["duration_minutes"] = int(duration.Minutes())
+		metadata.DurationMinutes = int(endTime.Sub(startTime).Minutes())
 	}
 
-	data, _ := json.Marshal(metadata)
-	return string(data)
+	return eventmeta.Encode(metadata)
 }
 
 func (cc *CalendarClient) fetchDriveDocAttachments(attachments []*calendar.EventAttachment) []config.EventAttachment {
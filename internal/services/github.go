@@ -2,7 +2,6 @@ package services
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -10,6 +9,7 @@ import (
 	"github.com/google/go-github/v45/github"
 	"github.com/iriam/worklogr/internal/auth"
 	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/eventmeta"
 	"github.com/iriam/worklogr/internal/utils"
 	"golang.org/x/oauth2"
 )
@@ -141,9 +141,11 @@ func (gc *GitHubClient) GetAuthStatus() *auth.AuthStatus {
 	return gc.authManager.GetAuthStatus()
 }
 
-// CollectGitHubEvents はSearch APIを使用して指定された時間範囲内でGitHubからイベントを収集します
+// CollectGitHubEvents はSearch APIを使用して指定された時間範囲内でGitHubからイベントを収集します。
+// 一部の検索に失敗した場合は、取得できたイベントと *PartialCollectionError を返します。
 func (gc *GitHubClient) CollectGitHubEvents(startTime, endTime time.Time) ([]*config.Event, error) {
 	var events []*config.Event
+	var failures partialFailures
 
 	githubLogger.Infof("%s から %s までGitHubイベントを収集します",
 		startTime.Format("2006-01-02 15:04:05"), endTime.Format("2006-01-02 15:04:05"))
@@ -161,6 +163,7 @@ func (gc *GitHubClient) CollectGitHubEvents(startTime, endTime time.Time) ([]*co
 	commits, err := gc.searchCommits(startDate, endDate)
 	if err != nil {
 		githubLogger.Warnf("コミット検索に失敗しました: %v", err)
+		failures.add("コミット検索: %v", err)
 	} else {
 		githubLogger.Infof("コミットを %d 件取得しました", len(commits))
 		events = append(events, commits...)
@@ -171,6 +174,7 @@ func (gc *GitHubClient) CollectGitHubEvents(startTime, endTime time.Time) ([]*co
 	issues, err := gc.searchIssues(startDate, endDate)
 	if err != nil {
 		githubLogger.Warnf("Issue検索に失敗しました: %v", err)
+		failures.add("Issue検索: %v", err)
 	} else {
 		githubLogger.Infof("Issueを %d 件取得しました", len(issues))
 		events = append(events, issues...)
@@ -181,6 +185,7 @@ func (gc *GitHubClient) CollectGitHubEvents(startTime, endTime time.Time) ([]*co
 	prs, err := gc.searchPullRequests(startDate, endDate)
 	if err != nil {
 		githubLogger.Warnf("プルリクエスト検索に失敗しました: %v", err)
+		failures.add("プルリクエスト検索: %v", err)
 	} else {
 		githubLogger.Infof("プルリクエストを %d 件取得しました", len(prs))
 		events = append(events, prs...)
@@ -188,16 +193,18 @@ func (gc *GitHubClient) CollectGitHubEvents(startTime, endTime time.Time) ([]*co
 
 	// 検索を使用してPRレビューを収集
 	githubLogger.Infof("ユーザー '%s' のPRレビューを検索します", gc.user)
-	reviews, err := gc.searchPRReviews(startDate, endDate)
+	reviews, reviewFailures, err := gc.searchPRReviews(startDate, endDate)
 	if err != nil {
 		githubLogger.Warnf("PRレビュー検索に失敗しました: %v", err)
+		failures.add("PRレビュー検索: %v", err)
 	} else {
 		githubLogger.Infof("PRレビューを %d 件取得しました", len(reviews))
 		events = append(events, reviews...)
+		failures = append(failures, reviewFailures...)
 	}
 
 	githubLogger.Infof("GitHubイベント収集完了: 合計 %d 件", len(events))
-	return events, nil
+	return events, failures.err()
 }
 
 // getUserRepositories gets repositories for the authenticated user
@@ -512,81 +519,70 @@ func (gc *GitHubClient) collectReleases(repo *github.Repository, startTime, endT
 
 // createCommitMetadata creates metadata for a commit event
 func (gc *GitHubClient) createCommitMetadata(repo *github.Repository, commit *github.RepositoryCommit) string {
-	metadata := map[string]interface{}{
-		"repository":    repo.GetFullName(),
-		"sha":           commit.GetSHA(),
-		"url":           commit.GetHTMLURL(),
-		"additions":     commit.GetStats().GetAdditions(),
-		"deletions":     commit.GetStats().GetDeletions(),
-		"changed_files": len(commit.Files),
-	}
-
-	data, _ := json.Marshal(metadata)
-	return string(data)
+	return eventmeta.Encode(&eventmeta.GitHubCommit{
+		Repository:   repo.GetFullName(),
+		SHA:          commit.GetSHA(),
+		URL:          commit.GetHTMLURL(),
+		Additions:    commit.GetStats().GetAdditions(),
+		Deletions:    commit.GetStats().GetDeletions(),
+		ChangedFiles: len(commit.Files),
+	})
 }
 
 // createPRMetadata creates metadata for a pull request event
 func (gc *GitHubClient) createPRMetadata(repo *github.Repository, pr *github.PullRequest, action string) string {
-	metadata := map[string]interface{}{
-		"repository": repo.GetFullName(),
-		"number":     pr.GetNumber(),
-		"action":     action,
-		"url":        pr.GetHTMLURL(),
-		"state":      pr.GetState(),
-		"base":       pr.GetBase().GetRef(),
-		"head":       pr.GetHead().GetRef(),
-		"additions":  pr.GetAdditions(),
-		"deletions":  pr.GetDeletions(),
-		"commits":    pr.GetCommits(),
-	}
-
-	data, _ := json.Marshal(metadata)
-	return string(data)
+	return eventmeta.Encode(&eventmeta.GitHubPullRequest{
+		Repository: repo.GetFullName(),
+		Number:     pr.GetNumber(),
+		Action:     action,
+		URL:        pr.GetHTMLURL(),
+		State:      pr.GetState(),
+		Base:       pr.GetBase().GetRef(),
+		Head:       pr.GetHead().GetRef(),
+		Additions:  pr.GetAdditions(),
+		Deletions:  pr.GetDeletions(),
+		Commits:    pr.GetCommits(),
+	})
 }
 
 // createPRReviewMetadata creates metadata for a pull request review event
 func (gc *GitHubClient) createPRReviewMetadata(repo *github.Repository, pr *github.PullRequest, review *github.PullRequestReview) string {
-	metadata := map[string]interface{}{
-		"repository":   repo.GetFullName(),
-		"pr_number":    pr.GetNumber(),
-		"review_id":    review.GetID(),
-		"review_state": review.GetState(),
-		"url":          review.GetHTMLURL(),
-		"pr_url":       pr.GetHTMLURL(),
-	}
-
-	data, _ := json.Marshal(metadata)
-	return string(data)
+	return eventmeta.Encode(&eventmeta.GitHubReview{
+		Repository:  repo.GetFullName(),
+		PRNumber:    pr.GetNumber(),
+		ReviewID:    review.GetID(),
+		ReviewState: review.GetState(),
+		URL:         review.GetHTMLURL(),
+		PRURL:       pr.GetHTMLURL(),
+	})
 }
 
 // createIssueMetadata creates metadata for an issue event
 func (gc *GitHubClient) createIssueMetadata(repo *github.Repository, issue *github.Issue, action string) string {
-	metadata := map[string]interface{}{
-		"repository": repo.GetFullName(),
-		"number":     issue.GetNumber(),
-		"action":     action,
-		"url":        issue.GetHTMLURL(),
-		"state":      issue.GetState(),
-		"labels":     issue.Labels,
+	var labels eventmeta.Labels
+	for _, label := range issue.Labels {
+		labels = append(labels, label.GetName())
 	}
-
-	data, _ := json.Marshal(metadata)
-	return string(data)
+	return eventmeta.Encode(&eventmeta.GitHubIssue{
+		Repository: repo.GetFullName(),
+		Number:     issue.GetNumber(),
+		Action:     action,
+		URL:        issue.GetHTMLURL(),
+		State:      issue.GetState(),
+		Labels:     labels,
+	})
 }
 
 // createReleaseMetadata creates metadata for a release event
 func (gc *GitHubClient) createReleaseMetadata(repo *github.Repository, release *github.RepositoryRelease) string {
-	metadata := map[string]interface{}{
-		"repository": repo.GetFullName(),
-		"tag_name":   release.GetTagName(),
-		"name":       release.GetName(),
-		"url":        release.GetHTMLURL(),
-		"prerelease": release.GetPrerelease(),
-		"draft":      release.GetDraft(),
-	}
-
-	data, _ := json.Marshal(metadata)
-	return string(data)
+	return eventmeta.Encode(&eventmeta.GitHubRelease{
+		Repository: repo.GetFullName(),
+		TagName:    release.GetTagName(),
+		Name:       release.GetName(),
+		URL:        release.GetHTMLURL(),
+		Prerelease: release.GetPrerelease(),
+		Draft:      release.GetDraft(),
+	})
 }
 
 // Search API functions for efficient data collection
@@ -684,9 +680,11 @@ func (gc *GitHubClient) searchPullRequests(startDate, endDate string) ([]*config
 	return events, nil
 }
 
-// searchPRReviews searches for PR reviews using GitHub Search API
-func (gc *GitHubClient) searchPRReviews(startDate, endDate string) ([]*config.Event, error) {
+// searchPRReviews searches for PR reviews using GitHub Search API.
+// PRs whose reviews could not be fetched are returned as failures.
+func (gc *GitHubClient) searchPRReviews(startDate, endDate string) ([]*config.Event, partialFailures, error) {
 	var events []*config.Event
+	var failures partialFailures
 
 	// Search for PRs reviewed by user
 	query := fmt.Sprintf("reviewed-by:%s type:pr updated:%s..%s", gc.user, startDate, endDate)
@@ -703,7 +701,7 @@ func (gc *GitHubClient) searchPRReviews(startDate, endDate string) ([]*config.Ev
 	for {
 		result, resp, err := gc.client.Search.Issues(gc.ctx, query, opt)
 		if err != nil {
-			return nil, fmt.Errorf("failed to search PR reviews: %w", err)
+			return nil, nil, fmt.Errorf("failed to search PR reviews: %w", err)
 		}
 
 		totalPRs += len(result.Issues)
@@ -736,6 +734,7 @@ func (gc *GitHubClient) searchPRReviews(startDate, endDate string) ([]*config.Ev
 				reviews, err := gc.getPRReviewsInDateRange(repoInfo.Owner, repoInfo.Name, issue.GetNumber(), startDate, endDate)
 				if err != nil {
 					githubLogger.Warnf("PR #%d のレビュー取得に失敗しました: %v", issue.GetNumber(), err)
+					failures.add("%s のレビュー取得: %v", prKey, err)
 					continue
 				}
 				events = append(events, reviews...)
@@ -748,7 +747,7 @@ func (gc *GitHubClient) searchPRReviews(startDate, endDate string) ([]*config.Ev
 		opt.Page = resp.NextPage
 	}
 
-	return events, nil
+	return events, failures, nil
 }
 
 // Helper functions for search
@@ -932,51 +931,39 @@ func (gc *GitHubClient) getActionTitle(eventType string) string {
 // Simplified metadata functions (without detailed stats)
 
 func (gc *GitHubClient) createSimpleCommitMetadata(commit *github.CommitResult) string {
-	metadata := map[string]interface{}{
-		"repository": commit.Repository.GetFullName(),
-		"sha":        commit.GetSHA(),
-		"url":        commit.GetHTMLURL(),
-	}
-
-	data, _ := json.Marshal(metadata)
-	return string(data)
+	return eventmeta.Encode(&eventmeta.GitHubCommit{
+		Repository: commit.Repository.GetFullName(),
+		SHA:        commit.GetSHA(),
+		URL:        commit.GetHTMLURL(),
+	})
 }
 
 func (gc *GitHubClient) createSimpleIssueMetadata(issue *github.Issue, repoName, action string) string {
-	metadata := map[string]interface{}{
-		"repository": repoName,
-		"number":     issue.GetNumber(),
-		"action":     action,
-		"url":        issue.GetHTMLURL(),
-		"state":      issue.GetState(),
-	}
-
-	data, _ := json.Marshal(metadata)
-	return string(data)
+	return eventmeta.Encode(&eventmeta.GitHubIssue{
+		Repository: repoName,
+		Number:     issue.GetNumber(),
+		Action:     action,
+		URL:        issue.GetHTMLURL(),
+		State:      issue.GetState(),
+	})
 }
 
 func (gc *GitHubClient) createSimplePRMetadata(issue *github.Issue, repoName, action string) string {
-	metadata := map[string]interface{}{
-		"repository": repoName,
-		"number":     issue.GetNumber(),
-		"action":     action,
-		"url":        issue.GetHTMLURL(),
-		"state":      issue.GetState(),
-	}
-
-	data, _ := json.Marshal(metadata)
-	return string(data)
+	return eventmeta.Encode(&eventmeta.GitHubPullRequest{
+		Repository: repoName,
+		Number:     issue.GetNumber(),
+		Action:     action,
+		URL:        issue.GetHTMLURL(),
+		State:      issue.GetState(),
+	})
 }
 
 func (gc *GitHubClient) createSimpleReviewMetadata(review *github.PullRequestReview, repoName string, prNumber int) string {
-	metadata := map[string]interface{}{
-		"repository":   repoName,
-		"pr_number":    prNumber,
-		"review_id":    review.GetID(),
-		"review_state": review.GetState(),
-		"url":          review.GetHTMLURL(),
-	}
-
-	data, _ := json.Marshal(metadata)
-	return string(data)
+	return eventmeta.Encode(&eventmeta.GitHubReview{
+		Repository:  repoName,
+		PRNumber:    prNumber,
+		ReviewID:    review.GetID(),
+		ReviewState: review.GetState(),
+		URL:         review.GetHTMLURL(),
+	})
 }
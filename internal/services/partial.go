@@ -0,0 +1,32 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PartialCollectionError は収集の一部（検索やページの取得など）に失敗したことを表します。
+// 一緒に返されたイベントは保存してかまいませんが、結果が完全ではないため、
+// 今回返されなかった保存済みイベントを削除済みとみなしてはいけません。
+type PartialCollectionError struct {
+	Failures []string
+}
+
+func (e *PartialCollectionError) Error() string {
+	return fmt.Sprintf("一部の取得に失敗しました: %s", strings.Join(e.Failures, "; "))
+}
+
+// partialFailures は収集中に失敗した取得を記録します
+type partialFailures []string
+
+func (f *partialFailures) add(format string, args ...interface{}) {
+	*f = append(*f, fmt.Sprintf(format, args...))
+}
+
+// err は失敗があれば PartialCollectionError を、なければ nil を返します
+func (f partialFailures) err() error {
+	if len(f) == 0 {
+		return nil
+	}
+	return &PartialCollectionError{Failures: f}
+}
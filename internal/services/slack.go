@@ -2,13 +2,13 @@ package services
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/iriam/worklogr/internal/auth"
 	"github.com/iriam/worklogr/internal/config"
+	"github.com/iriam/worklogr/internal/eventmeta"
 	"github.com/iriam/worklogr/internal/utils"
 	"github.com/slack-go/slack"
 )
@@ -142,7 +142,8 @@ func (sc *SlackClient) GetAuthStatus() *auth.AuthStatus {
 	return sc.authManager.GetAuthStatus()
 }
 
-// CollectSlackEvents は指定された時間範囲内でSlackからイベントを収集します
+// CollectSlackEvents は指定された時間範囲内でSlackからイベントを収集します。
+// 一部のページの取得に失敗した場合は、取得できたイベントと *PartialCollectionError を返します。
 func (sc *SlackClient) CollectSlackEvents(startTime, endTime time.Time) ([]*config.Event, error) {
 	var events []*config.Event
 
@@ -151,7 +152,7 @@ func (sc *SlackClient) CollectSlackEvents(startTime, endTime time.Time) ([]*conf
 
 	// 包括的なメッセージ収集のため検索ベースのアプローチを使用
 	slackLogger.Infof("検索ベース収集を使用します")
-	searchEvents, err := sc.collectMessagesViaSearch(startTime, endTime)
+	searchEvents, failures, err := sc.collectMessagesViaSearch(startTime, endTime)
 	if err != nil {
 		return nil, fmt.Errorf("検索ベースの収集に失敗しました: %w", err)
 	}
@@ -163,12 +164,14 @@ func (sc *SlackClient) CollectSlackEvents(startTime, endTime time.Time) ([]*conf
 	// Search API使用時は別途DM収集は不要です
 
 	slackLogger.Infof("Slackイベント収集完了: 合計 %d 件", len(events))
-	return events, nil
+	return events, failures.err()
 }
 
-// collectMessagesViaSearch はSlack Search APIを使用してユーザーメッセージを包括的に収集します
-func (sc *SlackClient) collectMessagesViaSearch(startTime, endTime time.Time) ([]*config.Event, error) {
+// collectMessagesViaSearch はSlack Search APIを使用してユーザーメッセージを包括的に収集します。
+// 取得に失敗したページは failures として返します。
+func (sc *SlackClient) collectMessagesViaSearch(startTime, endTime time.Time) ([]*config.Event, partialFailures, error) {
 	var events []*config.Event
+	var failures partialFailures
 
 	// タイムゾーンマネージャーを使用して設定されたタイムゾーンに時刻を変換
 	startTimeInTZ := sc.timezoneManager.ConvertToTimezone(startTime)
@@ -205,7 +208,7 @@ func (sc *SlackClient) collectMessagesViaSearch(startTime, endTime time.Time) ([
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("メッセージ検索に失敗しました: %w", err)
+		return nil, nil, fmt.Errorf("メッセージ検索に失敗しました: %w", err)
 	}
 
 	slackLogger.Infof("初回検索で %d 件のメッセージを取得しました", len(searchResult.Matches))
@@ -277,6 +280,7 @@ func (sc *SlackClient) collectMessagesViaSearch(startTime, endTime time.Time) ([
 
 			if err != nil {
 				slackLogger.Warnf("ページ %d の取得に失敗しました: %v", page, err)
+				failures.add("検索結果のページ %d: %v", page, err)
 				continue
 			}
 
@@ -326,19 +330,16 @@ func (sc *SlackClient) collectMessagesViaSearch(startTime, endTime time.Time) ([
 	}
 
 	slackLogger.Infof("検索収集完了: %d 件", len(events))
-	return events, nil
+	return events, failures, nil
 }
 
 // createSearchMessageMetadata は検索結果メッセージのメタデータを作成します
 func (sc *SlackClient) createSearchMessageMetadata(match slack.SearchMessage) string {
-	metadata := map[string]interface{}{
-		"channel_id":   match.Channel.ID,
-		"channel_name": match.Channel.Name,
-		"message_ts":   match.Timestamp,
-		"permalink":    match.Permalink,
-		"via_search":   true,
-	}
-
-	data, _ := json.Marshal(metadata)
-	return string(data)
+	return eventmeta.Encode(&eventmeta.SlackMessage{
+		ChannelID:   match.Channel.ID,
+		ChannelName: match.Channel.Name,
+		MessageTS:   match.Timestamp,
+		Permalink:   match.Permalink,
+		ViaSearch:   true,
+	})
 }
@@ -0,0 +1,177 @@
+// Package summary turns AI export payloads into markdown reports via an LLM provider.
+package summary
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/iriam/worklogr/internal/exporter"
+	"github.com/iriam/worklogr/internal/llm"
+)
+
+// DefaultSystemPrompt はLLMに渡すシステムプロンプトです
+const DefaultSystemPrompt = "あなたはエンジニアの作業ログから正確な業務報告を作成するアシスタントです。与えられたイベント以外の事実を推測で補わないでください。"
+
+// DefaultPromptTemplate はイベントJSONから報告を作成するデフォルトのテンプレートです
+const DefaultPromptTemplate = `以下は {{.Start}} から {{.End}} までの作業イベント（worklogr json-ai 形式）です。{{if gt .Parts 1}}（全{{.Parts}}分割中の{{.Part}}番目）{{end}}
+日ごとの作業内容、主な成果（PR・Issue・会議）、未完了の事項を Markdown の箇条書きでまとめてください。
+context.annotation は利用者が付けた注釈です。pinned のイベントは重要な成果として優先し、note はその補足として反映してください。
+
+{{.Data}}
+`
+
+// DefaultReduceTemplate は分割して要約した結果を1つの報告にまとめるテンプレートです
+const DefaultReduceTemplate = `以下は {{.Start}} から {{.End}} までの作業ログを{{.Parts}}分割して要約したものです。
+重複を除き、日ごとの作業内容、主な成果、未完了の事項を1つの Markdown 報告にまとめてください。
+
+{{.Data}}
+`
+
+// PromptData はプロンプトテンプレートに渡す値です
+type PromptData struct {
+	Start string
+	End   string
+	Part  int
+	Parts int
+	// Data はJSON（分割要約時は各パートの要約）です
+	Data string
+}
+
+// Summarizer はイベントを分割してLLMに送り、Markdown報告を作成します
+type Summarizer struct {
+	Provider       llm.Provider
+	System         string
+	PromptTemplate *template.Template
+	ReduceTemplate *template.Template
+	// MaxInputTokens は1リクエストあたりの推定トークン上限です
+	MaxInputTokens int
+}
+
+// Result は要約結果です
+type Result struct {
+	Markdown string
+	Requests int
+	Chunks   int
+}
+
+// NewSummarizer はデフォルトテンプレートを使用するSummarizerを作成します
+func NewSummarizer(provider llm.Provider, maxInputTokens int) *Summarizer {
+	return &Summarizer{
+		Provider:       provider,
+		System:         DefaultSystemPrompt,
+		PromptTemplate: template.Must(template.New("prompt").Parse(DefaultPromptTemplate)),
+		ReduceTemplate: template.Must(template.New("reduce").Parse(DefaultReduceTemplate)),
+		MaxInputTokens: maxInputTokens,
+	}
+}
+
+// LoadPromptTemplate はファイルからプロンプトテンプレートを読み込みます
+func LoadPromptTemplate(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt template: %w", err)
+	}
+	tmpl, err := template.New("prompt").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Summarize はAI用エクスポートデータを要約します。
+// データがトークン上限を超える場合はイベントを分割して要約し、最後に1つの報告にまとめます。
+func (s *Summarizer) Summarize(ctx context.Context, data *exporter.AIExportData, start, end time.Time) (*Result, error) {
+	startLabel := start.Format("2006-01-02 15:04")
+	endLabel := end.Format("2006-01-02 15:04")
+
+	// テンプレート本文とシステムプロンプトの分を差し引いた予算でイベントを分割する
+	overhead := exporter.EstimateTokens(s.System) + templateTokens(s.PromptTemplate) + 500
+	budget := s.MaxInputTokens - overhead
+	if s.MaxInputTokens > 0 && budget < 1000 {
+		budget = 1000
+	}
+	var groups [][]exporter.AIEvent
+	for _, chunk := range exporter.ChunkAIEvents(data.Events, exporter.ChunkOptions{MaxTokens: budget}) {
+		groups = append(groups, chunk.Events)
+	}
+	if len(groups) == 0 {
+		groups = [][]exporter.AIEvent{nil}
+	}
+
+	result := &Result{Chunks: len(groups)}
+	var partials []string
+	for i, group := range groups {
+		chunk := *data
+		chunk.Events = group
+		payload, err := json.MarshalIndent(&chunk, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal chunk %d: %w", i+1, err)
+		}
+
+		prompt, err := render(s.PromptTemplate, PromptData{
+			Start: startLabel,
+			End:   endLabel,
+			Part:  i + 1,
+			Parts: len(groups),
+			Data:  string(payload),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		text, err := s.Provider.Generate(ctx, llm.Request{System: s.System, Prompt: prompt})
+		result.Requests++
+		if err != nil {
+			return nil, fmt.Errorf("failed to summarize chunk %d/%d: %w", i+1, len(groups), err)
+		}
+		partials = append(partials, strings.TrimSpace(text))
+	}
+
+	if len(partials) == 1 {
+		result.Markdown = partials[0]
+		return result, nil
+	}
+
+	var combined strings.Builder
+	for i, partial := range partials {
+		fmt.Fprintf(&combined, "## パート %d\n\n%s\n\n", i+1, partial)
+	}
+	prompt, err := render(s.ReduceTemplate, PromptData{
+		Start: startLabel,
+		End:   endLabel,
+		Parts: len(partials),
+		Data:  combined.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := s.Provider.Generate(ctx, llm.Request{System: s.System, Prompt: prompt})
+	result.Requests++
+	if err != nil {
+		return nil, fmt.Errorf("failed to combine partial summaries: %w", err)
+	}
+	result.Markdown = strings.TrimSpace(text)
+	return result, nil
+}
+
+func render(tmpl *template.Template, data PromptData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func templateTokens(tmpl *template.Template) int {
+	if tmpl == nil || tmpl.Tree == nil || tmpl.Tree.Root == nil {
+		return 0
+	}
+	return exporter.EstimateTokens(tmpl.Tree.Root.String())
+}
@@ -0,0 +1,75 @@
+package summary
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iriam/worklogr/internal/exporter"
+	"github.com/iriam/worklogr/internal/llm"
+)
+
+type fakeProvider struct {
+	prompts []string
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) Generate(ctx context.Context, request llm.Request) (string, error) {
+	p.prompts = append(p.prompts, request.Prompt)
+	return fmt.Sprintf("summary %d", len(p.prompts)), nil
+}
+
+func sampleData(count int) *exporter.AIExportData {
+	data := &exporter.AIExportData{}
+	for i := 0; i < count; i++ {
+		data.Events = append(data.Events, exporter.AIEvent{
+			ID:      fmt.Sprintf("event-%d", i),
+			Service: "slack",
+			Content: strings.Repeat("x", 4000),
+		})
+	}
+	return data
+}
+
+func TestSummarizeSingleRequestWhenWithinBudget(t *testing.T) {
+	provider := &fakeProvider{}
+	summarizer := NewSummarizer(provider, 100000)
+
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	result, err := summarizer.Summarize(context.Background(), sampleData(2), start, start.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("Summarize returned error: %v", err)
+	}
+
+	if result.Requests != 1 || result.Chunks != 1 || result.Markdown != "summary 1" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if !strings.Contains(provider.prompts[0], "event-1") || !strings.Contains(provider.prompts[0], "2026-03-01 00:00") {
+		t.Fatalf("expected prompt to contain data and range, got: %.200s", provider.prompts[0])
+	}
+}
+
+func TestSummarizeSplitsAndCombinesWhenOverBudget(t *testing.T) {
+	provider := &fakeProvider{}
+	summarizer := NewSummarizer(provider, 2500)
+
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	result, err := summarizer.Summarize(context.Background(), sampleData(3), start, start.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("Summarize returned error: %v", err)
+	}
+
+	if result.Chunks != 3 || result.Requests != 4 {
+		t.Fatalf("expected 3 chunks and 4 requests, got %+v", result)
+	}
+	reducePrompt := provider.prompts[len(provider.prompts)-1]
+	if !strings.Contains(reducePrompt, "## パート 3") || !strings.Contains(reducePrompt, "summary 3") {
+		t.Fatalf("expected reduce prompt to include partial summaries, got: %s", reducePrompt)
+	}
+	if result.Markdown != "summary 4" {
+		t.Fatalf("unexpected markdown: %q", result.Markdown)
+	}
+}
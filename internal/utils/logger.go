@@ -32,8 +32,9 @@ func (l *Logger) Infof(format string, args ...interface{}) {
 }
 
 // Warnf は WARN レベルのログを出力します。
+// export -o - の出力に混ざらないよう、ERROR と同じく標準エラーに出力します。
 func (l *Logger) Warnf(format string, args ...interface{}) {
-	l.logf("WARN", os.Stdout, format, args...)
+	l.logf("WARN", os.Stderr, format, args...)
 }
 
 // Errorf は ERROR レベルのログを出力します。